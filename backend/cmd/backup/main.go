@@ -0,0 +1,140 @@
+// Command backup exporta e restaura os dados do AppMercado em um arquivo portátil (zip versionado).
+//
+//	go run ./cmd/backup export -out backup.zip                 # instância inteira
+//	go run ./cmd/backup export -out ana.zip -user ana@mail.com # apenas um usuário
+//	go run ./cmd/backup restore -in backup.zip [-dry-run] [-product-conflict overwrite]
+//	go run ./cmd/backup restore -in ana.zip -user ana@nova.com # dados de um usuário em outra conta
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/backup"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+)
+
+// A linha de comando tem acesso direto ao banco e age como administrador
+const adminRole = string(models.RoleAdmin)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "export":
+		runExport(os.Args[2:])
+	case "restore":
+		runRestore(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: backup export -out <arquivo.zip> [-user <email>]")
+	fmt.Fprintln(os.Stderr, "     backup restore -in <arquivo.zip> [-user <email>] [-dry-run] [-product-conflict keep|overwrite]")
+	os.Exit(2)
+}
+
+func newServices() (*services.BackupService, *repositories.BackupRepository) {
+	appConfig := config.Load()
+	database := repositories.NewPostgresConn(appConfig)
+	backupRepository := repositories.NewBackupRepository(database)
+	storeRepository := repositories.NewStoreRepository(database)
+	categoryRepository := repositories.NewCategoryRepository(database)
+	return services.NewBackupService(
+		backupRepository, storeRepository, categoryRepository, repositories.NewTransactionManager(database)), backupRepository
+}
+
+// findUserID resolve o e-mail informado em -user
+func findUserID(backupRepository *repositories.BackupRepository, email string) uint {
+	user, err := backupRepository.FindUserByEmail(email)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if user == nil {
+		log.Fatalf("usuário %s não encontrado", email)
+	}
+	return user.ID
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("out", "", "arquivo de destino (.zip)")
+	email := flags.String("user", "", "exporta apenas os dados deste usuário (e-mail)")
+	flags.Parse(args)
+	if *output == "" {
+		usage()
+	}
+
+	backupService, backupRepository := newServices()
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if *email == "" {
+		err = backupService.WriteInstanceBackup(file, adminRole)
+	} else {
+		err = backupService.WriteUserBackup(file, findUserID(backupRepository, *email), 0, adminRole)
+	}
+	if err != nil {
+		os.Remove(*output)
+		log.Fatal(err)
+	}
+	log.Printf("backup gravado em %s", *output)
+}
+
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := flags.String("in", "", "arquivo de backup (.zip)")
+	email := flags.String("user", "", "restaura um backup de usuário na conta deste e-mail")
+	dryRun := flags.Bool("dry-run", false, "valida e mostra o relatório sem gravar nada")
+	productConflict := flags.String("product-conflict", services.ProductConflictKeep, "keep ou overwrite")
+	flags.Parse(args)
+	if *input == "" {
+		usage()
+	}
+
+	backupService, backupRepository := newServices()
+
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+	archive, err := backup.NewReader(file, info.Size())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var targetUserID uint
+	if *email != "" {
+		targetUserID = findUserID(backupRepository, *email)
+	}
+
+	options := dto.RestoreOptionsDTO{DryRun: *dryRun, ProductConflict: *productConflict}
+	report, err := backupService.RestoreBackup(archive, options, targetUserID, 0, adminRole)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
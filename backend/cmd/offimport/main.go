@@ -0,0 +1,66 @@
+// Command offimport importa um dump do Open Food Facts para o catálogo de produtos.
+//
+//	go run ./cmd/offimport -file data/imports/products.csv.gz -country brazil
+//	go run ./cmd/offimport -resume 12
+//
+// O progresso é gravado a cada lote; uma importação interrompida (Ctrl+C, queda do servidor)
+// pode ser retomada com -resume <id>.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+)
+
+func main() {
+	file := flag.String("file", "", "caminho do dump (.csv, .tsv ou .jsonl, opcionalmente .gz)")
+	format := flag.String("format", "", "formato do dump: csv ou jsonl (padrão: pela extensão)")
+	country := flag.String("country", "", "importa apenas produtos vendidos no país (ex.: brazil)")
+	batchSize := flag.Int("batch", 1000, "produtos gravados por lote")
+	resume := flag.Uint("resume", 0, "ID de uma importação a retomar do último checkpoint")
+	flag.Parse()
+
+	if *file == "" && *resume == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	appConfig := config.Load()
+	database := repositories.NewPostgresConn(appConfig)
+
+	catalogImportService := services.NewCatalogImportService(
+		repositories.NewImportJobRepository(database),
+		repositories.NewProductRepository(database),
+		appConfig.CatalogImportDir)
+
+	jobID := *resume
+	if jobID == 0 {
+		job, err := catalogImportService.CreateOpenFoodFactsJob(*file, *format, *country, *batchSize, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		jobID = job.ID
+		log.Printf("importação %d criada para %s", job.ID, job.Source)
+	}
+
+	// Ctrl+C cancela após o lote atual; a importação pode ser retomada depois com -resume
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := catalogImportService.RunImportJob(ctx, jobID, func(job *models.ImportJob) {
+		response := catalogImportService.ToImportJobResponseDTO(job)
+		log.Printf("[%s] %.2f%% lidos=%d importados=%d ignorados=%d erros=%d",
+			response.Status, response.Progress, job.ProcessedRows, job.ImportedRows, job.SkippedRows, job.ErrorRows)
+	})
+	if err != nil {
+		log.Fatalf("importação %d interrompida: %v (retome com -resume %d)", jobID, err, jobID)
+	}
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"log"
+	"time"
+	_ "time/tzdata" // TIME_ZONE funciona mesmo em imagens sem a base de fusos do sistema
+
+	"github.com/Parron01/AppMercado/backend/internal/graph"
 	"github.com/Parron01/AppMercado/backend/internal/handlers"
 	"github.com/Parron01/AppMercado/backend/internal/repositories"
 	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
 	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/Parron01/AppMercado/backend/pkg/receipt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -23,23 +31,106 @@ func main() {
 	purchaseRepository := repositories.NewPurchaseRepository(database)
 	priceHistoryRepository := repositories.NewPriceHistoryRepository(database)
 	userCategoryProductRepository := repositories.NewUserCategoryProductRepository(database)
+	budgetRepository := repositories.NewBudgetRepository(database)
+	householdRepository := repositories.NewHouseholdRepository(database)
+	importJobRepository := repositories.NewImportJobRepository(database)
+	backupRepository := repositories.NewBackupRepository(database)
+	outboxRepository := repositories.NewOutboxRepository(database)
+	webhookRepository := repositories.NewWebhookRepository(database)
+	storeRepository := repositories.NewStoreRepository(database)
+	transactionManager := repositories.NewTransactionManager(database)
+
+	// Leiaute padrão das etiquetas de balança, usado pelos estabelecimentos sem um próprio
+	scaleBarcodeLayout, err := barcode.ParseLayout(appConfig.ScaleBarcodeLayout)
+	if err != nil {
+		log.Fatalf("SCALE_BARCODE_LAYOUT inválido: %v", err)
+	}
+
+	// Fuso das lojas, em que são lidas as datas impressas nos cupons e agrupadas as compras por dia
+	storeLocation, err := time.LoadLocation(appConfig.TimeZone)
+	if err != nil {
+		log.Fatalf("TIME_ZONE inválido: %v", err)
+	}
+	receiptParser := receipt.NewParser()
+	receiptParser.SetLocation(storeLocation)
 
 	// 4) Instancia serviços
 	userService := services.NewUserService(userRepository)
-	categoryService := services.NewCategoryService(categoryRepository)
+	categoryService := services.NewCategoryService(categoryRepository, budgetRepository, transactionManager)
 
 	// Configura dependência circular entre UserService e CategoryService
 	userService.SetCategoryService(categoryService)
 
 	authService := services.NewAuthService(userService, appConfig)
-	productService := services.NewProductService(productRepository)
-	purchaseService := services.NewPurchaseService(purchaseRepository, productService)
-	priceHistoryService := services.NewPriceHistoryService(priceHistoryRepository, productService, userService)
+	householdService := services.NewHouseholdService(householdRepository, userService)
+	productService := services.NewProductService(productRepository, transactionManager)
+	storeService := services.NewStoreService(storeRepository, transactionManager)
+	barcodeService := services.NewBarcodeService(productService, storeRepository, scaleBarcodeLayout)
+	purchaseService := services.NewPurchaseService(purchaseRepository, transactionManager, productService)
+	priceHistoryService := services.NewPriceHistoryService(priceHistoryRepository, productService, userService, householdService,
+		storeService, appConfig)
 	userCategoryProductService := services.NewUserCategoryProductService(userCategoryProductRepository, categoryService, productService)
+	suggestionService := services.NewSuggestionService(purchaseRepository)
+	suggestionService.SetLocation(storeLocation)
+	purchaseImportService := services.NewPurchaseImportService(purchaseService, transactionManager)
+	invoiceImportService := services.NewInvoiceImportService(purchaseService, transactionManager, newInvoiceFetcher(appConfig))
+	receiptService := services.NewReceiptService(productService, receiptParser)
+	exportService := services.NewExportService(purchaseRepository, priceHistoryRepository, userCategoryProductRepository)
+	eventPublisher := services.NewEventPublisher(outboxRepository, transactionManager)
+	budgetService := services.NewBudgetService(budgetRepository, categoryService,
+		services.NewOutboxNotifier(eventPublisher, services.NewLogNotifier()))
+	catalogImportService := services.NewCatalogImportService(importJobRepository, productRepository, appConfig.CatalogImportDir)
+	backupService := services.NewBackupService(backupRepository, storeRepository, categoryRepository, transactionManager)
+	webhookService := services.NewWebhookService(webhookRepository)
+	webhookDispatcher := services.NewWebhookDispatcher(outboxRepository, webhookRepository, transactionManager,
+		time.Duration(appConfig.WebhookDispatchIntervalSeconds)*time.Second,
+		time.Duration(appConfig.WebhookTimeoutSeconds)*time.Second,
+		appConfig.WebhookMaxAttempts)
+
+	// Importações que estavam rodando quando o servidor parou ficam disponíveis para retomada
+	if err := importJobRepository.MarkRunningJobsInterrupted(); err != nil {
+		log.Printf("falha ao marcar importações interrompidas: %v", err)
+	}
+
+	// Vincula a estabelecimentos os locais digitados em compras e preços antigos
+	if linked, err := storeService.BackfillStores(); err != nil {
+		log.Printf("falha ao vincular locais de compra a estabelecimentos: %v", err)
+	} else if linked > 0 {
+		log.Printf("%d locais de compra vinculados a estabelecimentos", linked)
+	}
+
+	// Registros de preço anteriores à média ponderada recebem a quantidade do item de compra
+	if backfilled, err := priceHistoryService.BackfillQuantities(); err != nil {
+		log.Printf("falha ao preencher a quantidade do histórico de preços: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("%d registros de preço com a quantidade preenchida", backfilled)
+	}
+
+	// Códigos de barras gravados antes da validação passam para a forma GTIN-14
+	if normalized, err := barcodeService.NormalizeBarcodes(); err != nil {
+		log.Printf("falha ao normalizar códigos de barras: %v", err)
+	} else if normalized > 0 {
+		log.Printf("%d códigos de barras normalizados para GTIN-14", normalized)
+	}
 
 	// 5) Resolve circular dependencies
 	purchaseService.SetPriceHistoryService(priceHistoryService)
 	productService.SetPriceHistoryService(priceHistoryService)
+	purchaseService.SetBudgetService(budgetService)
+	purchaseService.SetEventPublisher(eventPublisher)
+	purchaseService.SetStoreService(storeService)
+	purchaseService.SetBarcodeService(barcodeService)
+	receiptService.SetBarcodeService(barcodeService)
+	productService.SetEventPublisher(eventPublisher)
+
+	graphResolver, err := graph.NewResolver(userService, categoryService, productService, purchaseService,
+		userCategoryProductService, priceHistoryService)
+	if err != nil {
+		log.Fatalf("Erro ao montar o schema GraphQL: %v", err)
+	}
+
+	// Entrega os eventos da outbox aos webhooks em segundo plano
+	webhookDispatcher.Start(context.Background())
 
 	// 6) Cria Gin Engine e registra rotas/handlers
 	router := gin.Default()
@@ -53,14 +144,38 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	handlers.RegisterAuthRoutes(router, authService)
-	handlers.RegisterUserRoutes(router, userService, appConfig)
-	handlers.RegisterCategoryRoutes(router, categoryService, appConfig)
-	handlers.RegisterProductRoutes(router, productService, appConfig)
-	handlers.RegisterPurchaseRoutes(router, purchaseService, appConfig)
-	handlers.RegisterPriceHistoryRoutes(router, priceHistoryService, appConfig)
-	handlers.RegisterUserCategoryProductRoutes(router, userCategoryProductService, appConfig)
+	// Rotas da API e documentação OpenAPI (a cobertura da especificação é conferida por TestAPISpecCoversRoutes)
+	handlers.RegisterRoutes(router, handlers.Dependencies{
+		AuthService:                authService,
+		UserService:                userService,
+		CategoryService:            categoryService,
+		ProductService:             productService,
+		StoreService:               storeService,
+		BarcodeService:             barcodeService,
+		PurchaseService:            purchaseService,
+		PurchaseImportService:      purchaseImportService,
+		InvoiceImportService:       invoiceImportService,
+		ReceiptService:             receiptService,
+		PriceHistoryService:        priceHistoryService,
+		UserCategoryProductService: userCategoryProductService,
+		BudgetService:              budgetService,
+		HouseholdService:           householdService,
+		SuggestionService:          suggestionService,
+		ExportService:              exportService,
+		CatalogImportService:       catalogImportService,
+		BackupService:              backupService,
+		WebhookService:             webhookService,
+		GraphResolver:              graphResolver,
+	}, appConfig)
 
 	// 7) Inicia servidor HTTP na porta configurada
 	router.Run(":" + appConfig.ServerPort)
 }
+
+// newInvoiceFetcher escolhe como os documentos de NFC-e são obtidos a partir do QR Code
+func newInvoiceFetcher(appConfig *config.Config) services.InvoiceFetcher {
+	if appConfig.NFCeFetcher == "fixture" {
+		return services.NewFixtureInvoiceFetcher(appConfig.NFCeFixtureDir)
+	}
+	return services.NewHTTPInvoiceFetcher(time.Duration(appConfig.NFCeFetchTimeoutSeconds) * time.Second)
+}
@@ -0,0 +1,37 @@
+package dto
+
+// RestoreOptionsDTO represents the options accepted when restoring a backup
+type RestoreOptionsDTO struct {
+	DryRun bool `form:"dryRun"` // Valida e conta tudo, mas desfaz a transação
+	// ProductConflict decide o que fazer quando o produto do backup já existe com o mesmo código de barras:
+	// "keep" mantém o produto local (padrão) e "overwrite" atualiza nome, marca, embalagem e imagem (apenas admin,
+	// pois os produtos são do catálogo global)
+	ProductConflict string `form:"productConflict" binding:"omitempty,oneof=keep overwrite"`
+}
+
+// RestoreEntityReportDTO summarizes what happened to the records of one entity
+type RestoreEntityReportDTO struct {
+	Created int `json:"created"`
+	Matched int `json:"matched"` // Já existiam e foram reaproveitados
+	Updated int `json:"updated"` // Produtos sobrescritos (productConflict=overwrite)
+	Skipped int `json:"skipped"` // Duplicados ou pertencentes a compras já existentes
+}
+
+// ProductConflictDTO reports a backup product whose barcode already belongs to a different local product
+type ProductConflictDTO struct {
+	Barcode     string `json:"barcode"`
+	LocalName   string `json:"localName"`
+	ArchiveName string `json:"archiveName"`
+	Resolution  string `json:"resolution"` // keep, overwrite
+}
+
+// RestoreReportDTO represents the result of a restore
+type RestoreReportDTO struct {
+	DryRun           bool                               `json:"dryRun"`
+	Committed        bool                               `json:"committed"`
+	Scope            string                             `json:"scope"`
+	FormatVersion    int                                `json:"formatVersion"`
+	BackupCreatedAt  string                             `json:"backupCreatedAt"`
+	Entities         map[string]*RestoreEntityReportDTO `json:"entities"`
+	ProductConflicts []ProductConflictDTO               `json:"productConflicts"`
+}
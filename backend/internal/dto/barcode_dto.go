@@ -0,0 +1,41 @@
+package dto
+
+// BarcodeQueryDTO represents the options of a barcode reading
+type BarcodeQueryDTO struct {
+	StoreID uint `form:"storeId"` // Estabelecimento cujo leiaute de etiqueta e PLUs são usados
+}
+
+// BarcodeReadingDTO is the interpretation of a scanned barcode
+type BarcodeReadingDTO struct {
+	Code            string              `json:"code" example:"07891000100103"` // Normalizado para GTIN-14
+	VariableMeasure bool                `json:"variableMeasure"`               // Etiqueta de balança (prefixo 2)
+	Layout          string              `json:"layout,omitempty" example:"2PPPPPVVVVVVC"`
+	PLU             string              `json:"plu,omitempty" example:"1234"`
+	Price           *float64            `json:"price,omitempty"`  // Preço total impresso na etiqueta
+	Weight          *float64            `json:"weight,omitempty"` // Peso em kg impresso na etiqueta
+	UnitsPerPack    int                 `json:"unitsPerPack"`     // Unidades do produto na embalagem lida
+	Product         *ProductResponseDTO `json:"product"`          // null quando o código não está cadastrado
+}
+
+// CreateProductPLUDTO represents data needed to register the scale code of a product
+type CreateProductPLUDTO struct {
+	ProductID uint   `json:"productId" binding:"required"`
+	PLU       string `json:"plu" binding:"required,numeric,max=6" example:"1234"`
+	StoreID   *uint  `json:"storeId,omitempty"` // Sem ele, o PLU vale para todos os estabelecimentos
+}
+
+// ProductPLUQueryDTO represents the filters of the PLU list
+type ProductPLUQueryDTO struct {
+	ProductID uint `form:"productId"`
+	StoreID   uint `form:"storeId"`
+}
+
+// ProductPLUResponseDTO represents the response data for a product PLU
+type ProductPLUResponseDTO struct {
+	ID          uint   `json:"id"`
+	ProductID   uint   `json:"productId"`
+	ProductName string `json:"productName"`
+	StoreID     *uint  `json:"storeId,omitempty"`
+	PLU         string `json:"plu"`
+	CreatedAt   string `json:"createdAt"`
+}
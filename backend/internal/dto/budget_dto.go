@@ -0,0 +1,46 @@
+package dto
+
+// CreateBudgetDTO represents data needed to create a monthly budget
+type CreateBudgetDTO struct {
+	CategoryID        *uint    `json:"categoryId" example:"3"` // Omitir para orçamento do total
+	MonthlyLimit      float64  `json:"monthlyLimit" binding:"required,gt=0" example:"800"`
+	WarningThreshold  *float64 `json:"warningThreshold" binding:"omitempty,gt=0,lte=100" example:"80"`
+	RolloverEnabled   bool     `json:"rolloverEnabled" example:"true"`
+	RolloverMaxMonths *int     `json:"rolloverMaxMonths" binding:"omitempty,gte=1,lte=12" example:"3"`
+}
+
+// UpdateBudgetDTO represents data needed to update a budget
+type UpdateBudgetDTO struct {
+	MonthlyLimit      *float64 `json:"monthlyLimit,omitempty" binding:"omitempty,gt=0"`
+	WarningThreshold  *float64 `json:"warningThreshold,omitempty" binding:"omitempty,gt=0,lte=100"`
+	RolloverEnabled   *bool    `json:"rolloverEnabled,omitempty"`
+	RolloverMaxMonths *int     `json:"rolloverMaxMonths,omitempty" binding:"omitempty,gte=1,lte=12"`
+}
+
+// BudgetResponseDTO represents the response data for a budget
+type BudgetResponseDTO struct {
+	ID                uint    `json:"id"`
+	UserID            uint    `json:"userId"`
+	CategoryID        *uint   `json:"categoryId"`
+	CategoryName      string  `json:"categoryName"` // "Total" para o orçamento geral
+	MonthlyLimit      float64 `json:"monthlyLimit"`
+	WarningThreshold  float64 `json:"warningThreshold"`
+	RolloverEnabled   bool    `json:"rolloverEnabled"`
+	RolloverMaxMonths int     `json:"rolloverMaxMonths"`
+	CreatedAt         string  `json:"createdAt"`
+	UpdatedAt         string  `json:"updatedAt"`
+}
+
+// BudgetStatusDTO represents budget vs actual spending for a month
+type BudgetStatusDTO struct {
+	Budget           BudgetResponseDTO `json:"budget"`
+	Period           string            `json:"period"` // YYYY-MM
+	RolloverAmount   float64           `json:"rolloverAmount"`
+	AvailableAmount  float64           `json:"availableAmount"` // Limite mensal + rollover
+	SpentAmount      float64           `json:"spentAmount"`
+	RemainingAmount  float64           `json:"remainingAmount"`
+	PercentUsed      float64           `json:"percentUsed"`
+	ProjectedSpend   float64           `json:"projectedSpend"` // Projeção de gasto no fim do mês no ritmo atual
+	ProjectedOverrun bool              `json:"projectedOverrun"`
+	Status           string            `json:"status"` // ok, warning, exceeded
+}
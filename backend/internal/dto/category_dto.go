@@ -1,17 +1,57 @@
 package dto
 
 type CreateCategoryDTO struct {
-	Name string `json:"name" binding:"required" example:"Frutas"`
+	Name     string `json:"name" binding:"required" example:"Refrigerantes"`
+	ParentID *uint  `json:"parentId" example:"1"` // Categoria-pai (opcional); a nova categoria entra no fim das irmãs
 }
 
 type UpdateCategoryDTO struct {
 	Name string `json:"name" binding:"required" example:"Legumes"`
 }
 
+// MoveCategoryDTO muda a categoria de lugar na árvore
+type MoveCategoryDTO struct {
+	ParentID *uint `json:"parentId" example:"1"`                           // nil = torna a categoria raiz
+	Position *int  `json:"position" binding:"omitempty,gte=0" example:"0"` // Posição entre as novas irmãs; padrão: última
+}
+
+// ReorderCategoriesDTO define a ordem das subcategorias diretas de parentId (ou das raízes)
+type ReorderCategoriesDTO struct {
+	ParentID    *uint  `json:"parentId" example:"1"`
+	CategoryIDs []uint `json:"categoryIds" binding:"required,min=1"` // Todas as irmãs, na nova ordem
+}
+
 type CategoryResponseDTO struct {
 	ID        uint   `json:"id"`
 	Name      string `json:"name"`
 	UserID    uint   `json:"userId"`
+	ParentID  *uint  `json:"parentId"`
+	Position  int    `json:"position"`
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
 }
+
+// CategoryTreeQueryDTO filtra a árvore de categorias
+type CategoryTreeQueryDTO struct {
+	RootID *uint  `form:"rootId" example:"1"`      // Apenas a subárvore desta categoria
+	Month  string `form:"month" example:"2025-01"` // Mês dos gastos (YYYY-MM); padrão: mês atual
+}
+
+// CategoryTreeNodeDTO é uma categoria com suas subcategorias e os totais do mês.
+// Os totais "total*" incluem as subcategorias; um produto presente em mais de uma delas é contado uma vez.
+type CategoryTreeNodeDTO struct {
+	CategoryResponseDTO
+	Depth             int                   `json:"depth"`
+	ProductCount      int                   `json:"productCount"`      // Produtos vinculados diretamente
+	TotalProductCount int                   `json:"totalProductCount"` // Produtos da categoria e das subcategorias
+	SpentAmount       float64               `json:"spentAmount"`       // Gasto do mês com os produtos diretos
+	TotalSpentAmount  float64               `json:"totalSpentAmount"`  // Gasto do mês incluindo as subcategorias
+	Children          []CategoryTreeNodeDTO `json:"children"`
+}
+
+// CategoryTreeDTO é a árvore de categorias do usuário com os totais do mês
+type CategoryTreeDTO struct {
+	Period           string                `json:"period" example:"2025-01"`
+	TotalSpentAmount float64               `json:"totalSpentAmount"` // Soma dos produtos distintos de toda a árvore
+	Categories       []CategoryTreeNodeDTO `json:"categories"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// ExportQueryDTO represents the query parameters accepted by the export endpoints
+type ExportQueryDTO struct {
+	Format    string    `form:"format" binding:"omitempty,oneof=csv xlsx ndjson"` // default: csv
+	StartDate time.Time `form:"startDate" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndDate   time.Time `form:"endDate" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// GraphQLRequestDTO represents a GraphQL request (query, optional operation name and variables)
+type GraphQLRequestDTO struct {
+	Query         string         `json:"query" binding:"required" example:"{ me { name purchases(limit: 5) { purchaseDate items { quantity product { name } } } } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+// CreateHouseholdDTO represents data needed to create a household
+type CreateHouseholdDTO struct {
+	Name string `json:"name" binding:"required" example:"Casa"`
+}
+
+// JoinHouseholdDTO represents data needed to join a household
+type JoinHouseholdDTO struct {
+	InviteCode string `json:"inviteCode" binding:"required" example:"9f2c7a1b4e"`
+}
+
+// HouseholdMemberDTO represents a member of a household
+type HouseholdMemberDTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// HouseholdResponseDTO represents the response data for a household
+type HouseholdResponseDTO struct {
+	ID         uint                 `json:"id"`
+	Name       string               `json:"name"`
+	OwnerID    uint                 `json:"ownerId"`
+	InviteCode string               `json:"inviteCode"`
+	Members    []HouseholdMemberDTO `json:"members"`
+	CreatedAt  string               `json:"createdAt"`
+	UpdatedAt  string               `json:"updatedAt"`
+}
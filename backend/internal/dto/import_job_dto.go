@@ -0,0 +1,30 @@
+package dto
+
+// StartCatalogImportDTO represents the data needed to start an Open Food Facts catalog import
+type StartCatalogImportDTO struct {
+	File      string `json:"file" binding:"required"`                       // Relative to CATALOG_IMPORT_DIR
+	Format    string `json:"format" binding:"omitempty,oneof=csv jsonl"`    // Detected from the extension when empty
+	Country   string `json:"country"`                                       // Ex.: "brazil" or "en:brazil"; empty imports all
+	BatchSize int    `json:"batchSize" binding:"omitempty,min=1,max=10000"` // default: 1000
+}
+
+// ImportJobResponseDTO represents the progress of a bulk import job
+type ImportJobResponseDTO struct {
+	ID            uint    `json:"id"`
+	Kind          string  `json:"kind"`
+	Source        string  `json:"source"`
+	Format        string  `json:"format"`
+	Country       string  `json:"country,omitempty"`
+	BatchSize     int     `json:"batchSize"`
+	Status        string  `json:"status"`
+	Progress      float64 `json:"progress"` // Percentual do arquivo já lido
+	ProcessedRows int64   `json:"processedRows"`
+	ImportedRows  int64   `json:"importedRows"`
+	SkippedRows   int64   `json:"skippedRows"`
+	ErrorRows     int64   `json:"errorRows"`
+	LastError     string  `json:"lastError,omitempty"`
+	StartedAt     string  `json:"startedAt,omitempty"`
+	FinishedAt    string  `json:"finishedAt,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+	UpdatedAt     string  `json:"updatedAt"`
+}
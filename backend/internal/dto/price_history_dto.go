@@ -19,13 +19,19 @@ type PriceHistoryResponseDTO struct {
 	UserName      string  `json:"userName"`
 	PurchaseDate  string  `json:"purchaseDate"`
 	PurchasePlace string  `json:"purchasePlace"`
+	StoreID       *uint   `json:"storeId,omitempty"`
 	PricePaid     float64 `json:"pricePaid"`
-	CreatedAt     string  `json:"createdAt"`
-	UpdatedAt     string  `json:"updatedAt"`
+	Unit          string  `json:"unit"` // Unidade do item comprado; o preço pago é por ela
+	// Preço por kg, L ou unidade (itens contados dependem da embalagem cadastrada no produto)
+	UnitPrice     *float64 `json:"unitPrice,omitempty"`
+	UnitPriceUnit string   `json:"unitPriceUnit,omitempty"` // kg, L ou un
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
 }
 
 // PriceHistoryStatisticsDTO represents statistical data about a product's price history
 type PriceHistoryStatisticsDTO struct {
+	Scope           string  `json:"scope"` // personal, household or community
 	ProductID       uint    `json:"productId"`
 	ProductName     string  `json:"productName"`
 	CurrentAvgPrice float64 `json:"currentAvgPrice"`
@@ -35,4 +41,118 @@ type PriceHistoryStatisticsDTO struct {
 	RecordsCount    int     `json:"recordsCount"`
 	FirstRecordDate string  `json:"firstRecordDate"`
 	LastRecordDate  string  `json:"lastRecordDate"`
+
+	WeightedAvgPrice float64 `json:"weightedAvgPrice"` // Average weighted by the quantity bought
+	MedianPrice      float64 `json:"medianPrice"`
+	StdDevPrice      float64 `json:"stdDevPrice"`
+	P10Price         float64 `json:"p10Price"`
+	P90Price         float64 `json:"p90Price"`
+	LastPricePaid    float64 `json:"lastPricePaid"`
+
+	// Estatísticas por kg, L ou unidade, quando o produto tem a embalagem cadastrada
+	UnitPrices *UnitPriceStatisticsDTO `json:"unitPrices,omitempty"`
+}
+
+// UnitPriceStatisticsDTO represents the price statistics converted to price per kg, L or unit
+type UnitPriceStatisticsDTO struct {
+	Unit             string  `json:"unit"` // kg, L ou un
+	CurrentAvgPrice  float64 `json:"currentAvgPrice"`
+	WeightedAvgPrice float64 `json:"weightedAvgPrice"`
+	MedianPrice      float64 `json:"medianPrice"`
+	LowestPrice      float64 `json:"lowestPrice"`
+	HighestPrice     float64 `json:"highestPrice"`
+	LastPricePaid    float64 `json:"lastPricePaid"`
+}
+
+// PriceStatisticsQueryDTO represents the optional filters accepted by the price statistics endpoint
+type PriceStatisticsQueryDTO struct {
+	StartDate time.Time `form:"startDate" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndDate   time.Time `form:"endDate" time_format:"2006-01-02T15:04:05Z07:00"`
+	Store     string    `form:"store"`
+	StoreID   uint      `form:"storeId"`                                                      // Apenas uma filial
+	ChainID   uint      `form:"chainId"`                                                      // Todas as filiais de uma rede
+	Scope     string    `form:"scope" binding:"omitempty,oneof=personal household community"` // default: personal
+}
+
+// PriceComparisonQueryDTO represents the filters of the price comparison between chains or branches
+type PriceComparisonQueryDTO struct {
+	StartDate time.Time `form:"startDate" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndDate   time.Time `form:"endDate" time_format:"2006-01-02T15:04:05Z07:00"`
+	Scope     string    `form:"scope" binding:"omitempty,oneof=personal household community"` // default: personal
+	GroupBy   string    `form:"groupBy" binding:"omitempty,oneof=chain branch"`               // default: chain
+	ChainID   uint      `form:"chainId"`                                                      // Compara apenas as filiais desta rede
+	// Com lat e lng, compara apenas os estabelecimentos dentro do raio (km, padrão 5)
+	Lat    *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lng    *float64 `form:"lng" binding:"omitempty,min=-180,max=180"`
+	Radius float64  `form:"radius" binding:"omitempty,gt=0,max=50"`
+}
+
+// PriceGroupStatisticsDTO represents the prices of a product in one chain or branch
+type PriceGroupStatisticsDTO struct {
+	ID        uint   `json:"id"` // ID da rede ou do estabelecimento
+	Name      string `json:"name"`
+	ChainID   *uint  `json:"chainId,omitempty"` // Rede da filial (groupBy=branch)
+	ChainName string `json:"chainName,omitempty"`
+	// Distância até o ponto informado (da filial mais próxima, no agrupamento por rede)
+	DistanceKm       *float64 `json:"distanceKm,omitempty"`
+	RecordsCount     int      `json:"recordsCount"`
+	CurrentAvgPrice  float64  `json:"currentAvgPrice"`
+	WeightedAvgPrice float64  `json:"weightedAvgPrice"`
+	MedianPrice      float64  `json:"medianPrice"`
+	LowestPrice      float64  `json:"lowestPrice"`
+	HighestPrice     float64  `json:"highestPrice"`
+	LastPricePaid    float64  `json:"lastPricePaid"`
+	LastRecordDate   string   `json:"lastRecordDate"`
+	// Diferença percentual da mediana em relação ao grupo mais barato
+	DifferenceFromCheapest float64 `json:"differenceFromCheapest"`
+}
+
+// PriceComparisonDTO compares the prices of a product between chains or branches, cheapest first
+type PriceComparisonDTO struct {
+	Scope       string                    `json:"scope"`
+	ProductID   uint                      `json:"productId"`
+	ProductName string                    `json:"productName"`
+	GroupBy     string                    `json:"groupBy"`
+	Unit        string                    `json:"unit"` // kg, L ou un: os preços dos grupos são por esta unidade
+	Groups      []PriceGroupStatisticsDTO `json:"groups"`
+	// Registros em outra unidade base, que não entram na comparação
+	OtherUnitRecords int `json:"otherUnitRecords"`
+	// Grupos omitidos na comunidade por terem menos contribuintes que o limiar de k-anonimato
+	HiddenGroups int `json:"hiddenGroups"`
+}
+
+// CommunityPriceBucketDTO aggregates the community prices of a product paid in one week and unit
+// (no user identifiers, stores or exact dates)
+type CommunityPriceBucketDTO struct {
+	WeekStart    string  `json:"weekStart" example:"2025-01-13"` // Monday of the week (UTC)
+	Unit         string  `json:"unit"`
+	RecordsCount int     `json:"recordsCount"`
+	MedianPrice  float64 `json:"medianPrice"`
+	LowestPrice  float64 `json:"lowestPrice"`
+	HighestPrice float64 `json:"highestPrice"`
+}
+
+// CommunityPriceHistoryDTO represents the community price history of a product as weekly aggregates
+type CommunityPriceHistoryDTO struct {
+	Scope       string                    `json:"scope"`
+	ProductID   uint                      `json:"productId"`
+	ProductName string                    `json:"productName"`
+	Buckets     []CommunityPriceBucketDTO `json:"buckets"`
+	// Semanas omitidas por terem menos contribuintes que o limiar de k-anonimato
+	HiddenBuckets int `json:"hiddenBuckets"`
+}
+
+// PriceOutlierReportDTO represents a price history record flagged as an outlier for admin review
+type PriceOutlierReportDTO struct {
+	PriceHistoryID uint    `json:"priceHistoryId"`
+	ProductID      uint    `json:"productId"`
+	ProductName    string  `json:"productName"`
+	UserID         uint    `json:"userId"`
+	PurchaseDate   string  `json:"purchaseDate"`
+	PurchasePlace  string  `json:"purchasePlace"`
+	PricePaid      float64 `json:"pricePaid"`
+	MedianPrice    float64 `json:"medianPrice"`
+	LowerFence     float64 `json:"lowerFence"`
+	UpperFence     float64 `json:"upperFence"`
+	SampleSize     int     `json:"sampleSize"`
 }
@@ -4,12 +4,27 @@ package dto
 type CreateProductDTO struct {
 	Name    string `json:"name" binding:"required"`
 	Barcode string `json:"barcode" binding:"omitempty"` // Cliente envia "" para vazio ou omite
+
+	Brand        string `json:"brand"`
+	PackageLabel string `json:"packageLabel"`
+	ImageURL     string `json:"imageUrl" binding:"omitempty,url"`
+
+	// Conteúdo da embalagem; sem eles, são lidos do packageLabel quando possível
+	PackageQuantity *float64 `json:"packageQuantity,omitempty" binding:"omitempty,gt=0" example:"5"`
+	Unit            string   `json:"unit,omitempty" example:"kg"` // g, kg, ml, L ou un
 }
 
 // UpdateProductDTO representa os dados para atualizar um produto existente
 type UpdateProductDTO struct {
 	Name    *string `json:"name,omitempty"`
 	Barcode *string `json:"barcode,omitempty"` // Cliente pode enviar "", null, ou omitir
+
+	Brand        *string `json:"brand,omitempty"`
+	PackageLabel *string `json:"packageLabel,omitempty"`
+	ImageURL     *string `json:"imageUrl,omitempty"` // "" remove a imagem
+
+	PackageQuantity *float64 `json:"packageQuantity,omitempty" binding:"omitempty,gt=0"`
+	Unit            *string  `json:"unit,omitempty"` // "" remove a quantidade e a unidade
 }
 
 // ProductResponseDTO representa os dados de um produto para resposta HTTP
@@ -19,4 +34,91 @@ type ProductResponseDTO struct {
 	Barcode   *string `json:"barcode"` // Alterado para *string para refletir que pode ser null
 	CreatedAt string  `json:"createdAt"`
 	UpdatedAt string  `json:"updatedAt"`
+
+	Brand        string `json:"brand,omitempty"`
+	PackageLabel string `json:"packageLabel,omitempty"`
+	ImageURL     string `json:"imageUrl,omitempty"`
+
+	PackageQuantity *float64 `json:"packageQuantity,omitempty"`
+	Unit            string   `json:"unit,omitempty"`
+
+	Status         string `json:"status"`                   // approved, pending, rejected ou merged
+	ProposedByID   *uint  `json:"proposedById,omitempty"`   // Usuário que sugeriu o produto
+	ModerationNote string `json:"moderationNote,omitempty"` // Motivo da recusa ou observação do moderador
+}
+
+// CreateProductBarcodeDTO representa um código de barras adicional do produto (outra versão da
+// embalagem ou uma embalagem múltipla do produto)
+type CreateProductBarcodeDTO struct {
+	Code string `json:"code" binding:"required,numeric,min=8,max=14" example:"17891000100100"`
+	// Unidades do produto na embalagem com este código (ex.: 6 no fardo); padrão 1
+	UnitsPerPack int    `json:"unitsPerPack,omitempty" binding:"omitempty,min=1,max=1000" example:"6"`
+	Description  string `json:"description,omitempty" binding:"max=100" example:"Fardo com 6"`
+}
+
+// ProductBarcodeResponseDTO representa um código de barras adicional do produto
+type ProductBarcodeResponseDTO struct {
+	ID           uint   `json:"id"`
+	ProductID    uint   `json:"productId"`
+	Code         string `json:"code"`
+	UnitsPerPack int    `json:"unitsPerPack"`
+	Description  string `json:"description,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+// ProductDuplicatesQueryDTO representa os filtros da busca por produtos duplicados
+type ProductDuplicatesQueryDTO struct {
+	// Semelhança mínima entre os nomes (0 a 1); padrão 0.85
+	Threshold float64 `form:"threshold" binding:"omitempty,gt=0,lte=1" example:"0.85"`
+	Limit     int     `form:"limit" binding:"omitempty,min=1,max=200" example:"50"` // Padrão 50 grupos
+}
+
+// ProductDuplicateGroupDTO representa um grupo de produtos que parecem ser o mesmo
+type ProductDuplicateGroupDTO struct {
+	Reason            string               `json:"reason" example:"name"` // barcode (mesmo GTIN ou variante de embalagem) ou name
+	Score             float64              `json:"score"`                 // Semelhança dos nomes (1 para códigos iguais)
+	SuggestedTargetID uint                 `json:"suggestedTargetId"`     // Produto sugerido para receber os demais
+	Products          []ProductResponseDTO `json:"products"`
+}
+
+// MergeProductsDTO representa os produtos duplicados mesclados num produto de destino
+type MergeProductsDTO struct {
+	TargetID  uint   `json:"targetId" binding:"required" example:"12"`
+	SourceIDs []uint `json:"sourceIds" binding:"required,min=1"`
+	// Unidades do destino em cada origem que é uma embalagem múltipla dele (ID da origem -> unidades):
+	// os itens em "un" da origem são convertidos e o código dela vira um código de embalagem do destino
+	UnitsPerPack map[uint]int `json:"unitsPerPack,omitempty"`
+}
+
+// MergeProductsResultDTO resume uma mesclagem de produtos
+type MergeProductsResultDTO struct {
+	Product                     ProductResponseDTO `json:"product"`
+	MergedProductIDs            []uint             `json:"mergedProductIds"`
+	PurchaseItemsUpdated        int64              `json:"purchaseItemsUpdated"`
+	PriceHistoriesUpdated       int64              `json:"priceHistoriesUpdated"`
+	UserCategoryProductsUpdated int64              `json:"userCategoryProductsUpdated"`
+}
+
+// ProductProposalQueryDTO representa os filtros da lista de sugestões de produto
+type ProductProposalQueryDTO struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected merged" example:"pending"`
+}
+
+// ApproveProductProposalDTO representa a aprovação de uma sugestão, com correções opcionais do produto
+type ApproveProductProposalDTO struct {
+	UpdateProductDTO
+	Note string `json:"note,omitempty" binding:"max=255"`
+}
+
+// MergeProductProposalDTO representa uma sugestão aceita como duplicata de um produto do catálogo
+type MergeProductProposalDTO struct {
+	TargetID uint   `json:"targetId" binding:"required" example:"12"`
+	Note     string `json:"note,omitempty" binding:"max=255"`
+}
+
+// RejectProductProposalDTO representa a recusa de uma sugestão; o que já usa a sugestão migra para o
+// produto substituto
+type RejectProductProposalDTO struct {
+	Reason        string `json:"reason" binding:"required,max=255" example:"Nome genérico; use o produto do catálogo"`
+	ReplacementID uint   `json:"replacementId,omitempty" example:"12"` // Obrigatório quando a sugestão já foi usada
 }
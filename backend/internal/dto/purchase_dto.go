@@ -4,16 +4,47 @@ import "time"
 
 // PurchaseItemDTO represents an item in a purchase
 type PurchaseItemDTO struct {
-	ProductID uint    `json:"productId" binding:"required"`
-	Quantity  float64 `json:"quantity" binding:"required,gt=0"`
-	UnitPrice float64 `json:"unitPrice" binding:"required,gt=0"` // Renomeado de PricePaid para UnitPrice para maior clareza
+	ProductID uint `json:"productId" binding:"required_without=Barcode"`
+	// Código de barras lido no lugar do productId; etiquetas de balança (prefixo 2) também trazem o
+	// preço ou o peso, que completam quantity e unitPrice quando omitidos
+	Barcode   string  `json:"barcode,omitempty" binding:"omitempty,numeric,min=8,max=14" example:"2012340012507"`
+	Quantity  float64 `json:"quantity" binding:"omitempty,gt=0"`
+	UnitPrice float64 `json:"unitPrice" binding:"omitempty,gt=0"` // Renomeado de PricePaid para UnitPrice para maior clareza
+	// Unidade da quantidade (g, kg, ml, L ou un, o padrão); o preço unitário é por ela (ex.: 1,234 kg a R$ 5,99/kg)
+	Unit string `json:"unit,omitempty" example:"kg"`
+	// Confirma que o preço está correto mesmo se for considerado atípico (modo estrito)
+	ConfirmedPrice bool `json:"confirmedPrice"`
 }
 
 // CreatePurchaseDTO represents data needed to create a purchase
 type CreatePurchaseDTO struct {
 	PurchaseDate     time.Time         `json:"purchaseDate" binding:"required"`
-	PurchaseLocation string            `json:"purchaseLocation" binding:"required"`
+	PurchaseLocation string            `json:"purchaseLocation" binding:"required_without=StoreID"`
 	Items            []PurchaseItemDTO `json:"items" binding:"required,dive"`
+	// Estabelecimento já cadastrado; sem ele, o local digitado é casado com os estabelecimentos existentes
+	StoreID *uint `json:"storeId,omitempty"`
+	// CNPJ do estabelecimento (apenas dígitos), usado no casamento antes do nome
+	StoreCNPJ string `json:"storeCnpj,omitempty" binding:"omitempty,len=14,numeric"`
+	// Modo estrito: rejeita itens com preço atípico que não foram confirmados pelo cliente
+	StrictPriceCheck bool `json:"strictPriceCheck"`
+	// Chave de acesso da nota fiscal (44 dígitos); impede registrar a mesma nota duas vezes
+	InvoiceKey string `json:"invoiceKey,omitempty" binding:"omitempty,len=44,numeric"`
+}
+
+// PriceWarningDTO describes a purchase item whose unit price looks like an outlier
+type PriceWarningDTO struct {
+	ItemIndex      int      `json:"itemIndex"`
+	ProductID      uint     `json:"productId"`
+	ProductName    string   `json:"productName"`
+	UnitPrice      float64  `json:"unitPrice"`
+	MedianPrice    float64  `json:"medianPrice"`
+	ExpectedMin    float64  `json:"expectedMin"`
+	ExpectedMax    float64  `json:"expectedMax"`
+	Method         string   `json:"method"` // iqr or ratio
+	SampleSize     int      `json:"sampleSize"`
+	SuggestedPrice *float64 `json:"suggestedPrice,omitempty"` // Likely intended price (e.g. misplaced decimal point)
+	Confirmed      bool     `json:"confirmed"`
+	Message        string   `json:"message"`
 }
 
 // UpdatePurchaseDTO represents data needed to update a purchase
@@ -29,8 +60,12 @@ type PurchaseItemResponseDTO struct {
 	ProductID   uint    `json:"productId"`
 	ProductName string  `json:"productName"`
 	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
 	UnitPrice   float64 `json:"unitPrice"`
 	TotalPrice  float64 `json:"totalPrice"` // Renomeado de PricePaid para TotalPrice para maior clareza
+	// Preço por kg, L ou unidade, comparável entre compras a granel e embaladas
+	NormalizedUnitPrice *float64 `json:"normalizedUnitPrice,omitempty"`
+	NormalizedUnit      string   `json:"normalizedUnit,omitempty"`
 	// Removido o campo subtotal por ser redundante com totalPrice
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
@@ -41,7 +76,9 @@ type PurchaseResponseDTO struct {
 	ID               uint                      `json:"id"`
 	PurchaseDate     string                    `json:"purchaseDate"`
 	PurchaseLocation string                    `json:"purchaseLocation"`
+	StoreID          *uint                     `json:"storeId,omitempty"`
 	UserID           uint                      `json:"userId"`
+	InvoiceKey       *string                   `json:"invoiceKey,omitempty"`
 	Items            []PurchaseItemResponseDTO `json:"items"`
 	Total            float64                   `json:"total"`
 	CreatedAt        string                    `json:"createdAt"`
@@ -0,0 +1,104 @@
+package dto
+
+// PurchaseImportOptionsDTO represents the options of a CSV purchase import (multipart form fields).
+// Column references accept a header name (case-insensitive) or a 1-based column index.
+type PurchaseImportOptionsDTO struct {
+	DateColumn      string `form:"dateColumn" example:"Data"`
+	StoreColumn     string `form:"storeColumn" example:"Loja"`
+	ProductColumn   string `form:"productColumn" example:"Produto"`
+	BarcodeColumn   string `form:"barcodeColumn" example:"EAN"`
+	QuantityColumn  string `form:"quantityColumn" example:"Qtd"`
+	UnitPriceColumn string `form:"unitPriceColumn" example:"Preço"`
+	UnitColumn      string `form:"unitColumn" example:"Un"`         // Optional; items default to "un"
+	Delimiter       string `form:"delimiter" example:";"`           // Detected from the header when omitted
+	DateFormat      string `form:"dateFormat" example:"02/01/2006"` // Go layout; common formats are tried when omitted
+	NoHeader        bool   `form:"noHeader"`                        // First line is data; columns must be indices
+	DryRun          bool   `form:"dryRun"`                          // Validate everything and roll back
+	// Create products that cannot be resolved by barcode or name (pending proposals unless admin)
+	CreateMissingProducts bool `form:"createMissingProducts"`
+}
+
+// ImportRowMessageDTO represents an error or warning tied to a line of the imported file
+type ImportRowMessageDTO struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportPurchaseSummaryDTO summarizes a purchase assembled from imported rows
+type ImportPurchaseSummaryDTO struct {
+	Rows             []int   `json:"rows"`
+	PurchaseID       uint    `json:"purchaseId,omitempty"` // Only set when the import was committed
+	PurchaseDate     string  `json:"purchaseDate"`
+	PurchaseLocation string  `json:"purchaseLocation"`
+	ItemsCount       int     `json:"itemsCount"`
+	Total            float64 `json:"total"`
+}
+
+// PurchaseImportReportDTO represents the result of an import (or dry-run)
+type PurchaseImportReportDTO struct {
+	DryRun          bool                       `json:"dryRun"`
+	Committed       bool                       `json:"committed"`
+	TotalRows       int                        `json:"totalRows"`
+	ValidRows       int                        `json:"validRows"`
+	Purchases       []ImportPurchaseSummaryDTO `json:"purchases"`
+	ProductsCreated []string                   `json:"productsCreated"` // Products created (or to be created on dry-run)
+	Errors          []ImportRowMessageDTO      `json:"errors"`
+	Warnings        []ImportRowMessageDTO      `json:"warnings"`
+}
+
+// InvoiceImportOptionsDTO represents the options of an NFC-e/NF-e XML import
+type InvoiceImportOptionsDTO struct {
+	DryRun bool `form:"dryRun"` // Validate and match products without saving
+	// Create products for items that cannot be matched by barcode or name (pending proposals unless admin)
+	CreateMissingProducts bool `form:"createMissingProducts"`
+	// Import only the matched items instead of refusing the invoice
+	SkipUnknownItems bool `form:"skipUnknownItems"`
+}
+
+// InvoiceItemReportDTO represents an invoice item and how it was matched to a product
+type InvoiceItemReportDTO struct {
+	Number      int     `json:"number"`
+	Barcode     string  `json:"barcode,omitempty"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unitPrice"` // Net of the item discount
+	Discount    float64 `json:"discount"`
+	Total       float64 `json:"total"`
+	ProductID   uint    `json:"productId,omitempty"`
+	ProductName string  `json:"productName,omitempty"`
+	Status      string  `json:"status"` // matched, created, proposed or skipped
+}
+
+// ProposedProductDTO represents a product suggested for an invoice item without a match
+type ProposedProductDTO struct {
+	ItemNumber int    `json:"itemNumber"`
+	Name       string `json:"name"`
+	Barcode    string `json:"barcode,omitempty"`
+}
+
+// InvoiceImportReportDTO represents the result of an invoice import (or dry-run)
+type InvoiceImportReportDTO struct {
+	DryRun           bool                   `json:"dryRun"`
+	Committed        bool                   `json:"committed"`
+	AccessKey        string                 `json:"accessKey"`
+	StoreName        string                 `json:"storeName"`
+	StoreCNPJ        string                 `json:"storeCnpj"`
+	IssuedAt         string                 `json:"issuedAt"`
+	Total            float64                `json:"total"`
+	Discount         float64                `json:"discount"`
+	Items            []InvoiceItemReportDTO `json:"items"`
+	ProposedProducts []ProposedProductDTO   `json:"proposedProducts"`
+	ProductsCreated  []string               `json:"productsCreated"`
+	Warnings         []PriceWarningDTO      `json:"warnings"`
+	Purchase         *PurchaseResponseDTO   `json:"purchase,omitempty"` // Only set when committed
+}
+
+// ImportInvoiceURLDTO represents the NFC-e QR code URL to import and the import options
+type ImportInvoiceURLDTO struct {
+	URL                   string `json:"url" binding:"required,url"`
+	DryRun                bool   `json:"dryRun"`
+	CreateMissingProducts bool   `json:"createMissingProducts"`
+	SkipUnknownItems      bool   `json:"skipUnknownItems"`
+}
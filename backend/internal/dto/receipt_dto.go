@@ -0,0 +1,47 @@
+package dto
+
+// ParseReceiptTextDTO represents the raw text of a receipt (pasted or from OCR)
+type ParseReceiptTextDTO struct {
+	Text string `json:"text" binding:"required,max=100000"`
+}
+
+// ReceiptTextFieldDTO represents a text value extracted from a receipt and its confidence (0 to 1)
+type ReceiptTextFieldDTO struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ReceiptNumberFieldDTO represents a numeric value extracted from a receipt and its confidence (0 to 1)
+type ReceiptNumberFieldDTO struct {
+	Value      float64 `json:"value"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ReceiptItemDraftDTO represents a line item read from a receipt and the product it was matched to
+type ReceiptItemDraftDTO struct {
+	Line        int                   `json:"line"`
+	Code        ReceiptTextFieldDTO   `json:"code"`
+	Description ReceiptTextFieldDTO   `json:"description"`
+	Quantity    ReceiptNumberFieldDTO `json:"quantity"`
+	Unit        ReceiptTextFieldDTO   `json:"unit"`
+	UnitPrice   ReceiptNumberFieldDTO `json:"unitPrice"`
+	Total       ReceiptNumberFieldDTO `json:"total"`
+	Confidence  float64               `json:"confidence"`
+	ProductID   uint                  `json:"productId,omitempty"`
+	ProductName string                `json:"productName,omitempty"`
+	MatchedBy   string                `json:"matchedBy,omitempty"` // barcode, plu or name
+}
+
+// ReceiptDraftDTO represents a parsed receipt. Purchase is a draft to be reviewed and sent to /purchases/create;
+// it only contains the items that were matched to a product.
+type ReceiptDraftDTO struct {
+	Layout         string                `json:"layout"`
+	Store          ReceiptTextFieldDTO   `json:"store"`
+	CNPJ           ReceiptTextFieldDTO   `json:"cnpj"`
+	Date           ReceiptTextFieldDTO   `json:"date"`
+	Total          ReceiptNumberFieldDTO `json:"total"`
+	Items          []ReceiptItemDraftDTO `json:"items"`
+	UnmatchedItems int                   `json:"unmatchedItems"`
+	Warnings       []string              `json:"warnings"`
+	Purchase       CreatePurchaseDTO     `json:"purchase"`
+}
@@ -0,0 +1,99 @@
+package dto
+
+// CreateStoreDTO represents data needed to create a store
+type CreateStoreDTO struct {
+	Name       string   `json:"name" binding:"required,max=255" example:"Carrefour Centro"`
+	Chain      string   `json:"chain,omitempty" binding:"max=100" example:"Carrefour"` // Nome da rede; criada se não existir
+	ChainID    *uint    `json:"chainId,omitempty"`                                     // Rede já cadastrada (prevalece sobre chain)
+	BranchName string   `json:"branchName,omitempty" binding:"max=255" example:"Centro"`
+	Address    string   `json:"address,omitempty" binding:"max=255"`
+	CNPJ       string   `json:"cnpj,omitempty" binding:"omitempty,len=14,numeric" example:"45543915000181"` // Apenas dígitos
+	Latitude   *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	// Leiaute das etiquetas de balança (P = PLU, V = preço, W = peso, X = ignorado, C = verificador)
+	BarcodeLayout string `json:"barcodeLayout,omitempty" example:"2PPPPPVVVVVVC"`
+}
+
+// UpdateStoreDTO represents data needed to update a store; omitted fields are kept
+type UpdateStoreDTO struct {
+	Name       *string  `json:"name,omitempty" binding:"omitempty,max=255"`
+	Chain      *string  `json:"chain,omitempty" binding:"omitempty,max=100"` // Nome da rede; criada se não existir
+	ChainID    *uint    `json:"chainId,omitempty"`                           // 0 remove a filial da rede
+	BranchName *string  `json:"branchName,omitempty" binding:"omitempty,max=255"`
+	Address    *string  `json:"address,omitempty" binding:"omitempty,max=255"`
+	CNPJ       *string  `json:"cnpj,omitempty" binding:"omitempty,len=14,numeric"` // "" remove o CNPJ
+	Latitude   *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	// "" volta ao leiaute padrão
+	BarcodeLayout *string `json:"barcodeLayout,omitempty"`
+}
+
+// StoreResponseDTO represents the response data for a store
+type StoreResponseDTO struct {
+	ID            uint     `json:"id"`
+	Name          string   `json:"name"`
+	ChainID       *uint    `json:"chainId,omitempty"`
+	Chain         string   `json:"chain"`
+	BranchName    string   `json:"branchName"`
+	Address       string   `json:"address"`
+	CNPJ          *string  `json:"cnpj,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	BarcodeLayout string   `json:"barcodeLayout,omitempty"`
+	OwnerID       *uint    `json:"ownerId,omitempty"` // Presente nos estabelecimentos privados do usuário
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// StoreMatchDTO is a store suggested for a typed name, with its similarity score (0 to 1)
+type StoreMatchDTO struct {
+	Store StoreResponseDTO `json:"store"`
+	Score float64          `json:"score"`
+}
+
+// NearbyStoresQueryDTO represents the filters of the nearby stores search
+type NearbyStoresQueryDTO struct {
+	Lat    *float64 `form:"lat" binding:"required,min=-90,max=90" example:"-23.5505"`
+	Lng    *float64 `form:"lng" binding:"required,min=-180,max=180" example:"-46.6333"`
+	Radius float64  `form:"radius" binding:"omitempty,gt=0,max=50"`  // Em km; padrão 5
+	Limit  int      `form:"limit" binding:"omitempty,min=1,max=100"` // Padrão 20
+}
+
+// NearbyStoreDTO is a store within the searched radius, with its distance in km
+type NearbyStoreDTO struct {
+	Store      StoreResponseDTO `json:"store"`
+	DistanceKm float64          `json:"distanceKm"`
+}
+
+// MergeStoresDTO represents the duplicate stores merged into a surviving store
+type MergeStoresDTO struct {
+	TargetID  uint   `json:"targetId" binding:"required" example:"12"`
+	SourceIDs []uint `json:"sourceIds" binding:"required,min=1"`
+}
+
+// MergeStoresResultDTO summarizes a store merge
+type MergeStoresResultDTO struct {
+	Store                 StoreResponseDTO `json:"store"`
+	MergedStoreIDs        []uint           `json:"mergedStoreIds"`
+	PurchasesUpdated      int64            `json:"purchasesUpdated"`
+	PriceHistoriesUpdated int64            `json:"priceHistoriesUpdated"`
+}
+
+// CreateStoreChainDTO represents data needed to create a store chain
+type CreateStoreChainDTO struct {
+	Name string `json:"name" binding:"required,max=100" example:"Carrefour"`
+}
+
+// UpdateStoreChainDTO represents data needed to rename a store chain
+type UpdateStoreChainDTO struct {
+	Name string `json:"name" binding:"required,max=100" example:"Carrefour"`
+}
+
+// StoreChainResponseDTO represents the response data for a store chain
+type StoreChainResponseDTO struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	BranchCount int64  `json:"branchCount"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+// RestockSuggestionDTO represents a recurring product that is probably running out
+type RestockSuggestionDTO struct {
+	ProductID          uint    `json:"productId"`
+	ProductName        string  `json:"productName"`
+	Unit               string  `json:"unit"` // kg, L or un: quantities below are in this unit
+	LastPurchaseDate   string  `json:"lastPurchaseDate"`
+	ExpectedRunOutDate string  `json:"expectedRunOutDate"`
+	DaysUntilRunOut    float64 `json:"daysUntilRunOut"` // Negative when already overdue
+	MeanIntervalDays   float64 `json:"meanIntervalDays"`
+	QuantityPerDay     float64 `json:"quantityPerDay"`
+	SuggestedQuantity  float64 `json:"suggestedQuantity"`
+	PurchaseCount      int     `json:"purchaseCount"`
+	Confidence         float64 `json:"confidence"` // 0..1
+}
+
+// BuyAgainSuggestionDTO represents a product ranked by purchase frequency and recency
+type BuyAgainSuggestionDTO struct {
+	ProductID        uint    `json:"productId"`
+	ProductName      string  `json:"productName"`
+	PurchaseCount    int     `json:"purchaseCount"`
+	LastPurchaseDate string  `json:"lastPurchaseDate"`
+	DaysSinceLast    float64 `json:"daysSinceLast"`
+	Score            float64 `json:"score"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+// CreateWebhookDTO represents data needed to create a webhook subscription
+type CreateWebhookDTO struct {
+	URL         string   `json:"url" binding:"required,url" example:"https://casa.local/hooks/mercado"`
+	EventTypes  []string `json:"eventTypes" binding:"required,min=1,dive,required" example:"purchase.created,price.alert"`
+	Description string   `json:"description" binding:"max=255" example:"Home Assistant"`
+}
+
+// UpdateWebhookDTO represents data needed to update a webhook subscription
+type UpdateWebhookDTO struct {
+	URL         *string  `json:"url,omitempty" binding:"omitempty,url"`
+	EventTypes  []string `json:"eventTypes,omitempty" binding:"omitempty,min=1,dive,required"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponseDTO represents the response data for a webhook subscription.
+// The secret is only returned when the subscription is created or the secret is rotated.
+type WebhookResponseDTO struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// WebhookDeliveryResponseDTO represents one entry of the delivery log
+type WebhookDeliveryResponseDTO struct {
+	ID             uint   `json:"id"`
+	SubscriptionID uint   `json:"subscriptionId"`
+	EventID        uint   `json:"eventId"`
+	EventType      string `json:"eventType"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	NextAttemptAt  string `json:"nextAttemptAt,omitempty"` // Apenas para entregas pendentes
+	LastStatusCode int    `json:"lastStatusCode,omitempty"`
+	LastError      string `json:"lastError,omitempty"`
+	LastDurationMs int64  `json:"lastDurationMs"`
+	DeliveredAt    string `json:"deliveredAt,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+}
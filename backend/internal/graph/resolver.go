@@ -0,0 +1,166 @@
+// Package graph expõe os dados do app em GraphQL. Cada nível da consulta é resolvido em lote
+// (uma consulta IN por campo) e produtos e categorias passam por dataloaders da requisição, de
+// modo que a mesma entidade pedida em vários pontos da resposta é buscada uma única vez.
+// As regras de acesso são as dos services: cada usuário vê apenas os próprios dados, exceto admins.
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/dataloader"
+	"github.com/Parron01/AppMercado/backend/pkg/graphql"
+)
+
+// maxDepth limita o aninhamento das consultas
+const maxDepth = 8
+
+// maxComplexity limita o número de campos de uma consulta, com os fragmentos expandidos
+const maxComplexity = 1000
+
+// maxPageSize limita o tamanho das páginas das listas paginadas
+const maxPageSize = 100
+
+// Resolver monta o schema GraphQL sobre os services do app
+type Resolver struct {
+	userService                *services.UserService
+	categoryService            *services.CategoryService
+	productService             *services.ProductService
+	purchaseService            *services.PurchaseService
+	userCategoryProductService *services.UserCategoryProductService
+	priceHistoryService        *services.PriceHistoryService
+	schema                     *graphql.Schema
+}
+
+// NewResolver creates a new Resolver and builds its schema
+func NewResolver(
+	userService *services.UserService,
+	categoryService *services.CategoryService,
+	productService *services.ProductService,
+	purchaseService *services.PurchaseService,
+	userCategoryProductService *services.UserCategoryProductService,
+	priceHistoryService *services.PriceHistoryService) (*Resolver, error) {
+	resolver := &Resolver{
+		userService:                userService,
+		categoryService:            categoryService,
+		productService:             productService,
+		purchaseService:            purchaseService,
+		userCategoryProductService: userCategoryProductService,
+		priceHistoryService:        priceHistoryService,
+	}
+	schema, err := resolver.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	schema.MaxDepth = maxDepth
+	schema.MaxComplexity = maxComplexity
+	resolver.schema = schema
+	return resolver, nil
+}
+
+// SDL descreve o schema na linguagem de definição do GraphQL
+func (resolver *Resolver) SDL() string {
+	return resolver.schema.SDL()
+}
+
+// Execute executa a consulta em nome do usuário, com dataloaders novos para a requisição
+func (resolver *Resolver) Execute(
+	ctx context.Context, userID uint, userRole string,
+	query string, operationName string, variables map[string]any) *graphql.Response {
+	state := &requestState{userID: userID, userRole: userRole}
+	state.products = dataloader.New(func(ids []uint) (map[uint]*models.Product, error) {
+		return resolver.productService.GetProductsByIDs(ids, userID, userRole)
+	})
+	state.categories = dataloader.New(func(ids []uint) (map[uint]*models.Category, error) {
+		return resolver.categoryService.GetCategoriesByIDs(ids, userID, userRole)
+	})
+	return resolver.schema.Execute(context.WithValue(ctx, requestStateKey{}, state), query, operationName, variables)
+}
+
+// requestState guarda o usuário da requisição e os dataloaders
+type requestState struct {
+	userID     uint
+	userRole   string
+	products   *dataloader.Loader[uint, *models.Product]
+	categories *dataloader.Loader[uint, *models.Category]
+}
+
+type requestStateKey struct{}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(requestStateKey{}).(*requestState)
+}
+
+// loadEach busca em lote as chaves de cada objeto e monta o resultado na ordem dos objetos;
+// chaves não encontradas (ou não visíveis ao usuário) resultam em null
+func loadEach[V any](
+	loader *dataloader.Loader[uint, V], sources []any, key func(source any) uint) ([]any, error) {
+	keys := make([]uint, len(sources))
+	for i, source := range sources {
+		keys[i] = key(source)
+	}
+	values, err := loader.LoadMany(keys)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]any, len(sources))
+	for i, key := range keys {
+		if value, found := values[key]; found {
+			results[i] = value
+		}
+	}
+	return results, nil
+}
+
+// groupEach monta, na ordem dos objetos, as listas agrupadas pela chave de cada um (lista vazia quando não há itens)
+func groupEach[V any](grouped map[uint][]V, sources []any, key func(source any) uint) []any {
+	results := make([]any, len(sources))
+	for i, source := range sources {
+		values := grouped[key(source)]
+		if values == nil {
+			values = []V{}
+		}
+		results[i] = values
+	}
+	return results
+}
+
+// idArgument lê um argumento do tipo ID
+func idArgument(args map[string]any, name string) (uint, error) {
+	text, _ := args[name].(string)
+	id, err := strconv.ParseUint(text, 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("ID inválido: " + text)
+	}
+	return uint(id), nil
+}
+
+// pageArguments lê limit e offset, limitando o tamanho da página
+func pageArguments(args map[string]any) (limit, offset int, err error) {
+	limit, _ = args["limit"].(int)
+	offset, _ = args["offset"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, errors.New("limit deve estar entre 1 e " + strconv.Itoa(maxPageSize))
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset não pode ser negativo")
+	}
+	return limit, offset, nil
+}
+
+// dateArgument lê uma data opcional em RFC 3339, como nos endpoints REST
+func dateArgument(args map[string]any, name string) (*time.Time, error) {
+	text, _ := args[name].(string)
+	if text == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return nil, errors.New(name + ": data inválida, use RFC 3339")
+	}
+	return &date, nil
+}
@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeRows são as linhas de cada tabela servidas pelo driver de teste
+var fakeRows = map[string][]map[string]driver.Value{
+	"users": {
+		{"id": int64(1), "name": "Ana", "email": "ana@exemplo.com", "role": string(models.RoleStandard)},
+		{"id": int64(2), "name": "Bruno", "email": "bruno@exemplo.com", "role": string(models.RoleStandard)},
+	},
+	"categories": {
+		{"id": int64(10), "user_id": int64(1), "name": "Mercearia da Ana"},
+		{"id": int64(20), "user_id": int64(2), "name": "Mercearia do Bruno"},
+	},
+	"products": {
+		{"id": int64(30), "name": "Arroz", "status": string(models.ProductApproved)},
+		{"id": int64(31), "name": "Feijão sugerido", "status": string(models.ProductPending), "proposed_by_id": int64(2)},
+	},
+	"product_redirects": {
+		{"id": int64(1), "from_product_id": int64(5), "product_id": int64(30)},
+	},
+	"purchases": {
+		{"id": int64(100), "user_id": int64(1), "purchase_location": "Mercado A", "total": 10.5},
+		{"id": int64(200), "user_id": int64(2), "purchase_location": "Mercado B", "total": 99.9},
+	},
+}
+
+var (
+	tablePattern  = regexp.MustCompile(`FROM "(\w+)"`)
+	filterPattern = regexp.MustCompile(`"?(from_product_id|user_id|id)"? (?:IN|=) `)
+)
+
+// fakeConnector é um driver database/sql mínimo: responde SELECTs de uma tabela filtrando as
+// linhas pela coluna (id, user_id ou from_product_id) comparada na primeira condição do WHERE
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (stmt fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var table string
+	if match := tablePattern.FindStringSubmatch(stmt.query); match != nil {
+		table = match[1]
+	}
+	column := ""
+	if match := filterPattern.FindStringSubmatch(stmt.query); match != nil {
+		column = match[1]
+	}
+
+	rows := &fakeResult{}
+	for _, row := range fakeRows[table] {
+		if column != "" && !containsValue(args, row[column]) {
+			continue
+		}
+		rows.rows = append(rows.rows, row)
+	}
+	for _, row := range fakeRows[table] {
+		for name := range row {
+			if !containsColumn(rows.columns, name) {
+				rows.columns = append(rows.columns, name)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func containsValue(values []driver.Value, value driver.Value) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeResult struct {
+	columns []string
+	rows    []map[string]driver.Value
+	next    int
+}
+
+func (result *fakeResult) Columns() []string { return result.columns }
+func (result *fakeResult) Close() error      { return nil }
+
+func (result *fakeResult) Next(dest []driver.Value) error {
+	if result.next >= len(result.rows) {
+		return io.EOF
+	}
+	for i, column := range result.columns {
+		dest[i] = result.rows[result.next][column]
+	}
+	result.next++
+	return nil
+}
+
+func newTestResolver(t *testing.T) *Resolver {
+	t.Helper()
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fakeConnector{})}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	transactionManager := repositories.NewTransactionManager(database)
+	userService := services.NewUserService(repositories.NewUserRepository(database))
+	categoryService := services.NewCategoryService(repositories.NewCategoryRepository(database), repositories.NewBudgetRepository(database), transactionManager)
+	productService := services.NewProductService(repositories.NewProductRepository(database), transactionManager)
+	purchaseService := services.NewPurchaseService(repositories.NewPurchaseRepository(database), transactionManager, productService)
+	ucpService := services.NewUserCategoryProductService(
+		repositories.NewUserCategoryProductRepository(database), categoryService, productService)
+	priceHistoryService := services.NewPriceHistoryService(
+		repositories.NewPriceHistoryRepository(database), productService, userService, nil, nil, &config.Config{})
+
+	resolver, err := NewResolver(userService, categoryService, productService, purchaseService, ucpService, priceHistoryService)
+	if err != nil {
+		t.Fatalf("NewResolver: %v", err)
+	}
+	return resolver
+}
+
+func TestResolverOwnership(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      models.Role
+		query     string
+		wantData  string
+		wantError string
+	}{
+		{
+			name:     "própria categoria",
+			role:     models.RoleStandard,
+			query:    `{ category(id: 10) { name } }`,
+			wantData: `{"category":{"name":"Mercearia da Ana"}}`,
+		},
+		{
+			name:      "categoria de outro usuário",
+			role:      models.RoleStandard,
+			query:     `{ category(id: 20) { name userId } }`,
+			wantData:  `{"category":null}`,
+			wantError: "categoria não encontrada",
+		},
+		{
+			name:     "própria compra",
+			role:     models.RoleStandard,
+			query:    `{ purchase(id: 100) { purchaseLocation total } }`,
+			wantData: `{"purchase":{"purchaseLocation":"Mercado A","total":10.5}}`,
+		},
+		{
+			name:      "compra de outro usuário",
+			role:      models.RoleStandard,
+			query:     `{ purchase(id: 200) { purchaseLocation total } }`,
+			wantData:  `{"purchase":null}`,
+			wantError: "compra não encontrada",
+		},
+		{
+			name:     "listas trazem apenas os dados do usuário",
+			role:     models.RoleStandard,
+			query:    `{ categories { id } purchases { id } }`,
+			wantData: `{"categories":[{"id":"10"}],"purchases":[{"id":"100"}]}`,
+		},
+		{
+			name:      "usuários de outros só com admin",
+			role:      models.RoleStandard,
+			query:     `{ users { purchases { id } categories { id } } }`,
+			wantData:  `{"users":null}`,
+			wantError: "permissão negada",
+		},
+		{
+			name:     "produto mesclado leva ao que o absorveu",
+			role:     models.RoleStandard,
+			query:    `{ product(id: 5) { id name } }`,
+			wantData: `{"product":{"id":"30","name":"Arroz"}}`,
+		},
+		{
+			name:      "sugestão pendente de outro usuário",
+			role:      models.RoleStandard,
+			query:     `{ product(id: 31) { name } }`,
+			wantData:  `{"product":null}`,
+			wantError: "produto não encontrado",
+		},
+		{
+			name:     "admin lê categoria e compra de outro usuário",
+			role:     models.RoleAdmin,
+			query:    `{ category(id: 20) { name } purchase(id: 200) { total } }`,
+			wantData: `{"category":{"name":"Mercearia do Bruno"},"purchase":{"total":99.9}}`,
+		},
+	}
+
+	resolver := newTestResolver(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := resolver.Execute(context.Background(), 1, string(test.role), test.query, "", nil)
+
+			data, err := json.Marshal(response.Data)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(data) != test.wantData {
+				t.Errorf("data = %s\n  esperado %s", data, test.wantData)
+			}
+			switch {
+			case test.wantError == "" && len(response.Errors) > 0:
+				t.Errorf("erro inesperado: %s", response.Errors[0].Message)
+			case test.wantError != "" && (len(response.Errors) == 0 || !strings.Contains(response.Errors[0].Message, test.wantError)):
+				t.Errorf("errors = %+v, esperado conter %q", response.Errors, test.wantError)
+			}
+		})
+	}
+}
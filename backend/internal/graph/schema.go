@@ -0,0 +1,663 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/graphql"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// priceHistoryNode é um registro de preço como exposto no GraphQL (escopos personal e household)
+type priceHistoryNode struct {
+	ID            *uint
+	UserID        *uint
+	ProductID     uint
+	PurchaseDate  string
+	PurchasePlace string
+	StoreID       *uint
+	PricePaid     float64
+	Quantity      float64
+	Unit          string
+}
+
+func toPriceHistoryNode(priceHistory *models.PriceHistory) *priceHistoryNode {
+	return &priceHistoryNode{
+		ID:            &priceHistory.ID,
+		UserID:        &priceHistory.UserID,
+		ProductID:     priceHistory.ProductID,
+		PurchaseDate:  priceHistory.PurchaseDate.Format(time.RFC3339),
+		PurchasePlace: priceHistory.PurchasePlace,
+		StoreID:       priceHistory.StoreID,
+		PricePaid:     utils.FormatForDisplay(priceHistory.PricePaid),
+		Quantity:      priceHistory.Quantity,
+		Unit:          priceHistory.Unit,
+	}
+}
+
+// Chaves usadas para agrupar e carregar em lote
+func modelID(source any) uint {
+	switch source := source.(type) {
+	case *models.User:
+		return source.ID
+	case *models.Category:
+		return source.ID
+	case *models.Product:
+		return source.ID
+	case *models.Purchase:
+		return source.ID
+	}
+	return 0
+}
+
+func productIDOf(source any) uint {
+	switch source := source.(type) {
+	case *models.PurchaseItem:
+		return source.ProductID
+	case *models.UserCategoryProduct:
+		return source.ProductID
+	case *priceHistoryNode:
+		return source.ProductID
+	}
+	return 0
+}
+
+func typedSources[T any](sources []any) []T {
+	typed := make([]T, len(sources))
+	for i, source := range sources {
+		typed[i] = source.(T)
+	}
+	return typed
+}
+
+// sourceIDs retorna os IDs dos objetos sem repetição (o mesmo objeto pode aparecer em vários pontos do nível)
+func sourceIDs(sources []any) []uint {
+	ids := make([]uint, 0, len(sources))
+	seen := make(map[uint]bool, len(sources))
+	for _, source := range sources {
+		if id := modelID(source); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// buildSchema define os tipos e os resolvers
+func (resolver *Resolver) buildSchema() (*graphql.Schema, error) {
+	productField := &graphql.FieldDef{
+		Name: "product",
+		Type: "Product",
+		BatchResolve: func(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+			return loadEach(stateFrom(ctx).products, sources, productIDOf)
+		},
+	}
+
+	userType := &graphql.Object{
+		Name: "User",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "name", Type: "String!"},
+			{Name: "email", Type: "String!"},
+			{Name: "role", Type: "String!"},
+			{Name: "createdAt", Type: "String!"},
+			{Name: "categories", Type: "[Category!]!", BatchResolve: resolver.userCategories},
+			{
+				Name:         "purchases",
+				Type:         "[Purchase!]!",
+				Description:  "Compras do usuário, da mais recente para a mais antiga, paginadas por usuário",
+				Args:         []*graphql.ArgumentDef{{Name: "limit", Type: "Int", Default: 20}, {Name: "offset", Type: "Int", Default: 0}},
+				BatchResolve: resolver.userPurchases,
+			},
+			{Name: "userCategoryProducts", Type: "[UserCategoryProduct!]!", BatchResolve: resolver.userUserCategoryProducts},
+		},
+	}
+
+	categoryType := &graphql.Object{
+		Name: "Category",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "name", Type: "String!"},
+			{Name: "userId", Type: "ID!"},
+			{Name: "parentId", Type: "ID"},
+			{Name: "position", Type: "Int!", Description: "Ordem entre as categorias irmãs"},
+			{Name: "createdAt", Type: "String!"},
+			{
+				Name: "parent",
+				Type: "Category",
+				BatchResolve: func(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+					return loadEach(stateFrom(ctx).categories, sources, func(source any) uint {
+						if parentID := source.(*models.Category).ParentID; parentID != nil {
+							return *parentID
+						}
+						return 0
+					})
+				},
+			},
+			{
+				Name:         "products",
+				Type:         "[Product!]!",
+				Description:  "Produtos da categoria e de suas subcategorias",
+				BatchResolve: resolver.categoryProducts,
+			},
+			{
+				Name:         "userCategoryProducts",
+				Type:         "[UserCategoryProduct!]!",
+				Description:  "Vínculos da categoria e de suas subcategorias",
+				BatchResolve: resolver.categoryUserCategoryProducts,
+			},
+		},
+	}
+
+	scopeArgument := &graphql.ArgumentDef{Name: "scope", Type: "String", Default: services.PriceScopePersonal}
+	productType := &graphql.Object{
+		Name:        "Product",
+		Description: "Produto do catálogo global",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "name", Type: "String!"},
+			{Name: "barcode", Type: "String"},
+			{Name: "brand", Type: "String!"},
+			{Name: "packageLabel", Type: "String!"},
+			{Name: "packageQuantity", Type: "Float", Description: "Conteúdo da embalagem na unidade"},
+			{Name: "unit", Type: "String!", Description: "g, kg, ml, L ou un (vazio sem embalagem cadastrada)"},
+			{Name: "imageUrl", Type: "String!"},
+			{
+				Name:         "categories",
+				Type:         "[Category!]!",
+				Description:  "Categorias do usuário autenticado às quais o produto pertence",
+				BatchResolve: resolver.productCategories,
+			},
+			{
+				Name:        "priceHistory",
+				Type:        "[PriceHistory!]!",
+				Description: "Histórico de preços no escopo personal ou household; a comunidade só aparece agregada, em statistics",
+				Args: []*graphql.ArgumentDef{
+					scopeArgument,
+					{Name: "startDate", Type: "String"},
+					{Name: "endDate", Type: "String"},
+				},
+				BatchResolve: resolver.productPriceHistory,
+			},
+			{
+				Name:        "statistics",
+				Type:        "PriceStatistics",
+				Description: "Estatísticas de preço no escopo informado, com as mesmas regras de /price-history/product/:productId/statistics",
+				Args: []*graphql.ArgumentDef{
+					scopeArgument,
+					{Name: "store", Type: "String"},
+					{Name: "startDate", Type: "String"},
+					{Name: "endDate", Type: "String"},
+				},
+				BatchResolve: resolver.productStatistics,
+			},
+		},
+	}
+
+	purchaseType := &graphql.Object{
+		Name: "Purchase",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "purchaseDate", Type: "String!"},
+			{Name: "purchaseLocation", Type: "String!"},
+			{Name: "storeId", Type: "ID"},
+			{Name: "userId", Type: "ID!"},
+			{Name: "invoiceKey", Type: "String"},
+			{Name: "total", Type: "Float!"},
+			{Name: "items", Type: "[PurchaseItem!]!", BatchResolve: resolver.purchaseItems},
+		},
+	}
+
+	purchaseItemType := &graphql.Object{
+		Name: "PurchaseItem",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "productId", Type: "ID!"},
+			{Name: "quantity", Type: "Float!"},
+			{Name: "unit", Type: "String!", Description: "g, kg, ml, L ou un; o preço unitário é por ela"},
+			{Name: "unitPrice", Type: "Float!"},
+			{Name: "totalPrice", Type: "Float!"},
+			productField,
+		},
+	}
+
+	priceHistoryType := &graphql.Object{
+		Name:        "PriceHistory",
+		Description: "Registro de preço pago",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID"},
+			{Name: "userId", Type: "ID"},
+			{Name: "productId", Type: "ID!"},
+			{Name: "purchaseDate", Type: "String!"},
+			{Name: "purchasePlace", Type: "String!"},
+			{Name: "storeId", Type: "ID"},
+			{Name: "pricePaid", Type: "Float!"},
+			{Name: "quantity", Type: "Float!"},
+			{Name: "unit", Type: "String!"},
+			productField,
+		},
+	}
+
+	userCategoryProductType := &graphql.Object{
+		Name: "UserCategoryProduct",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "ID!"},
+			{Name: "userId", Type: "ID!"},
+			{Name: "categoryId", Type: "ID!"},
+			{Name: "productId", Type: "ID!"},
+			{Name: "createdAt", Type: "String!"},
+			{
+				Name: "category",
+				Type: "Category",
+				BatchResolve: func(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+					return loadEach(stateFrom(ctx).categories, sources, func(source any) uint {
+						return source.(*models.UserCategoryProduct).CategoryID
+					})
+				},
+			},
+			productField,
+		},
+	}
+
+	priceStatisticsType := &graphql.Object{
+		Name: "PriceStatistics",
+		Fields: []*graphql.FieldDef{
+			{Name: "scope", Type: "String!"},
+			{Name: "productId", Type: "ID!"},
+			{Name: "recordsCount", Type: "Int!"},
+			{Name: "currentAvgPrice", Type: "Float!"},
+			{Name: "weightedAvgPrice", Type: "Float!"},
+			{Name: "medianPrice", Type: "Float!"},
+			{Name: "lowestPrice", Type: "Float!"},
+			{Name: "highestPrice", Type: "Float!"},
+			{Name: "priceVariation", Type: "Float!"},
+			{Name: "stdDevPrice", Type: "Float!"},
+			{Name: "p10Price", Type: "Float!"},
+			{Name: "p90Price", Type: "Float!"},
+			{Name: "lastPricePaid", Type: "Float!"},
+			{Name: "firstRecordDate", Type: "String!"},
+			{Name: "lastRecordDate", Type: "String!"},
+			{Name: "unitPrices", Type: "UnitPriceStatistics", Description: "Estatísticas por kg, L ou unidade"},
+		},
+	}
+
+	unitPriceStatisticsType := &graphql.Object{
+		Name: "UnitPriceStatistics",
+		Fields: []*graphql.FieldDef{
+			{Name: "unit", Type: "String!"},
+			{Name: "currentAvgPrice", Type: "Float!"},
+			{Name: "weightedAvgPrice", Type: "Float!"},
+			{Name: "medianPrice", Type: "Float!"},
+			{Name: "lowestPrice", Type: "Float!"},
+			{Name: "highestPrice", Type: "Float!"},
+			{Name: "lastPricePaid", Type: "Float!"},
+		},
+	}
+
+	pageArgs := func(defaultLimit int) []*graphql.ArgumentDef {
+		return []*graphql.ArgumentDef{{Name: "limit", Type: "Int", Default: defaultLimit}, {Name: "offset", Type: "Int", Default: 0}}
+	}
+	queryType := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.FieldDef{
+			{Name: "me", Type: "User!", Resolve: resolver.me},
+			{Name: "users", Type: "[User!]!", Description: "Todos os usuários (apenas admin)", Resolve: resolver.users},
+			{Name: "product", Type: "Product", Args: []*graphql.ArgumentDef{{Name: "id", Type: "ID!"}}, Resolve: resolver.product},
+			{
+				Name:    "products",
+				Type:    "[Product!]!",
+				Args:    append([]*graphql.ArgumentDef{{Name: "search", Type: "String"}}, pageArgs(50)...),
+				Resolve: resolver.products,
+			},
+			{Name: "category", Type: "Category", Args: []*graphql.ArgumentDef{{Name: "id", Type: "ID!"}}, Resolve: resolver.category},
+			{Name: "categories", Type: "[Category!]!", Description: "Categorias do usuário autenticado", Resolve: resolver.categories},
+			{Name: "purchase", Type: "Purchase", Args: []*graphql.ArgumentDef{{Name: "id", Type: "ID!"}}, Resolve: resolver.purchase},
+			{
+				Name:        "purchases",
+				Type:        "[Purchase!]!",
+				Description: "Compras do usuário autenticado, da mais recente para a mais antiga",
+				Args:        pageArgs(20),
+				Resolve:     resolver.purchases,
+			},
+			{
+				Name:        "userCategoryProducts",
+				Type:        "[UserCategoryProduct!]!",
+				Description: "Vínculos produto-categoria do usuário autenticado",
+				Resolve:     resolver.userCategoryProducts,
+			},
+		},
+	}
+
+	return graphql.NewSchema(queryType, userType, categoryType, productType, purchaseType, purchaseItemType,
+		priceHistoryType, userCategoryProductType, priceStatisticsType, unitPriceStatisticsType)
+}
+
+// Query
+
+func (resolver *Resolver) me(ctx context.Context, source any, args map[string]any) (any, error) {
+	state := stateFrom(ctx)
+	users, err := resolver.userService.GetUsersByIDs([]uint{state.userID}, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	user, found := users[state.userID]
+	if !found {
+		return nil, errors.New("usuário não encontrado")
+	}
+	return user, nil
+}
+
+func (resolver *Resolver) users(ctx context.Context, source any, args map[string]any) (any, error) {
+	return resolver.userService.GetAllUsers(stateFrom(ctx).userRole)
+}
+
+func (resolver *Resolver) product(ctx context.Context, source any, args map[string]any) (any, error) {
+	id, err := idArgument(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	// Mesmo caminho de GET /products/:id: visibilidade da moderação e redirecionamento de produtos mesclados
+	state := stateFrom(ctx)
+	product, err := resolver.productService.GetVisibleProductByID(id, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	state.products.Prime(product.ID, product)
+	return product, nil
+}
+
+func (resolver *Resolver) products(ctx context.Context, source any, args map[string]any) (any, error) {
+	limit, offset, err := pageArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	search, _ := args["search"].(string)
+	state := stateFrom(ctx)
+	products, err := resolver.productService.SearchProducts(search, limit, offset, state.userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		state.products.Prime(product.ID, product)
+	}
+	if products == nil {
+		return []*models.Product{}, nil
+	}
+	return products, nil
+}
+
+func (resolver *Resolver) category(ctx context.Context, source any, args map[string]any) (any, error) {
+	id, err := idArgument(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	category, found, err := stateFrom(ctx).categories.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("categoria não encontrada")
+	}
+	return category, nil
+}
+
+func (resolver *Resolver) categories(ctx context.Context, source any, args map[string]any) (any, error) {
+	state := stateFrom(ctx)
+	categoriesByUser, err := resolver.categoryService.GetCategoriesByUserIDs([]uint{state.userID}, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.primeCategories(state, categoriesByUser[state.userID]), nil
+}
+
+func (resolver *Resolver) purchase(ctx context.Context, source any, args map[string]any) (any, error) {
+	id, err := idArgument(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	state := stateFrom(ctx)
+	purchases, err := resolver.purchaseService.GetPurchasesByIDs([]uint{id}, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	purchase, found := purchases[id]
+	if !found {
+		return nil, errors.New("compra não encontrada")
+	}
+	return purchase, nil
+}
+
+func (resolver *Resolver) purchases(ctx context.Context, source any, args map[string]any) (any, error) {
+	limit, offset, err := pageArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	state := stateFrom(ctx)
+	purchasesByUser, err := resolver.purchaseService.GetPurchasesByUserIDs([]uint{state.userID}, limit, offset, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	if purchases := purchasesByUser[state.userID]; purchases != nil {
+		return purchases, nil
+	}
+	return []*models.Purchase{}, nil
+}
+
+func (resolver *Resolver) userCategoryProducts(ctx context.Context, source any, args map[string]any) (any, error) {
+	state := stateFrom(ctx)
+	ucpsByUser, err := resolver.userCategoryProductService.GetUserCategoryProductsByUserIDs([]uint{state.userID}, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	if ucps := ucpsByUser[state.userID]; ucps != nil {
+		return ucps, nil
+	}
+	return []*models.UserCategoryProduct{}, nil
+}
+
+// User
+
+func (resolver *Resolver) userCategories(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	state := stateFrom(ctx)
+	categoriesByUser, err := resolver.categoryService.GetCategoriesByUserIDs(sourceIDs(sources), state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	for _, categories := range categoriesByUser {
+		resolver.primeCategories(state, categories)
+	}
+	return groupEach(categoriesByUser, sources, modelID), nil
+}
+
+func (resolver *Resolver) userPurchases(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	limit, offset, err := pageArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	state := stateFrom(ctx)
+	purchasesByUser, err := resolver.purchaseService.GetPurchasesByUserIDs(sourceIDs(sources), limit, offset, state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	return groupEach(purchasesByUser, sources, modelID), nil
+}
+
+func (resolver *Resolver) userUserCategoryProducts(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	state := stateFrom(ctx)
+	ucpsByUser, err := resolver.userCategoryProductService.GetUserCategoryProductsByUserIDs(sourceIDs(sources), state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	return groupEach(ucpsByUser, sources, modelID), nil
+}
+
+// Category
+
+func (resolver *Resolver) categoryUserCategoryProducts(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	state := stateFrom(ctx)
+	ucpsByCategory, err := resolver.userCategoryProductService.GetUserCategoryProductsByCategories(
+		typedSources[*models.Category](sources), state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	return groupEach(ucpsByCategory, sources, modelID), nil
+}
+
+func (resolver *Resolver) categoryProducts(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	ucpLists, err := resolver.categoryUserCategoryProducts(ctx, sources, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []uint
+	for _, ucps := range ucpLists {
+		for _, ucp := range ucps.([]*models.UserCategoryProduct) {
+			productIDs = append(productIDs, ucp.ProductID)
+		}
+	}
+	products, err := stateFrom(ctx).products.LoadMany(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(sources))
+	for i, ucps := range ucpLists {
+		categoryProducts := []*models.Product{}
+		seen := make(map[uint]bool)
+		for _, ucp := range ucps.([]*models.UserCategoryProduct) {
+			// O mesmo produto pode estar vinculado à categoria e a uma subcategoria
+			if product, found := products[ucp.ProductID]; found && !seen[product.ID] {
+				seen[product.ID] = true
+				categoryProducts = append(categoryProducts, product)
+			}
+		}
+		results[i] = categoryProducts
+	}
+	return results, nil
+}
+
+// Product
+
+func (resolver *Resolver) productCategories(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	state := stateFrom(ctx)
+	ucpsByProduct, err := resolver.userCategoryProductService.GetUserCategoryProductsByProducts(sourceIDs(sources), state.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryIDs []uint
+	for _, ucps := range ucpsByProduct {
+		for _, ucp := range ucps {
+			categoryIDs = append(categoryIDs, ucp.CategoryID)
+		}
+	}
+	categories, err := state.categories.LoadMany(categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(sources))
+	for i, source := range sources {
+		productCategories := []*models.Category{}
+		for _, ucp := range ucpsByProduct[modelID(source)] {
+			if category, found := categories[ucp.CategoryID]; found {
+				productCategories = append(productCategories, category)
+			}
+		}
+		results[i] = productCategories
+	}
+	return results, nil
+}
+
+func (resolver *Resolver) productPriceHistory(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	startDate, err := dateArgument(args, "startDate")
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := dateArgument(args, "endDate")
+	if err != nil {
+		return nil, err
+	}
+	scope, _ := args["scope"].(string)
+
+	state := stateFrom(ctx)
+	historiesByProduct, restricted, err := resolver.priceHistoryService.GetPriceHistoryByProductsScoped(
+		sourceIDs(sources), scope, state.userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(sources))
+	for i, source := range sources {
+		productID := modelID(source)
+		if restrictedErr, blocked := restricted[productID]; blocked {
+			results[i] = restrictedErr
+			continue
+		}
+		nodes := []*priceHistoryNode{}
+		for _, priceHistory := range historiesByProduct[productID] {
+			nodes = append(nodes, toPriceHistoryNode(priceHistory))
+		}
+		results[i] = nodes
+	}
+	return results, nil
+}
+
+func (resolver *Resolver) productStatistics(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	queryDTO := dto.PriceStatisticsQueryDTO{}
+	queryDTO.Scope, _ = args["scope"].(string)
+	queryDTO.Store, _ = args["store"].(string)
+	for name, target := range map[string]*time.Time{"startDate": &queryDTO.StartDate, "endDate": &queryDTO.EndDate} {
+		date, err := dateArgument(args, name)
+		if err != nil {
+			return nil, err
+		}
+		if date != nil {
+			*target = *date
+		}
+	}
+
+	state := stateFrom(ctx)
+	statistics, restricted, err := resolver.priceHistoryService.GetProductsPriceStatistics(
+		typedSources[*models.Product](sources), queryDTO, state.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(sources))
+	for i, source := range sources {
+		productID := modelID(source)
+		if restrictedErr, blocked := restricted[productID]; blocked {
+			results[i] = restrictedErr
+			continue
+		}
+		results[i] = statistics[productID]
+	}
+	return results, nil
+}
+
+// Purchase
+
+func (resolver *Resolver) purchaseItems(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+	state := stateFrom(ctx)
+	itemsByPurchase, err := resolver.purchaseService.GetPurchaseItems(typedSources[*models.Purchase](sources), state.userID, state.userRole)
+	if err != nil {
+		return nil, err
+	}
+	return groupEach(itemsByPurchase, sources, modelID), nil
+}
+
+// primeCategories adiciona ao dataloader as categorias já carregadas por outra consulta
+func (resolver *Resolver) primeCategories(state *requestState, categories []*models.Category) []*models.Category {
+	for _, category := range categories {
+		state.categories.Prime(category.ID, category)
+	}
+	if categories == nil {
+		return []*models.Category{}
+	}
+	return categories
+}
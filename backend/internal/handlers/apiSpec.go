@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/pkg/graphql"
+	"github.com/Parron01/AppMercado/backend/pkg/openapi"
+)
+
+// Parâmetros de query lidos diretamente pelos handlers (sem DTO de binding)
+type (
+	priceHistoryByProductQuery struct {
+		Scope     string    `form:"scope" binding:"omitempty,oneof=personal household community"` // padrão: personal
+		StartDate time.Time `form:"startDate"`                                                    // RFC3339; exige endDate
+		EndDate   time.Time `form:"endDate"`
+	}
+	priceOutliersQuery struct {
+		ProductID uint `form:"productId"`
+	}
+	userCategoryProductDeleteQuery struct {
+		CategoryID uint `form:"categoryId" binding:"required"`
+		ProductID  uint `form:"productId" binding:"required"`
+	}
+	budgetMonthQuery struct {
+		Month string `form:"month" example:"2025-01"` // padrão: mês atual
+	}
+	restockQuery struct {
+		HorizonDays int `form:"horizonDays" binding:"omitempty,gte=0" example:"7"`
+	}
+	buyAgainQuery struct {
+		Limit int `form:"limit" binding:"omitempty,min=1" example:"20"`
+	}
+	webhookDeliveriesQuery struct {
+		Limit int `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	}
+	storeSearchQuery struct {
+		Search string `form:"search" example:"carrefour"`
+	}
+	storeMatchQuery struct {
+		Name  string `form:"name" binding:"required" example:"Carrefur Centro"`
+		Limit int    `form:"limit" binding:"omitempty,min=1,max=50" example:"5"`
+	}
+)
+
+var apiTags = []openapi.Tag{
+	{Name: "auth", Description: "Cadastro e login (JWT)"},
+	{Name: "users", Description: "Administração de usuários"},
+	{Name: "categories", Description: "Categorias de cada usuário"},
+	{Name: "products", Description: "Catálogo global de produtos"},
+	{Name: "product-proposals", Description: "Sugestões de produtos pelos usuários e fila de moderação"},
+	{Name: "catalog-import", Description: "Importação em massa do catálogo (Open Food Facts)"},
+	{Name: "stores", Description: "Estabelecimentos compartilhados entre os usuários"},
+	{Name: "chains", Description: "Redes de estabelecimentos e suas filiais"},
+	{Name: "barcodes", Description: "Leitura de códigos de barras e PLUs das etiquetas de balança"},
+	{Name: "purchases", Description: "Compras e seus itens"},
+	{Name: "purchase-import", Description: "Importação de compras (CSV, NFC-e e cupom em texto)"},
+	{Name: "price-history", Description: "Histórico de preços"},
+	{Name: "user-category-products", Description: "Associação de produtos às categorias do usuário"},
+	{Name: "budgets", Description: "Orçamentos mensais"},
+	{Name: "households", Description: "Domicílios compartilhados"},
+	{Name: "suggestions", Description: "Sugestões de reposição e recompra"},
+	{Name: "exports", Description: "Exportação de dados (CSV, XLSX, NDJSON)"},
+	{Name: "backups", Description: "Backup e restauração"},
+	{Name: "webhooks", Description: "Webhooks assinados com HMAC"},
+	{Name: "graphql", Description: "Consultas GraphQL sobre os mesmos dados e regras de acesso"},
+	{Name: "docs", Description: "Esta documentação"},
+}
+
+// NewAPIDocument monta a especificação OpenAPI de todas as rotas registradas pelos Register*Routes.
+// A tabela de rotas (apiRoutes) é mantida à mão; só os schemas saem dos DTOs. Toda rota nova precisa
+// entrar em apiRoutes: TestAPISpecCoversRoutes falha sem ela.
+func NewAPIDocument() *openapi.Document {
+	return openapi.Build(openapi.Info{
+		Title:       "AppMercado API",
+		Version:     "1.0.0",
+		Description: "API de controle de compras de supermercado e histórico de preços.",
+	}, apiTags, apiRoutes())
+}
+
+func apiRoutes() []openapi.Route {
+	message := func(text string) openapi.Object { return openapi.Object{"message": text} }
+	const exportFormats = "Formato escolhido em format: csv (padrão), xlsx ou ndjson."
+
+	return []openapi.Route{
+		// Auth
+		{Method: http.MethodPost, Path: "/auth/register", Tag: "auth", Summary: "Cadastra um usuário e retorna o token", Public: true,
+			Body: dto.CreateUserDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Usuário criado com sucesso", "user": dto.UserResponseDTO{}, "token": ""}},
+		{Method: http.MethodPost, Path: "/auth/login", Tag: "auth", Summary: "Autentica e retorna o token", Public: true,
+			Body: dto.LoginUserDTO{}, Response: openapi.Object{"user": dto.UserResponseDTO{}, "token": ""}},
+
+		// Users
+		{Method: http.MethodGet, Path: "/users/all", Tag: "users", Summary: "Lista todos os usuários", Admin: true,
+			Response: openapi.Object{"users": []dto.UserResponseDTO{}, "count": 0}},
+		{Method: http.MethodDelete, Path: "/users/delete/:id", Tag: "users", Summary: "Remove um usuário", Admin: true,
+			Response: message("Usuário deletado com sucesso")},
+
+		// Categories
+		{Method: http.MethodPost, Path: "/categories/create", Tag: "categories", Summary: "Cria uma categoria",
+			Body: dto.CreateCategoryDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Categoria criada com sucesso", "category": dto.CategoryResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/categories/my", Tag: "categories", Summary: "Lista as categorias do usuário",
+			Response: openapi.Object{"categories": []dto.CategoryResponseDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/categories/tree", Tag: "categories", Summary: "Árvore de categorias com os totais do mês",
+			Description: "Os totais de cada categoria incluem os das subcategorias; um produto em mais de uma delas conta uma vez.",
+			Query:       dto.CategoryTreeQueryDTO{}, Errors: []int{http.StatusNotFound}, Response: openapi.Object{"tree": dto.CategoryTreeDTO{}}},
+		{Method: http.MethodPut, Path: "/categories/reorder", Tag: "categories", Summary: "Reordena as subcategorias diretas de uma categoria",
+			Body:     dto.ReorderCategoriesDTO{},
+			Response: openapi.Object{"message": "Categorias reordenadas com sucesso", "categories": []dto.CategoryResponseDTO{}}},
+		{Method: http.MethodPut, Path: "/categories/:id/move", Tag: "categories", Summary: "Move uma categoria na árvore",
+			Description: "As subcategorias acompanham a categoria; não é possível movê-la para dentro de si mesma ou de uma subcategoria.",
+			Body:        dto.MoveCategoryDTO{},
+			Response:    openapi.Object{"message": "Categoria movida com sucesso", "category": dto.CategoryResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/categories/:id", Tag: "categories", Summary: "Busca uma categoria",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"category": dto.CategoryResponseDTO{}}},
+		{Method: http.MethodPut, Path: "/categories/update/:id", Tag: "categories", Summary: "Renomeia uma categoria",
+			Body: dto.UpdateCategoryDTO{}, Errors: []int{http.StatusForbidden},
+			Response: openapi.Object{"message": "Categoria atualizada com sucesso", "category": dto.CategoryResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/categories/delete/:id", Tag: "categories", Summary: "Remove uma categoria",
+			Description: "As subcategorias sobem um nível.", Errors: []int{http.StatusForbidden}, Response: message("Categoria deletada com sucesso")},
+		{Method: http.MethodGet, Path: "/categories/all", Tag: "categories", Summary: "Lista as categorias de todos os usuários", Admin: true,
+			Response: openapi.Object{"categories": []dto.CategoryResponseDTO{}, "count": 0}},
+
+		// Products
+		{Method: http.MethodPost, Path: "/products/create", Tag: "products", Summary: "Cria um produto", Admin: true,
+			Body: dto.CreateProductDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Produto criado com sucesso.", "product": dto.ProductResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/products/:id", Tag: "products", Summary: "Busca um produto",
+			Response: dto.ProductResponseDTO{}},
+		{Method: http.MethodGet, Path: "/products/all", Tag: "products", Summary: "Lista todos os produtos",
+			Response: []dto.ProductResponseDTO{}},
+		{Method: http.MethodPut, Path: "/products/update/:id", Tag: "products", Summary: "Atualiza um produto", Admin: true,
+			Body: dto.UpdateProductDTO{}, Response: dto.ProductResponseDTO{}},
+		{Method: http.MethodDelete, Path: "/products/delete/:id", Tag: "products", Summary: "Remove um produto", Admin: true,
+			Response: message("Produto deletado com sucesso")},
+		{Method: http.MethodGet, Path: "/products/:id/statistics", Tag: "products", Summary: "Estatísticas de preço do produto",
+			Query: dto.PriceStatisticsQueryDTO{}, Response: openapi.Object{"statistics": dto.PriceHistoryStatisticsDTO{}}},
+		{Method: http.MethodGet, Path: "/products/:id/price-comparison", Tag: "products", Summary: "Compara os preços do produto entre redes ou filiais",
+			Description: "Na comunidade, grupos com poucos contribuintes são omitidos e contados em hiddenGroups. " +
+				"Com lat e lng, considera apenas os estabelecimentos dentro do raio (radius em km, padrão 5).",
+			Query: dto.PriceComparisonQueryDTO{}, Errors: []int{http.StatusUnprocessableEntity},
+			Response: openapi.Object{"comparison": dto.PriceComparisonDTO{}}},
+		{Method: http.MethodPost, Path: "/products/proposals/create", Tag: "product-proposals", Summary: "Sugere um produto novo",
+			Description: "A sugestão fica pendente de moderação, mas já pode ser usada nas compras e categorias de quem sugeriu.",
+			Body:        dto.CreateProductDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Sugestão registrada", "product": dto.ProductResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/products/proposals/all", Tag: "product-proposals", Summary: "Lista as sugestões de produto",
+			Description: "Para admin, a fila de moderação (status padrão pending); para os demais, as próprias sugestões.",
+			Query:       dto.ProductProposalQueryDTO{},
+			Response:    openapi.Object{"proposals": []dto.ProductResponseDTO{}, "count": 0}},
+		{Method: http.MethodPut, Path: "/products/proposals/:id/approve", Tag: "product-proposals", Summary: "Aprova uma sugestão", Admin: true,
+			Description: "Os campos de produto informados corrigem a sugestão antes de ela entrar no catálogo.",
+			Body:        dto.ApproveProductProposalDTO{},
+			Response:    openapi.Object{"message": "Sugestão aprovada", "product": dto.ProductResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/products/proposals/:id/merge", Tag: "product-proposals", Summary: "Mescla uma sugestão a um produto existente", Admin: true,
+			Body:     dto.MergeProductProposalDTO{},
+			Response: openapi.Object{"message": "Sugestão mesclada ao produto existente", "result": dto.MergeProductsResultDTO{}}},
+		{Method: http.MethodPost, Path: "/products/proposals/:id/reject", Tag: "product-proposals", Summary: "Recusa uma sugestão", Admin: true,
+			Description: "Compras, preços e categorias que já usam a sugestão migram para replacementId, obrigatório nesse caso.",
+			Body:        dto.RejectProductProposalDTO{},
+			Response:    openapi.Object{"message": "Sugestão recusada", "product": dto.ProductResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/products/duplicates", Tag: "products", Summary: "Lista grupos de produtos que parecem duplicados", Admin: true,
+			Description: "Liga produtos com o mesmo item no código de barras (inclusive variantes de embalagem e códigos adicionais) " +
+				"e produtos com nomes parecidos, ignorando o tamanho da embalagem.",
+			Query: dto.ProductDuplicatesQueryDTO{}, Response: openapi.Object{"groups": []dto.ProductDuplicateGroupDTO{}, "count": 0}},
+		{Method: http.MethodPost, Path: "/products/merge", Tag: "products", Summary: "Mescla produtos duplicados", Admin: true,
+			Description: "Itens de compra, preços, categorias, códigos e PLUs das origens passam ao destino; os IDs das origens continuam levando ao destino.",
+			Body:        dto.MergeProductsDTO{},
+			Response:    openapi.Object{"message": "Produtos mesclados com sucesso", "result": dto.MergeProductsResultDTO{}}},
+		{Method: http.MethodGet, Path: "/products/:id/barcodes", Tag: "products", Summary: "Lista os códigos de barras adicionais do produto",
+			Response: openapi.Object{"barcodes": []dto.ProductBarcodeResponseDTO{}, "count": 0}},
+		{Method: http.MethodPost, Path: "/products/:id/barcodes", Tag: "products", Summary: "Cadastra um código de barras adicional", Admin: true,
+			Description: "Códigos de embalagens múltiplas (unitsPerPack > 1) levam ao produto base e convertem a quantidade e o preço lidos para unidades.",
+			Body:        dto.CreateProductBarcodeDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Código de barras cadastrado com sucesso", "barcode": dto.ProductBarcodeResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/products/:id/barcodes/:barcodeId", Tag: "products", Summary: "Remove um código de barras adicional", Admin: true,
+			Response: message("Código de barras removido com sucesso")},
+
+		// Catalog import
+		{Method: http.MethodPost, Path: "/products/import/off", Tag: "catalog-import", Admin: true,
+			Summary:     "Inicia a importação de um dump do Open Food Facts",
+			Description: "O arquivo deve estar em CATALOG_IMPORT_DIR; a importação roda em segundo plano.",
+			Body:        dto.StartCatalogImportDTO{}, Status: http.StatusAccepted,
+			Response: openapi.Object{"message": "Importação iniciada", "job": dto.ImportJobResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/products/import/jobs", Tag: "catalog-import", Summary: "Lista as importações recentes", Admin: true,
+			Response: openapi.Object{"jobs": []dto.ImportJobResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/products/import/jobs/:id", Tag: "catalog-import", Summary: "Progresso de uma importação", Admin: true,
+			Response: openapi.Object{"job": dto.ImportJobResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/products/import/jobs/:id/resume", Tag: "catalog-import", Summary: "Retoma uma importação interrompida", Admin: true,
+			Status: http.StatusAccepted, Response: openapi.Object{"message": "Importação retomada", "job": dto.ImportJobResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/products/import/jobs/:id/cancel", Tag: "catalog-import", Summary: "Cancela uma importação em andamento", Admin: true,
+			Response: message("Cancelamento solicitado")},
+
+		// Stores
+		{Method: http.MethodPost, Path: "/stores/create", Tag: "stores", Summary: "Cadastra um estabelecimento",
+			Description: "Um nome parecido com o de um estabelecimento existente (ou o mesmo CNPJ) retorna 409 com o storeId a usar.",
+			Body:        dto.CreateStoreDTO{}, Status: http.StatusCreated, Errors: []int{http.StatusConflict},
+			Response: openapi.Object{"message": "Estabelecimento criado com sucesso", "store": dto.StoreResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/stores/all", Tag: "stores", Summary: "Lista os estabelecimentos",
+			Query: storeSearchQuery{}, Response: openapi.Object{"stores": []dto.StoreResponseDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/stores/match", Tag: "stores", Summary: "Sugere estabelecimentos para um nome digitado",
+			Query: storeMatchQuery{}, Response: openapi.Object{"matches": []dto.StoreMatchDTO{}}},
+		{Method: http.MethodGet, Path: "/stores/nearby", Tag: "stores", Summary: "Lista os estabelecimentos próximos a um ponto",
+			Description: "Apenas estabelecimentos com coordenadas, do mais próximo ao mais distante; radius em km.",
+			Query:       dto.NearbyStoresQueryDTO{}, Response: openapi.Object{"stores": []dto.NearbyStoreDTO{}, "count": 0}},
+		{Method: http.MethodPost, Path: "/stores/merge", Tag: "stores", Summary: "Mescla estabelecimentos duplicados", Admin: true,
+			Description: "Compras e preços das origens passam a apontar para o destino; os nomes das origens continuam reconhecidos.",
+			Body:        dto.MergeStoresDTO{},
+			Response:    openapi.Object{"message": "Estabelecimentos mesclados com sucesso", "result": dto.MergeStoresResultDTO{}}},
+		{Method: http.MethodGet, Path: "/stores/:id", Tag: "stores", Summary: "Busca um estabelecimento",
+			Response: openapi.Object{"store": dto.StoreResponseDTO{}}},
+		{Method: http.MethodPut, Path: "/stores/update/:id", Tag: "stores", Summary: "Atualiza um estabelecimento", Admin: true,
+			Body: dto.UpdateStoreDTO{}, Response: openapi.Object{"message": "Estabelecimento atualizado com sucesso", "store": dto.StoreResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/stores/delete/:id", Tag: "stores", Summary: "Remove um estabelecimento sem compras vinculadas", Admin: true,
+			Response: message("Estabelecimento removido com sucesso")},
+
+		// Chains
+		{Method: http.MethodPost, Path: "/chains/create", Tag: "chains", Summary: "Cadastra uma rede", Admin: true,
+			Body: dto.CreateStoreChainDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Rede criada com sucesso", "chain": dto.StoreChainResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/chains/all", Tag: "chains", Summary: "Lista as redes",
+			Response: openapi.Object{"chains": []dto.StoreChainResponseDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/chains/:id", Tag: "chains", Summary: "Busca uma rede e suas filiais",
+			Response: openapi.Object{"chain": dto.StoreChainResponseDTO{}, "branches": []dto.StoreResponseDTO{}}},
+		{Method: http.MethodPut, Path: "/chains/update/:id", Tag: "chains", Summary: "Renomeia uma rede", Admin: true,
+			Body: dto.UpdateStoreChainDTO{}, Response: openapi.Object{"message": "Rede atualizada com sucesso", "chain": dto.StoreChainResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/chains/delete/:id", Tag: "chains", Summary: "Remove uma rede sem filiais", Admin: true,
+			Response: message("Rede removida com sucesso")},
+
+		// Barcodes
+		{Method: http.MethodGet, Path: "/barcodes/:code", Tag: "barcodes", Summary: "Interpreta um código de barras",
+			Description: "Valida o dígito verificador e normaliza para GTIN-14; etiquetas de balança (prefixo 2) trazem o PLU e o preço ou o peso.",
+			Query:       dto.BarcodeQueryDTO{}, Response: dto.BarcodeReadingDTO{}},
+		{Method: http.MethodPost, Path: "/plus/create", Tag: "barcodes", Summary: "Cadastra o PLU de balança de um produto", Admin: true,
+			Body: dto.CreateProductPLUDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "PLU cadastrado com sucesso", "plu": dto.ProductPLUResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/plus/all", Tag: "barcodes", Summary: "Lista os PLUs de balança",
+			Query: dto.ProductPLUQueryDTO{}, Response: openapi.Object{"plus": []dto.ProductPLUResponseDTO{}, "count": 0}},
+		{Method: http.MethodDelete, Path: "/plus/delete/:id", Tag: "barcodes", Summary: "Remove um PLU de balança", Admin: true,
+			Response: message("PLU removido com sucesso")},
+
+		// Purchases
+		{Method: http.MethodPost, Path: "/purchases/create", Tag: "purchases", Summary: "Registra uma compra",
+			Description: "Itens com preço atípico geram avisos; no modo estrito a compra é recusada (422) até os itens serem confirmados.",
+			Body:        dto.CreatePurchaseDTO{}, Status: http.StatusCreated, Errors: []int{http.StatusConflict, http.StatusUnprocessableEntity},
+			Response: openapi.Object{"message": "Purchase created successfully", "purchase": dto.PurchaseResponseDTO{}, "warnings": []dto.PriceWarningDTO{}}},
+		{Method: http.MethodGet, Path: "/purchases/:id", Tag: "purchases", Summary: "Busca uma compra",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"purchase": dto.PurchaseResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/purchases/my", Tag: "purchases", Summary: "Lista as compras do usuário",
+			Response: openapi.Object{"purchases": []dto.PurchaseResponseDTO{}, "count": 0}},
+		{Method: http.MethodDelete, Path: "/purchases/delete/:id", Tag: "purchases", Summary: "Remove uma compra",
+			Errors: []int{http.StatusForbidden}, Response: message("Purchase deleted successfully")},
+		{Method: http.MethodGet, Path: "/purchases/all", Tag: "purchases", Summary: "Lista as compras de todos os usuários", Admin: true,
+			Response: openapi.Object{"purchases": []dto.PurchaseResponseDTO{}, "count": 0}},
+
+		// Purchase import
+		{Method: http.MethodPost, Path: "/purchases/import", Tag: "purchase-import", Summary: "Importa compras de um CSV",
+			Description: "Retorna 200 em dryRun e 422 com o relatório quando alguma linha tem erro (nada é gravado).",
+			Form:        dto.PurchaseImportOptionsDTO{}, FileField: "file", Status: http.StatusCreated,
+			Errors:   []int{http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity},
+			Response: openapi.Object{"report": dto.PurchaseImportReportDTO{}}},
+		{Method: http.MethodPost, Path: "/purchases/import/nfce", Tag: "purchase-import", Summary: "Importa o XML de uma NFC-e/NF-e",
+			Description: "Aceita o XML no campo file de um multipart ou como corpo bruto (opções na query string).",
+			Query:       dto.InvoiceImportOptionsDTO{}, FileField: "file", RawBody: []string{"application/xml"}, Status: http.StatusCreated,
+			Errors:   []int{http.StatusConflict, http.StatusUnprocessableEntity},
+			Response: openapi.Object{"report": dto.InvoiceImportReportDTO{}}},
+		{Method: http.MethodPost, Path: "/purchases/import/nfce-url", Tag: "purchase-import", Summary: "Importa uma NFC-e pela URL do QR code",
+			Body: dto.ImportInvoiceURLDTO{}, Status: http.StatusCreated,
+			Errors:   []int{http.StatusConflict, http.StatusUnprocessableEntity, http.StatusBadGateway},
+			Response: openapi.Object{"report": dto.InvoiceImportReportDTO{}}},
+		{Method: http.MethodPost, Path: "/purchases/import/receipt-text", Tag: "purchase-import", Summary: "Converte o texto de um cupom em rascunho de compra",
+			Body: dto.ParseReceiptTextDTO{}, Errors: []int{http.StatusUnprocessableEntity},
+			Response: openapi.Object{"draft": dto.ReceiptDraftDTO{}}},
+
+		// Price history
+		{Method: http.MethodGet, Path: "/price-history/outliers", Tag: "price-history", Summary: "Registros de preço atípicos para revisão", Admin: true,
+			Query: priceOutliersQuery{}, Response: openapi.Object{"outliers": []dto.PriceOutlierReportDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/price-history/:id", Tag: "price-history", Summary: "Busca um registro de preço",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"priceHistory": dto.PriceHistoryResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/price-history/product/:id", Tag: "price-history", Summary: "Histórico de preços de um produto",
+			Description: "No escopo community os preços vêm agregados por semana e unidade, sem usuários nem locais, e exigem um número mínimo de contribuidores no período (422) e em cada semana.",
+			Query:       priceHistoryByProductQuery{}, Errors: []int{http.StatusUnprocessableEntity},
+			Response: openapi.OneOf{
+				openapi.Object{"scope": "personal", "priceHistories": []dto.PriceHistoryResponseDTO{}, "count": 0},
+				dto.CommunityPriceHistoryDTO{},
+			}},
+		{Method: http.MethodDelete, Path: "/price-history/delete/:id", Tag: "price-history", Summary: "Remove um registro de preço",
+			Errors: []int{http.StatusForbidden}, Response: message("Histórico de preço removido com sucesso")},
+		{Method: http.MethodGet, Path: "/price-history/all", Tag: "price-history", Summary: "Lista todo o histórico de preços", Admin: true,
+			Response: openapi.Object{"priceHistories": []dto.PriceHistoryResponseDTO{}, "count": 0}},
+
+		// User category products
+		{Method: http.MethodPost, Path: "/user-category-products/create", Tag: "user-category-products", Summary: "Associa um produto a uma categoria",
+			Body: dto.CreateUserCategoryProductDTO{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden},
+			Response: openapi.Object{"message": "Produto categorizado com sucesso", "userCategoryProduct": dto.UserCategoryProductResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/user-category-products/:id", Tag: "user-category-products", Summary: "Busca uma associação",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"userCategoryProduct": dto.UserCategoryProductResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/user-category-products/my", Tag: "user-category-products", Summary: "Lista as associações do usuário",
+			Response: openapi.Object{"userCategoryProducts": []dto.UserCategoryProductResponseDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/user-category-products/category/:id", Tag: "user-category-products", Summary: "Lista os produtos de uma categoria e de suas subcategorias",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"userCategoryProducts": []dto.UserCategoryProductResponseDTO{}, "count": 0}},
+		{Method: http.MethodDelete, Path: "/user-category-products/delete/:id", Tag: "user-category-products", Summary: "Remove uma associação",
+			Errors: []int{http.StatusForbidden}, Response: message("Categorização de produto removida com sucesso")},
+		{Method: http.MethodDelete, Path: "/user-category-products/delete", Tag: "user-category-products", Summary: "Remove uma associação pela categoria e produto",
+			Query: userCategoryProductDeleteQuery{}, Errors: []int{http.StatusForbidden}, Response: message("Categorização de produto removida com sucesso")},
+		{Method: http.MethodGet, Path: "/user-category-products/all", Tag: "user-category-products", Summary: "Lista as associações de todos os usuários", Admin: true,
+			Response: openapi.Object{"userCategoryProducts": []dto.UserCategoryProductResponseDTO{}, "count": 0}},
+
+		// Budgets
+		{Method: http.MethodPost, Path: "/budgets/create", Tag: "budgets", Summary: "Cria um orçamento mensal",
+			Body: dto.CreateBudgetDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Orçamento criado com sucesso", "budget": dto.BudgetResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/budgets/my", Tag: "budgets", Summary: "Lista os orçamentos do usuário",
+			Response: openapi.Object{"budgets": []dto.BudgetResponseDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/budgets/status", Tag: "budgets", Summary: "Consumo de todos os orçamentos no mês",
+			Query: budgetMonthQuery{}, Response: openapi.Object{"statuses": []dto.BudgetStatusDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/budgets/:id", Tag: "budgets", Summary: "Busca um orçamento",
+			Errors: []int{http.StatusForbidden}, Response: openapi.Object{"budget": dto.BudgetResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/budgets/:id/status", Tag: "budgets", Summary: "Consumo de um orçamento no mês",
+			Query: budgetMonthQuery{}, Errors: []int{http.StatusForbidden}, Response: openapi.Object{"status": dto.BudgetStatusDTO{}}},
+		{Method: http.MethodPut, Path: "/budgets/update/:id", Tag: "budgets", Summary: "Atualiza um orçamento",
+			Body: dto.UpdateBudgetDTO{}, Errors: []int{http.StatusForbidden},
+			Response: openapi.Object{"message": "Orçamento atualizado com sucesso", "budget": dto.BudgetResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/budgets/delete/:id", Tag: "budgets", Summary: "Remove um orçamento",
+			Errors: []int{http.StatusForbidden}, Response: message("Orçamento removido com sucesso")},
+
+		// Households
+		{Method: http.MethodPost, Path: "/households/create", Tag: "households", Summary: "Cria um domicílio",
+			Body: dto.CreateHouseholdDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Domicílio criado com sucesso", "household": dto.HouseholdResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/households/join", Tag: "households", Summary: "Entra em um domicílio pelo código de convite",
+			Body: dto.JoinHouseholdDTO{}, Response: openapi.Object{"message": "Você entrou no domicílio", "household": dto.HouseholdResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/households/leave", Tag: "households", Summary: "Sai do domicílio atual",
+			Errors: []int{http.StatusBadRequest}, Response: message("Você saiu do domicílio")},
+		{Method: http.MethodGet, Path: "/households/my", Tag: "households", Summary: "Domicílio do usuário",
+			Errors: []int{http.StatusNotFound}, Response: openapi.Object{"household": dto.HouseholdResponseDTO{}}},
+
+		// Suggestions
+		{Method: http.MethodGet, Path: "/suggestions/restock", Tag: "suggestions", Summary: "Produtos que provavelmente estão acabando",
+			Query: restockQuery{}, Response: openapi.Object{"suggestions": []dto.RestockSuggestionDTO{}, "count": 0}},
+		{Method: http.MethodGet, Path: "/suggestions/buy-again", Tag: "suggestions", Summary: "Produtos comprados com frequência",
+			Query: buyAgainQuery{}, Response: openapi.Object{"suggestions": []dto.BuyAgainSuggestionDTO{}, "count": 0}},
+
+		// Exports
+		{Method: http.MethodGet, Path: "/exports/purchases", Tag: "exports", Summary: "Exporta as compras do usuário",
+			Description: exportFormats, Query: dto.ExportQueryDTO{}, Download: "text/csv"},
+		{Method: http.MethodGet, Path: "/exports/price-history", Tag: "exports", Summary: "Exporta o histórico de preços do usuário",
+			Description: exportFormats, Query: dto.ExportQueryDTO{}, Download: "text/csv"},
+		{Method: http.MethodGet, Path: "/exports/categories", Tag: "exports", Summary: "Exporta as categorias e produtos do usuário",
+			Description: exportFormats, Query: dto.ExportQueryDTO{}, Download: "text/csv"},
+
+		// Backups
+		{Method: http.MethodGet, Path: "/backups/instance", Tag: "backups", Summary: "Backup completo da instância (zip)", Admin: true,
+			Download: "application/zip"},
+		{Method: http.MethodGet, Path: "/backups/users/:id", Tag: "backups", Summary: "Backup dos dados de um usuário (zip)",
+			Errors: []int{http.StatusForbidden}, Download: "application/zip"},
+		{Method: http.MethodPost, Path: "/backups/restore", Tag: "backups", Summary: "Restaura um backup na instância", Admin: true,
+			Form: dto.RestoreOptionsDTO{}, FileField: "file", Status: http.StatusCreated, Errors: []int{http.StatusRequestEntityTooLarge},
+			Response: openapi.Object{"report": dto.RestoreReportDTO{}}},
+		{Method: http.MethodPost, Path: "/backups/users/:id/restore", Tag: "backups", Summary: "Restaura um backup na conta de um usuário",
+			Form: dto.RestoreOptionsDTO{}, FileField: "file", Status: http.StatusCreated,
+			Errors:   []int{http.StatusForbidden, http.StatusRequestEntityTooLarge},
+			Response: openapi.Object{"report": dto.RestoreReportDTO{}}},
+
+		// Webhooks
+		{Method: http.MethodGet, Path: "/webhooks/event-types", Tag: "webhooks", Summary: "Tipos de evento disponíveis",
+			Response: openapi.Object{"eventTypes": []string{}}},
+		{Method: http.MethodPost, Path: "/webhooks/create", Tag: "webhooks", Summary: "Cria uma assinatura de webhook",
+			Description: "O segredo usado na assinatura HMAC só é retornado aqui e na rotação.",
+			Body:        dto.CreateWebhookDTO{}, Status: http.StatusCreated,
+			Response: openapi.Object{"message": "Webhook criado com sucesso", "webhook": dto.WebhookResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/webhooks/all", Tag: "webhooks", Summary: "Lista os webhooks do usuário",
+			Response: openapi.Object{"webhooks": []dto.WebhookResponseDTO{}}},
+		{Method: http.MethodGet, Path: "/webhooks/:id", Tag: "webhooks", Summary: "Busca um webhook",
+			Response: openapi.Object{"webhook": dto.WebhookResponseDTO{}}},
+		{Method: http.MethodPut, Path: "/webhooks/update/:id", Tag: "webhooks", Summary: "Atualiza um webhook",
+			Body: dto.UpdateWebhookDTO{}, Response: openapi.Object{"message": "Webhook atualizado com sucesso", "webhook": dto.WebhookResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/webhooks/:id/rotate-secret", Tag: "webhooks", Summary: "Gera um novo segredo",
+			Response: openapi.Object{"message": "Segredo do webhook atualizado", "webhook": dto.WebhookResponseDTO{}}},
+		{Method: http.MethodDelete, Path: "/webhooks/delete/:id", Tag: "webhooks", Summary: "Remove um webhook",
+			Response: message("Webhook excluído com sucesso")},
+		{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Tag: "webhooks", Summary: "Log de entregas do webhook",
+			Query: webhookDeliveriesQuery{}, Response: openapi.Object{"deliveries": []dto.WebhookDeliveryResponseDTO{}}},
+		{Method: http.MethodPost, Path: "/webhooks/deliveries/:id/redeliver", Tag: "webhooks", Summary: "Reagenda uma entrega",
+			Response: openapi.Object{"message": "Entrega reagendada", "delivery": dto.WebhookDeliveryResponseDTO{}}},
+
+		// GraphQL
+		{Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Executa uma consulta GraphQL",
+			Description: "Apenas consultas (query). Erros de campos são retornados em errors com status 200; o schema está em /graphql/schema.",
+			Body:        dto.GraphQLRequestDTO{}, Response: graphql.Response{}},
+		{Method: http.MethodGet, Path: "/graphql/schema", Tag: "graphql", Summary: "Schema GraphQL em SDL",
+			Download: "text/plain"},
+
+		// Docs
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "Esta especificação", Public: true,
+			Response: openapi.Object{}},
+		{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "Swagger UI", Public: true,
+			Download: "text/html"},
+		{Method: http.MethodGet, Path: "/docs/assets/:file", Tag: "docs", Summary: "Arquivos da Swagger UI (swagger-ui.css e swagger-ui-bundle.js)",
+			Public: true, Download: "application/octet-stream"},
+	}
+}
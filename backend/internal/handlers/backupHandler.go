@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/backup"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo do arquivo de backup enviado para restauração
+const maxBackupFileSize = 512 << 20 // 512 MB
+
+// RegisterBackupRoutes configures backup and restore routes
+func RegisterBackupRoutes(router *gin.Engine, backupService *services.BackupService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	backupGroup := router.Group("/backups")
+	{
+		// Download a backup of the whole instance (admin only)
+		backupGroup.GET("/instance", authMiddleware, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem exportar a instância"})
+				return
+			}
+
+			setBackupHeaders(c, "instancia")
+			if err := backupService.WriteInstanceBackup(c.Writer, userRole); err != nil {
+				log.Printf("[backup] instância: %v", err)
+				c.Abort()
+			}
+		})
+
+		// Download a backup of a user's data (own data or admin)
+		backupGroup.GET("/users/:id", authMiddleware, func(c *gin.Context) {
+			targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+			if uint(targetUserID) != userID && userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Você só pode exportar os seus próprios dados"})
+				return
+			}
+
+			setBackupHeaders(c, fmt.Sprintf("usuario-%d", targetUserID))
+			if err := backupService.WriteUserBackup(c.Writer, uint(targetUserID), userID, userRole); err != nil {
+				log.Printf("[backup] usuário=%d: %v", targetUserID, err)
+				c.Abort()
+			}
+		})
+
+		// Restore a backup into the instance, matching or creating its users (admin only)
+		backupGroup.POST("/restore", authMiddleware, func(c *gin.Context) {
+			restoreBackup(c, backupService, 0)
+		})
+
+		// Restore a user backup into the given account (own account or admin)
+		backupGroup.POST("/users/:id/restore", authMiddleware, func(c *gin.Context) {
+			targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil || targetUserID == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+			restoreBackup(c, backupService, uint(targetUserID))
+		})
+	}
+}
+
+// setBackupHeaders prepares the response for a backup download
+func setBackupHeaders(c *gin.Context, fileName string) {
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appmercado-%s-%s.zip"`,
+		fileName, time.Now().Format("20060102")))
+	c.Status(http.StatusOK)
+}
+
+// restoreBackup reads the uploaded archive (multipart "file") and restores it
+func restoreBackup(c *gin.Context, backupService *services.BackupService, targetUserID uint) {
+	userID := c.GetUint("userID")
+	userRole := c.GetString("userRole")
+	if userRole != string(models.RoleAdmin) && (targetUserID == 0 || targetUserID != userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você só pode restaurar dados na sua própria conta"})
+		return
+	}
+
+	var options dto.RestoreOptionsDTO
+	if err := c.ShouldBind(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "arquivo de backup é obrigatório no campo file"})
+		return
+	}
+	if fileHeader.Size > maxBackupFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "arquivo excede o tamanho máximo de 512 MB"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	archive, err := backup.NewReader(file, fileHeader.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := backupService.RestoreBackup(archive, options, targetUserID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if report.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"report": report})
+}
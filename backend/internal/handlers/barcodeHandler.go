@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBarcodeRoutes configura as rotas de leitura de códigos de barras e de PLUs das balanças
+func RegisterBarcodeRoutes(router *gin.Engine, barcodeService *services.BarcodeService, appConfig *config.Config) {
+	authMw := middleware.AuthMiddleware(appConfig)
+
+	barcodeGroup := router.Group("/barcodes")
+	{
+		// Interpreta um código lido (?storeId= usa o leiaute de etiqueta e os PLUs do estabelecimento):
+		// GTIN-14 normalizado, produto e, nas etiquetas de balança, o PLU e o preço ou peso
+		barcodeGroup.GET("/:code", authMw, func(c *gin.Context) {
+			var queryDTO dto.BarcodeQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros inválidos: " + err.Error()})
+				return
+			}
+
+			reading, err := barcodeService.ReadBarcode(c.Param("code"), queryDTO.StoreID, c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, barcodeService.ToBarcodeReadingDTO(reading))
+		})
+	}
+
+	pluGroup := router.Group("/plus")
+	{
+		// Cadastra o PLU de balança de um produto, geral ou de um estabelecimento (apenas Admin)
+		pluGroup.POST("/create", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem cadastrar PLUs"})
+				return
+			}
+
+			var createDTO dto.CreateProductPLUDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			plu, err := barcodeService.CreatePLU(createDTO, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "PLU cadastrado com sucesso",
+				"plu":     barcodeService.ToProductPLUResponseDTO(plu),
+			})
+		})
+
+		// Lista os PLUs (?productId=, ?storeId=)
+		pluGroup.GET("/all", authMw, func(c *gin.Context) {
+			var queryDTO dto.ProductPLUQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros inválidos: " + err.Error()})
+				return
+			}
+
+			plus, err := barcodeService.GetPLUs(queryDTO)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			pluDTOs := barcodeService.ToProductPLUResponseDTOList(plus)
+			c.JSON(http.StatusOK, gin.H{
+				"plus":  pluDTOs,
+				"count": len(pluDTOs),
+			})
+		})
+
+		// Remove um PLU (apenas Admin)
+		pluGroup.DELETE("/delete/:id", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem remover PLUs"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de PLU inválido"})
+				return
+			}
+
+			if err := barcodeService.DeletePLU(uint(id), userRole); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "PLU removido com sucesso"})
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBudgetRoutes configures budget routes
+func RegisterBudgetRoutes(router *gin.Engine, budgetService *services.BudgetService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	budgetGroup := router.Group("/budgets")
+	{
+		// Create a new budget (category or total)
+		budgetGroup.POST("/create", authMiddleware, func(c *gin.Context) {
+			var createDTO dto.CreateBudgetDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// Get authenticated user ID
+			userID := c.GetUint("userID")
+
+			budget, err := budgetService.CreateBudget(createDTO, userID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Orçamento criado com sucesso",
+				"budget":  budgetService.ToBudgetResponseDTO(budget),
+			})
+		})
+
+		// Get all budgets for the authenticated user
+		budgetGroup.GET("/my", authMiddleware, func(c *gin.Context) {
+			userID := c.GetUint("userID")
+
+			budgets, err := budgetService.GetBudgetsByUserID(userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			budgetDTOs := budgetService.ToBudgetResponseDTOList(budgets)
+
+			c.JSON(http.StatusOK, gin.H{
+				"budgets": budgetDTOs,
+				"count":   len(budgetDTOs),
+			})
+		})
+
+		// Budget vs actual for all budgets of the authenticated user (?month=YYYY-MM, default current month)
+		budgetGroup.GET("/status", authMiddleware, func(c *gin.Context) {
+			month, err := services.ParseBudgetPeriod(c.Query("month"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			statuses, err := budgetService.GetBudgetStatusesByUserID(userID, month)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"statuses": statuses,
+				"count":    len(statuses),
+			})
+		})
+
+		// Get a specific budget
+		budgetGroup.GET("/:id", authMiddleware, func(c *gin.Context) {
+			budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de orçamento inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			budget, err := budgetService.GetBudgetByID(uint(budgetID), userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"budget": budgetService.ToBudgetResponseDTO(budget),
+			})
+		})
+
+		// Budget vs actual for a specific budget (?month=YYYY-MM, default current month)
+		budgetGroup.GET("/:id/status", authMiddleware, func(c *gin.Context) {
+			budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de orçamento inválido"})
+				return
+			}
+
+			month, err := services.ParseBudgetPeriod(c.Query("month"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			status, err := budgetService.GetBudgetStatus(uint(budgetID), month, userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"status": status})
+		})
+
+		// Update a budget
+		budgetGroup.PUT("/update/:id", authMiddleware, func(c *gin.Context) {
+			budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de orçamento inválido"})
+				return
+			}
+
+			var updateDTO dto.UpdateBudgetDTO
+			if err := c.ShouldBindJSON(&updateDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			budget, err := budgetService.UpdateBudget(uint(budgetID), updateDTO, userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Orçamento atualizado com sucesso",
+				"budget":  budgetService.ToBudgetResponseDTO(budget),
+			})
+		})
+
+		// Delete a budget
+		budgetGroup.DELETE("/delete/:id", authMiddleware, func(c *gin.Context) {
+			budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de orçamento inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			if err := budgetService.DeleteBudget(uint(budgetID), userID, userRole); err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Orçamento removido com sucesso",
+			})
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCatalogImportRoutes configures the bulk catalog import routes (admin only)
+func RegisterCatalogImportRoutes(router *gin.Engine, catalogImportService *services.CatalogImportService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	importGroup := router.Group("/products/import")
+	importGroup.Use(authMiddleware, func(c *gin.Context) {
+		if c.GetString("userRole") != string(models.RoleAdmin) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem importar o catálogo"})
+			return
+		}
+		c.Next()
+	})
+	{
+		// Start importing an Open Food Facts dump from CATALOG_IMPORT_DIR (runs in background)
+		importGroup.POST("/off", func(c *gin.Context) {
+			var startDTO dto.StartCatalogImportDTO
+			if err := c.ShouldBindJSON(&startDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			job, err := catalogImportService.StartOpenFoodFactsImport(startDTO, userID, userRole)
+			if errors.Is(err, services.ErrCatalogImportRunning) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "Importação iniciada",
+				"job":     catalogImportService.ToImportJobResponseDTO(job),
+			})
+		})
+
+		// List the most recent import jobs
+		importGroup.GET("/jobs", func(c *gin.Context) {
+			jobs, err := catalogImportService.GetImportJobs(c.GetString("userRole"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"jobs": catalogImportService.ToImportJobResponseDTOList(jobs)})
+		})
+
+		// Get the progress of an import job
+		importGroup.GET("/jobs/:id", func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			job, err := catalogImportService.GetImportJob(uint(id), c.GetString("userRole"))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"job": catalogImportService.ToImportJobResponseDTO(job)})
+		})
+
+		// Resume a failed, cancelled or interrupted job from its last checkpoint
+		importGroup.POST("/jobs/:id/resume", func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			job, err := catalogImportService.ResumeImportJob(uint(id), c.GetString("userRole"))
+			if errors.Is(err, services.ErrCatalogImportRunning) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "Importação retomada",
+				"job":     catalogImportService.ToImportJobResponseDTO(job),
+			})
+		})
+
+		// Cancel a running job; batches already written are kept
+		importGroup.POST("/jobs/:id/cancel", func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			if err := catalogImportService.CancelImportJob(uint(id), c.GetString("userRole")); err != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Cancelamento solicitado"})
+		})
+	}
+}
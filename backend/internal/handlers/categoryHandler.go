@@ -65,6 +65,88 @@ func RegisterCategoryRoutes(router *gin.Engine, categoryService *services.Catego
 			})
 		})
 
+		// Rota para buscar a árvore de categorias do usuário com os totais do mês (?month=YYYY-MM&rootId=)
+		categoryGroup.GET("/tree", authMiddleware, func(context *gin.Context) {
+			var queryDTO dto.CategoryTreeQueryDTO
+			if err := context.ShouldBindQuery(&queryDTO); err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			month, err := services.ParseBudgetPeriod(queryDTO.Month)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// Pegando ID do usuário autenticado do contexto
+			userID := context.GetUint("userID")
+
+			tree, err := categoryService.GetCategoryTree(queryDTO, month, userID)
+			if err != nil {
+				context.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			context.JSON(http.StatusOK, gin.H{
+				"tree": tree,
+			})
+		})
+
+		// Rota para reordenar as subcategorias diretas de uma categoria (ou as categorias raiz)
+		categoryGroup.PUT("/reorder", authMiddleware, func(context *gin.Context) {
+			var reorderDTO dto.ReorderCategoriesDTO
+			if err := context.ShouldBindJSON(&reorderDTO); err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// Pegando ID e role do usuário autenticado do contexto
+			userID := context.GetUint("userID")
+			userRole := context.GetString("userRole")
+
+			categories, err := categoryService.ReorderCategories(reorderDTO, userID, userRole)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			context.JSON(http.StatusOK, gin.H{
+				"message":    "Categorias reordenadas com sucesso",
+				"categories": categoryService.ToCategoryResponseDTOList(categories),
+			})
+		})
+
+		// Rota para mover uma categoria (e suas subcategorias) para outra categoria-pai ou posição
+		categoryGroup.PUT("/:id/move", authMiddleware, func(context *gin.Context) {
+			// Obtendo ID da categoria
+			categoryID, err := strconv.ParseUint(context.Param("id"), 10, 32)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			var moveDTO dto.MoveCategoryDTO
+			if err := context.ShouldBindJSON(&moveDTO); err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// Pegando ID e role do usuário autenticado do contexto
+			userID := context.GetUint("userID")
+			userRole := context.GetString("userRole")
+
+			movedCategory, err := categoryService.MoveCategory(uint(categoryID), moveDTO, userID, userRole)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			context.JSON(http.StatusOK, gin.H{
+				"message":  "Categoria movida com sucesso",
+				"category": categoryService.ToCategoryResponseDTO(movedCategory),
+			})
+		})
+
 		// Rota para buscar uma categoria específica
 		categoryGroup.GET("/:id", authMiddleware, func(context *gin.Context) {
 			// Obtendo ID da categoria
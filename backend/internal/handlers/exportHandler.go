@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/Parron01/AppMercado/backend/pkg/export"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFunc is the signature shared by the ExportService methods
+type exportFunc func(output io.Writer, format export.Format, userID uint, filter repositories.DateRangeFilter) error
+
+// RegisterExportRoutes configures data export routes (?format=csv|xlsx|ndjson&startDate=&endDate=)
+func RegisterExportRoutes(router *gin.Engine, exportService *services.ExportService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	exportGroup := router.Group("/exports")
+	{
+		// Purchases, one row per item
+		exportGroup.GET("/purchases", authMiddleware, exportHandler(exportService, "compras", exportService.ExportPurchases))
+
+		// Price history entries of the user
+		exportGroup.GET("/price-history", authMiddleware, exportHandler(exportService, "historico-precos", exportService.ExportPriceHistory))
+
+		// Category to product mappings of the user
+		exportGroup.GET("/categories", authMiddleware, exportHandler(exportService, "categorias", exportService.ExportCategories))
+	}
+}
+
+// exportHandler streams an export straight to the response body
+func exportHandler(exportService *services.ExportService, fileName string, run exportFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var queryDTO dto.ExportQueryDTO
+		if err := c.ShouldBindQuery(&queryDTO); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		format, filter, err := exportService.ParseExportQuery(queryDTO)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID := c.GetUint("userID")
+
+		c.Header("Content-Type", format.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`,
+			fileName, time.Now().Format("20060102"), format.FileExtension()))
+		c.Status(http.StatusOK)
+
+		// Headers are already sent: a failure midway can only be logged and the download is truncated
+		if err := run(c.Writer, format, userID, filter); err != nil {
+			log.Printf("[exportação] usuário=%d arquivo=%s: %v", userID, fileName, err)
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/graph"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterGraphQLRoutes configura o endpoint GraphQL e a rota que descreve o schema
+func RegisterGraphQLRoutes(router *gin.Engine, resolver *graph.Resolver, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	// Executa uma consulta em nome do usuário autenticado; erros de campos vêm em "errors" com status 200
+	router.POST("/graphql", authMiddleware, func(c *gin.Context) {
+		var request dto.GraphQLRequestDTO
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := resolver.Execute(c.Request.Context(), c.GetUint("userID"), c.GetString("userRole"),
+			request.Query, request.OperationName, request.Variables)
+		c.JSON(http.StatusOK, response)
+	})
+
+	// Schema em SDL, para ferramentas e geração de tipos no frontend
+	router.GET("/graphql/schema", authMiddleware, func(c *gin.Context) {
+		c.String(http.StatusOK, resolver.SDL())
+	})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterHouseholdRoutes configures household routes
+func RegisterHouseholdRoutes(router *gin.Engine, householdService *services.HouseholdService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	householdGroup := router.Group("/households")
+	{
+		// Create a household owned by the authenticated user
+		householdGroup.POST("/create", authMiddleware, func(c *gin.Context) {
+			var createDTO dto.CreateHouseholdDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			household, err := householdService.CreateHousehold(createDTO, userID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message":   "Domicílio criado com sucesso",
+				"household": householdService.ToHouseholdResponseDTO(household),
+			})
+		})
+
+		// Join a household using its invite code
+		householdGroup.POST("/join", authMiddleware, func(c *gin.Context) {
+			var joinDTO dto.JoinHouseholdDTO
+			if err := c.ShouldBindJSON(&joinDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			household, err := householdService.JoinHousehold(joinDTO, userID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message":   "Você entrou no domicílio",
+				"household": householdService.ToHouseholdResponseDTO(household),
+			})
+		})
+
+		// Leave the authenticated user's household
+		householdGroup.POST("/leave", authMiddleware, func(c *gin.Context) {
+			userID := c.GetUint("userID")
+
+			if err := householdService.LeaveHousehold(userID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Você saiu do domicílio",
+			})
+		})
+
+		// Get the authenticated user's household
+		householdGroup.GET("/my", authMiddleware, func(c *gin.Context) {
+			userID := c.GetUint("userID")
+
+			household, err := householdService.GetHouseholdByUserID(userID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"household": householdService.ToHouseholdResponseDTO(household),
+			})
+		})
+	}
+}
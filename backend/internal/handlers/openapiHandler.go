@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	_ "embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/Parron01/AppMercado/backend/pkg/openapi"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+//go:embed swagger/index.html
+var swaggerPage []byte
+
+// swaggerAssets são os arquivos da Swagger UI servidos pela própria API (embutidos no binário pelo
+// módulo swaggo/files, fixado no go.sum), sem depender de CDN
+var swaggerAssets = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+// RegisterOpenAPIRoutes serve a especificação em /openapi.json e a Swagger UI em /docs
+func RegisterOpenAPIRoutes(router *gin.Engine, document *openapi.Document) {
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	})
+
+	router.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerPage)
+	})
+
+	router.GET("/docs/assets/:file", func(c *gin.Context) {
+		contentType, ok := swaggerAssets[c.Param("file")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Arquivo não encontrado"})
+			return
+		}
+		content, err := fs.ReadFile(swaggerFiles.FS, c.Param("file"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Data(http.StatusOK, contentType, content)
+	})
+}
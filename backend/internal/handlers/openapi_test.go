@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Parron01/AppMercado/backend/internal/graph"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/Parron01/AppMercado/backend/pkg/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter registra todas as rotas da API com RegisterRoutes, como em cmd/server, sem banco
+// nem services reais
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	graphResolver, err := graph.NewResolver(nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("graph.NewResolver: %v", err)
+	}
+
+	RegisterRoutes(router, Dependencies{ExportService: &services.ExportService{}, GraphResolver: graphResolver}, &config.Config{})
+	return router
+}
+
+// TestAPISpecCoversRoutes confere se a especificação cobre exatamente as rotas registradas:
+// rotas sem documentação e operações documentadas que não existem mais fazem o teste falhar
+func TestAPISpecCoversRoutes(t *testing.T) {
+	router := newTestRouter(t)
+	document := NewAPIDocument()
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+openapi.PathFromGin(route.Path)] = true
+		if !document.Has(route.Method, route.Path) {
+			t.Errorf("rota sem documentação em apiRoutes: %s %s", route.Method, route.Path)
+		}
+	}
+	for _, operation := range document.Operations() {
+		method, path, _ := strings.Cut(operation, " ")
+		if !registered[method+" "+openapi.PathFromGin(path)] {
+			t.Errorf("operação documentada sem rota: %s", operation)
+		}
+	}
+	if len(registered) == 0 {
+		t.Fatal("nenhuma rota registrada")
+	}
+}
+
+// TestSwaggerUIServedLocally confere que a Swagger UI não carrega arquivos de CDN e que os arquivos
+// referenciados pela página são servidos pela API
+func TestSwaggerUIServedLocally(t *testing.T) {
+	router := newTestRouter(t)
+	page := string(swaggerPage)
+	if strings.Contains(page, "https://") || strings.Contains(page, "http://") {
+		t.Errorf("a página /docs não deve carregar arquivos externos:\n%s", page)
+	}
+
+	for file := range swaggerAssets {
+		if !strings.Contains(page, "/docs/assets/"+file) {
+			t.Errorf("a página /docs não referencia %s", file)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/assets/"+file, nil))
+		if recorder.Code != http.StatusOK || recorder.Body.Len() == 0 {
+			t.Errorf("GET /docs/assets/%s = %d (%d bytes)", file, recorder.Code, recorder.Body.Len())
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/assets/index.html", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET /docs/assets/index.html = %d, esperado 404", recorder.Code)
+	}
+}
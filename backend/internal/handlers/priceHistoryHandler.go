@@ -1,12 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Parron01/AppMercado/backend/internal/middleware"
-	"github.com/Parron01/AppMercado/backend/internal/models"
 	"github.com/Parron01/AppMercado/backend/internal/services"
 	"github.com/Parron01/AppMercado/backend/pkg/config"
 	"github.com/gin-gonic/gin"
@@ -18,6 +18,32 @@ func RegisterPriceHistoryRoutes(router *gin.Engine, priceHistoryService *service
 
 	priceHistoryGroup := router.Group("/price-history")
 	{
+		// Report of existing price history rows that look like outliers (admin only, ?productId= optional)
+		priceHistoryGroup.GET("/outliers", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+
+			var productID uint64
+			if productIDStr := c.Query("productId"); productIDStr != "" {
+				parsedID, err := strconv.ParseUint(productIDStr, 10, 32)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "ID de produto inválido"})
+					return
+				}
+				productID = parsedID
+			}
+
+			outliers, err := priceHistoryService.GetPriceOutlierReport(uint(productID), userRole)
+			if err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"outliers": outliers,
+				"count":    len(outliers),
+			})
+		})
+
 		// Get a specific price history entry
 		priceHistoryGroup.GET("/:id", authMw, func(c *gin.Context) {
 			// Get price history ID
@@ -47,6 +73,7 @@ func RegisterPriceHistoryRoutes(router *gin.Engine, priceHistoryService *service
 		})
 
 		// Get price history for a specific product
+		// ?scope=personal (default) | household | community; community data is aggregated by week
 		priceHistoryGroup.GET("/product/:id", authMw, func(c *gin.Context) {
 			// Get product ID
 			productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -55,37 +82,52 @@ func RegisterPriceHistoryRoutes(router *gin.Engine, priceHistoryService *service
 				return
 			}
 
+			scope := c.DefaultQuery("scope", services.PriceScopePersonal)
+
 			// Parse optional date range parameters
 			startDateStr := c.Query("startDate")
 			endDateStr := c.Query("endDate")
 
-			var priceHistories []*models.PriceHistory
-			var err2 error
-
+			var startDate, endDate *time.Time
 			if startDateStr != "" && endDateStr != "" {
 				// Parse dates
-				startDate, err := time.Parse(time.RFC3339, startDateStr)
+				parsedStart, err := time.Parse(time.RFC3339, startDateStr)
 				if err != nil {
 					c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de data inicial inválido. Use RFC3339."})
 					return
 				}
 
-				endDate, err := time.Parse(time.RFC3339, endDateStr)
+				parsedEnd, err := time.Parse(time.RFC3339, endDateStr)
 				if err != nil {
 					c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de data final inválido. Use RFC3339."})
 					return
 				}
 
-				// Get price history for product in date range
-				priceHistories, err2 = priceHistoryService.GetPriceHistoryByProductAndDateRange(
-					uint(productID), startDate, endDate)
-			} else {
-				// Get all price history for product
-				priceHistories, err2 = priceHistoryService.GetPriceHistoryByProductID(uint(productID))
+				startDate, endDate = &parsedStart, &parsedEnd
+			}
+
+			// Get authenticated user ID
+			userID := c.GetUint("userID")
+
+			// Community data is only returned as weekly aggregates, never record by record
+			if scope == services.PriceScopeCommunity {
+				history, err := priceHistoryService.GetCommunityPriceHistory(uint(productID), userID, startDate, endDate)
+				if errors.Is(err, services.ErrInsufficientContributors) {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+					return
+				}
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusOK, history)
+				return
 			}
 
-			if err2 != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err2.Error()})
+			priceHistories, err := priceHistoryService.GetPriceHistoryByProductScoped(
+				uint(productID), scope, userID, startDate, endDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
 
@@ -93,6 +135,7 @@ func RegisterPriceHistoryRoutes(router *gin.Engine, priceHistoryService *service
 			priceHistoryDTOs := priceHistoryService.ToPriceHistoryResponseDTOList(priceHistories)
 
 			c.JSON(http.StatusOK, gin.H{
+				"scope":          scope,
 				"priceHistories": priceHistoryDTOs,
 				"count":          len(priceHistoryDTOs),
 			})
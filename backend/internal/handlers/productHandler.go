@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -44,7 +45,196 @@ func RegisterProductRoutes(router *gin.Engine, productService *services.ProductS
 			})
 		})
 
-		// Rota para buscar um produto específico (qualquer usuário autenticado)
+		// Sugere um produto novo (qualquer usuário autenticado); a sugestão já pode ser usada nas compras
+		// de quem sugeriu enquanto aguarda a moderação
+		productGroup.POST("/proposals/create", authMw, func(c *gin.Context) {
+			var createDTO dto.CreateProductDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			product, err := productService.ProposeProduct(createDTO, c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Sugestão registrada; o produto já pode ser usado nas suas compras enquanto aguarda moderação",
+				"product": productService.ToProductResponseDTO(product),
+			})
+		})
+
+		// Lista as sugestões: a fila de moderação para o Admin (?status=, padrão pending), as próprias para os demais
+		productGroup.GET("/proposals/all", authMw, func(c *gin.Context) {
+			var queryDTO dto.ProductProposalQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros inválidos: " + err.Error()})
+				return
+			}
+
+			proposals, err := productService.GetProductProposals(queryDTO, c.GetUint("userID"), c.GetString("userRole"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			proposalDTOs := make([]dto.ProductResponseDTO, len(proposals))
+			for i, proposal := range proposals {
+				proposalDTOs[i] = productService.ToProductResponseDTO(proposal)
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"proposals": proposalDTOs,
+				"count":     len(proposalDTOs),
+			})
+		})
+
+		// Aprova uma sugestão, com correções opcionais (apenas Admin)
+		productGroup.PUT("/proposals/:id/approve", authMw, func(c *gin.Context) {
+			if c.GetString("userRole") != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem moderar sugestões"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de sugestão inválido"})
+				return
+			}
+
+			var approveDTO dto.ApproveProductProposalDTO
+			if err := c.ShouldBindJSON(&approveDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			product, err := productService.ApproveProductProposal(uint(id), approveDTO)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Sugestão aprovada",
+				"product": productService.ToProductResponseDTO(product),
+			})
+		})
+
+		// Mescla uma sugestão a um produto do catálogo do qual ela é duplicata (apenas Admin)
+		productGroup.POST("/proposals/:id/merge", authMw, func(c *gin.Context) {
+			if c.GetString("userRole") != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem moderar sugestões"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de sugestão inválido"})
+				return
+			}
+
+			var mergeDTO dto.MergeProductProposalDTO
+			if err := c.ShouldBindJSON(&mergeDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			result, err := productService.MergeProductProposal(uint(id), mergeDTO)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Sugestão mesclada ao produto existente",
+				"result":  productService.ToMergeProductsResultDTO(result),
+			})
+		})
+
+		// Recusa uma sugestão, migrando o que já a usa para um produto substituto (apenas Admin)
+		productGroup.POST("/proposals/:id/reject", authMw, func(c *gin.Context) {
+			if c.GetString("userRole") != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem moderar sugestões"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de sugestão inválido"})
+				return
+			}
+
+			var rejectDTO dto.RejectProductProposalDTO
+			if err := c.ShouldBindJSON(&rejectDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			product, err := productService.RejectProductProposal(uint(id), rejectDTO)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Sugestão recusada",
+				"product": productService.ToProductResponseDTO(product),
+			})
+		})
+
+		// Lista grupos de produtos que parecem duplicados (apenas Admin)
+		productGroup.GET("/duplicates", authMw, func(c *gin.Context) {
+			if c.GetString("userRole") != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem buscar produtos duplicados"})
+				return
+			}
+
+			// Filtros opcionais: threshold (semelhança mínima dos nomes) e limit
+			var queryDTO dto.ProductDuplicatesQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros inválidos: " + err.Error()})
+				return
+			}
+
+			groups, err := productService.FindDuplicateProducts(queryDTO)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"groups": groups,
+				"count":  len(groups),
+			})
+		})
+
+		// Mescla produtos duplicados no de destino (apenas Admin)
+		productGroup.POST("/merge", authMw, func(c *gin.Context) {
+			if c.GetString("userRole") != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem mesclar produtos"})
+				return
+			}
+
+			var mergeDTO dto.MergeProductsDTO
+			if err := c.ShouldBindJSON(&mergeDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			result, err := productService.MergeProducts(mergeDTO)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Produtos mesclados com sucesso",
+				"result":  productService.ToMergeProductsResultDTO(result),
+			})
+		})
+
+		// Rota para buscar um produto específico (qualquer usuário autenticado; sugestões pendentes só para quem sugeriu e admins)
 		productGroup.GET("/:id", authMw, func(c *gin.Context) {
 			idStr := c.Param("id")
 			id, err := strconv.ParseUint(idStr, 10, 32)
@@ -53,7 +243,7 @@ func RegisterProductRoutes(router *gin.Engine, productService *services.ProductS
 				return
 			}
 
-			product, err := productService.GetProductByID(uint(id))
+			product, err := productService.GetVisibleProductByID(uint(id), c.GetUint("userID"), c.GetString("userRole"))
 			if err != nil {
 				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 				return
@@ -61,9 +251,9 @@ func RegisterProductRoutes(router *gin.Engine, productService *services.ProductS
 			c.JSON(http.StatusOK, productService.ToProductResponseDTO(product))
 		})
 
-		// Rota para listar todos os produtos (qualquer usuário autenticado)
+		// Rota para listar os produtos (qualquer usuário autenticado; sugestões pendentes só para quem sugeriu)
 		productGroup.GET("/all", authMw, func(c *gin.Context) {
-			products, err := productService.GetAllProducts()
+			products, err := productService.GetAllProducts(c.GetUint("userID"))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -131,8 +321,21 @@ func RegisterProductRoutes(router *gin.Engine, productService *services.ProductS
 				return
 			}
 
+			// Filtros opcionais: startDate, endDate (RFC3339), store e scope (personal, household, community)
+			var queryDTO dto.PriceStatisticsQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros de filtro inválidos. Use datas em RFC3339."})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
 			// Obter estatísticas de preço
-			statistics, err := productService.GetProductStatistics(uint(id))
+			statistics, err := productService.GetProductStatistics(uint(id), queryDTO, userID)
+			if errors.Is(err, services.ErrInsufficientContributors) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
 			if err != nil {
 				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 				return
@@ -140,5 +343,113 @@ func RegisterProductRoutes(router *gin.Engine, productService *services.ProductS
 
 			c.JSON(http.StatusOK, gin.H{"statistics": statistics})
 		})
+
+		// Compara os preços de um produto entre redes ou filiais
+		productGroup.GET("/:id/price-comparison", authMw, func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de produto inválido"})
+				return
+			}
+
+			// Filtros opcionais: startDate, endDate (RFC3339), scope, groupBy (chain, branch) e chainId
+			var queryDTO dto.PriceComparisonQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros de filtro inválidos: " + err.Error()})
+				return
+			}
+
+			comparison, err := productService.GetProductPriceComparison(uint(id), queryDTO, c.GetUint("userID"))
+			if errors.Is(err, services.ErrInsufficientContributors) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"comparison": comparison})
+		})
+
+		// Lista os códigos de barras adicionais do produto (embalagens e variantes)
+		productGroup.GET("/:id/barcodes", authMw, func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de produto inválido"})
+				return
+			}
+
+			productBarcodes, err := productService.GetProductBarcodes(uint(id))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			barcodeDTOs := productService.ToProductBarcodeResponseDTOList(productBarcodes)
+			c.JSON(http.StatusOK, gin.H{
+				"barcodes": barcodeDTOs,
+				"count":    len(barcodeDTOs),
+			})
+		})
+
+		// Cadastra um código de barras adicional do produto (apenas Admin)
+		productGroup.POST("/:id/barcodes", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem cadastrar códigos de barras"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de produto inválido"})
+				return
+			}
+
+			var createDTO dto.CreateProductBarcodeDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			productBarcode, err := productService.AddProductBarcode(uint(id), createDTO)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Código de barras cadastrado com sucesso",
+				"barcode": productService.ToProductBarcodeResponseDTO(productBarcode),
+			})
+		})
+
+		// Remove um código de barras adicional do produto (apenas Admin)
+		productGroup.DELETE("/:id/barcodes/:barcodeId", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem remover códigos de barras"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de produto inválido"})
+				return
+			}
+			barcodeID, err := strconv.ParseUint(c.Param("barcodeId"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de código de barras inválido"})
+				return
+			}
+
+			if err := productService.RemoveProductBarcode(uint(id), uint(barcodeID)); err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Código de barras removido com sucesso"})
+		})
 	}
 }
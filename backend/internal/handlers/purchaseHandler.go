@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -29,7 +30,21 @@ func RegisterPurchaseRoutes(router *gin.Engine, purchaseService *services.Purcha
 			userID := c.GetUint("userID")
 
 			// Create purchase
-			purchase, err := purchaseService.CreatePurchase(createDTO, userID)
+			purchase, warnings, err := purchaseService.CreatePurchase(createDTO, userID)
+			var outlierErr *services.PriceOutlierError
+			if errors.As(err, &outlierErr) {
+				// Strict mode: client must resend with confirmedPrice=true on the flagged items
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":    err.Error(),
+					"warnings": outlierErr.Warnings,
+				})
+				return
+			}
+			var duplicateErr *services.DuplicateInvoiceError
+			if errors.As(err, &duplicateErr) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "purchaseId": duplicateErr.PurchaseID})
+				return
+			}
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
@@ -38,9 +53,14 @@ func RegisterPurchaseRoutes(router *gin.Engine, purchaseService *services.Purcha
 			// Convert to DTO
 			purchaseResponse := purchaseService.ToPurchaseResponseDTO(purchase)
 
+			if warnings == nil {
+				warnings = []dto.PriceWarningDTO{}
+			}
+
 			c.JSON(http.StatusCreated, gin.H{
 				"message":  "Purchase created successfully",
 				"purchase": purchaseResponse,
+				"warnings": warnings,
 			})
 		})
 
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Tamanho máximo dos arquivos importados
+const (
+	maxImportFileSize  = 10 << 20 // CSV: 10 MB
+	maxInvoiceFileSize = 2 << 20  // XML de nota fiscal: 2 MB
+)
+
+// RegisterPurchaseImportRoutes configures bulk purchase import routes
+func RegisterPurchaseImportRoutes(
+	router *gin.Engine,
+	purchaseImportService *services.PurchaseImportService,
+	invoiceImportService *services.InvoiceImportService,
+	receiptService *services.ReceiptService,
+	appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	purchaseGroup := router.Group("/purchases")
+	{
+		// Import purchases from a CSV file (multipart: "file" + column mapping options)
+		purchaseGroup.POST("/import", authMiddleware, func(c *gin.Context) {
+			var options dto.PurchaseImportOptionsDTO
+			if err := c.ShouldBind(&options); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "arquivo CSV é obrigatório no campo file"})
+				return
+			}
+			if fileHeader.Size > maxImportFileSize {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "arquivo excede o tamanho máximo de 10 MB"})
+				return
+			}
+
+			file, err := fileHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			defer file.Close()
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			report, err := purchaseImportService.ImportPurchasesCSV(file, options, userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// Row errors prevent the commit: nothing was written
+			if len(report.Errors) > 0 {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"report": report})
+				return
+			}
+
+			status := http.StatusCreated
+			if report.DryRun {
+				status = http.StatusOK
+			}
+			c.JSON(status, gin.H{"report": report})
+		})
+
+		// Import a purchase from an NFC-e/NF-e XML (multipart "file" or raw XML body)
+		purchaseGroup.POST("/import/nfce", authMiddleware, func(c *gin.Context) {
+			// Form binding reads query/multipart fields only, leaving a raw XML body untouched
+			var options dto.InvoiceImportOptionsDTO
+			if err := c.ShouldBindWith(&options, binding.Form); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			body, err := openImportBody(c, maxInvoiceFileSize)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			defer body.Close()
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			report, err := invoiceImportService.ImportInvoiceXML(body, options, userID, userRole)
+			respondInvoiceImport(c, report, err)
+		})
+
+		// Import a purchase from the URL encoded in an NFC-e QR code
+		purchaseGroup.POST("/import/nfce-url", authMiddleware, func(c *gin.Context) {
+			var urlDTO dto.ImportInvoiceURLDTO
+			if err := c.ShouldBindJSON(&urlDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			options := dto.InvoiceImportOptionsDTO{
+				DryRun:                urlDTO.DryRun,
+				CreateMissingProducts: urlDTO.CreateMissingProducts,
+				SkipUnknownItems:      urlDTO.SkipUnknownItems,
+			}
+			report, err := invoiceImportService.ImportInvoiceURL(c.Request.Context(), urlDTO.URL, options, userID, userRole)
+			respondInvoiceImport(c, report, err)
+		})
+
+		// Parse a plain-text receipt into a purchase draft (nothing is saved)
+		purchaseGroup.POST("/import/receipt-text", authMiddleware, func(c *gin.Context) {
+			var receiptDTO dto.ParseReceiptTextDTO
+			if err := c.ShouldBindJSON(&receiptDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			draft, err := receiptService.ParseReceiptText(receiptDTO.Text, c.GetUint("userID"))
+			if errors.Is(err, services.ErrNoReceiptItems) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"draft": draft})
+		})
+	}
+}
+
+// openImportBody returns the uploaded file (multipart field "file") or the raw request body
+func openImportBody(c *gin.Context, maxSize int64) (io.ReadCloser, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("arquivo é obrigatório no campo file")
+		}
+		if fileHeader.Size > maxSize {
+			return nil, errors.New("arquivo excede o tamanho máximo permitido")
+		}
+		return fileHeader.Open()
+	}
+	if c.Request.ContentLength == 0 {
+		return nil, errors.New("corpo da requisição vazio")
+	}
+	return http.MaxBytesReader(c.Writer, c.Request.Body, maxSize), nil
+}
+
+// respondInvoiceImport maps the result of an invoice import to the HTTP response
+func respondInvoiceImport(c *gin.Context, report *dto.InvoiceImportReportDTO, err error) {
+	var duplicateErr *services.DuplicateInvoiceError
+	var fetchErr *services.InvoiceFetchError
+	switch {
+	case errors.As(err, &fetchErr):
+		// SEFAZ unavailable or returned something unexpected
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	case errors.As(err, &duplicateErr):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "purchaseId": duplicateErr.PurchaseID})
+		return
+	case errors.Is(err, services.ErrUnknownInvoiceItems):
+		// The report lists the proposed products
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "report": report})
+		return
+	case err != nil:
+		var outlierErr *services.PriceOutlierError
+		if errors.As(err, &outlierErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "warnings": outlierErr.Warnings})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if report.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"report": report})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/Parron01/AppMercado/backend/internal/graph"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Dependencies reúne os services usados pelas rotas da API
+type Dependencies struct {
+	AuthService                *services.AuthService
+	UserService                *services.UserService
+	CategoryService            *services.CategoryService
+	ProductService             *services.ProductService
+	StoreService               *services.StoreService
+	BarcodeService             *services.BarcodeService
+	PurchaseService            *services.PurchaseService
+	PurchaseImportService      *services.PurchaseImportService
+	InvoiceImportService       *services.InvoiceImportService
+	ReceiptService             *services.ReceiptService
+	PriceHistoryService        *services.PriceHistoryService
+	UserCategoryProductService *services.UserCategoryProductService
+	BudgetService              *services.BudgetService
+	HouseholdService           *services.HouseholdService
+	SuggestionService          *services.SuggestionService
+	ExportService              *services.ExportService
+	CatalogImportService       *services.CatalogImportService
+	BackupService              *services.BackupService
+	WebhookService             *services.WebhookService
+	GraphResolver              *graph.Resolver
+}
+
+// RegisterRoutes registra todas as rotas da API e a documentação OpenAPI. É a única lista de rotas,
+// usada pelo servidor e pelo teste de cobertura da especificação (TestAPISpecCoversRoutes).
+func RegisterRoutes(router *gin.Engine, deps Dependencies, appConfig *config.Config) {
+	RegisterAuthRoutes(router, deps.AuthService)
+	RegisterUserRoutes(router, deps.UserService, appConfig)
+	RegisterCategoryRoutes(router, deps.CategoryService, appConfig)
+	RegisterProductRoutes(router, deps.ProductService, appConfig)
+	RegisterStoreRoutes(router, deps.StoreService, appConfig)
+	RegisterBarcodeRoutes(router, deps.BarcodeService, appConfig)
+	RegisterPurchaseRoutes(router, deps.PurchaseService, appConfig)
+	RegisterPurchaseImportRoutes(router, deps.PurchaseImportService, deps.InvoiceImportService, deps.ReceiptService, appConfig)
+	RegisterPriceHistoryRoutes(router, deps.PriceHistoryService, appConfig)
+	RegisterUserCategoryProductRoutes(router, deps.UserCategoryProductService, appConfig)
+	RegisterBudgetRoutes(router, deps.BudgetService, appConfig)
+	RegisterHouseholdRoutes(router, deps.HouseholdService, appConfig)
+	RegisterSuggestionRoutes(router, deps.SuggestionService, appConfig)
+	RegisterExportRoutes(router, deps.ExportService, appConfig)
+	RegisterCatalogImportRoutes(router, deps.CatalogImportService, appConfig)
+	RegisterBackupRoutes(router, deps.BackupService, appConfig)
+	RegisterWebhookRoutes(router, deps.WebhookService, appConfig)
+	RegisterGraphQLRoutes(router, deps.GraphResolver, appConfig)
+
+	RegisterOpenAPIRoutes(router, NewAPIDocument())
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterStoreRoutes configura as rotas de estabelecimentos
+func RegisterStoreRoutes(router *gin.Engine, storeService *services.StoreService, appConfig *config.Config) {
+	authMw := middleware.AuthMiddleware(appConfig)
+
+	storeGroup := router.Group("/stores")
+	{
+		// Cadastra um estabelecimento (qualquer usuário autenticado); nomes parecidos com um existente retornam 409
+		storeGroup.POST("/create", authMw, func(c *gin.Context) {
+			var createDTO dto.CreateStoreDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			store, err := storeService.CreateStore(createDTO)
+			var duplicateErr *services.DuplicateStoreError
+			if errors.As(err, &duplicateErr) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "storeId": duplicateErr.StoreID})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Estabelecimento criado com sucesso",
+				"store":   storeService.ToStoreResponseDTO(store),
+			})
+		})
+
+		// Lista os estabelecimentos (?search=trecho do nome)
+		storeGroup.GET("/all", authMw, func(c *gin.Context) {
+			stores, err := storeService.GetStores(c.Query("search"), c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			storeDTOs := storeService.ToStoreResponseDTOList(stores)
+			c.JSON(http.StatusOK, gin.H{
+				"stores": storeDTOs,
+				"count":  len(storeDTOs),
+			})
+		})
+
+		// Sugere os estabelecimentos mais parecidos com um nome digitado (?name=&limit=5)
+		storeGroup.GET("/match", authMw, func(c *gin.Context) {
+			name := c.Query("name")
+			if name == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "name é obrigatório"})
+				return
+			}
+			limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+			if err != nil || limit < 1 || limit > 50 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit inválido"})
+				return
+			}
+
+			matches, err := storeService.MatchStores(name, limit, c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"matches": storeService.ToStoreMatchDTOList(matches)})
+		})
+
+		// Lista os estabelecimentos próximos a um ponto (?lat=&lng=&radius=5 em km&limit=20)
+		storeGroup.GET("/nearby", authMw, func(c *gin.Context) {
+			var queryDTO dto.NearbyStoresQueryDTO
+			if err := c.ShouldBindQuery(&queryDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetros inválidos: " + err.Error()})
+				return
+			}
+
+			stores, err := storeService.GetNearbyStores(queryDTO, c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			storeDTOs := storeService.ToNearbyStoreDTOList(stores)
+			c.JSON(http.StatusOK, gin.H{
+				"stores": storeDTOs,
+				"count":  len(storeDTOs),
+			})
+		})
+
+		// Mescla estabelecimentos duplicados no de destino (apenas Admin); compras e preços passam a
+		// apontar para o destino e os nomes de origem continuam reconhecidos
+		storeGroup.POST("/merge", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem mesclar estabelecimentos"})
+				return
+			}
+
+			var mergeDTO dto.MergeStoresDTO
+			if err := c.ShouldBindJSON(&mergeDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			result, err := storeService.MergeStores(mergeDTO, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Estabelecimentos mesclados com sucesso",
+				"result":  storeService.ToMergeStoresResultDTO(result),
+			})
+		})
+
+		// Busca um estabelecimento
+		storeGroup.GET("/:id", authMw, func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de estabelecimento inválido"})
+				return
+			}
+
+			store, err := storeService.GetStoreByID(uint(id), c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Estabelecimento não encontrado"})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"store": storeService.ToStoreResponseDTO(store)})
+		})
+
+		// Atualiza um estabelecimento (apenas Admin)
+		storeGroup.PUT("/update/:id", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem alterar estabelecimentos"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de estabelecimento inválido"})
+				return
+			}
+
+			var updateDTO dto.UpdateStoreDTO
+			if err := c.ShouldBindJSON(&updateDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			store, err := storeService.UpdateStore(uint(id), updateDTO, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Estabelecimento atualizado com sucesso",
+				"store":   storeService.ToStoreResponseDTO(store),
+			})
+		})
+
+		// Remove um estabelecimento sem compras ou preços vinculados (apenas Admin)
+		storeGroup.DELETE("/delete/:id", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem remover estabelecimentos"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de estabelecimento inválido"})
+				return
+			}
+
+			if err := storeService.DeleteStore(uint(id), userRole); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Estabelecimento removido com sucesso"})
+		})
+	}
+
+	chainGroup := router.Group("/chains")
+	{
+		// Cadastra uma rede (apenas Admin)
+		chainGroup.POST("/create", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem cadastrar redes"})
+				return
+			}
+
+			var createDTO dto.CreateStoreChainDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			chain, err := storeService.CreateChain(createDTO, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			// A criação pode vincular filiais existentes; a contagem é relida
+			_, branches, _ := storeService.GetChainWithBranches(chain.ID, c.GetUint("userID"))
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Rede criada com sucesso",
+				"chain":   storeService.ToStoreChainResponseDTO(chain, int64(len(branches))),
+			})
+		})
+
+		// Lista as redes com a quantidade de filiais
+		chainGroup.GET("/all", authMw, func(c *gin.Context) {
+			chains, branchCounts, err := storeService.GetChains(c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			chainDTOs := make([]dto.StoreChainResponseDTO, 0, len(chains))
+			for _, chain := range chains {
+				chainDTOs = append(chainDTOs, storeService.ToStoreChainResponseDTO(chain, branchCounts[chain.ID]))
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"chains": chainDTOs,
+				"count":  len(chainDTOs),
+			})
+		})
+
+		// Busca uma rede e suas filiais
+		chainGroup.GET("/:id", authMw, func(c *gin.Context) {
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de rede inválido"})
+				return
+			}
+
+			chain, branches, err := storeService.GetChainWithBranches(uint(id), c.GetUint("userID"))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Rede não encontrada"})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"chain":    storeService.ToStoreChainResponseDTO(chain, int64(len(branches))),
+				"branches": storeService.ToStoreResponseDTOList(branches),
+			})
+		})
+
+		// Renomeia uma rede (apenas Admin)
+		chainGroup.PUT("/update/:id", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem alterar redes"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de rede inválido"})
+				return
+			}
+
+			var updateDTO dto.UpdateStoreChainDTO
+			if err := c.ShouldBindJSON(&updateDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			chain, err := storeService.UpdateChain(uint(id), updateDTO, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			_, branches, _ := storeService.GetChainWithBranches(chain.ID, c.GetUint("userID"))
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Rede atualizada com sucesso",
+				"chain":   storeService.ToStoreChainResponseDTO(chain, int64(len(branches))),
+			})
+		})
+
+		// Remove uma rede sem filiais (apenas Admin)
+		chainGroup.DELETE("/delete/:id", authMw, func(c *gin.Context) {
+			userRole := c.GetString("userRole")
+			if userRole != string(models.RoleAdmin) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Apenas administradores podem remover redes"})
+				return
+			}
+
+			id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID de rede inválido"})
+				return
+			}
+
+			if err := storeService.DeleteChain(uint(id), userRole); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Rede removida com sucesso"})
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSuggestionRoutes configures restock and "buy again" suggestion routes
+func RegisterSuggestionRoutes(router *gin.Engine, suggestionService *services.SuggestionService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	suggestionGroup := router.Group("/suggestions")
+	{
+		// Products that are probably running out (?horizonDays=7)
+		suggestionGroup.GET("/restock", authMiddleware, func(c *gin.Context) {
+			horizonDays, err := strconv.Atoi(c.DefaultQuery("horizonDays", "7"))
+			if err != nil || horizonDays < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "horizonDays inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			suggestions, err := suggestionService.GetRestockSuggestions(userID, horizonDays, time.Now())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"suggestions": suggestions,
+				"count":       len(suggestions),
+			})
+		})
+
+		// Products ranked by purchase frequency and recency (?limit=20)
+		suggestionGroup.GET("/buy-again", authMiddleware, func(c *gin.Context) {
+			limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+			if err != nil || limit < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			suggestions, err := suggestionService.GetBuyAgainSuggestions(userID, limit, time.Now())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"suggestions": suggestions,
+				"count":       len(suggestions),
+			})
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/middleware"
+	"github.com/Parron01/AppMercado/backend/internal/services"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWebhookRoutes configures webhook subscription routes
+func RegisterWebhookRoutes(router *gin.Engine, webhookService *services.WebhookService, appConfig *config.Config) {
+	authMiddleware := middleware.AuthMiddleware(appConfig)
+
+	webhookGroup := router.Group("/webhooks")
+	{
+		// List the event types that can be subscribed
+		webhookGroup.GET("/event-types", authMiddleware, func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"eventTypes": services.WebhookEventTypes})
+		})
+
+		// Create a webhook subscription (the secret is only shown in this response)
+		webhookGroup.POST("/create", authMiddleware, func(c *gin.Context) {
+			var createDTO dto.CreateWebhookDTO
+			if err := c.ShouldBindJSON(&createDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+
+			subscription, err := webhookService.CreateWebhook(createDTO, userID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Webhook criado com sucesso",
+				"webhook": webhookService.ToWebhookWithSecretResponseDTO(subscription),
+			})
+		})
+
+		// List the webhooks of the authenticated user
+		webhookGroup.GET("/all", authMiddleware, func(c *gin.Context) {
+			userID := c.GetUint("userID")
+
+			subscriptions, err := webhookService.GetWebhooksByUserID(userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"webhooks": webhookService.ToWebhookResponseDTOList(subscriptions)})
+		})
+
+		// Get a webhook by ID
+		webhookGroup.GET("/:id", authMiddleware, func(c *gin.Context) {
+			webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			subscription, err := webhookService.GetWebhookByID(uint(webhookID), userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"webhook": webhookService.ToWebhookResponseDTO(subscription)})
+		})
+
+		// Update a webhook (URL, event types, description or active flag)
+		webhookGroup.PUT("/update/:id", authMiddleware, func(c *gin.Context) {
+			webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			var updateDTO dto.UpdateWebhookDTO
+			if err := c.ShouldBindJSON(&updateDTO); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			subscription, err := webhookService.UpdateWebhook(uint(webhookID), updateDTO, userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Webhook atualizado com sucesso",
+				"webhook": webhookService.ToWebhookResponseDTO(subscription),
+			})
+		})
+
+		// Generate a new signing secret
+		webhookGroup.POST("/:id/rotate-secret", authMiddleware, func(c *gin.Context) {
+			webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			subscription, err := webhookService.RotateWebhookSecret(uint(webhookID), userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message": "Segredo do webhook atualizado",
+				"webhook": webhookService.ToWebhookWithSecretResponseDTO(subscription),
+			})
+		})
+
+		// Delete a webhook
+		webhookGroup.DELETE("/delete/:id", authMiddleware, func(c *gin.Context) {
+			webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			if err := webhookService.DeleteWebhook(uint(webhookID), userID, userRole); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Webhook excluído com sucesso"})
+		})
+
+		// Delivery log of a webhook (?limit=50)
+		webhookGroup.GET("/:id/deliveries", authMiddleware, func(c *gin.Context) {
+			webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+			limit, _ := strconv.Atoi(c.Query("limit"))
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			deliveries, err := webhookService.GetWebhookDeliveries(uint(webhookID), limit, userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"deliveries": webhookService.ToWebhookDeliveryResponseDTOList(deliveries)})
+		})
+
+		// Schedule a delivery to be sent again
+		webhookGroup.POST("/deliveries/:id/redeliver", authMiddleware, func(c *gin.Context) {
+			deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+				return
+			}
+
+			userID := c.GetUint("userID")
+			userRole := c.GetString("userRole")
+
+			delivery, err := webhookService.RedeliverWebhook(uint(deliveryID), userID, userRole)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":  "Entrega reagendada",
+				"delivery": webhookService.ToWebhookDeliveryResponseDTO(delivery),
+			})
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// Budget define um limite mensal de gastos do usuário para uma categoria ou para o total
+type Budget struct {
+	gorm.Model
+	UserID     uint      `gorm:"not null;index:idx_budget_user_category"`
+	User       User      `gorm:"foreignKey:UserID"`
+	CategoryID *uint     `gorm:"index:idx_budget_user_category"` // nil = orçamento do total de compras
+	Category   *Category `gorm:"foreignKey:CategoryID"`
+
+	MonthlyLimit     float64 `gorm:"type:decimal(10,4);not null"`
+	WarningThreshold float64 `gorm:"type:decimal(5,2);not null;default:80"` // Percentual do limite que dispara o alerta
+
+	// Rollover: saldo não utilizado de meses anteriores é somado ao limite do mês
+	RolloverEnabled   bool `gorm:"not null;default:false"`
+	RolloverMaxMonths int  `gorm:"not null;default:1"` // Quantos meses anteriores podem acumular saldo
+
+	// Controle para não repetir o mesmo alerta no mesmo mês
+	LastAlertPeriod string `gorm:"size:7"`  // Formato YYYY-MM
+	LastAlertLevel  string `gorm:"size:20"` // warning, exceeded
+}
@@ -4,7 +4,10 @@ import "gorm.io/gorm"
 
 type Category struct {
 	gorm.Model
-	Name   string `gorm:"size:100;not null"`
-	UserID uint   `gorm:"not null"`
-	User   User   `gorm:"foreignKey:UserID"`
+	Name     string    `gorm:"size:100;not null"`
+	UserID   uint      `gorm:"not null"`
+	User     User      `gorm:"foreignKey:UserID"`
+	ParentID *uint     `gorm:"index"`               // nil = categoria raiz
+	Parent   *Category `gorm:"foreignKey:ParentID"` // Ex.: Bebidas > Refrigerantes
+	Position int       `gorm:"not null;default:0"`  // Ordem entre as categorias irmãs
 }
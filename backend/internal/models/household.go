@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// Household agrupa usuários que compartilham compras (mesma residência)
+type Household struct {
+	gorm.Model
+	Name       string `gorm:"size:100;not null"`
+	OwnerID    uint   `gorm:"not null"`
+	Owner      User   `gorm:"foreignKey:OwnerID"`
+	InviteCode string `gorm:"size:32;uniqueIndex;not null"` // Código usado para entrar no domicílio
+	Members    []User `gorm:"foreignKey:HouseholdID"`
+}
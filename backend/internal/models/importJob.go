@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportJobStatus representa a situação de uma importação em lote
+type ImportJobStatus string
+
+const (
+	ImportJobPending     ImportJobStatus = "pending"
+	ImportJobRunning     ImportJobStatus = "running"
+	ImportJobCompleted   ImportJobStatus = "completed"
+	ImportJobFailed      ImportJobStatus = "failed"
+	ImportJobCancelled   ImportJobStatus = "cancelled"
+	ImportJobInterrupted ImportJobStatus = "interrupted" // Servidor reiniciado durante a execução
+)
+
+// ImportJob registra o progresso de uma importação em lote, permitindo retomá-la do último checkpoint
+type ImportJob struct {
+	gorm.Model
+	Kind        string          `gorm:"size:50;not null;index"` // Ex.: "openfoodfacts"
+	Source      string          `gorm:"size:500;not null"`      // Caminho do arquivo importado
+	Format      string          `gorm:"size:20;not null"`
+	Country     string          `gorm:"size:100"`
+	BatchSize   int             `gorm:"not null"`
+	Status      ImportJobStatus `gorm:"size:20;not null;index"`
+	CreatedByID uint            `gorm:"not null"`
+
+	// Checkpoint: atualizado a cada lote gravado
+	TotalBytes     int64
+	ProcessedBytes int64
+	ResumeOffset   int64 // Posição no arquivo logo após o último registro gravado
+	ProcessedRows  int64 // Registros lidos (inclusive filtrados e inválidos)
+	ImportedRows   int64 // Produtos criados ou com colunas vazias preenchidas
+	SkippedRows    int64 // Filtrados por país, sem código de barras/nome ou com sugestão pendente
+	ErrorRows      int64 // Registros malformados
+
+	LastError  string `gorm:"size:1000"`
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+
+	// Concessão de execução: o processo que executa a importação (API ou cmd/offimport) a renova
+	// periodicamente; vencida, a importação é considerada interrompida
+	LeaseExpiresAt *time.Time
+}
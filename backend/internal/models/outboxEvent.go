@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxEvent é um evento de domínio gravado na mesma transação da alteração que o originou.
+// O dispatcher de webhooks o lê depois e cria as entregas para as assinaturas interessadas.
+type OutboxEvent struct {
+	gorm.Model
+	EventType    string     `gorm:"size:50;not null;index"`
+	UserID       *uint      `gorm:"index"`              // Dono do evento; nil = evento global (ex.: produto atualizado)
+	Payload      string     `gorm:"type:text;not null"` // JSON com os dados do evento
+	DispatchedAt *time.Time `gorm:"index"`              // Preenchido quando as entregas foram criadas
+}
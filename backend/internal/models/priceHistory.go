@@ -14,6 +14,10 @@ type PriceHistory struct {
 	UserID        uint      `gorm:"not null;index:idx_price_history_user"`
 	User          User      `gorm:"foreignKey:UserID"`
 	PurchaseDate  time.Time `gorm:"not null;index:idx_price_history_date"`
-	PurchasePlace string    `gorm:"size:255"`                    // Store where the product was purchased
-	PricePaid     float64   `gorm:"type:decimal(10,4);not null"` // Aumentado para decimal(10,4)
+	PurchasePlace string    `gorm:"size:255"` // Store where the product was purchased (copy of Store.Name)
+	StoreID       *uint     `gorm:"index"`
+	Store         *Store    `gorm:"foreignKey:StoreID"`
+	PricePaid     float64   `gorm:"type:decimal(10,4);not null"`           // Aumentado para decimal(10,4)
+	Quantity      float64   `gorm:"type:decimal(10,4);not null;default:1"` // Quantidade comprada, usada na média ponderada
+	Unit          string    `gorm:"size:5;not null;default:un"`            // Unidade do item (g, kg, ml, L, un); o preço pago é por ela
 }
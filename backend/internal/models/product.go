@@ -2,13 +2,43 @@ package models
 
 import "gorm.io/gorm"
 
+// ProductStatus representa a situação de moderação de um produto
+type ProductStatus string
+
+const (
+	ProductApproved ProductStatus = "approved" // Produto do catálogo, visível a todos
+	ProductPending  ProductStatus = "pending"  // Sugestão de usuário aguardando moderação, usável apenas por quem sugeriu
+	ProductRejected ProductStatus = "rejected" // Sugestão recusada
+	ProductMerged   ProductStatus = "merged"   // Sugestão mesclada a um produto existente
+)
+
 // Product define a estrutura da tabela de produtos
 type Product struct {
 	gorm.Model
 	Name    string  `gorm:"size:255;not null"`
 	Barcode *string `gorm:"size:100;uniqueIndex"` // Alterado para *string para permitir NULL
 
+	Brand        string `gorm:"size:255"`
+	PackageLabel string `gorm:"size:100"` // Tamanho/quantidade como impresso na embalagem (ex.: "1 L", "500 g")
+	// Conteúdo da embalagem na unidade de medida (g, kg, ml, L, un), base do preço por kg/L/unidade
+	PackageQuantity *float64 `gorm:"type:decimal(10,3)"`
+	Unit            string   `gorm:"size:5"`
+	ImageURL        string   `gorm:"size:500"`
+
+	// Moderação das sugestões de usuários
+	Status         ProductStatus `gorm:"size:10;not null;default:approved;index"`
+	ProposedByID   *uint         `gorm:"index"`    // Usuário que sugeriu o produto
+	ModerationNote string        `gorm:"size:255"` // Motivo da recusa ou observação do moderador
+
 	// Relacionamentos (serão mais explorados ao criar as tabelas de junção e PriceHistory)
 	// UserCategoryProducts []UserCategoryProduct `gorm:"foreignKey:ProductID"`
 	// PriceHistories       []PriceHistory        `gorm:"foreignKey:ProductID"`
 }
+
+// ProductRedirect leva o ID de um produto mesclado ao produto que o absorveu, para que links e
+// integrações com o ID antigo continuem funcionando
+type ProductRedirect struct {
+	gorm.Model
+	FromProductID uint `gorm:"not null;uniqueIndex"`
+	ProductID     uint `gorm:"not null;index"`
+}
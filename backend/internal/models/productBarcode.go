@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// ProductBarcode é um código de barras adicional de um produto (EAN regional, de embalagem promocional
+// ou de embalagem múltipla). O código principal continua em Product.Barcode.
+type ProductBarcode struct {
+	gorm.Model
+	ProductID uint     `gorm:"not null;index"`
+	Product   *Product `gorm:"foreignKey:ProductID"`
+	Code      string   `gorm:"size:14;not null;uniqueIndex"` // GTIN-14
+	// Unidades do produto em uma embalagem com este código (ex.: 6 no fardo com 6 latas); 1 = o próprio produto
+	UnitsPerPack int    `gorm:"not null;default:1"`
+	Description  string `gorm:"size:100"` // Ex.: "Fardo com 6", "Embalagem promocional"
+}
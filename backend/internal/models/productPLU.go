@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// ProductPLU é o código interno (PLU) com que a balança identifica um produto de peso variável nas
+// etiquetas com prefixo 2. Sem estabelecimento, o PLU vale para todos os que não têm um próprio.
+type ProductPLU struct {
+	gorm.Model
+	ProductID uint     `gorm:"not null;index"`
+	Product   *Product `gorm:"foreignKey:ProductID"`
+	StoreID   *uint    `gorm:"uniqueIndex:idx_store_plu"`
+	Store     *Store   `gorm:"foreignKey:StoreID"`
+	PLU       string   `gorm:"column:plu;size:6;not null;uniqueIndex:idx_store_plu"` // Sem zeros à esquerda
+}
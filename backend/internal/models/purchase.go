@@ -10,9 +10,12 @@ import (
 type Purchase struct {
 	gorm.Model
 	PurchaseDate     time.Time `gorm:"not null;index:idx_purchase_date_location_user"`
-	PurchaseLocation string    `gorm:"size:255;index:idx_purchase_date_location_user"`
-	UserID           uint      `gorm:"not null;index:idx_purchase_date_location_user"`
+	PurchaseLocation string    `gorm:"size:255;index:idx_purchase_date_location_user"` // Nome do estabelecimento (cópia de Store.Name)
+	StoreID          *uint     `gorm:"index"`                                          // Nulo apenas em compras ainda não vinculadas a um estabelecimento
+	Store            *Store    `gorm:"foreignKey:StoreID"`
+	UserID           uint      `gorm:"not null;index:idx_purchase_date_location_user;uniqueIndex:idx_purchase_user_invoice_key"`
 	User             User      `gorm:"foreignKey:UserID"`
+	InvoiceKey       *string   `gorm:"size:44;uniqueIndex:idx_purchase_user_invoice_key"` // Chave de acesso da NF-e/NFC-e importada
 
 	// Relationships
 	Items []PurchaseItem `gorm:"foreignKey:PurchaseID"`
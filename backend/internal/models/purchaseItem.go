@@ -8,6 +8,7 @@ type PurchaseItem struct {
 	PurchaseID uint    `gorm:"not null"`
 	ProductID  uint    `gorm:"not null"`
 	Quantity   float64 `gorm:"type:decimal(10,4);not null"` // Aumentado para decimal(10,4)
+	Unit       string  `gorm:"size:5;not null;default:un"`  // Unidade da quantidade (g, kg, ml, L, un); o preço unitário é por ela
 	UnitPrice  float64 `gorm:"type:decimal(10,4);not null"` // Aumentado para decimal(10,4)
 	TotalPrice float64 `gorm:"type:decimal(10,4);not null"` // Aumentado para decimal(10,4)
 
@@ -0,0 +1,36 @@
+package models
+
+import "gorm.io/gorm"
+
+// StoreChain é uma rede de estabelecimentos (ex.: "Carrefour"); cada Store da rede é uma filial
+type StoreChain struct {
+	gorm.Model
+	Name           string `gorm:"size:100;not null"`
+	NormalizedName string `gorm:"size:100;not null;uniqueIndex"` // Nome sem acentos, pontuação e maiúsculas
+}
+
+// Store é um estabelecimento onde as compras são feitas. Os cadastrados (ou identificados pelo CNPJ) são
+// compartilhados entre os usuários; os criados a partir de um local digitado pertencem a quem o digitou.
+type Store struct {
+	gorm.Model
+	OwnerID        *uint       `gorm:"index"`                   // Usuário que digitou o local; nil = compartilhado
+	Name           string      `gorm:"size:255;not null"`       // Nome exibido (ex.: "Carrefour Centro")
+	NormalizedName string      `gorm:"size:255;not null;index"` // Nome sem acentos, pontuação e maiúsculas, usado no casamento
+	ChainID        *uint       `gorm:"index"`                   // Rede à qual a filial pertence
+	Chain          *StoreChain `gorm:"foreignKey:ChainID"`
+	BranchName     string      `gorm:"size:255"` // Filial (ex.: "Centro")
+	Address        string      `gorm:"size:255"`
+	CNPJ           *string     `gorm:"column:cnpj;size:14;uniqueIndex"` // Apenas dígitos
+	Latitude       *float64    `gorm:"type:decimal(9,6)"`
+	Longitude      *float64    `gorm:"type:decimal(9,6)"`
+	// Leiaute das etiquetas de peso variável das balanças (ex.: "2PPPPPVVVVVVC"); vazio usa o padrão
+	BarcodeLayout string `gorm:"size:13"`
+}
+
+// StoreAlias é um nome alternativo que leva a um estabelecimento, como o de um estabelecimento
+// duplicado que foi mesclado a ele
+type StoreAlias struct {
+	gorm.Model
+	StoreID        uint   `gorm:"not null;index"`
+	NormalizedName string `gorm:"size:255;not null;uniqueIndex"`
+}
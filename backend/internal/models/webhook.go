@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription é um endpoint do usuário que recebe eventos assinados com HMAC-SHA256
+type WebhookSubscription struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	User        User   `gorm:"foreignKey:UserID"`
+	URL         string `gorm:"size:500;not null"`
+	Secret      string `gorm:"size:64;not null"`  // Chave do HMAC das entregas
+	EventTypes  string `gorm:"size:500;not null"` // Tipos de evento separados por vírgula
+	Description string `gorm:"size:255"`
+	Active      bool   `gorm:"not null;default:true"`
+}
+
+// WebhookDeliveryStatus representa a situação de uma entrega
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Tentativas esgotadas
+)
+
+// WebhookDelivery registra a entrega de um evento a uma assinatura e suas tentativas
+type WebhookDelivery struct {
+	gorm.Model
+	SubscriptionID uint                  `gorm:"not null;index"`
+	EventID        uint                  `gorm:"not null;index"`
+	Event          OutboxEvent           `gorm:"foreignKey:EventID"`
+	EventType      string                `gorm:"size:50;not null"`
+	Status         WebhookDeliveryStatus `gorm:"size:20;not null;index:idx_webhook_delivery_due"`
+	Attempts       int                   `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `gorm:"index:idx_webhook_delivery_due"`
+	LastStatusCode int
+	LastError      string `gorm:"size:1000"`
+	LastDurationMs int64
+	DeliveredAt    *time.Time
+}
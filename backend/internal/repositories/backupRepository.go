@@ -0,0 +1,316 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// BackupRepository handles the bulk reads and the lookups used by backup and restore.
+// Nos métodos Stream*, userID 0 percorre a instância inteira.
+type BackupRepository struct {
+	database *gorm.DB
+}
+
+// NewBackupRepository creates a new instance of BackupRepository
+func NewBackupRepository(db *gorm.DB) *BackupRepository {
+	return &BackupRepository{database: db}
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *BackupRepository) WithTx(tx *gorm.DB) *BackupRepository {
+	return &BackupRepository{database: tx}
+}
+
+// ownedBy restringe a consulta aos registros do usuário, quando informado
+func ownedBy(query *gorm.DB, column string, userID uint) *gorm.DB {
+	if userID == 0 {
+		return query
+	}
+	return query.Where(column+" = ?", userID)
+}
+
+// StreamUsers percorre os usuários (ou apenas o usuário informado)
+func (repo *BackupRepository) StreamUsers(userID uint, fn func(user *models.User) error) error {
+	query := ownedBy(repo.database.Model(&models.User{}), "id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// StreamHouseholds percorre todos os domicílios
+func (repo *BackupRepository) StreamHouseholds(fn func(household *models.Household) error) error {
+	return streamRows(repo.database.Model(&models.Household{}).Order("id"), fn)
+}
+
+// StreamCategories percorre as categorias
+func (repo *BackupRepository) StreamCategories(userID uint, fn func(category *models.Category) error) error {
+	query := ownedBy(repo.database.Model(&models.Category{}), "user_id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// Produtos e estabelecimentos referenciados pelos dados de um usuário (o parâmetro é repetido em cada ramo)
+const (
+	userProductIDsSQL = `SELECT pi.product_id FROM purchase_items AS pi
+			JOIN purchases AS p ON p.id = pi.purchase_id AND p.deleted_at IS NULL
+			WHERE pi.deleted_at IS NULL AND p.user_id = @user
+		UNION
+			SELECT product_id FROM price_histories WHERE deleted_at IS NULL AND user_id = @user
+		UNION
+			SELECT product_id FROM user_category_products WHERE deleted_at IS NULL AND user_id = @user`
+
+	userStoreIDsSQL = `SELECT store_id FROM purchases WHERE deleted_at IS NULL AND user_id = @user
+		UNION
+			SELECT store_id FROM price_histories WHERE deleted_at IS NULL AND user_id = @user`
+
+	// Os registros ligados a produtos ou estabelecimentos removidos ficam fora do arquivo, que não os inclui
+	activeProductSQL = "product_id IN (SELECT id FROM products WHERE deleted_at IS NULL)"
+	activeStoreSQL   = "(store_id IS NULL OR store_id IN (SELECT id FROM stores WHERE deleted_at IS NULL))"
+)
+
+// StreamProducts percorre os produtos; para um usuário, apenas os referenciados por suas compras,
+// histórico de preços ou categorias
+func (repo *BackupRepository) StreamProducts(userID uint, fn func(product *models.Product) error) error {
+	query := repo.database.Model(&models.Product{})
+	if userID != 0 {
+		query = query.Where("id IN ("+userProductIDsSQL+")", sql.Named("user", userID))
+	}
+	return streamRows(query.Order("id"), fn)
+}
+
+// StreamProductBarcodes percorre os códigos de barras adicionais; para um usuário, apenas os dos
+// produtos que ele referencia
+func (repo *BackupRepository) StreamProductBarcodes(userID uint, fn func(productBarcode *models.ProductBarcode) error) error {
+	query := repo.database.Model(&models.ProductBarcode{}).Where(activeProductSQL)
+	if userID != 0 {
+		query = query.Where("product_id IN ("+userProductIDsSQL+")", sql.Named("user", userID))
+	}
+	return streamRows(query.Order("id"), fn)
+}
+
+// ProductRedirectRow é um redirecionamento com o nome e o código do produto mesclado (removido)
+type ProductRedirectRow struct {
+	models.ProductRedirect
+	FromName    string
+	FromBarcode *string
+}
+
+// StreamProductRedirects percorre os redirecionamentos de produtos mesclados; para um usuário,
+// apenas os que levam aos produtos que ele referencia
+func (repo *BackupRepository) StreamProductRedirects(userID uint, fn func(redirect *ProductRedirectRow) error) error {
+	query := repo.database.Model(&models.ProductRedirect{}).
+		Select("product_redirects.*, products.name AS from_name, products.barcode AS from_barcode").
+		Joins("JOIN products ON products.id = product_redirects.from_product_id").
+		Where("product_redirects." + activeProductSQL)
+	if userID != 0 {
+		query = query.Where("product_redirects.product_id IN ("+userProductIDsSQL+")", sql.Named("user", userID))
+	}
+	return streamRows(query.Order("product_redirects.id"), fn)
+}
+
+// StreamStores percorre os estabelecimentos; para um usuário, apenas os referenciados por suas
+// compras ou histórico de preços
+func (repo *BackupRepository) StreamStores(userID uint, fn func(store *models.Store) error) error {
+	query := repo.database.Model(&models.Store{})
+	if userID != 0 {
+		query = query.Where("id IN ("+userStoreIDsSQL+")", sql.Named("user", userID))
+	}
+	return streamRows(query.Order("id"), fn)
+}
+
+// StreamProductPLUs percorre os PLUs; para um usuário, apenas os dos produtos que ele referencia,
+// gerais ou dos estabelecimentos em que ele comprou
+func (repo *BackupRepository) StreamProductPLUs(userID uint, fn func(productPLU *models.ProductPLU) error) error {
+	query := repo.database.Model(&models.ProductPLU{}).Where(activeProductSQL).Where(activeStoreSQL)
+	if userID != 0 {
+		query = query.Where("product_id IN ("+userProductIDsSQL+") AND (store_id IS NULL OR store_id IN ("+userStoreIDsSQL+"))",
+			sql.Named("user", userID))
+	}
+	return streamRows(query.Order("id"), fn)
+}
+
+// GetStoreChainNames retorna o nome de cada rede de estabelecimentos
+func (repo *BackupRepository) GetStoreChainNames() (map[uint]string, error) {
+	var chains []models.StoreChain
+	if err := repo.database.Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(chains))
+	for _, chain := range chains {
+		names[chain.ID] = chain.Name
+	}
+	return names, nil
+}
+
+// StreamPurchases percorre as compras
+func (repo *BackupRepository) StreamPurchases(userID uint, fn func(purchase *models.Purchase) error) error {
+	query := ownedBy(repo.database.Model(&models.Purchase{}), "user_id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// StreamPurchaseItems percorre os itens das compras (de compras não excluídas)
+func (repo *BackupRepository) StreamPurchaseItems(userID uint, fn func(item *models.PurchaseItem) error) error {
+	query := repo.database.Model(&models.PurchaseItem{}).Select("purchase_items.*").
+		Joins("JOIN purchases AS p ON p.id = purchase_items.purchase_id AND p.deleted_at IS NULL")
+	query = ownedBy(query, "p.user_id", userID).Order("purchase_items.id")
+	return streamRows(query, fn)
+}
+
+// StreamPriceHistory percorre o histórico de preços
+func (repo *BackupRepository) StreamPriceHistory(userID uint, fn func(entry *models.PriceHistory) error) error {
+	query := ownedBy(repo.database.Model(&models.PriceHistory{}), "user_id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// StreamUserCategoryProducts percorre os vínculos entre categorias e produtos
+func (repo *BackupRepository) StreamUserCategoryProducts(userID uint, fn func(ucp *models.UserCategoryProduct) error) error {
+	query := ownedBy(repo.database.Model(&models.UserCategoryProduct{}), "user_id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// StreamBudgets percorre os orçamentos
+func (repo *BackupRepository) StreamBudgets(userID uint, fn func(budget *models.Budget) error) error {
+	query := ownedBy(repo.database.Model(&models.Budget{}), "user_id", userID).Order("id")
+	return streamRows(query, fn)
+}
+
+// Create insere um registro restaurado (as datas de criação e atualização do backup são mantidas)
+func (repo *BackupRepository) Create(record any) error {
+	return repo.database.Create(record).Error
+}
+
+// Save atualiza um registro existente
+func (repo *BackupRepository) Save(record any) error {
+	return repo.database.Save(record).Error
+}
+
+// findFirst executa a consulta e retorna nil (sem erro) quando nada é encontrado
+func findFirst[T any](query *gorm.DB) (*T, error) {
+	var record T
+	if err := query.First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// FindUserByEmail busca um usuário pelo e-mail (sem diferenciar maiúsculas)
+func (repo *BackupRepository) FindUserByEmail(email string) (*models.User, error) {
+	return findFirst[models.User](repo.database.Where("LOWER(email) = ?", strings.ToLower(email)))
+}
+
+// FindHouseholdByInviteCode busca um domicílio pelo código de convite
+func (repo *BackupRepository) FindHouseholdByInviteCode(inviteCode string) (*models.Household, error) {
+	return findFirst[models.Household](repo.database.Where("invite_code = ?", inviteCode))
+}
+
+// SetUserHousehold vincula o usuário ao domicílio
+func (repo *BackupRepository) SetUserHousehold(userID uint, householdID uint) error {
+	return repo.database.Model(&models.User{}).Where("id = ?", userID).Update("household_id", householdID).Error
+}
+
+// FindProductByBarcode busca um produto pelo código de barras. Produtos removidos também são retornados
+// (o código continua reservado a eles pelo índice único); sem produto com o código principal, busca entre
+// os códigos adicionais de embalagem unitária, para onde vão os códigos dos produtos mesclados.
+func (repo *BackupRepository) FindProductByBarcode(barcode string) (*models.Product, error) {
+	product, err := findFirst[models.Product](repo.database.Unscoped().Where("barcode = ?", barcode))
+	if product != nil || err != nil {
+		return product, err
+	}
+	query := repo.database.Where(
+		"id IN (SELECT product_id FROM product_barcodes WHERE code = ? AND units_per_pack = 1 AND deleted_at IS NULL)", barcode)
+	return findFirst[models.Product](query)
+}
+
+// FindProductBarcode busca um código adicional pelo código, inclusive removido (o índice único o reserva)
+func (repo *BackupRepository) FindProductBarcode(code string) (*models.ProductBarcode, error) {
+	return findFirst[models.ProductBarcode](repo.database.Unscoped().Where("code = ?", code))
+}
+
+// FindPLU busca o PLU do estabelecimento (ou o geral, com storeID nil)
+func (repo *BackupRepository) FindPLU(plu string, storeID *uint) (*models.ProductPLU, error) {
+	query := repo.database.Where("plu = ?", plu)
+	if storeID == nil {
+		query = query.Where("store_id IS NULL")
+	} else {
+		query = query.Where("store_id = ?", *storeID)
+	}
+	return findFirst[models.ProductPLU](query)
+}
+
+// FindProductRedirect busca o produto que absorveu um produto mesclado
+func (repo *BackupRepository) FindProductRedirect(fromProductID uint) (*models.ProductRedirect, error) {
+	return findFirst[models.ProductRedirect](repo.database.Where("from_product_id = ?", fromProductID))
+}
+
+// FindProductByID busca um produto ativo pelo ID
+func (repo *BackupRepository) FindProductByID(id uint) (*models.Product, error) {
+	return findFirst[models.Product](repo.database.Where("id = ?", id))
+}
+
+// ReleaseProductBarcode libera o código de barras de um produto removido
+func (repo *BackupRepository) ReleaseProductBarcode(productID uint) error {
+	return repo.database.Unscoped().Model(&models.Product{}).Where("id = ?", productID).Update("barcode", nil).Error
+}
+
+// FindProductWithoutBarcodeByName busca um produto sem código de barras pelo nome (sem diferenciar maiúsculas)
+func (repo *BackupRepository) FindProductWithoutBarcodeByName(name string) (*models.Product, error) {
+	query := repo.database.Where("barcode IS NULL AND LOWER(name) = ?", strings.ToLower(name)).Order("id")
+	return findFirst[models.Product](query)
+}
+
+// FindCategoryByName busca uma categoria do usuário pelo nome (sem diferenciar maiúsculas)
+func (repo *BackupRepository) FindCategoryByName(userID uint, name string) (*models.Category, error) {
+	query := repo.database.Where("user_id = ? AND LOWER(name) = ?", userID, strings.ToLower(name)).Order("id")
+	return findFirst[models.Category](query)
+}
+
+// FindPurchase busca uma compra já existente do usuário: pela chave da nota fiscal, quando houver,
+// ou pela data e local
+func (repo *BackupRepository) FindPurchase(userID uint, invoiceKey *string, date time.Time, location string) (*models.Purchase, error) {
+	if invoiceKey != nil {
+		purchase, err := findFirst[models.Purchase](repo.database.Where("user_id = ? AND invoice_key = ?", userID, *invoiceKey))
+		if purchase != nil || err != nil {
+			return purchase, err
+		}
+	}
+	query := repo.database.Where("user_id = ? AND purchase_location = ? AND purchase_date BETWEEN ? AND ?",
+		userID, location, date.Add(-1*time.Second), date.Add(1*time.Second))
+	return findFirst[models.Purchase](query)
+}
+
+// PriceHistoryExists indica se o usuário já tem o mesmo registro de preço
+func (repo *BackupRepository) PriceHistoryExists(entry *models.PriceHistory) (bool, error) {
+	var count int64
+	err := repo.database.Model(&models.PriceHistory{}).
+		Where("user_id = ? AND product_id = ? AND purchase_date = ? AND price_paid = ?",
+			entry.UserID, entry.ProductID, entry.PurchaseDate, entry.PricePaid).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UserCategoryProductExists indica se o produto já está na categoria do usuário
+func (repo *BackupRepository) UserCategoryProductExists(userID, categoryID, productID uint) (bool, error) {
+	var count int64
+	err := repo.database.Model(&models.UserCategoryProduct{}).
+		Where("user_id = ? AND category_id = ? AND product_id = ?", userID, categoryID, productID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BudgetExists indica se o usuário já tem orçamento para a categoria (ou para o total, com categoryID nil)
+func (repo *BackupRepository) BudgetExists(userID uint, categoryID *uint) (bool, error) {
+	var count int64
+	query := repo.database.Model(&models.Budget{}).Where("user_id = ?", userID)
+	if categoryID == nil {
+		query = query.Where("category_id IS NULL")
+	} else {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
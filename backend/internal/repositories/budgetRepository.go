@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// BudgetRepository handles database operations for budgets
+type BudgetRepository struct {
+	database *gorm.DB
+}
+
+// NewBudgetRepository creates a new instance of BudgetRepository
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{database: db}
+}
+
+// PurchaseSpending representa o total gasto em uma compra, já filtrado por categoria quando aplicável
+type PurchaseSpending struct {
+	PurchaseDate time.Time
+	Total        float64
+}
+
+// CreateBudget adds a new budget to the database
+func (repo *BudgetRepository) CreateBudget(budget *models.Budget) error {
+	return repo.database.Create(budget).Error
+}
+
+// GetBudgetByID retrieves a budget by its ID
+func (repo *BudgetRepository) GetBudgetByID(id uint) (*models.Budget, error) {
+	var budget models.Budget
+	if err := repo.database.Preload("Category").First(&budget, id).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// GetBudgetsByUserID retrieves all budgets for a specific user
+func (repo *BudgetRepository) GetBudgetsByUserID(userID uint) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	if err := repo.database.Preload("Category").Where("user_id = ?", userID).
+		Order("category_id NULLS FIRST").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// GetBudgetByUserAndCategory retrieves the budget of a user for a category (nil = total budget)
+func (repo *BudgetRepository) GetBudgetByUserAndCategory(userID uint, categoryID *uint) (*models.Budget, error) {
+	var budget models.Budget
+	query := repo.database.Where("user_id = ?", userID)
+	if categoryID == nil {
+		query = query.Where("category_id IS NULL")
+	} else {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+	if err := query.First(&budget).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// UpdateBudget updates an existing budget in the database
+func (repo *BudgetRepository) UpdateBudget(budget *models.Budget) error {
+	return repo.database.Omit("Category", "User").Save(budget).Error
+}
+
+// DeleteBudget removes a budget from the database
+func (repo *BudgetRepository) DeleteBudget(id uint) error {
+	return repo.database.Delete(&models.Budget{}, id).Error
+}
+
+// DeleteBudgetsByCategoryID remove os orçamentos da categoria informada
+func (repo *BudgetRepository) DeleteBudgetsByCategoryID(categoryID uint) error {
+	return repo.database.Where("category_id = ?", categoryID).Delete(&models.Budget{}).Error
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *BudgetRepository) WithTx(tx *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{database: tx}
+}
+
+// GetSpendingByPurchase soma, por compra, os itens comprados pelo usuário no intervalo [startDate, endDate).
+// Quando categoryID é informado, considera apenas produtos mapeados via UserCategoryProduct para a categoria
+// ou para qualquer uma de suas subcategorias.
+func (repo *BudgetRepository) GetSpendingByPurchase(
+	userID uint, categoryID *uint, startDate, endDate time.Time) ([]PurchaseSpending, error) {
+
+	query := repo.database.Table("purchase_items AS pi").
+		Select("p.purchase_date AS purchase_date, COALESCE(SUM(pi.total_price), 0) AS total").
+		Joins("JOIN purchases AS p ON p.id = pi.purchase_id AND p.deleted_at IS NULL").
+		Where("pi.deleted_at IS NULL AND p.user_id = ? AND p.purchase_date >= ? AND p.purchase_date < ?",
+			userID, startDate, endDate)
+
+	if categoryID != nil {
+		// Subconsulta evita contar o mesmo item duas vezes caso o mapeamento esteja duplicado
+		query = query.Where("pi.product_id IN (?)",
+			repo.database.Model(&models.UserCategoryProduct{}).
+				Select("product_id").
+				Where("user_id = ? AND category_id IN (?)", userID, categorySubtreeIDs(repo.database, []uint{*categoryID})))
+	}
+
+	var spending []PurchaseSpending
+	if err := query.Group("p.id, p.purchase_date").Order("p.purchase_date").Scan(&spending).Error; err != nil {
+		return nil, err
+	}
+	return spending, nil
+}
@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryRepository struct {
@@ -48,3 +52,199 @@ func (repository *CategoryRepository) GetAllCategories() ([]*models.Category, er
 	}
 	return categories, nil
 }
+
+// GetCategoriesByIDs busca de uma vez as categorias com os IDs informados
+func (repository *CategoryRepository) GetCategoriesByIDs(ids []uint) ([]*models.Category, error) {
+	var categories []*models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	if err := repository.database.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetCategoriesByUserIDs busca de uma vez as categorias de vários usuários
+func (repository *CategoryRepository) GetCategoriesByUserIDs(userIDs []uint) ([]*models.Category, error) {
+	var categories []*models.Category
+	if len(userIDs) == 0 {
+		return categories, nil
+	}
+	if err := repository.database.Where("user_id IN ?", userIDs).Order("name, id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repository *CategoryRepository) WithTx(tx *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{database: tx}
+}
+
+// categoryTreeDepthGuard interrompe a recursão caso a árvore contenha um ciclo gravado fora do serviço
+const categoryTreeDepthGuard = 32
+
+// categorySubtreeCTE monta a CTE recursiva "subtree" com as categorias que satisfazem rootCondition e todas
+// as suas subcategorias. Cada linha traz a raiz de origem (root_id), a profundidade relativa à raiz e o
+// caminho (position, id) usado para ordenar a árvore em pré-ordem.
+func categorySubtreeCTE(rootCondition string) string {
+	return fmt.Sprintf(`WITH RECURSIVE subtree AS (
+			SELECT id AS root_id, id, 0 AS depth, ARRAY[position, id] AS path
+			FROM categories WHERE deleted_at IS NULL AND %s
+		UNION ALL
+			SELECT s.root_id, c.id, s.depth + 1, s.path || ARRAY[c.position, c.id]
+			FROM categories AS c JOIN subtree AS s ON c.parent_id = s.id
+			WHERE c.deleted_at IS NULL AND s.depth < %d
+		) `, rootCondition, categoryTreeDepthGuard)
+}
+
+// categorySubtreeIDs é a subconsulta com os IDs das categorias informadas e de todas as suas subcategorias
+func categorySubtreeIDs(db *gorm.DB, rootIDs []uint) *gorm.DB {
+	return db.Raw(categorySubtreeCTE("id IN ?")+"SELECT id FROM subtree", rootIDs)
+}
+
+// GetCategoryTree busca em uma única consulta as categorias do usuário em pré-ordem (cada categoria seguida
+// de suas subcategorias, irmãs ordenadas por posição). Com rootID, retorna apenas a subárvore dessa categoria.
+func (repository *CategoryRepository) GetCategoryTree(userID uint, rootID *uint) ([]*models.Category, error) {
+	rootCondition, args := "user_id = ? AND parent_id IS NULL", []any{userID}
+	if rootID != nil {
+		rootCondition, args = "user_id = ? AND id = ?", []any{userID, *rootID}
+	}
+
+	var categories []*models.Category
+	err := repository.database.Raw(categorySubtreeCTE(rootCondition)+`
+		SELECT c.* FROM subtree AS s JOIN categories AS c ON c.id = s.id
+		ORDER BY s.path`, args...).Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetSubtreeCategoryIDs retorna, para cada categoria informada, o próprio ID e os de todas as suas subcategorias
+func (repository *CategoryRepository) GetSubtreeCategoryIDs(rootIDs []uint) (map[uint][]uint, error) {
+	subtrees := make(map[uint][]uint, len(rootIDs))
+	if len(rootIDs) == 0 {
+		return subtrees, nil
+	}
+	var rows []struct {
+		RootID uint
+		ID     uint
+	}
+	err := repository.database.Raw(categorySubtreeCTE("id IN ?")+`
+		SELECT root_id, id FROM subtree ORDER BY root_id, path`, rootIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		subtrees[row.RootID] = append(subtrees[row.RootID], row.ID)
+	}
+	return subtrees, nil
+}
+
+// GetSubtreeHeight retorna quantos níveis de subcategorias existem abaixo da categoria (0 = sem subcategorias)
+func (repository *CategoryRepository) GetSubtreeHeight(id uint) (int, error) {
+	var height int
+	err := repository.database.Raw(categorySubtreeCTE("id = ?")+"SELECT COALESCE(MAX(depth), 0) FROM subtree", id).
+		Scan(&height).Error
+	return height, err
+}
+
+// GetCategoryAncestorIDs retorna o ID da categoria seguido dos IDs de seus ancestrais, até a raiz
+func (repository *CategoryRepository) GetCategoryAncestorIDs(id uint) ([]uint, error) {
+	var ids []uint
+	err := repository.database.Raw(fmt.Sprintf(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = ? AND deleted_at IS NULL
+		UNION ALL
+			SELECT c.id, c.parent_id, a.depth + 1
+			FROM categories AS c JOIN ancestors AS a ON c.id = a.parent_id
+			WHERE c.deleted_at IS NULL AND a.depth < %d
+		)
+		SELECT id FROM ancestors ORDER BY depth`, categoryTreeDepthGuard), id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// LockCategoryTree bloqueia (SELECT ... FOR UPDATE, em ordem de ID) as categorias do usuário até o fim da
+// transação. Toda a árvore do usuário, inclusive a categoria-pai de um movimento, fica travada: dois
+// movimentos concorrentes na mesma árvore não conseguem validar ciclos e profundidade ao mesmo tempo.
+func (repository *CategoryRepository) LockCategoryTree(userID uint) error {
+	var ids []uint
+	return repository.database.Model(&models.Category{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		Order("id").
+		Pluck("id", &ids).Error
+}
+
+// GetChildCategories busca as subcategorias diretas (ou as raízes do usuário, com parentID nil) em ordem de posição
+func (repository *CategoryRepository) GetChildCategories(userID uint, parentID *uint) ([]*models.Category, error) {
+	query := repository.database.Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var categories []*models.Category
+	if err := query.Order("position, id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// NextCategoryPosition retorna a posição seguinte à última subcategoria de parentID (ou raiz do usuário)
+func (repository *CategoryRepository) NextCategoryPosition(userID uint, parentID *uint) (int, error) {
+	query := repository.database.Model(&models.Category{}).Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var position int
+	err := query.Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error
+	return position, err
+}
+
+// UpdateCategoryPlacement grava a categoria-pai e a posição sem tocar nos demais campos
+func (repository *CategoryRepository) UpdateCategoryPlacement(id uint, parentID *uint, position int) error {
+	return repository.database.Model(&models.Category{}).Where("id = ?", id).
+		Updates(map[string]any{"parent_id": parentID, "position": position}).Error
+}
+
+// CategoryProductSpending é o total gasto com um produto vinculado a uma categoria
+type CategoryProductSpending struct {
+	CategoryID uint
+	ProductID  uint
+	Total      float64
+}
+
+// GetCategoryProductSpending soma, para cada produto vinculado às categorias informadas, os itens comprados
+// pelo usuário no intervalo [startDate, endDate). Produtos sem compras no período aparecem com total zero.
+func (repository *CategoryRepository) GetCategoryProductSpending(
+	userID uint, categoryIDs []uint, startDate, endDate time.Time) ([]CategoryProductSpending, error) {
+
+	var spending []CategoryProductSpending
+	if len(categoryIDs) == 0 {
+		return spending, nil
+	}
+	// DISTINCT evita contar o mesmo item duas vezes caso o vínculo esteja duplicado
+	err := repository.database.Raw(`
+		SELECT ucp.category_id, ucp.product_id, COALESCE(SUM(pi.total_price), 0) AS total
+		FROM (
+			SELECT DISTINCT category_id, product_id FROM user_category_products
+			WHERE deleted_at IS NULL AND user_id = ? AND category_id IN ?
+		) AS ucp
+		LEFT JOIN purchase_items AS pi ON pi.product_id = ucp.product_id AND pi.deleted_at IS NULL
+			AND pi.purchase_id IN (
+				SELECT id FROM purchases
+				WHERE deleted_at IS NULL AND user_id = ? AND purchase_date >= ? AND purchase_date < ?)
+		GROUP BY ucp.category_id, ucp.product_id`,
+		userID, categoryIDs, userID, startDate, endDate).Scan(&spending).Error
+	if err != nil {
+		return nil, err
+	}
+	return spending, nil
+}
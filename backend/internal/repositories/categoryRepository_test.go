@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statementRecorder guarda o SQL gerado pelo GORM em modo DryRun, sem banco
+type statementRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (recorder *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	recorder.statements = append(recorder.statements, strings.Join(strings.Fields(sql), " "))
+}
+
+func newDryRunDatabase(t *testing.T) (*gorm.DB, *statementRecorder) {
+	t.Helper()
+	recorder := &statementRecorder{Interface: logger.Discard}
+	database, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: recorder})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return database, recorder
+}
+
+// TestSubtreeScopedQueries confere que orçamentos e vínculos de uma categoria incluem as subcategorias
+func TestSubtreeScopedQueries(t *testing.T) {
+	categoryID := uint(3)
+	subtree := "category_id IN (WITH RECURSIVE subtree AS ( SELECT id AS root_id, id, 0 AS depth, ARRAY[position, id] AS path " +
+		"FROM categories WHERE deleted_at IS NULL AND id IN (3) UNION ALL"
+
+	tests := []struct {
+		name  string
+		query func(database *gorm.DB)
+		want  []string
+	}{
+		{
+			name: "gasto do orçamento",
+			query: func(database *gorm.DB) {
+				now := time.Now()
+				NewBudgetRepository(database).GetSpendingByPurchase(7, &categoryID, now, now)
+			},
+			want: []string{"pi.product_id IN (SELECT \"product_id\" FROM \"user_category_products\" WHERE (user_id = 7 AND " + subtree},
+		},
+		{
+			name: "gasto do orçamento geral",
+			query: func(database *gorm.DB) {
+				now := time.Now()
+				NewBudgetRepository(database).GetSpendingByPurchase(7, nil, now, now)
+			},
+		},
+		{
+			name: "vínculos da categoria",
+			query: func(database *gorm.DB) {
+				NewUserCategoryProductRepository(database).GetUserCategoryProductsByCategoryID(categoryID)
+			},
+			want: []string{"FROM \"user_category_products\" WHERE " + subtree},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			database, recorder := newDryRunDatabase(t)
+			test.query(database)
+			if len(recorder.statements) != 1 {
+				t.Fatalf("%d consultas, esperado 1: %q", len(recorder.statements), recorder.statements)
+			}
+			sql := recorder.statements[0]
+			for _, want := range test.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("consulta sem %q:\n%s", want, sql)
+				}
+			}
+			if len(test.want) == 0 && strings.Contains(sql, "subtree") {
+				t.Errorf("consulta sem categoria não deveria percorrer a árvore:\n%s", sql)
+			}
+		})
+	}
+}
+
+// TestDeleteBudgetsByCategoryID confere que a remoção dos orçamentos de uma categoria é lógica e
+// restrita à categoria
+func TestDeleteBudgetsByCategoryID(t *testing.T) {
+	database, recorder := newDryRunDatabase(t)
+	// Sem banco, a escrita não pode abrir a transação padrão do GORM
+	database = database.Session(&gorm.Session{SkipDefaultTransaction: true})
+	NewBudgetRepository(database).DeleteBudgetsByCategoryID(3)
+	if len(recorder.statements) == 0 {
+		t.Fatal("nenhuma consulta registrada")
+	}
+	want := `UPDATE "budgets" SET "deleted_at"=`
+	if sql := recorder.statements[0]; !strings.HasPrefix(sql, want) || !strings.Contains(sql, "category_id = 3") {
+		t.Errorf("consulta = %s", sql)
+	}
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// HouseholdRepository handles database operations for households
+type HouseholdRepository struct {
+	database *gorm.DB
+}
+
+// NewHouseholdRepository creates a new instance of HouseholdRepository
+func NewHouseholdRepository(db *gorm.DB) *HouseholdRepository {
+	return &HouseholdRepository{database: db}
+}
+
+// CreateHousehold cria o domicílio e vincula o dono a ele na mesma transação
+func (repo *HouseholdRepository) CreateHousehold(household *models.Household) error {
+	return repo.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Owner", "Members").Create(household).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", household.OwnerID).
+			Update("household_id", household.ID).Error
+	})
+}
+
+// GetHouseholdByID retrieves a household with its members
+func (repo *HouseholdRepository) GetHouseholdByID(id uint) (*models.Household, error) {
+	var household models.Household
+	if err := repo.database.Preload("Members").First(&household, id).Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// GetHouseholdByInviteCode retrieves a household by its invite code
+func (repo *HouseholdRepository) GetHouseholdByInviteCode(inviteCode string) (*models.Household, error) {
+	var household models.Household
+	if err := repo.database.Where("invite_code = ?", inviteCode).First(&household).Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// GetMemberIDs retorna os IDs dos usuários que fazem parte do domicílio
+func (repo *HouseholdRepository) GetMemberIDs(householdID uint) ([]uint, error) {
+	var memberIDs []uint
+	if err := repo.database.Model(&models.User{}).Where("household_id = ?", householdID).
+		Pluck("id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+	return memberIDs, nil
+}
+
+// SetUserHousehold vincula (ou desvincula, com nil) um usuário a um domicílio
+func (repo *HouseholdRepository) SetUserHousehold(userID uint, householdID *uint) error {
+	return repo.database.Model(&models.User{}).Where("id = ?", userID).
+		Update("household_id", householdID).Error
+}
+
+// UpdateHousehold updates an existing household
+func (repo *HouseholdRepository) UpdateHousehold(household *models.Household) error {
+	return repo.database.Omit("Owner", "Members").Save(household).Error
+}
+
+// DeleteHousehold remove o domicílio e desvincula todos os membros
+func (repo *HouseholdRepository) DeleteHousehold(id uint) error {
+	return repo.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("household_id = ?", id).
+			Update("household_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Household{}, id).Error
+	})
+}
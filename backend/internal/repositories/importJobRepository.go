@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// importJobLockKey é a chave do advisory lock que serializa a obtenção da concessão de execução das
+// importações, entre a API e a linha de comando
+const importJobLockKey = 7311001
+
+// ImportJobRepository handles database operations for import jobs
+type ImportJobRepository struct {
+	database *gorm.DB
+}
+
+// NewImportJobRepository creates a new instance of ImportJobRepository
+func NewImportJobRepository(db *gorm.DB) *ImportJobRepository {
+	return &ImportJobRepository{database: db}
+}
+
+// CreateImportJob adds a new import job to the database
+func (repo *ImportJobRepository) CreateImportJob(job *models.ImportJob) error {
+	return repo.database.Create(job).Error
+}
+
+// GetImportJobByID retrieves an import job by its ID
+func (repo *ImportJobRepository) GetImportJobByID(id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := repo.database.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetImportJobs retrieves the most recent import jobs of a kind
+func (repo *ImportJobRepository) GetImportJobs(kind string, limit int) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	if err := repo.database.Where("kind = ?", kind).Order("id desc").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// UpdateImportJob saves the status and checkpoint of an import job
+func (repo *ImportJobRepository) UpdateImportJob(job *models.ImportJob) error {
+	return repo.database.Save(job).Error
+}
+
+// activeJobs filtra as importações do tipo em execução com a concessão ainda válida
+func activeJobs(query *gorm.DB, kind string, now time.Time) *gorm.DB {
+	return query.Model(&models.ImportJob{}).
+		Where("kind = ? AND status = ? AND lease_expires_at > ?", kind, models.ImportJobRunning, now)
+}
+
+// CountActiveImportJobs conta as importações do tipo em execução (com a concessão válida) em qualquer processo
+func (repo *ImportJobRepository) CountActiveImportJobs(kind string, now time.Time) (int64, error) {
+	var count int64
+	err := activeJobs(repo.database, kind, now).Count(&count).Error
+	return count, err
+}
+
+// ClaimImportJob obtém a concessão de execução da importação até leaseUntil, marcando-a como em execução.
+// Retorna false quando outra importação do mesmo tipo (ou esta mesma, em outro processo) está em execução.
+// O advisory lock da transação impede que dois processos obtenham a concessão ao mesmo tempo.
+func (repo *ImportJobRepository) ClaimImportJob(id uint, kind string, now time.Time, leaseUntil time.Time) (bool, error) {
+	claimed := false
+	err := repo.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", importJobLockKey).Error; err != nil {
+			return err
+		}
+		var active int64
+		if err := activeJobs(tx, kind, now).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return nil
+		}
+		result := tx.Model(&models.ImportJob{}).
+			Where("id = ? AND kind = ?", id, kind).
+			Updates(map[string]any{"status": models.ImportJobRunning, "lease_expires_at": leaseUntil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// RenewImportJobLease prorroga a concessão de uma importação em execução
+func (repo *ImportJobRepository) RenewImportJobLease(id uint, leaseUntil time.Time) error {
+	return repo.database.Model(&models.ImportJob{}).
+		Where("id = ? AND status = ?", id, models.ImportJobRunning).
+		Update("lease_expires_at", leaseUntil).Error
+}
+
+// MarkRunningJobsInterrupted marca como interrompidas as importações em execução cuja concessão venceu
+// (o processo que as executava terminou), para que possam ser retomadas. As de outro processo ainda
+// ativo, como o cmd/offimport, não são alteradas.
+func (repo *ImportJobRepository) MarkRunningJobsInterrupted() error {
+	return repo.database.Model(&models.ImportJob{}).
+		Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at <= ?)", models.ImportJobRunning, time.Now()).
+		Updates(map[string]any{"status": models.ImportJobInterrupted, "lease_expires_at": nil}).Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository handles database operations for outbox events
+type OutboxRepository struct {
+	database *gorm.DB
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{database: db}
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{database: tx}
+}
+
+// CreateEvent adds a new event to the outbox
+func (repo *OutboxRepository) CreateEvent(event *models.OutboxEvent) error {
+	return repo.database.Create(event).Error
+}
+
+// GetPendingEvents retorna os eventos ainda não distribuídos, dos mais antigos para os mais novos.
+// As linhas ficam bloqueadas até o fim da transação (SKIP LOCKED evita que dois dispatchers as peguem).
+func (repo *OutboxRepository) GetPendingEvents(limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := repo.database.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkEventDispatched registra que as entregas do evento foram criadas
+func (repo *OutboxRepository) MarkEventDispatched(eventID uint, dispatchedAt time.Time) error {
+	return repo.database.Model(&models.OutboxEvent{}).Where("id = ?", eventID).Update("dispatched_at", dispatchedAt).Error
+}
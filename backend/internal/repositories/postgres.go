@@ -18,7 +18,16 @@ func NewPostgresConn(config *config.Config) *gorm.DB {
 		panic("failed to connect database: " + error.Error())
 	}
 
-	database.AutoMigrate(&models.User{}, &models.Category{}, &models.Product{}, &models.Purchase{},
-		&models.PurchaseItem{}, &models.PriceHistory{}, &models.UserCategoryProduct{})
+	// O casamento de estabelecimentos busca os candidatos pela semelhança de trigramas
+	if err := database.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		panic("failed to enable pg_trgm: " + err.Error())
+	}
+
+	database.AutoMigrate(&models.User{}, &models.Category{}, &models.Product{},
+		&models.StoreChain{}, &models.Store{}, &models.StoreAlias{},
+		&models.ProductPLU{}, &models.ProductBarcode{}, &models.ProductRedirect{},
+		&models.Purchase{}, &models.PurchaseItem{}, &models.PriceHistory{}, &models.UserCategoryProduct{},
+		&models.Budget{}, &models.Household{}, &models.ImportJob{}, &models.OutboxEvent{}, &models.WebhookSubscription{}, &models.WebhookDelivery{})
+	database.Exec("CREATE INDEX IF NOT EXISTS idx_stores_normalized_name_trgm ON stores USING gin (normalized_name gin_trgm_ops)")
 	return database
 }
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Parron01/AppMercado/backend/internal/models"
@@ -17,6 +18,11 @@ func NewPriceHistoryRepository(db *gorm.DB) *PriceHistoryRepository {
 	return &PriceHistoryRepository{database: db}
 }
 
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *PriceHistoryRepository) WithTx(tx *gorm.DB) *PriceHistoryRepository {
+	return &PriceHistoryRepository{database: tx}
+}
+
 // CreatePriceHistory adds a new price history record to the database
 func (repo *PriceHistoryRepository) CreatePriceHistory(priceHistory *models.PriceHistory) error {
 	return repo.database.Create(priceHistory).Error
@@ -91,66 +97,129 @@ func (repo *PriceHistoryRepository) GetAllPriceHistory() ([]*models.PriceHistory
 	return priceHistories, nil
 }
 
-// GetPriceStatisticsByProductID retrieves price statistics for a product
-func (repo *PriceHistoryRepository) GetPriceStatisticsByProductID(productID uint) (
-	lowestPrice float64, highestPrice float64, firstDate time.Time, lastDate time.Time, count int64, err error) {
+// PriceHistoryFilter restringe os registros considerados em consultas e estatísticas de preço
+type PriceHistoryFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Store     string     // Trecho do local de compra (case-insensitive)
+	StoreID   *uint      // Apenas uma filial
+	ChainID   *uint      // Todas as filiais de uma rede
+	Area      *GeoRadius // Apenas estabelecimentos dentro da área
+	Unit      string     // Apenas itens comprados nessa unidade
+	UserIDs   []uint     // nil = registros de todos os usuários
+}
 
-	// Get lowest price
-	err = repo.database.Model(&models.PriceHistory{}).
-		Where("product_id = ?", productID).
-		Select("MIN(price_paid)").
-		Row().
-		Scan(&lowestPrice)
-	if err != nil {
-		return 0, 0, time.Time{}, time.Time{}, 0, err
-	}
+// PriceStatistics agrega as estatísticas de preço de um produto
+type PriceStatistics struct {
+	Count            int64
+	LowestPrice      float64
+	HighestPrice     float64
+	AvgPrice         float64
+	WeightedAvgPrice float64 // Média ponderada pela quantidade comprada
+	MedianPrice      float64
+	StdDevPrice      float64
+	P10Price         float64
+	P90Price         float64
+	LastPricePaid    float64
+	FirstDate        *time.Time
+	LastDate         *time.Time
+}
 
-	// Get highest price
-	err = repo.database.Model(&models.PriceHistory{}).
-		Where("product_id = ?", productID).
-		Select("MAX(price_paid)").
-		Row().
-		Scan(&highestPrice)
-	if err != nil {
-		return 0, 0, time.Time{}, time.Time{}, 0, err
-	}
+// priceStatisticsColumns são as colunas agregadas de PriceStatistics
+var priceStatisticsColumns = statisticsColumns("price_paid", "quantity")
 
-	// Get earliest date
-	err = repo.database.Model(&models.PriceHistory{}).
-		Where("product_id = ?", productID).
-		Order("purchase_date ASC").
-		Limit(1).
-		Select("purchase_date").
-		Row().
-		Scan(&firstDate)
-	if err != nil {
-		return 0, 0, time.Time{}, time.Time{}, 0, err
-	}
+// statisticsColumns monta as colunas agregadas das estatísticas sobre as expressões de preço e de
+// quantidade informadas
+func statisticsColumns(price string, quantity string) string {
+	return strings.NewReplacer("{price}", price, "{quantity}", quantity).Replace(`COUNT(*) AS count,
+	COALESCE(MIN({price}), 0) AS lowest_price,
+	COALESCE(MAX({price}), 0) AS highest_price,
+	COALESCE(AVG({price}), 0) AS avg_price,
+	COALESCE(SUM({price} * {quantity}) / NULLIF(SUM({quantity}), 0), 0) AS weighted_avg_price,
+	COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY {price}), 0) AS median_price,
+	COALESCE(stddev_samp({price}), 0) AS std_dev_price,
+	COALESCE(percentile_cont(0.1) WITHIN GROUP (ORDER BY {price}), 0) AS p10_price,
+	COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY {price}), 0) AS p90_price,
+	COALESCE((array_agg({price} ORDER BY purchase_date DESC, price_histories.id DESC))[1], 0) AS last_price_paid,
+	MIN(purchase_date) AS first_date,
+	MAX(purchase_date) AS last_date`)
+}
 
-	// Get latest date
-	err = repo.database.Model(&models.PriceHistory{}).
-		Where("product_id = ?", productID).
-		Order("purchase_date DESC").
-		Limit(1).
-		Select("purchase_date").
-		Row().
-		Scan(&lastDate)
-	if err != nil {
-		return 0, 0, time.Time{}, time.Time{}, 0, err
-	}
+// baseFactorSQL é o fator que leva uma quantidade na unidade da coluna para kg, L ou unidade
+func baseFactorSQL(column string) string {
+	return "CASE " + column + " WHEN 'g' THEN 0.001 WHEN 'ml' THEN 0.001 ELSE 1 END"
+}
 
-	// Get count of records
-	err = repo.database.Model(&models.PriceHistory{}).
-		Where("product_id = ?", productID).
-		Count(&count).Error
-	if err != nil {
-		return 0, 0, time.Time{}, time.Time{}, 0, err
+// baseUnitSQL é a unidade em que o preço é comparado para a unidade da coluna (g -> kg, ml -> L)
+func baseUnitSQL(column string) string {
+	return "CASE " + column + " WHEN 'g' THEN 'kg' WHEN 'kg' THEN 'kg' WHEN 'ml' THEN 'L' WHEN 'L' THEN 'L' WHEN 'un' THEN 'un' END"
+}
+
+// Preço, quantidade e unidade normalizados de um registro (exigem o JOIN com products): itens medidos
+// (g, kg, ml, L) são convertidos pela própria unidade e itens contados (un) pela embalagem do produto,
+// de modo que compras a granel e embaladas do mesmo produto possam ser comparadas. Sem como
+// converter (item contado de produto sem embalagem), o preço normalizado é nulo.
+var (
+	countedItemSQL = "price_histories.unit NOT IN ('g', 'kg', 'ml', 'L')"
+
+	normalizedPriceSQL = "CASE WHEN " + countedItemSQL +
+		" THEN price_histories.price_paid / NULLIF(products.package_quantity * (" + baseFactorSQL("products.unit") + "), 0)" +
+		" ELSE price_histories.price_paid / (" + baseFactorSQL("price_histories.unit") + ") END"
+
+	normalizedQuantitySQL = "CASE WHEN " + countedItemSQL +
+		" THEN price_histories.quantity * products.package_quantity * (" + baseFactorSQL("products.unit") + ")" +
+		" ELSE price_histories.quantity * (" + baseFactorSQL("price_histories.unit") + ") END"
+
+	normalizedUnitSQL = "CASE WHEN " + countedItemSQL +
+		" THEN " + baseUnitSQL("products.unit") + " ELSE " + baseUnitSQL("price_histories.unit") + " END"
+)
+
+// GetPriceStatisticsByProductID calcula todas as estatísticas de preço de um produto em uma única consulta agregada
+func (repo *PriceHistoryRepository) GetPriceStatisticsByProductID(
+	productID uint, filter PriceHistoryFilter) (*PriceStatistics, error) {
+
+	var statistics PriceStatistics
+	query := repo.database.Model(&models.PriceHistory{}).
+		Select(priceStatisticsColumns).
+		Where("product_id = ?", productID)
+
+	query = applyPriceHistoryFilter(query, filter)
+
+	if err := query.Scan(&statistics).Error; err != nil {
+		return nil, err
 	}
+	return &statistics, nil
+}
 
-	return lowestPrice, highestPrice, firstDate, lastDate, count, nil
+// BackfillQuantities copia para os registros de preço a quantidade do item de compra que os originou.
+// Registros gravados antes da coluna quantity ficaram com 1 e distorceriam a média ponderada. Não há chave
+// entre as tabelas, então o registro é pareado pelo usuário, produto, data e preço unitário da compra (na
+// ordem de criação quando o mesmo produto aparece mais de uma vez). Só altera registros com quantidade
+// diferente, então pode rodar a cada inicialização. Registros lançados sem compra mantêm a quantidade 1.
+func (repo *PriceHistoryRepository) BackfillQuantities() (int64, error) {
+	result := repo.database.Exec(`
+		UPDATE price_histories AS ph SET quantity = matched.quantity
+		FROM (
+			SELECT h.id, i.quantity
+			FROM (
+				SELECT id, user_id, product_id, purchase_date, price_paid,
+					ROW_NUMBER() OVER (PARTITION BY user_id, product_id, purchase_date, price_paid ORDER BY id) AS rn
+				FROM price_histories WHERE deleted_at IS NULL
+			) AS h
+			JOIN (
+				SELECT pi.quantity, p.user_id, pi.product_id, p.purchase_date, pi.unit_price,
+					ROW_NUMBER() OVER (PARTITION BY p.user_id, pi.product_id, p.purchase_date, pi.unit_price ORDER BY pi.id) AS rn
+				FROM purchase_items AS pi
+				JOIN purchases AS p ON p.id = pi.purchase_id AND p.deleted_at IS NULL
+				WHERE pi.deleted_at IS NULL
+			) AS i ON i.user_id = h.user_id AND i.product_id = h.product_id AND i.purchase_date = h.purchase_date
+				AND i.unit_price = h.price_paid AND i.rn = h.rn
+		) AS matched
+		WHERE ph.id = matched.id AND ph.quantity <> matched.quantity`)
+	return result.RowsAffected, result.Error
 }
 
-// CalculateAveragePriceForProduct calcula o preço médio de um produto baseado em todos os registros de histórico
+// CalculateAveragePriceForProduct calcula o preço médio de um produto ponderado pela quantidade comprada
 func (repo *PriceHistoryRepository) CalculateAveragePriceForProduct(productID uint) (float64, error) {
 	var result struct {
 		AvgPrice float64
@@ -159,7 +228,7 @@ func (repo *PriceHistoryRepository) CalculateAveragePriceForProduct(productID ui
 	// Utilizamos o banco de dados para calcular a média diretamente, preservando precisão máxima
 	// Nota: CAST para decimal(10,4) garante consistência nos tipos
 	err := repo.database.Model(&models.PriceHistory{}).
-		Select("COALESCE(AVG(CAST(price_paid AS DECIMAL(10,4))), 0.0) as avg_price").
+		Select(`COALESCE(SUM(CAST(price_paid AS DECIMAL(10,4)) * quantity) / NULLIF(SUM(quantity), 0), 0.0) as avg_price`).
 		Where("product_id = ?", productID).
 		Scan(&result).Error
 
@@ -169,3 +238,303 @@ func (repo *PriceHistoryRepository) CalculateAveragePriceForProduct(productID ui
 
 	return result.AvgPrice, nil
 }
+
+// GetPriceHistoryByProductFiltered retrieves price history records for a product matching the filter
+func (repo *PriceHistoryRepository) GetPriceHistoryByProductFiltered(
+	productID uint, filter PriceHistoryFilter) ([]*models.PriceHistory, error) {
+	var priceHistories []*models.PriceHistory
+	query := applyPriceHistoryFilter(repo.database.Where("product_id = ?", productID), filter)
+	if err := query.
+		Preload("Product").
+		Preload("User").
+		Order("purchase_date desc").
+		Find(&priceHistories).Error; err != nil {
+		return nil, err
+	}
+	return priceHistories, nil
+}
+
+// CountDistinctContributors conta quantos usuários distintos têm registros do produto que atendem ao filtro
+func (repo *PriceHistoryRepository) CountDistinctContributors(productID uint, filter PriceHistoryFilter) (int64, error) {
+	var count int64
+	query := repo.database.Model(&models.PriceHistory{}).
+		Select("COUNT(DISTINCT user_id)").
+		Where("product_id = ?", productID)
+	if err := applyPriceHistoryFilter(query, filter).Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetPriceStatisticsByProductIDs calcula as estatísticas de vários produtos em uma única consulta agrupada.
+// Produtos sem registros ficam fora do map.
+func (repo *PriceHistoryRepository) GetPriceStatisticsByProductIDs(
+	productIDs []uint, filter PriceHistoryFilter) (map[uint]*PriceStatistics, error) {
+
+	var rows []struct {
+		ProductID uint
+		PriceStatistics
+	}
+	statistics := make(map[uint]*PriceStatistics, len(productIDs))
+	if len(productIDs) == 0 {
+		return statistics, nil
+	}
+	query := repo.database.Model(&models.PriceHistory{}).
+		Select("product_id, "+priceStatisticsColumns).
+		Where("product_id IN ?", productIDs).
+		Group("product_id")
+	if err := applyPriceHistoryFilter(query, filter).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		statistics[rows[i].ProductID] = &rows[i].PriceStatistics
+	}
+	return statistics, nil
+}
+
+// UnitPriceStatistics são as estatísticas de preço de um produto normalizadas para uma unidade base
+type UnitPriceStatistics struct {
+	ProductID uint
+	Unit      string // kg, L ou un
+	PriceStatistics
+}
+
+// GetUnitPriceStatisticsByProductIDs calcula as estatísticas por kg, L ou unidade de vários produtos,
+// uma linha por produto e unidade base. Registros que não podem ser normalizados ficam de fora.
+func (repo *PriceHistoryRepository) GetUnitPriceStatisticsByProductIDs(
+	productIDs []uint, filter PriceHistoryFilter) (map[uint][]UnitPriceStatistics, error) {
+
+	var rows []UnitPriceStatistics
+	statistics := make(map[uint][]UnitPriceStatistics, len(productIDs))
+	if len(productIDs) == 0 {
+		return statistics, nil
+	}
+	query := repo.database.Model(&models.PriceHistory{}).
+		Select("price_histories.product_id, "+normalizedUnitSQL+" AS unit, "+
+			statisticsColumns(normalizedPriceSQL, normalizedQuantitySQL)).
+		Joins("JOIN products ON products.id = price_histories.product_id").
+		Where("price_histories.product_id IN ?", productIDs).
+		Where("(" + normalizedPriceSQL + ") IS NOT NULL").
+		Group("price_histories.product_id, " + normalizedUnitSQL)
+	if err := applyPriceHistoryFilter(query, filter).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		statistics[row.ProductID] = append(statistics[row.ProductID], row)
+	}
+	return statistics, nil
+}
+
+// GetPriceHistoryByProductIDsFiltered busca de uma vez os registros de vários produtos que atendem ao filtro,
+// sem carregar produto e usuário
+func (repo *PriceHistoryRepository) GetPriceHistoryByProductIDsFiltered(
+	productIDs []uint, filter PriceHistoryFilter) ([]*models.PriceHistory, error) {
+	var priceHistories []*models.PriceHistory
+	if len(productIDs) == 0 {
+		return priceHistories, nil
+	}
+	query := applyPriceHistoryFilter(repo.database.Where("product_id IN ?", productIDs), filter)
+	if err := query.Order("purchase_date desc, id desc").Find(&priceHistories).Error; err != nil {
+		return nil, err
+	}
+	return priceHistories, nil
+}
+
+// CountDistinctContributorsByProductIDs conta, por produto, quantos usuários distintos têm registros que atendem ao filtro
+func (repo *PriceHistoryRepository) CountDistinctContributorsByProductIDs(
+	productIDs []uint, filter PriceHistoryFilter) (map[uint]int64, error) {
+	var rows []struct {
+		ProductID    uint
+		Contributors int64
+	}
+	counts := make(map[uint]int64, len(productIDs))
+	if len(productIDs) == 0 {
+		return counts, nil
+	}
+	query := repo.database.Model(&models.PriceHistory{}).
+		Select("product_id, COUNT(DISTINCT user_id) AS contributors").
+		Where("product_id IN ?", productIDs).
+		Group("product_id")
+	if err := applyPriceHistoryFilter(query, filter).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ProductID] = row.Contributors
+	}
+	return counts, nil
+}
+
+// applyPriceHistoryFilter aplica os filtros opcionais de data, local e usuários
+func applyPriceHistoryFilter(query *gorm.DB, filter PriceHistoryFilter) *gorm.DB {
+	if filter.StartDate != nil {
+		query = query.Where("purchase_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("purchase_date <= ?", *filter.EndDate)
+	}
+	if filter.Store != "" {
+		query = query.Where("purchase_place ILIKE ?", "%"+filter.Store+"%")
+	}
+	if filter.StoreID != nil {
+		query = query.Where("store_id = ?", *filter.StoreID)
+	}
+	if filter.ChainID != nil {
+		query = query.Where("store_id IN (SELECT id FROM stores WHERE chain_id = ? AND deleted_at IS NULL)", *filter.ChainID)
+	}
+	if filter.Area != nil {
+		condition, args := filter.Area.condition()
+		query = query.Where("store_id IN (SELECT stores.id FROM stores WHERE stores.deleted_at IS NULL AND "+condition+")", args...)
+	}
+	if filter.Unit != "" {
+		query = query.Where("price_histories.unit = ?", filter.Unit)
+	}
+	if filter.UserIDs != nil {
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+	return query
+}
+
+// Preço, quantidade e unidade comparáveis de um registro: os normalizados quando há conversão e,
+// senão, os do próprio registro (item contado de produto sem embalagem fica por unidade)
+var (
+	comparableUnitSQL     = "CASE WHEN (" + normalizedPriceSQL + ") IS NULL THEN price_histories.unit ELSE " + normalizedUnitSQL + " END"
+	comparablePriceSQL    = "COALESCE(" + normalizedPriceSQL + ", price_histories.price_paid)"
+	comparableQuantitySQL = "COALESCE(" + normalizedQuantitySQL + ", price_histories.quantity)"
+)
+
+// GroupPriceStatistics são as estatísticas de preço de um produto em uma rede ou filial, em uma unidade base
+type GroupPriceStatistics struct {
+	GroupID      uint   // ID da rede ou do estabelecimento
+	Unit         string // kg, L ou un
+	Contributors int64  // Usuários distintos com registros no grupo
+	PriceStatistics
+}
+
+// GetPriceStatisticsByStoreGroup calcula as estatísticas de preço de um produto por rede (byChain)
+// ou por filial, em uma única consulta agrupada, com os preços convertidos para kg, L ou unidade:
+// uma linha por grupo e unidade base. Registros sem estabelecimento (ou de filiais sem rede, no
+// agrupamento por rede) ficam de fora.
+func (repo *PriceHistoryRepository) GetPriceStatisticsByStoreGroup(
+	productID uint, filter PriceHistoryFilter, byChain bool) ([]GroupPriceStatistics, error) {
+
+	var rows []GroupPriceStatistics
+	columns := comparableUnitSQL + " AS unit, COUNT(DISTINCT price_histories.user_id) AS contributors, " +
+		statisticsColumns(comparablePriceSQL, comparableQuantitySQL)
+	query := repo.database.Model(&models.PriceHistory{}).
+		Joins("JOIN products ON products.id = price_histories.product_id").
+		Where("price_histories.product_id = ?", productID)
+	if byChain {
+		query = query.
+			Select("stores.chain_id AS group_id, " + columns).
+			Joins("JOIN stores ON stores.id = price_histories.store_id AND stores.deleted_at IS NULL").
+			Where("stores.chain_id IS NOT NULL").
+			Group("stores.chain_id, " + comparableUnitSQL)
+	} else {
+		query = query.
+			Select("price_histories.store_id AS group_id, " + columns).
+			Where("price_histories.store_id IS NOT NULL").
+			Group("price_histories.store_id, " + comparableUnitSQL)
+	}
+	if err := applyPriceHistoryFilter(query, filter).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetRecentPricesForProduct retorna os preços pagos mais recentes de um produto que atendem ao filtro
+func (repo *PriceHistoryRepository) GetRecentPricesForProduct(
+	productID uint, filter PriceHistoryFilter, limit int) ([]float64, error) {
+	var prices []float64
+	query := applyPriceHistoryFilter(repo.database.Model(&models.PriceHistory{}).Where("product_id = ?", productID), filter)
+	if err := query.Order("purchase_date desc").Limit(limit).Pluck("price_paid", &prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// PriceOutlierRow representa um registro de histórico fora das cercas IQR do seu produto
+type PriceOutlierRow struct {
+	ID            uint
+	ProductID     uint
+	ProductName   string
+	UserID        uint
+	PurchaseDate  time.Time
+	PurchasePlace string
+	PricePaid     float64
+	MedianPrice   float64
+	LowerFence    float64
+	UpperFence    float64
+	SampleSize    int
+}
+
+// GetPriceOutliers lista registros de histórico fora das cercas IQR de cada produto.
+// A margem é o maior valor entre multiplier*IQR e minMarginRatio*mediana, evitando falsos positivos
+// quando quase todos os preços são iguais. productID = 0 considera todos os produtos.
+func (repo *PriceHistoryRepository) GetPriceOutliers(
+	productID uint, minSamples int, multiplier, minMarginRatio float64) ([]PriceOutlierRow, error) {
+
+	productFilter := ""
+	args := []interface{}{}
+	if productID != 0 {
+		productFilter = "AND product_id = ?"
+		args = append(args, productID)
+	}
+	args = append(args, minSamples, multiplier, minMarginRatio, multiplier, minMarginRatio)
+
+	var rows []PriceOutlierRow
+	err := repo.database.Raw(`
+		WITH quartiles AS (
+			SELECT product_id,
+				percentile_cont(0.25) WITHIN GROUP (ORDER BY price_paid) AS q1,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price_paid) AS median,
+				percentile_cont(0.75) WITHIN GROUP (ORDER BY price_paid) AS q3,
+				COUNT(*) AS sample_size
+			FROM price_histories
+			WHERE deleted_at IS NULL `+productFilter+`
+			GROUP BY product_id
+			HAVING COUNT(*) >= ?
+		), fences AS (
+			SELECT product_id, median, sample_size,
+				q1 - GREATEST(? * (q3 - q1), ? * median) AS lower_fence,
+				q3 + GREATEST(? * (q3 - q1), ? * median) AS upper_fence
+			FROM quartiles
+		)
+		SELECT ph.id, ph.product_id, p.name AS product_name, ph.user_id, ph.purchase_date,
+			ph.purchase_place, ph.price_paid, f.median AS median_price, f.lower_fence, f.upper_fence, f.sample_size
+		FROM price_histories ph
+		JOIN fences f ON f.product_id = ph.product_id
+		JOIN products p ON p.id = ph.product_id
+		WHERE ph.deleted_at IS NULL AND (ph.price_paid < f.lower_fence OR ph.price_paid > f.upper_fence)
+		ORDER BY ph.product_id, ph.purchase_date DESC`, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// PriceHistoryExportRow representa um registro do histórico de preços na exportação
+type PriceHistoryExportRow struct {
+	ID            uint
+	PurchaseDate  time.Time
+	PurchasePlace string
+	ProductID     uint
+	ProductName   string
+	Barcode       *string
+	PricePaid     float64
+	Quantity      float64
+	Unit          string
+}
+
+// StreamPriceHistoryByUserID percorre o histórico de preços do usuário no intervalo, ordenado por data,
+// entregando um registro por vez a fn
+func (repo *PriceHistoryRepository) StreamPriceHistoryByUserID(
+	userID uint, filter DateRangeFilter, fn func(row *PriceHistoryExportRow) error) error {
+	query := repo.database.Table("price_histories AS ph").
+		Select(`ph.id, ph.purchase_date, ph.purchase_place, ph.product_id,
+			pr.name AS product_name, pr.barcode, ph.price_paid, ph.quantity, ph.unit`).
+		Joins("JOIN products AS pr ON pr.id = ph.product_id").
+		Where("ph.deleted_at IS NULL AND ph.user_id = ?", userID)
+	query = filter.apply(query, "ph.purchase_date").Order("ph.purchase_date, ph.id")
+
+	return streamRows(query, fn)
+}
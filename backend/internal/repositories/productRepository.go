@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductRepository define a interface para operações de banco de dados de produtos
@@ -15,6 +19,11 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (r *ProductRepository) WithTx(tx *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: tx}
+}
+
 // CreateProduct adiciona um novo produto ao banco de dados
 func (r *ProductRepository) CreateProduct(product *models.Product) error {
 	return r.db.Create(product).Error
@@ -38,6 +47,64 @@ func (r *ProductRepository) GetProductByBarcode(barcode string) (*models.Product
 	return &product, nil
 }
 
+// GetProductByName busca, dentre os produtos visíveis ao usuário, um produto pelo nome, ignorando
+// maiúsculas/minúsculas e espaços nas pontas
+func (r *ProductRepository) GetProductByName(name string, userID uint) (*models.Product, error) {
+	var product models.Product
+	if err := visibleTo(r.db, userID).Where("LOWER(TRIM(name)) = LOWER(TRIM(?))", name).Order("id").First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// catalogTextColumns são as colunas de texto que a importação do catálogo apenas preenche quando vazias
+var catalogTextColumns = []string{"name", "brand", "package_label", "image_url"}
+
+// UpsertProductsByBarcode cria ou completa produtos em lote pelo código de barras. Produtos já cadastrados
+// só têm preenchidas as colunas vazias (dados curados não são sobrescritos); produtos removidos e
+// sugestões ainda não aprovadas não são alterados, nem na situação de moderação.
+// Retorna o número de linhas inseridas ou completadas.
+func (r *ProductRepository) UpsertProductsByBarcode(products []models.Product) (int64, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	updates := clause.Set{}
+	missing := make([]string, 0, len(catalogTextColumns)+1)
+	for _, column := range catalogTextColumns {
+		updates = append(updates, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr(fmt.Sprintf(`COALESCE(NULLIF("products".%[1]s, ''), excluded.%[1]s)`, column)),
+		})
+		missing = append(missing, fmt.Sprintf(`(COALESCE("products".%[1]s, '') = '' AND excluded.%[1]s <> '')`, column))
+	}
+	// Conteúdo e unidade da embalagem andam juntos: só são preenchidos quando o conteúdo está vazio
+	updates = append(updates,
+		clause.Assignment{
+			Column: clause.Column{Name: "package_quantity"},
+			Value:  gorm.Expr(`COALESCE("products".package_quantity, excluded.package_quantity)`),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: "unit"},
+			Value:  gorm.Expr(`CASE WHEN "products".package_quantity IS NULL THEN excluded.unit ELSE "products".unit END`),
+		},
+		clause.Assignment{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+	)
+	missing = append(missing, `("products".package_quantity IS NULL AND excluded.package_quantity IS NOT NULL)`)
+
+	// O índice único do código de barras inclui os removidos: o conflito com eles não atualiza nada
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "barcode"}},
+		DoUpdates: updates,
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr(`"products".deleted_at IS NULL`),
+			gorm.Expr(`"products".status = ?`, models.ProductApproved),
+			gorm.Expr("(" + strings.Join(missing, " OR ") + ")"),
+		}},
+	}).Create(&products)
+	return result.RowsAffected, result.Error
+}
+
 // GetAllProducts retorna todos os produtos
 func (r *ProductRepository) GetAllProducts() ([]models.Product, error) {
 	var products []models.Product
@@ -47,12 +114,357 @@ func (r *ProductRepository) GetAllProducts() ([]models.Product, error) {
 	return products, nil
 }
 
+// visibleTo limita a consulta aos produtos aprovados e às sugestões pendentes do próprio usuário
+func visibleTo(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where("(status <> ? OR proposed_by_id = ?)", models.ProductPending, userID)
+}
+
+// GetVisibleProducts retorna os produtos aprovados e as sugestões pendentes do usuário
+func (r *ProductRepository) GetVisibleProducts(userID uint) ([]models.Product, error) {
+	var products []models.Product
+	if err := visibleTo(r.db, userID).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetProposals lista as sugestões de produto, inclusive as já recusadas ou mescladas (removidas),
+// opcionalmente filtrando pela situação e por quem sugeriu (0 para todos), das mais antigas para as mais novas
+func (r *ProductRepository) GetProposals(status models.ProductStatus, proposedByID uint) ([]*models.Product, error) {
+	var products []*models.Product
+	query := r.db.Unscoped().Where("proposed_by_id IS NOT NULL")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if proposedByID != 0 {
+		query = query.Where("proposed_by_id = ?", proposedByID)
+	}
+	if err := query.Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// CountPendingProposals conta as sugestões do usuário ainda aguardando moderação
+func (r *ProductRepository) CountPendingProposals(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Product{}).
+		Where("status = ? AND proposed_by_id = ?", models.ProductPending, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetPendingProposalBarcodes retorna, dentre os códigos informados, os de sugestões aguardando moderação
+func (r *ProductRepository) GetPendingProposalBarcodes(codes []string) (map[string]bool, error) {
+	pending := make(map[string]bool)
+	if len(codes) == 0 {
+		return pending, nil
+	}
+	var found []string
+	if err := r.db.Model(&models.Product{}).
+		Where("barcode IN ? AND status = ?", codes, models.ProductPending).
+		Pluck("barcode", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, code := range found {
+		pending[code] = true
+	}
+	return pending, nil
+}
+
+// CountProductReferences conta os itens de compra, registros de preço e associações com categorias do produto
+func (r *ProductRepository) CountProductReferences(id uint) (int64, error) {
+	var total int64
+	for _, model := range []any{&models.PurchaseItem{}, &models.PriceHistory{}, &models.UserCategoryProduct{}} {
+		var count int64
+		if err := r.db.Model(model).Where("product_id = ?", id).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
 // UpdateProduct atualiza um produto existente no banco de dados
 func (r *ProductRepository) UpdateProduct(product *models.Product) error {
 	return r.db.Save(product).Error
 }
 
-// DeleteProduct remove um produto do banco de dados pelo ID
+// DeleteProduct remove um produto do banco de dados pelo ID, com seus códigos adicionais e PLUs
 func (r *ProductRepository) DeleteProduct(id uint) error {
+	if err := r.db.Unscoped().Where("product_id = ?", id).Delete(&models.ProductBarcode{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Unscoped().Where("product_id = ?", id).Delete(&models.ProductPLU{}).Error; err != nil {
+		return err
+	}
 	return r.db.Delete(&models.Product{}, id).Error
 }
+
+// GetProductsByIDs busca de uma vez os produtos com os IDs informados
+func (r *ProductRepository) GetProductsByIDs(ids []uint) ([]*models.Product, error) {
+	return r.getProductsByIDs(r.db, ids)
+}
+
+// GetVisibleProductsByIDs busca de uma vez, dentre os produtos visíveis ao usuário, os com os IDs informados
+func (r *ProductRepository) GetVisibleProductsByIDs(ids []uint, userID uint) ([]*models.Product, error) {
+	return r.getProductsByIDs(visibleTo(r.db, userID), ids)
+}
+
+func (r *ProductRepository) getProductsByIDs(query *gorm.DB, ids []uint) ([]*models.Product, error) {
+	var products []*models.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	if err := query.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// SearchProducts lista os produtos visíveis ao usuário em ordem alfabética, opcionalmente filtrando por
+// trecho do nome ou da marca
+func (r *ProductRepository) SearchProducts(search string, limit, offset int, userID uint) ([]*models.Product, error) {
+	var products []*models.Product
+	query := visibleTo(r.db.Model(&models.Product{}), userID)
+	if search != "" {
+		query = query.Where("name ILIKE ? OR brand ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	if err := query.Order("name, id").Limit(limit).Offset(offset).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetProductsWithBarcode lista os produtos que têm código de barras, usados na normalização para GTIN-14
+func (r *ProductRepository) GetProductsWithBarcode() ([]*models.Product, error) {
+	var products []*models.Product
+	if err := r.db.Where("barcode IS NOT NULL").Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpdateProductBarcode grava apenas o código de barras do produto
+func (r *ProductRepository) UpdateProductBarcode(id uint, barcode string) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", id).Update("barcode", barcode).Error
+}
+
+// CreatePLU cadastra o PLU de balança de um produto
+func (r *ProductRepository) CreatePLU(plu *models.ProductPLU) error {
+	return r.db.Create(plu).Error
+}
+
+// GetPLUByID busca um PLU pelo ID
+func (r *ProductRepository) GetPLUByID(id uint) (*models.ProductPLU, error) {
+	var plu models.ProductPLU
+	if err := r.db.Preload("Product").First(&plu, id).Error; err != nil {
+		return nil, err
+	}
+	return &plu, nil
+}
+
+// GetPLU busca o PLU exato de um estabelecimento (ou o geral, com storeID nil)
+func (r *ProductRepository) GetPLU(plu string, storeID *uint) (*models.ProductPLU, error) {
+	var productPLU models.ProductPLU
+	query := r.db.Preload("Product").Where("plu = ?", plu)
+	if storeID != nil {
+		query = query.Where("store_id = ?", *storeID)
+	} else {
+		query = query.Where("store_id IS NULL")
+	}
+	if err := query.First(&productPLU).Error; err != nil {
+		return nil, err
+	}
+	return &productPLU, nil
+}
+
+// FindPLU procura o PLU do estabelecimento e, sem ele, o PLU geral
+func (r *ProductRepository) FindPLU(plu string, storeID *uint) (*models.ProductPLU, error) {
+	var productPLU models.ProductPLU
+	query := r.db.Preload("Product").Where("plu = ?", plu)
+	if storeID != nil {
+		query = query.Where("store_id = ? OR store_id IS NULL", *storeID).Order("store_id IS NULL")
+	} else {
+		query = query.Where("store_id IS NULL")
+	}
+	if err := query.First(&productPLU).Error; err != nil {
+		return nil, err
+	}
+	return &productPLU, nil
+}
+
+// GetPLUs lista os PLUs, opcionalmente de um produto ou de um estabelecimento
+func (r *ProductRepository) GetPLUs(productID uint, storeID uint) ([]*models.ProductPLU, error) {
+	var plus []*models.ProductPLU
+	query := r.db.Preload("Product")
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+	if storeID != 0 {
+		query = query.Where("store_id = ?", storeID)
+	}
+	if err := query.Order("store_id NULLS FIRST, plu").Find(&plus).Error; err != nil {
+		return nil, err
+	}
+	return plus, nil
+}
+
+// DeletePLU remove definitivamente um PLU, liberando o código para outro produto
+func (r *ProductRepository) DeletePLU(id uint) error {
+	return r.db.Unscoped().Delete(&models.ProductPLU{}, id).Error
+}
+
+// CreateProductBarcode cadastra um código de barras adicional de um produto
+func (r *ProductRepository) CreateProductBarcode(productBarcode *models.ProductBarcode) error {
+	return r.db.Create(productBarcode).Error
+}
+
+// GetProductBarcodeByCode busca um código adicional (GTIN-14), com o produto
+func (r *ProductRepository) GetProductBarcodeByCode(code string) (*models.ProductBarcode, error) {
+	var productBarcode models.ProductBarcode
+	if err := r.db.Preload("Product").Where("code = ?", code).First(&productBarcode).Error; err != nil {
+		return nil, err
+	}
+	return &productBarcode, nil
+}
+
+// GetProductBarcodes lista os códigos adicionais de um produto
+func (r *ProductRepository) GetProductBarcodes(productID uint) ([]*models.ProductBarcode, error) {
+	var productBarcodes []*models.ProductBarcode
+	if err := r.db.Where("product_id = ?", productID).Order("units_per_pack, code").Find(&productBarcodes).Error; err != nil {
+		return nil, err
+	}
+	return productBarcodes, nil
+}
+
+// GetAllProductBarcodes lista todos os códigos adicionais, usados na busca por produtos duplicados
+func (r *ProductRepository) GetAllProductBarcodes() ([]*models.ProductBarcode, error) {
+	var productBarcodes []*models.ProductBarcode
+	if err := r.db.Order("id").Find(&productBarcodes).Error; err != nil {
+		return nil, err
+	}
+	return productBarcodes, nil
+}
+
+// GetRegisteredBarcodes retorna, dentre os códigos informados, os já cadastrados como códigos adicionais
+func (r *ProductRepository) GetRegisteredBarcodes(codes []string) (map[string]bool, error) {
+	registered := make(map[string]bool)
+	if len(codes) == 0 {
+		return registered, nil
+	}
+	var found []string
+	if err := r.db.Model(&models.ProductBarcode{}).Where("code IN ?", codes).Pluck("code", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, code := range found {
+		registered[code] = true
+	}
+	return registered, nil
+}
+
+// DeleteProductBarcode remove definitivamente um código adicional do produto, liberando o código
+func (r *ProductRepository) DeleteProductBarcode(productID uint, id uint) (int64, error) {
+	result := r.db.Unscoped().Where("product_id = ?", productID).Delete(&models.ProductBarcode{}, id)
+	return result.RowsAffected, result.Error
+}
+
+// GetProductRedirect busca o produto que absorveu um produto mesclado
+func (r *ProductRepository) GetProductRedirect(fromProductID uint) (*models.ProductRedirect, error) {
+	var redirect models.ProductRedirect
+	if err := r.db.Where("from_product_id = ?", fromProductID).First(&redirect).Error; err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// GetProductRedirects busca de uma vez os redirecionamentos dos produtos mesclados informados
+func (r *ProductRepository) GetProductRedirects(fromProductIDs []uint) ([]models.ProductRedirect, error) {
+	var redirects []models.ProductRedirect
+	if err := r.db.Where("from_product_id IN ?", fromProductIDs).Find(&redirects).Error; err != nil {
+		return nil, err
+	}
+	return redirects, nil
+}
+
+// RepointProductsResult conta as linhas movidas para o produto de destino numa mesclagem
+type RepointProductsResult struct {
+	PurchaseItems        int64
+	PriceHistories       int64
+	UserCategoryProducts int64
+}
+
+// RepointProducts move para o produto de destino os itens de compra, os registros de preço, as
+// associações com categorias, os códigos adicionais, os PLUs e os redirecionamentos dos produtos de
+// origem, e cria o redirecionamento de cada origem. Os itens em "un" de uma origem com unitsPerPack
+// são convertidos para unidades do destino (quantidade multiplicada, preço dividido).
+func (r *ProductRepository) RepointProducts(
+	sourceIDs []uint, targetID uint, unitsPerPack map[uint]int) (*RepointProductsResult, error) {
+	for sourceID, units := range unitsPerPack {
+		if units <= 1 {
+			continue
+		}
+		err := r.db.Model(&models.PurchaseItem{}).
+			Where("product_id = ? AND unit = ?", sourceID, "un").
+			Updates(map[string]any{
+				"quantity":   gorm.Expr("quantity * ?", units),
+				"unit_price": gorm.Expr("unit_price / ?", units),
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+		err = r.db.Model(&models.PriceHistory{}).
+			Where("product_id = ? AND unit = ?", sourceID, "un").
+			Updates(map[string]any{
+				"quantity":   gorm.Expr("quantity * ?", units),
+				"price_paid": gorm.Expr("price_paid / ?", units),
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &RepointProductsResult{}
+	update := r.db.Model(&models.PurchaseItem{}).Where("product_id IN ?", sourceIDs).Update("product_id", targetID)
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	result.PurchaseItems = update.RowsAffected
+
+	update = r.db.Model(&models.PriceHistory{}).Where("product_id IN ?", sourceIDs).Update("product_id", targetID)
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	result.PriceHistories = update.RowsAffected
+
+	// Associações que o usuário já tem com o destino na mesma categoria (ou repetidas entre as origens)
+	// são removidas antes da mudança
+	err := r.db.Exec(`
+		UPDATE user_category_products u SET deleted_at = NOW()
+		WHERE u.product_id IN ? AND u.deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM user_category_products other
+			WHERE other.deleted_at IS NULL AND other.user_id = u.user_id AND other.category_id = u.category_id
+			AND (other.product_id = ? OR (other.product_id IN ? AND other.id < u.id)))`,
+		sourceIDs, targetID, sourceIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	update = r.db.Model(&models.UserCategoryProduct{}).Where("product_id IN ?", sourceIDs).Update("product_id", targetID)
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	result.UserCategoryProducts = update.RowsAffected
+
+	for _, model := range []any{&models.ProductBarcode{}, &models.ProductPLU{}, &models.ProductRedirect{}} {
+		if err := r.db.Model(model).Where("product_id IN ?", sourceIDs).Update("product_id", targetID).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, sourceID := range sourceIDs {
+		redirect := &models.ProductRedirect{FromProductID: sourceID, ProductID: targetID}
+		if err := r.db.Create(redirect).Error; err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -17,6 +17,11 @@ func NewPurchaseRepository(db *gorm.DB) *PurchaseRepository {
 	return &PurchaseRepository{database: db}
 }
 
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *PurchaseRepository) WithTx(tx *gorm.DB) *PurchaseRepository {
+	return &PurchaseRepository{database: tx}
+}
+
 // GetPurchaseByDateAndLocation busca uma compra pelo local e data
 func (repo *PurchaseRepository) GetPurchaseByDateAndLocation(date time.Time, location string, userID uint) (*models.Purchase, error) {
 	var purchase models.Purchase
@@ -32,6 +37,15 @@ func (repo *PurchaseRepository) GetPurchaseByDateAndLocation(date time.Time, loc
 	return &purchase, err
 }
 
+// GetPurchaseByInvoiceKey busca a compra do usuário registrada com a chave de acesso da nota fiscal
+func (repo *PurchaseRepository) GetPurchaseByInvoiceKey(invoiceKey string, userID uint) (*models.Purchase, error) {
+	var purchase models.Purchase
+	if err := repo.database.Where("invoice_key = ? AND user_id = ?", invoiceKey, userID).First(&purchase).Error; err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
 // CreatePurchase adds a new purchase to the database
 func (repo *PurchaseRepository) CreatePurchase(purchase *models.Purchase) error {
 	if err := repo.database.Create(purchase).Error; err != nil {
@@ -71,6 +85,10 @@ func (repo *PurchaseRepository) DeletePurchase(id uint) error {
 		if err := tx.Where("purchase_id = ?", id).Delete(&models.PurchaseItem{}).Error; err != nil {
 			return err
 		}
+		// Libera a chave da nota fiscal para que ela possa ser importada novamente
+		if err := tx.Model(&models.Purchase{}).Where("id = ?", id).Update("invoice_key", nil).Error; err != nil {
+			return err
+		}
 		// Then delete the purchase
 		if err := tx.Delete(&models.Purchase{}, id).Error; err != nil {
 			return err
@@ -87,3 +105,117 @@ func (repo *PurchaseRepository) GetAllPurchases() ([]*models.Purchase, error) {
 	}
 	return purchases, nil
 }
+
+// GetPurchasesByUserIDs busca as compras de vários usuários, da mais recente para a mais antiga,
+// sem carregar os itens. limit <= 0 não limita a quantidade.
+func (repo *PurchaseRepository) GetPurchasesByUserIDs(userIDs []uint, limit, offset int) ([]*models.Purchase, error) {
+	var purchases []*models.Purchase
+	if len(userIDs) == 0 {
+		return purchases, nil
+	}
+	query := repo.database.Where("user_id IN ?", userIDs).Order("purchase_date desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	if err := query.Find(&purchases).Error; err != nil {
+		return nil, err
+	}
+	return purchases, nil
+}
+
+// GetPurchasesByIDs busca de uma vez as compras com os IDs informados, sem carregar os itens
+func (repo *PurchaseRepository) GetPurchasesByIDs(ids []uint) ([]*models.Purchase, error) {
+	var purchases []*models.Purchase
+	if len(ids) == 0 {
+		return purchases, nil
+	}
+	if err := repo.database.Where("id IN ?", ids).Find(&purchases).Error; err != nil {
+		return nil, err
+	}
+	return purchases, nil
+}
+
+// GetPurchaseItemsByPurchaseIDs busca de uma vez os itens de várias compras, sem carregar os produtos
+func (repo *PurchaseRepository) GetPurchaseItemsByPurchaseIDs(purchaseIDs []uint) ([]*models.PurchaseItem, error) {
+	var items []*models.PurchaseItem
+	if len(purchaseIDs) == 0 {
+		return items, nil
+	}
+	if err := repo.database.Where("purchase_id IN ?", purchaseIDs).Order("purchase_id, id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PurchaseItemHistoryRow representa um item comprado pelo usuário, com a data da compra e a quantidade
+// convertida para a unidade base (kg, L ou un)
+type PurchaseItemHistoryRow struct {
+	ProductID    uint
+	ProductName  string
+	PurchaseDate time.Time
+	Unit         string
+	Quantity     float64
+}
+
+// Unidade base e quantidade convertida de um item comprado: itens medidos (g, kg, ml, L) pela própria
+// unidade e itens contados pela embalagem do produto. Itens contados de produto sem embalagem
+// cadastrada continuam em unidades.
+var (
+	packagedItemSQL = "pi.unit NOT IN ('g', 'kg', 'ml', 'L') AND pr.package_quantity IS NOT NULL AND (" +
+		baseUnitSQL("pr.unit") + ") IS NOT NULL"
+
+	purchaseItemUnitSQL = "CASE WHEN " + packagedItemSQL + " THEN " + baseUnitSQL("pr.unit") +
+		" ELSE COALESCE(" + baseUnitSQL("pi.unit") + ", 'un') END"
+
+	purchaseItemQuantitySQL = "CASE WHEN " + packagedItemSQL +
+		" THEN pi.quantity * pr.package_quantity * (" + baseFactorSQL("pr.unit") + ")" +
+		" ELSE pi.quantity * (" + baseFactorSQL("pi.unit") + ") END"
+)
+
+// GetPurchaseItemHistoryByUserID retorna os itens comprados pelo usuário desde a data informada, com
+// as quantidades na unidade base, ordenados por produto, unidade e data da compra
+func (repo *PurchaseRepository) GetPurchaseItemHistoryByUserID(userID uint, since time.Time) ([]PurchaseItemHistoryRow, error) {
+	var rows []PurchaseItemHistoryRow
+	err := repo.database.Table("purchase_items AS pi").
+		Select("pi.product_id, pr.name AS product_name, p.purchase_date, "+
+			purchaseItemUnitSQL+" AS unit, "+purchaseItemQuantitySQL+" AS quantity").
+		Joins("JOIN purchases AS p ON p.id = pi.purchase_id AND p.deleted_at IS NULL").
+		Joins("JOIN products AS pr ON pr.id = pi.product_id").
+		Where("pi.deleted_at IS NULL AND p.user_id = ? AND p.purchase_date >= ?", userID, since).
+		Order("pi.product_id, unit, p.purchase_date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// PurchaseExportRow representa um item de compra na exportação (uma linha por item)
+type PurchaseExportRow struct {
+	PurchaseID       uint
+	PurchaseDate     time.Time
+	PurchaseLocation string
+	ProductID        uint
+	ProductName      string
+	Barcode          *string
+	Quantity         float64
+	Unit             string
+	UnitPrice        float64
+	TotalPrice       float64
+}
+
+// StreamPurchaseItemsByUserID percorre os itens das compras do usuário no intervalo, ordenados por data,
+// entregando um item por vez a fn
+func (repo *PurchaseRepository) StreamPurchaseItemsByUserID(
+	userID uint, filter DateRangeFilter, fn func(row *PurchaseExportRow) error) error {
+	query := repo.database.Table("purchase_items AS pi").
+		Select(`p.id AS purchase_id, p.purchase_date, p.purchase_location,
+			pi.product_id, pr.name AS product_name, pr.barcode,
+			pi.quantity, pi.unit, pi.unit_price, pi.total_price`).
+		Joins("JOIN purchases AS p ON p.id = pi.purchase_id AND p.deleted_at IS NULL").
+		Joins("JOIN products AS pr ON pr.id = pi.product_id").
+		Where("pi.deleted_at IS NULL AND p.user_id = ?", userID)
+	query = filter.apply(query, "p.purchase_date").Order("p.purchase_date, p.id, pi.id")
+
+	return streamRows(query, fn)
+}
@@ -0,0 +1,427 @@
+package repositories
+
+import (
+	"math"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// StoreRepository handles database operations for stores
+type StoreRepository struct {
+	database *gorm.DB
+}
+
+// NewStoreRepository creates a new instance of StoreRepository
+func NewStoreRepository(db *gorm.DB) *StoreRepository {
+	return &StoreRepository{database: db}
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *StoreRepository) WithTx(tx *gorm.DB) *StoreRepository {
+	return &StoreRepository{database: tx}
+}
+
+// CreateStore adds a new store to the database
+func (repo *StoreRepository) CreateStore(store *models.Store) error {
+	return repo.database.Omit("Chain").Create(store).Error
+}
+
+// storesVisibleTo restringe a consulta aos estabelecimentos compartilhados e aos do próprio usuário
+// (userID 0 vê apenas os compartilhados)
+func storesVisibleTo(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where("(stores.owner_id IS NULL OR stores.owner_id = ?)", userID)
+}
+
+// GetStoreByID retrieves a store by its ID
+func (repo *StoreRepository) GetStoreByID(id uint) (*models.Store, error) {
+	var store models.Store
+	if err := repo.database.Preload("Chain").First(&store, id).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// GetVisibleStoreByID busca um estabelecimento compartilhado ou do próprio usuário
+func (repo *StoreRepository) GetVisibleStoreByID(id uint, userID uint) (*models.Store, error) {
+	var store models.Store
+	if err := storesVisibleTo(repo.database.Preload("Chain"), userID).First(&store, id).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// FindStoreByCNPJ busca o estabelecimento com o CNPJ (apenas dígitos); nil quando não há
+func (repo *StoreRepository) FindStoreByCNPJ(cnpj string) (*models.Store, error) {
+	return findFirst[models.Store](repo.database.Preload("Chain").Where("cnpj = ?", cnpj))
+}
+
+// FindStoreByAlias busca, dentre os estabelecimentos visíveis ao usuário, o que tem o nome alternativo
+func (repo *StoreRepository) FindStoreByAlias(normalizedName string, userID uint) (*models.Store, error) {
+	query := repo.database.Preload("Chain").
+		Where("id IN (SELECT store_id FROM store_aliases WHERE normalized_name = ? AND deleted_at IS NULL)", normalizedName)
+	return findFirst[models.Store](storesVisibleTo(query, userID))
+}
+
+// GetStoreCandidates busca os estabelecimentos visíveis ao usuário com nome igual ou parecido (pela
+// semelhança de trigramas do pg_trgm, que usa o índice) com o nome normalizado; a semelhança exata é
+// calculada por quem chama
+func (repo *StoreRepository) GetStoreCandidates(normalizedName string, userID uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	query := repo.database.Preload("Chain").
+		Where("(stores.normalized_name = ? OR stores.normalized_name % ?)", normalizedName, normalizedName).
+		Order("stores.normalized_name, stores.id")
+	if err := storesVisibleTo(query, userID).Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// GetStoresByIDs busca de uma vez os estabelecimentos informados, com a rede
+func (repo *StoreRepository) GetStoresByIDs(ids []uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	if len(ids) == 0 {
+		return stores, nil
+	}
+	if err := repo.database.Preload("Chain").Where("id IN ?", ids).Order("id").Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// GeoRadius é uma área circular em torno de um ponto, usada nas buscas por proximidade
+type GeoRadius struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// haversineSQL calcula em SQL a distância em km entre o estabelecimento e o ponto (lat, lat, lng);
+// usa apenas funções matemáticas do Postgres, sem depender do PostGIS
+const haversineSQL = `2 * 6371 * ASIN(SQRT(
+	POWER(SIN(RADIANS(stores.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(stores.latitude)) * POWER(SIN(RADIANS(stores.longitude - ?) / 2), 2)))`
+
+// kmPerDegree é a distância aproximada de um grau de latitude
+const kmPerDegree = 111.045
+
+// condition monta a condição SQL sobre a tabela stores: um retângulo que envolve o círculo, que
+// descarta a maioria das linhas sem trigonometria, e a distância exata pela fórmula de haversine
+func (area GeoRadius) condition() (string, []any) {
+	latDelta := area.RadiusKm / kmPerDegree
+	sql := "stores.latitude IS NOT NULL AND stores.longitude IS NOT NULL AND stores.latitude BETWEEN ? AND ?"
+	args := []any{area.Latitude - latDelta, area.Latitude + latDelta}
+	// Perto dos polos o retângulo de longitude perde o sentido; fica só a distância exata
+	if cosLat := math.Cos(area.Latitude * math.Pi / 180); cosLat > 0.01 {
+		lngDelta := area.RadiusKm / (kmPerDegree * cosLat)
+		sql += " AND stores.longitude BETWEEN ? AND ?"
+		args = append(args, area.Longitude-lngDelta, area.Longitude+lngDelta)
+	}
+	sql += " AND " + haversineSQL + " <= ?"
+	args = append(args, area.Latitude, area.Latitude, area.Longitude, area.RadiusKm)
+	return sql, args
+}
+
+// StoreDistance é um estabelecimento dentro de uma área e sua distância até o centro
+type StoreDistance struct {
+	StoreID    uint
+	DistanceKm float64
+}
+
+// GetStoresNearby retorna os estabelecimentos visíveis ao usuário com coordenadas dentro da área, do
+// mais próximo para o mais distante (limit <= 0 = todos)
+func (repo *StoreRepository) GetStoresNearby(area GeoRadius, limit int, userID uint) ([]StoreDistance, error) {
+	var distances []StoreDistance
+	condition, args := area.condition()
+	query := storesVisibleTo(repo.database.Model(&models.Store{}), userID).
+		Select("stores.id AS store_id, "+haversineSQL+" AS distance_km", area.Latitude, area.Latitude, area.Longitude).
+		Where(condition, args...).
+		Order("distance_km, stores.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(&distances).Error; err != nil {
+		return nil, err
+	}
+	return distances, nil
+}
+
+// GetStoresByChainID retorna as filiais de uma rede, inclusive as próprias de cada usuário
+func (repo *StoreRepository) GetStoresByChainID(chainID uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	if err := repo.database.Where("chain_id = ?", chainID).Order("normalized_name, id").Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// GetVisibleStoresByChainID retorna as filiais de uma rede visíveis ao usuário
+func (repo *StoreRepository) GetVisibleStoresByChainID(chainID uint, userID uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	query := storesVisibleTo(repo.database.Where("chain_id = ?", chainID), userID)
+	if err := query.Order("normalized_name, id").Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// GetUnchainedStoresByPrefix retorna os estabelecimentos sem rede cujo nome normalizado é o informado
+// ou começa por ele seguido de espaço
+func (repo *StoreRepository) GetUnchainedStoresByPrefix(normalizedName string) ([]*models.Store, error) {
+	var stores []*models.Store
+	err := repo.database.
+		Where("chain_id IS NULL AND (normalized_name = ? OR LEFT(normalized_name, ?) = ?)",
+			normalizedName, len([]rune(normalizedName))+1, normalizedName+" ").
+		Order("id").Find(&stores).Error
+	if err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// SearchStores busca, dentre os estabelecimentos visíveis ao usuário, os cujo nome normalizado contém
+// o trecho informado
+func (repo *StoreRepository) SearchStores(normalizedSearch string, userID uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	query := storesVisibleTo(repo.database.Preload("Chain"), userID).Order("normalized_name, id")
+	if normalizedSearch != "" {
+		query = query.Where("normalized_name LIKE ?", "%"+normalizedSearch+"%")
+	}
+	if err := query.Find(&stores).Error; err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// UpdateStore saves the changes of an existing store
+func (repo *StoreRepository) UpdateStore(store *models.Store) error {
+	return repo.database.Omit("Chain").Save(store).Error
+}
+
+// DeleteStore removes a store, its aliases and its scale PLUs from the database
+func (repo *StoreRepository) DeleteStore(id uint) error {
+	if err := repo.database.Unscoped().Where("store_id = ?", id).Delete(&models.StoreAlias{}).Error; err != nil {
+		return err
+	}
+	if err := repo.database.Unscoped().Where("store_id = ?", id).Delete(&models.ProductPLU{}).Error; err != nil {
+		return err
+	}
+	return repo.database.Delete(&models.Store{}, id).Error
+}
+
+// CreateChain adds a new store chain to the database
+func (repo *StoreRepository) CreateChain(chain *models.StoreChain) error {
+	return repo.database.Create(chain).Error
+}
+
+// GetChainByID retrieves a store chain by its ID
+func (repo *StoreRepository) GetChainByID(id uint) (*models.StoreChain, error) {
+	var chain models.StoreChain
+	if err := repo.database.First(&chain, id).Error; err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// FindChainByName busca a rede pelo nome normalizado; nil quando não há
+func (repo *StoreRepository) FindChainByName(normalizedName string) (*models.StoreChain, error) {
+	return findFirst[models.StoreChain](repo.database.Where("normalized_name = ?", normalizedName))
+}
+
+// FindChainPrefixOf busca a rede cujo nome normalizado é o do estabelecimento ou o inicia seguido de
+// espaço, preferindo o nome de rede mais longo; nil quando nenhuma rede casa
+func (repo *StoreRepository) FindChainPrefixOf(normalizedName string) (*models.StoreChain, error) {
+	query := repo.database.
+		Where("normalized_name = ? OR LEFT(?, LENGTH(normalized_name) + 1) = normalized_name || ' '", normalizedName, normalizedName).
+		Order("LENGTH(normalized_name) DESC, id")
+	return findFirst[models.StoreChain](query)
+}
+
+// GetAllChains retorna todas as redes, em ordem alfabética
+func (repo *StoreRepository) GetAllChains() ([]*models.StoreChain, error) {
+	var chains []*models.StoreChain
+	if err := repo.database.Order("normalized_name, id").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GetChainsByIDs busca de uma vez as redes informadas
+func (repo *StoreRepository) GetChainsByIDs(ids []uint) ([]*models.StoreChain, error) {
+	var chains []*models.StoreChain
+	if len(ids) == 0 {
+		return chains, nil
+	}
+	if err := repo.database.Where("id IN ?", ids).Order("id").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// CountStoresByChain conta as filiais de cada rede visíveis ao usuário
+func (repo *StoreRepository) CountStoresByChain(userID uint) (map[uint]int64, error) {
+	var rows []struct {
+		ChainID uint
+		Count   int64
+	}
+	if err := storesVisibleTo(repo.database.Model(&models.Store{}), userID).
+		Select("chain_id, COUNT(*) AS count").
+		Where("chain_id IS NOT NULL").
+		Group("chain_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ChainID] = row.Count
+	}
+	return counts, nil
+}
+
+// UpdateChain saves the changes of an existing store chain
+func (repo *StoreRepository) UpdateChain(chain *models.StoreChain) error {
+	return repo.database.Save(chain).Error
+}
+
+// DeleteChain removes a store chain from the database
+func (repo *StoreRepository) DeleteChain(id uint) error {
+	return repo.database.Delete(&models.StoreChain{}, id).Error
+}
+
+// AliasExists indica se o nome alternativo já está cadastrado
+func (repo *StoreRepository) AliasExists(normalizedName string) (bool, error) {
+	var count int64
+	err := repo.database.Model(&models.StoreAlias{}).Where("normalized_name = ?", normalizedName).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAlias adds a new store alias to the database
+func (repo *StoreRepository) CreateAlias(alias *models.StoreAlias) error {
+	return repo.database.Create(alias).Error
+}
+
+// RepointStores move para o estabelecimento de destino as compras, os registros de preço, os nomes
+// alternativos e os PLUs de balança dos estabelecimentos de origem; os locais digitados passam a ser o nome do destino.
+// Retorna quantas compras e registros de preço foram alterados.
+func (repo *StoreRepository) RepointStores(sourceIDs []uint, target *models.Store) (purchases int64, priceHistories int64, err error) {
+	result := repo.database.Model(&models.Purchase{}).
+		Where("store_id IN ?", sourceIDs).
+		Updates(map[string]any{"store_id": target.ID, "purchase_location": target.Name})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	purchases = result.RowsAffected
+
+	result = repo.database.Model(&models.PriceHistory{}).
+		Where("store_id IN ?", sourceIDs).
+		Updates(map[string]any{"store_id": target.ID, "purchase_place": target.Name})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	priceHistories = result.RowsAffected
+
+	err = repo.database.Model(&models.StoreAlias{}).
+		Where("store_id IN ?", sourceIDs).
+		Update("store_id", target.ID).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// PLUs de balança que o destino já tem (ou repetidos entre as origens) são descartados antes da mudança
+	err = repo.database.Exec(`
+		DELETE FROM product_plus p WHERE p.store_id IN ? AND EXISTS (
+			SELECT 1 FROM product_plus other
+			WHERE other.plu = p.plu AND (other.store_id = ? OR (other.store_id IN ? AND other.id < p.id)))`,
+		sourceIDs, target.ID, sourceIDs).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	err = repo.database.Model(&models.ProductPLU{}).
+		Where("store_id IN ?", sourceIDs).
+		Update("store_id", target.ID).Error
+	return purchases, priceHistories, err
+}
+
+// GetLegacyChainNames retorna a rede gravada como texto (coluna chain, anterior à tabela de redes)
+// dos estabelecimentos ainda sem chain_id; vazio quando a coluna não existe mais
+func (repo *StoreRepository) GetLegacyChainNames() (map[uint]string, error) {
+	names := make(map[uint]string)
+	if !repo.database.Migrator().HasColumn(&models.Store{}, "chain") {
+		return names, nil
+	}
+	var rows []struct {
+		ID    uint
+		Chain string
+	}
+	if err := repo.database.Raw(
+		"SELECT id, chain FROM stores WHERE chain_id IS NULL AND deleted_at IS NULL AND TRIM(chain) <> ''").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		names[row.ID] = row.Chain
+	}
+	return names, nil
+}
+
+// DropLegacyChainColumn remove a coluna chain depois que as redes foram migradas
+func (repo *StoreRepository) DropLegacyChainColumn() error {
+	if !repo.database.Migrator().HasColumn(&models.Store{}, "chain") {
+		return nil
+	}
+	return repo.database.Migrator().DropColumn(&models.Store{}, "chain")
+}
+
+// CountStoreReferences conta as compras e registros de preço que apontam para o estabelecimento
+func (repo *StoreRepository) CountStoreReferences(id uint) (int64, error) {
+	var purchases, priceHistories int64
+	if err := repo.database.Model(&models.Purchase{}).Where("store_id = ?", id).Count(&purchases).Error; err != nil {
+		return 0, err
+	}
+	if err := repo.database.Model(&models.PriceHistory{}).Where("store_id = ?", id).Count(&priceHistories).Error; err != nil {
+		return 0, err
+	}
+	return purchases + priceHistories, nil
+}
+
+// UnlinkedLocation é um nome de local digitado por um usuário em compras ou preços ainda sem estabelecimento
+type UnlinkedLocation struct {
+	UserID uint
+	Name   string
+	Count  int64 // Quantidade de registros com esse nome
+}
+
+// GetUnlinkedLocations agrupa por usuário os nomes de local das compras e do histórico de preços sem
+// store_id, do mais usado para o menos usado
+func (repo *StoreRepository) GetUnlinkedLocations() ([]UnlinkedLocation, error) {
+	var locations []UnlinkedLocation
+	err := repo.database.Raw(`
+		SELECT user_id, name, SUM(count) AS count FROM (
+			SELECT user_id, purchase_location AS name, COUNT(*) AS count FROM purchases
+			WHERE store_id IS NULL AND deleted_at IS NULL AND TRIM(purchase_location) <> ''
+			GROUP BY user_id, purchase_location
+		UNION ALL
+			SELECT user_id, purchase_place AS name, COUNT(*) AS count FROM price_histories
+			WHERE store_id IS NULL AND deleted_at IS NULL AND TRIM(purchase_place) <> ''
+			GROUP BY user_id, purchase_place
+		) AS locations
+		GROUP BY user_id, name
+		ORDER BY count DESC, user_id, name`).Scan(&locations).Error
+	return locations, err
+}
+
+// LinkLocations vincula ao estabelecimento as compras e registros de preço do usuário sem store_id
+// cujo local é um dos nomes informados
+func (repo *StoreRepository) LinkLocations(storeID uint, userID uint, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if err := repo.database.Model(&models.Purchase{}).
+		Where("store_id IS NULL AND user_id = ? AND purchase_location IN ?", userID, names).
+		Update("store_id", storeID).Error; err != nil {
+		return err
+	}
+	return repo.database.Model(&models.PriceHistory{}).
+		Where("store_id IS NULL AND user_id = ? AND purchase_place IN ?", userID, names).
+		Update("store_id", storeID).Error
+}
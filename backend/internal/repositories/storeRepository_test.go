@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestStoreQueriesVisibility confere que as buscas de estabelecimentos se limitam aos compartilhados e
+// aos do próprio usuário, e que os candidatos do casamento são filtrados no banco
+func TestStoreQueriesVisibility(t *testing.T) {
+	visibility := "(stores.owner_id IS NULL OR stores.owner_id = 7)"
+
+	tests := []struct {
+		name  string
+		query func(database *gorm.DB)
+		want  []string
+	}{
+		{
+			name: "candidatos do casamento",
+			query: func(database *gorm.DB) {
+				NewStoreRepository(database).GetStoreCandidates("mercado central", 7)
+			},
+			want: []string{"stores.normalized_name = 'mercado central' OR stores.normalized_name % 'mercado central'", visibility},
+		},
+		{
+			name: "nome alternativo",
+			query: func(database *gorm.DB) {
+				NewStoreRepository(database).FindStoreByAlias("mercado central", 7)
+			},
+			want: []string{"SELECT store_id FROM store_aliases WHERE normalized_name = 'mercado central'", visibility},
+		},
+		{
+			name: "busca por nome",
+			query: func(database *gorm.DB) {
+				NewStoreRepository(database).SearchStores("central", 7)
+			},
+			want: []string{"normalized_name LIKE '%central%'", visibility},
+		},
+		{
+			name: "filiais por rede",
+			query: func(database *gorm.DB) {
+				NewStoreRepository(database).CountStoresByChain(7)
+			},
+			want: []string{visibility},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			database, recorder := newDryRunDatabase(t)
+			test.query(database)
+			if len(recorder.statements) == 0 {
+				t.Fatal("nenhuma consulta registrada")
+			}
+			sql := recorder.statements[0]
+			for _, want := range test.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("consulta sem %q:\n%s", want, sql)
+				}
+			}
+		})
+	}
+}
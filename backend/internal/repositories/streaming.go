@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DateRangeFilter restringe consultas a um intervalo de datas (limites opcionais e inclusivos)
+type DateRangeFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// apply aplica o intervalo à coluna informada
+func (filter DateRangeFilter) apply(query *gorm.DB, column string) *gorm.DB {
+	if filter.StartDate != nil {
+		query = query.Where(column+" >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where(column+" <= ?", *filter.EndDate)
+	}
+	return query
+}
+
+// streamRows percorre o resultado da consulta com um cursor, entregando uma linha por vez a fn,
+// sem carregar o conjunto completo em memória. Um erro retornado por fn interrompe a leitura.
+func streamRows[T any](query *gorm.DB, fn func(row *T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// TransactionManager executa operações de vários repositórios em uma única transação.
+// Os repositórios participam da transação através de seus métodos WithTx.
+type TransactionManager struct {
+	database *gorm.DB
+}
+
+// NewTransactionManager cria uma nova instância de TransactionManager
+func NewTransactionManager(db *gorm.DB) *TransactionManager {
+	return &TransactionManager{database: db}
+}
+
+// Transaction executa fn dentro de uma transação; qualquer erro retornado desfaz todas as alterações
+func (manager *TransactionManager) Transaction(fn func(tx *gorm.DB) error) error {
+	return manager.database.Transaction(fn)
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -38,10 +40,12 @@ func (repo *UserCategoryProductRepository) GetUserCategoryProductsByUserID(userI
 	return ucps, nil
 }
 
-// GetUserCategoryProductsByCategoryID retrieves all user-category-product relationships for a specific category
+// GetUserCategoryProductsByCategoryID retrieves all user-category-product relationships for a specific category,
+// including the ones of its subcategories
 func (repo *UserCategoryProductRepository) GetUserCategoryProductsByCategoryID(categoryID uint) ([]*models.UserCategoryProduct, error) {
 	var ucps []*models.UserCategoryProduct
-	if err := repo.database.Preload("User").Preload("Category").Preload("Product").Where("category_id = ?", categoryID).Find(&ucps).Error; err != nil {
+	if err := repo.database.Preload("User").Preload("Category").Preload("Product").
+		Where("category_id IN (?)", categorySubtreeIDs(repo.database, []uint{categoryID})).Order("id").Find(&ucps).Error; err != nil {
 		return nil, err
 	}
 	return ucps, nil
@@ -56,6 +60,43 @@ func (repo *UserCategoryProductRepository) GetUserCategoryProductsByProductID(pr
 	return ucps, nil
 }
 
+// GetUserCategoryProductsByCategoryIDs retrieves the relationships of several categories at once, without preloading
+func (repo *UserCategoryProductRepository) GetUserCategoryProductsByCategoryIDs(categoryIDs []uint) ([]*models.UserCategoryProduct, error) {
+	var ucps []*models.UserCategoryProduct
+	if len(categoryIDs) == 0 {
+		return ucps, nil
+	}
+	if err := repo.database.Where("category_id IN ?", categoryIDs).Order("id").Find(&ucps).Error; err != nil {
+		return nil, err
+	}
+	return ucps, nil
+}
+
+// GetUserCategoryProductsByUserAndProductIDs retrieves the user's relationships for several products at once, without preloading
+func (repo *UserCategoryProductRepository) GetUserCategoryProductsByUserAndProductIDs(
+	userID uint, productIDs []uint) ([]*models.UserCategoryProduct, error) {
+	var ucps []*models.UserCategoryProduct
+	if len(productIDs) == 0 {
+		return ucps, nil
+	}
+	if err := repo.database.Where("user_id = ? AND product_id IN ?", userID, productIDs).Order("id").Find(&ucps).Error; err != nil {
+		return nil, err
+	}
+	return ucps, nil
+}
+
+// GetUserCategoryProductsByUserIDs retrieves the relationships of several users at once, without preloading
+func (repo *UserCategoryProductRepository) GetUserCategoryProductsByUserIDs(userIDs []uint) ([]*models.UserCategoryProduct, error) {
+	var ucps []*models.UserCategoryProduct
+	if len(userIDs) == 0 {
+		return ucps, nil
+	}
+	if err := repo.database.Where("user_id IN ?", userIDs).Order("id").Find(&ucps).Error; err != nil {
+		return nil, err
+	}
+	return ucps, nil
+}
+
 // GetUserCategoryProduct retrieves a specific user-category-product relationship
 func (repo *UserCategoryProductRepository) GetUserCategoryProduct(userID, categoryID, productID uint) (*models.UserCategoryProduct, error) {
 	var ucp models.UserCategoryProduct
@@ -94,3 +135,28 @@ func (repo *UserCategoryProductRepository) GetAllUserCategoryProducts() ([]*mode
 	}
 	return ucps, nil
 }
+
+// CategoryProductExportRow representa uma associação categoria-produto do usuário na exportação
+type CategoryProductExportRow struct {
+	CategoryID   uint
+	CategoryName string
+	ProductID    uint
+	ProductName  string
+	Barcode      *string
+	CreatedAt    time.Time
+}
+
+// StreamCategoryProductsByUserID percorre as associações do usuário criadas no intervalo,
+// ordenadas por categoria e produto, entregando uma por vez a fn
+func (repo *UserCategoryProductRepository) StreamCategoryProductsByUserID(
+	userID uint, filter DateRangeFilter, fn func(row *CategoryProductExportRow) error) error {
+	query := repo.database.Table("user_category_products AS ucp").
+		Select(`ucp.category_id, c.name AS category_name, ucp.product_id,
+			pr.name AS product_name, pr.barcode, ucp.created_at`).
+		Joins("JOIN categories AS c ON c.id = ucp.category_id AND c.deleted_at IS NULL").
+		Joins("JOIN products AS pr ON pr.id = ucp.product_id").
+		Where("ucp.deleted_at IS NULL AND ucp.user_id = ?", userID)
+	query = filter.apply(query, "ucp.created_at").Order("c.name, pr.name, ucp.id")
+
+	return streamRows(query, fn)
+}
@@ -65,3 +65,15 @@ func (repository *UserRepository) GetAllUsers() ([]*models.User, error) {
 	}
 	return users, nil
 }
+
+// GetUsersByIDs busca de uma vez os usuários com os IDs informados
+func (repository *UserRepository) GetUsersByIDs(ids []uint) ([]*models.User, error) {
+	var users []*models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := repository.database.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository handles database operations for webhook subscriptions and deliveries
+type WebhookRepository struct {
+	database *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{database: db}
+}
+
+// WithTx retorna uma cópia do repositório que opera dentro da transação informada
+func (repo *WebhookRepository) WithTx(tx *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{database: tx}
+}
+
+// CreateSubscription adds a new webhook subscription
+func (repo *WebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return repo.database.Create(subscription).Error
+}
+
+// GetSubscriptionByID retrieves a webhook subscription by its ID
+func (repo *WebhookRepository) GetSubscriptionByID(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := repo.database.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetSubscriptionsByUserID retrieves all webhook subscriptions of a user
+func (repo *WebhookRepository) GetSubscriptionsByUserID(userID uint) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	if err := repo.database.Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetActiveSubscriptionsForEvent retorna as assinaturas ativas que querem o tipo de evento.
+// Eventos com dono vão apenas para as assinaturas do dono; eventos globais, para todas.
+func (repo *WebhookRepository) GetActiveSubscriptionsForEvent(eventType string, userID *uint) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	query := repo.database.Where("active = ? AND (',' || event_types || ',') LIKE ?", true, "%,"+eventType+",%")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription updates an existing webhook subscription
+func (repo *WebhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	return repo.database.Save(subscription).Error
+}
+
+// DeleteSubscription removes a webhook subscription; pending deliveries are discarded
+func (repo *WebhookRepository) DeleteSubscription(id uint) error {
+	return repo.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ? AND status = ?", id, models.WebhookDeliveryPending).
+			Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, id).Error
+	})
+}
+
+// CreateDeliveries adds the deliveries of an event
+func (repo *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return repo.database.Create(&deliveries).Error
+}
+
+// GetDueDeliveries retorna as entregas pendentes cuja próxima tentativa já venceu, com o evento e a
+// assinatura. As linhas ficam bloqueadas até o fim da transação.
+func (repo *WebhookRepository) GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := repo.database.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDeliveryByID retrieves a delivery by its ID
+func (repo *WebhookRepository) GetDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := repo.database.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// GetEventByID retrieves an outbox event by its ID
+func (repo *WebhookRepository) GetEventByID(id uint) (*models.OutboxEvent, error) {
+	var event models.OutboxEvent
+	if err := repo.database.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetDeliveriesBySubscriptionID retorna as entregas mais recentes de uma assinatura (log de entregas)
+func (repo *WebhookRepository) GetDeliveriesBySubscriptionID(subscriptionID uint, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := repo.database.Where("subscription_id = ?", subscriptionID).Order("id desc").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the result of a delivery attempt
+func (repo *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return repo.database.Omit(clause.Associations).Save(delivery).Error
+}
@@ -0,0 +1,1264 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/backup"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Resolução de conflitos de código de barras na restauração
+const (
+	ProductConflictKeep      = "keep"
+	ProductConflictOverwrite = "overwrite"
+)
+
+// BackupService writes and restores portable backups of a user's or the whole instance's data
+type BackupService struct {
+	backupRepository   *repositories.BackupRepository
+	storeRepository    *repositories.StoreRepository
+	categoryRepository *repositories.CategoryRepository
+	transactionManager *repositories.TransactionManager
+}
+
+// NewBackupService creates a new instance of BackupService
+func NewBackupService(
+	backupRepo *repositories.BackupRepository,
+	storeRepo *repositories.StoreRepository,
+	categoryRepo *repositories.CategoryRepository,
+	transactionManager *repositories.TransactionManager) *BackupService {
+	return &BackupService{
+		backupRepository:   backupRepo,
+		storeRepository:    storeRepo,
+		categoryRepository: categoryRepo,
+		transactionManager: transactionManager,
+	}
+}
+
+// WriteInstanceBackup grava o backup de todos os dados da instância (apenas admin)
+func (service *BackupService) WriteInstanceBackup(output io.Writer, userRole string) error {
+	if userRole != string(models.RoleAdmin) {
+		return errors.New("WriteInstanceBackup: permissão negada: apenas administradores podem exportar a instância")
+	}
+	return service.writeBackup(output, 0, backup.Manifest{Scope: backup.ScopeInstance})
+}
+
+// WriteUserBackup grava o backup dos dados de um usuário (o próprio usuário ou admin)
+func (service *BackupService) WriteUserBackup(output io.Writer, targetUserID uint, userID uint, userRole string) error {
+	if targetUserID != userID && userRole != string(models.RoleAdmin) {
+		return errors.New("WriteUserBackup: permissão negada: você só pode exportar os seus próprios dados")
+	}
+
+	var email string
+	err := service.backupRepository.StreamUsers(targetUserID, func(user *models.User) error {
+		email = user.Email
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return errors.New("WriteUserBackup: usuário não encontrado")
+	}
+
+	return service.writeBackup(output, targetUserID, backup.Manifest{Scope: backup.ScopeUser, UserEmail: email})
+}
+
+// writeBackup grava todas as entidades em streaming; userID 0 inclui a instância inteira
+func (service *BackupService) writeBackup(output io.Writer, userID uint, manifest backup.Manifest) error {
+	repo := service.backupRepository
+	writer := backup.NewWriter(output)
+	manifest.CreatedAt = time.Now().UTC()
+
+	err := writeEntity(writer, backup.EntityUsers, func(write func(any) error) error {
+		return repo.StreamUsers(userID, func(user *models.User) error {
+			record := backup.UserRecord{
+				Base:         backupBase(user.Model),
+				Name:         user.Name,
+				Email:        user.Email,
+				PasswordHash: user.PasswordHash,
+				Role:         user.Role,
+			}
+			// O domicílio só faz sentido no backup da instância, que inclui os domicílios
+			if userID == 0 {
+				record.HouseholdID = user.HouseholdID
+			}
+			return write(record)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if userID == 0 {
+		err = writeEntity(writer, backup.EntityHouseholds, func(write func(any) error) error {
+			return repo.StreamHouseholds(func(household *models.Household) error {
+				return write(backup.HouseholdRecord{
+					Base:       backupBase(household.Model),
+					Name:       household.Name,
+					OwnerID:    household.OwnerID,
+					InviteCode: household.InviteCode,
+				})
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeEntity(writer, backup.EntityCategories, func(write func(any) error) error {
+		return repo.StreamCategories(userID, func(category *models.Category) error {
+			return write(backup.CategoryRecord{
+				Base:     backupBase(category.Model),
+				UserID:   category.UserID,
+				Name:     category.Name,
+				ParentID: category.ParentID,
+				Position: category.Position,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityProducts, func(write func(any) error) error {
+		return repo.StreamProducts(userID, func(product *models.Product) error {
+			return write(backup.ProductRecord{
+				Base:         backupBase(product.Model),
+				Name:         product.Name,
+				Barcode:      product.Barcode,
+				Brand:        product.Brand,
+				PackageLabel: product.PackageLabel,
+				ImageURL:     product.ImageURL,
+
+				PackageQuantity: product.PackageQuantity,
+				Unit:            product.Unit,
+
+				Status:         string(product.Status),
+				ProposedByID:   product.ProposedByID,
+				ModerationNote: product.ModerationNote,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityProductBarcodes, func(write func(any) error) error {
+		return repo.StreamProductBarcodes(userID, func(productBarcode *models.ProductBarcode) error {
+			return write(backup.ProductBarcodeRecord{
+				Base:         backupBase(productBarcode.Model),
+				ProductID:    productBarcode.ProductID,
+				Code:         productBarcode.Code,
+				UnitsPerPack: productBarcode.UnitsPerPack,
+				Description:  productBarcode.Description,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityProductRedirects, func(write func(any) error) error {
+		return repo.StreamProductRedirects(userID, func(redirect *repositories.ProductRedirectRow) error {
+			return write(backup.ProductRedirectRecord{
+				Base:          backupBase(redirect.Model),
+				FromProductID: redirect.FromProductID,
+				FromName:      redirect.FromName,
+				FromBarcode:   redirect.FromBarcode,
+				ProductID:     redirect.ProductID,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	chainNames, err := repo.GetStoreChainNames()
+	if err != nil {
+		return err
+	}
+	err = writeEntity(writer, backup.EntityStores, func(write func(any) error) error {
+		return repo.StreamStores(userID, func(store *models.Store) error {
+			chainName := ""
+			if store.ChainID != nil {
+				chainName = chainNames[*store.ChainID]
+			}
+			return write(backup.StoreRecord{
+				Base:       backupBase(store.Model),
+				OwnerID:    store.OwnerID,
+				Name:       store.Name,
+				Chain:      chainName,
+				BranchName: store.BranchName,
+				Address:    store.Address,
+				CNPJ:       store.CNPJ,
+				Latitude:   store.Latitude,
+				Longitude:  store.Longitude,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityProductPLUs, func(write func(any) error) error {
+		return repo.StreamProductPLUs(userID, func(productPLU *models.ProductPLU) error {
+			return write(backup.ProductPLURecord{
+				Base:      backupBase(productPLU.Model),
+				ProductID: productPLU.ProductID,
+				StoreID:   productPLU.StoreID,
+				PLU:       productPLU.PLU,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityPurchases, func(write func(any) error) error {
+		return repo.StreamPurchases(userID, func(purchase *models.Purchase) error {
+			return write(backup.PurchaseRecord{
+				Base:             backupBase(purchase.Model),
+				UserID:           purchase.UserID,
+				PurchaseDate:     purchase.PurchaseDate,
+				PurchaseLocation: purchase.PurchaseLocation,
+				StoreID:          purchase.StoreID,
+				InvoiceKey:       purchase.InvoiceKey,
+				Total:            purchase.Total,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityPurchaseItems, func(write func(any) error) error {
+		return repo.StreamPurchaseItems(userID, func(item *models.PurchaseItem) error {
+			return write(backup.PurchaseItemRecord{
+				Base:       backupBase(item.Model),
+				PurchaseID: item.PurchaseID,
+				ProductID:  item.ProductID,
+				Quantity:   item.Quantity,
+				Unit:       item.Unit,
+				UnitPrice:  item.UnitPrice,
+				TotalPrice: item.TotalPrice,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityPriceHistory, func(write func(any) error) error {
+		return repo.StreamPriceHistory(userID, func(entry *models.PriceHistory) error {
+			return write(backup.PriceHistoryRecord{
+				Base:          backupBase(entry.Model),
+				UserID:        entry.UserID,
+				ProductID:     entry.ProductID,
+				PurchaseDate:  entry.PurchaseDate,
+				PurchasePlace: entry.PurchasePlace,
+				StoreID:       entry.StoreID,
+				PricePaid:     entry.PricePaid,
+				Quantity:      entry.Quantity,
+				Unit:          entry.Unit,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityUserCategoryProducts, func(write func(any) error) error {
+		return repo.StreamUserCategoryProducts(userID, func(ucp *models.UserCategoryProduct) error {
+			return write(backup.UserCategoryProductRecord{
+				Base:       backupBase(ucp.Model),
+				UserID:     ucp.UserID,
+				CategoryID: ucp.CategoryID,
+				ProductID:  ucp.ProductID,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeEntity(writer, backup.EntityBudgets, func(write func(any) error) error {
+		return repo.StreamBudgets(userID, func(budget *models.Budget) error {
+			return write(backup.BudgetRecord{
+				Base:              backupBase(budget.Model),
+				UserID:            budget.UserID,
+				CategoryID:        budget.CategoryID,
+				MonthlyLimit:      budget.MonthlyLimit,
+				WarningThreshold:  budget.WarningThreshold,
+				RolloverEnabled:   budget.RolloverEnabled,
+				RolloverMaxMonths: budget.RolloverMaxMonths,
+				LastAlertPeriod:   budget.LastAlertPeriod,
+				LastAlertLevel:    budget.LastAlertLevel,
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close(manifest)
+}
+
+// writeEntity abre o arquivo da entidade e entrega a stream a função de gravação
+func writeEntity(writer *backup.Writer, name string, stream func(write func(any) error) error) error {
+	entity, err := writer.Entity(name)
+	if err != nil {
+		return err
+	}
+	return stream(entity.Write)
+}
+
+func backupBase(model gorm.Model) backup.Base {
+	return backup.Base{ID: model.ID, CreatedAt: model.CreatedAt, UpdatedAt: model.UpdatedAt}
+}
+
+func restoredModel(base backup.Base) gorm.Model {
+	return gorm.Model{CreatedAt: base.CreatedAt, UpdatedAt: base.UpdatedAt}
+}
+
+// RestoreBackup restaura um backup em uma única transação. Os registros recebem novos IDs e as
+// referências são remapeadas; usuários são casados pelo e-mail, produtos pelo código de barras (ou
+// pelo nome, sem código), categorias pelo nome e estabelecimentos pelo CNPJ ou nome parecido.
+// Compras e preços sem estabelecimento (arquivos da versão 1) são vinculados pelo local digitado. Compras, preços e vínculos já existentes são
+// ignorados, de modo que restaurar o mesmo arquivo duas vezes não duplica dados.
+//
+// Com targetUserID != 0, um backup de usuário é restaurado na conta informada (migração entre
+// instâncias); com 0, os usuários do arquivo são casados ou criados (apenas admin). Os produtos são do
+// catálogo global: só admin os sobrescreve, e os criados na restauração de um usuário comum ficam
+// como sugestões dele, pendentes de moderação.
+func (service *BackupService) RestoreBackup(
+	archive *backup.Reader, options dto.RestoreOptionsDTO, targetUserID uint, userID uint, userRole string) (*dto.RestoreReportDTO, error) {
+	isAdmin := userRole == string(models.RoleAdmin)
+	switch {
+	case targetUserID == 0 && !isAdmin:
+		return nil, errors.New("RestoreBackup: permissão negada: apenas administradores podem restaurar a instância")
+	case targetUserID != 0 && targetUserID != userID && !isAdmin:
+		return nil, errors.New("RestoreBackup: permissão negada: você só pode restaurar dados na sua própria conta")
+	case targetUserID != 0 && archive.Manifest.Scope != backup.ScopeUser:
+		return nil, errors.New("RestoreBackup: backups da instância só podem ser restaurados por completo")
+	case options.ProductConflict == ProductConflictOverwrite && !isAdmin:
+		return nil, errors.New("RestoreBackup: permissão negada: apenas administradores podem sobrescrever produtos do catálogo")
+	}
+	if options.ProductConflict == "" {
+		options.ProductConflict = ProductConflictKeep
+	}
+
+	report := &dto.RestoreReportDTO{
+		DryRun:           options.DryRun,
+		Scope:            archive.Manifest.Scope,
+		FormatVersion:    archive.Manifest.FormatVersion,
+		BackupCreatedAt:  archive.Manifest.CreatedAt.Format(time.RFC3339),
+		Entities:         make(map[string]*dto.RestoreEntityReportDTO, len(backup.Entities)),
+		ProductConflicts: []dto.ProductConflictDTO{},
+	}
+	for _, entity := range backup.Entities {
+		report.Entities[entity] = &dto.RestoreEntityReportDTO{}
+	}
+
+	// Produtos novos entram no catálogo apenas numa restauração feita por admin; nas demais viram
+	// sugestões do usuário, pendentes de moderação
+	creation := newImportProductCreation(true, targetUserID, userRole)
+
+	txErr := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		state := &restoreState{
+			repo:               service.backupRepository.WithTx(tx),
+			storeRepository:    service.storeRepository.WithTx(tx),
+			categoryRepository: service.categoryRepository.WithTx(tx),
+			storeMatchers:      make(map[uint]*storeMatcher),
+			archive:            archive,
+			options:            options,
+			targetUserID:       targetUserID,
+			proposedBy:         creation.proposedBy,
+			report:             report,
+			users:              make(map[uint]uint),
+			households:         make(map[uint]uint),
+			categories:         make(map[uint]uint),
+			products:           make(map[uint]uint),
+			proposedProducts:   make(map[uint]bool),
+			stores:             make(map[uint]uint),
+			purchases:          make(map[uint]uint),
+			pendingHouseholds:  make(map[uint]uint),
+			pendingParents:     make(map[uint]uint),
+		}
+		if err := state.restore(); err != nil {
+			return err
+		}
+		if options.DryRun {
+			return errImportRollback
+		}
+		return nil
+	})
+	if errors.Is(txErr, errImportRollback) {
+		return report, nil
+	}
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// restoreState guarda o mapeamento entre os IDs do arquivo e os IDs locais durante a restauração
+type restoreState struct {
+	repo               *repositories.BackupRepository
+	storeRepository    *repositories.StoreRepository
+	categoryRepository *repositories.CategoryRepository
+	storeMatchers      map[uint]*storeMatcher // Por usuário dono; 0 = apenas os estabelecimentos compartilhados
+	archive            *backup.Reader
+	options            dto.RestoreOptionsDTO
+	targetUserID       uint
+	proposedBy         *uint // Usuário que sugere os produtos criados; nil numa restauração feita por admin
+	report             *dto.RestoreReportDTO
+
+	users      map[uint]uint
+	households map[uint]uint
+	categories map[uint]uint
+	products   map[uint]uint
+	stores     map[uint]uint
+
+	proposedProducts map[uint]bool // Produtos criados nesta restauração como sugestões do usuário
+	purchases        map[uint]uint // 0 = compra já existente, seus itens são ignorados
+
+	pendingHouseholds map[uint]uint // Usuário criado -> domicílio do arquivo
+	pendingParents    map[uint]uint // Categoria criada -> categoria-pai do arquivo
+}
+
+func (state *restoreState) restore() error {
+	steps := []func() error{
+		state.restoreUsers, state.restoreHouseholds, state.restoreCategories, state.restoreProducts,
+		state.restoreProductBarcodes, state.restoreProductRedirects, state.restoreStores, state.restoreProductPLUs,
+		state.restorePurchases, state.restorePurchaseItems, state.restorePriceHistory,
+		state.restoreUserCategoryProducts, state.restoreBudgets,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup resolve uma referência do arquivo para o ID local
+func lookup(ids map[uint]uint, archiveID uint, entity string, referenced string, recordID uint) (uint, error) {
+	localID, ok := ids[archiveID]
+	if !ok {
+		return 0, fmt.Errorf("RestoreBackup: %s %d referencia %s %d, ausente no backup", entity, recordID, referenced, archiveID)
+	}
+	return localID, nil
+}
+
+func (state *restoreState) restoreUsers() error {
+	counts := state.report.Entities[backup.EntityUsers]
+	return state.archive.Each(backup.EntityUsers, func(decode func(any) error) error {
+		var record backup.UserRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+
+		// Restauração na conta de um usuário: os dados do arquivo passam a ser dele
+		if state.targetUserID != 0 {
+			state.users[record.ID] = state.targetUserID
+			counts.Matched++
+			return nil
+		}
+
+		existing, err := state.repo.FindUserByEmail(record.Email)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			state.users[record.ID] = existing.ID
+			counts.Matched++
+			return nil
+		}
+
+		role := record.Role
+		if !models.IsValidRole(role) {
+			role = string(models.DefaultRole())
+		}
+		user := models.User{
+			Model:        restoredModel(record.Base),
+			Name:         record.Name,
+			Email:        record.Email,
+			PasswordHash: record.PasswordHash,
+			Role:         role,
+		}
+		if err := state.repo.Create(&user); err != nil {
+			return err
+		}
+		state.users[record.ID] = user.ID
+		if record.HouseholdID != nil {
+			state.pendingHouseholds[user.ID] = *record.HouseholdID
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+// restoreHouseholds recria os domicílios (casados pelo código de convite) e vincula a eles os usuários
+// criados nesta restauração; usuários que já existiam mantêm o domicílio atual
+func (state *restoreState) restoreHouseholds() error {
+	counts := state.report.Entities[backup.EntityHouseholds]
+	err := state.archive.Each(backup.EntityHouseholds, func(decode func(any) error) error {
+		var record backup.HouseholdRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+
+		existing, err := state.repo.FindHouseholdByInviteCode(record.InviteCode)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			state.households[record.ID] = existing.ID
+			counts.Matched++
+			return nil
+		}
+
+		ownerID, err := lookup(state.users, record.OwnerID, "domicílio", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+		household := models.Household{
+			Model:      restoredModel(record.Base),
+			Name:       record.Name,
+			OwnerID:    ownerID,
+			InviteCode: record.InviteCode,
+		}
+		if err := state.repo.Create(&household); err != nil {
+			return err
+		}
+		state.households[record.ID] = household.ID
+		counts.Created++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for localUserID, archiveHouseholdID := range state.pendingHouseholds {
+		householdID, ok := state.households[archiveHouseholdID]
+		if !ok {
+			continue
+		}
+		if err := state.repo.SetUserHousehold(localUserID, householdID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreCategories casa as categorias pelo nome e recria as demais. A hierarquia é refeita ao final, pois a
+// categoria-pai pode aparecer depois da subcategoria no arquivo, com as mesmas validações de ciclo e
+// profundidade de MoveCategory; categorias que já existiam mantêm o lugar atual.
+func (state *restoreState) restoreCategories() error {
+	counts := state.report.Entities[backup.EntityCategories]
+	err := state.archive.Each(backup.EntityCategories, func(decode func(any) error) error {
+		var record backup.CategoryRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		userID, err := lookup(state.users, record.UserID, "categoria", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := state.repo.FindCategoryByName(userID, record.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			state.categories[record.ID] = existing.ID
+			counts.Matched++
+			return nil
+		}
+
+		category := models.Category{Model: restoredModel(record.Base), Name: record.Name, UserID: userID, Position: record.Position}
+		if err := state.repo.Create(&category); err != nil {
+			return err
+		}
+		state.categories[record.ID] = category.ID
+		if record.ParentID != nil {
+			state.pendingParents[category.ID] = *record.ParentID
+		}
+		counts.Created++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// As subcategorias entram no fim das irmãs na ordem de posição do arquivo
+	var children []*models.Category
+	for localCategoryID, archiveParentID := range state.pendingParents {
+		if _, ok := state.categories[archiveParentID]; !ok {
+			continue
+		}
+		category, err := state.categoryRepository.GetCategoryByID(localCategoryID)
+		if err != nil {
+			return err
+		}
+		children = append(children, category)
+	}
+	slices.SortFunc(children, func(a, b *models.Category) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+	})
+
+	locked := make(map[uint]bool)
+	for _, category := range children {
+		if !locked[category.UserID] {
+			if err := state.categoryRepository.LockCategoryTree(category.UserID); err != nil {
+				return err
+			}
+			locked[category.UserID] = true
+		}
+		// Relida porque mover uma irmã anterior pode ter mudado a posição dela
+		category, err := state.categoryRepository.GetCategoryByID(category.ID)
+		if err != nil {
+			return err
+		}
+		parentID := state.categories[state.pendingParents[category.ID]]
+		operation := fmt.Sprintf("RestoreBackup: categoria %q", category.Name)
+		if err := moveCategory(state.categoryRepository, operation, category, &parentID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreProducts casa os produtos pelo código de barras (ou pelo nome, sem código). Quando o código
+// já pertence a um produto com outro nome, o conflito é registrado e resolvido por productConflict.
+func (state *restoreState) restoreProducts() error {
+	counts := state.report.Entities[backup.EntityProducts]
+	return state.archive.Each(backup.EntityProducts, func(decode func(any) error) error {
+		var record backup.ProductRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+
+		var existing *models.Product
+		var err error
+		code := record.Barcode
+		if code != nil && strings.TrimSpace(*code) == "" {
+			code = nil
+		}
+		// Backups anteriores à normalização trazem códigos válidos em outras formas (EAN-13, UPC-A)
+		if code != nil {
+			if normalized, err := barcode.Normalize(*code); err == nil {
+				code = &normalized
+			}
+		}
+		if code != nil {
+			existing, err = state.findProductByBarcode(*code)
+		} else {
+			existing, err = state.repo.FindProductWithoutBarcodeByName(record.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			product := models.Product{
+				Model:        restoredModel(record.Base),
+				Name:         record.Name,
+				Barcode:      code,
+				Brand:        record.Brand,
+				PackageLabel: record.PackageLabel,
+				ImageURL:     record.ImageURL,
+
+				PackageQuantity: record.PackageQuantity,
+				Unit:            record.Unit,
+
+				Status:       models.ProductPending,
+				ProposedByID: state.proposedBy,
+			}
+			// Numa restauração feita por admin a moderação do arquivo é mantida
+			if state.proposedBy == nil {
+				if product.Status, err = restoredProductStatus(record); err != nil {
+					return err
+				}
+				if record.ProposedByID != nil {
+					if proposedBy, ok := state.users[*record.ProposedByID]; ok {
+						product.ProposedByID = &proposedBy
+					}
+				}
+				product.ModerationNote = record.ModerationNote
+			}
+			if err := state.repo.Create(&product); err != nil {
+				return err
+			}
+			state.products[record.ID] = product.ID
+			if state.proposedBy != nil {
+				state.proposedProducts[product.ID] = true
+			}
+			counts.Created++
+			return nil
+		}
+
+		state.products[record.ID] = existing.ID
+		if code != nil && !strings.EqualFold(existing.Name, record.Name) {
+			state.report.ProductConflicts = append(state.report.ProductConflicts, dto.ProductConflictDTO{
+				Barcode:     *code,
+				LocalName:   existing.Name,
+				ArchiveName: record.Name,
+				Resolution:  state.options.ProductConflict,
+			})
+		}
+		if code == nil || state.options.ProductConflict != ProductConflictOverwrite {
+			counts.Matched++
+			return nil
+		}
+
+		existing.Name = record.Name
+		if record.Brand != "" {
+			existing.Brand = record.Brand
+		}
+		if record.PackageLabel != "" {
+			existing.PackageLabel = record.PackageLabel
+		}
+		if record.ImageURL != "" {
+			existing.ImageURL = record.ImageURL
+		}
+		if record.PackageQuantity != nil && record.Unit != "" {
+			existing.PackageQuantity, existing.Unit = record.PackageQuantity, record.Unit
+		}
+		if err := state.repo.Save(existing); err != nil {
+			return err
+		}
+		counts.Updated++
+		return nil
+	})
+}
+
+// restoredProductStatus valida a situação de moderação do produto do arquivo (vazia até a versão 2)
+func restoredProductStatus(record backup.ProductRecord) (models.ProductStatus, error) {
+	switch status := models.ProductStatus(record.Status); status {
+	case "":
+		return models.ProductApproved, nil
+	case models.ProductApproved, models.ProductPending, models.ProductRejected, models.ProductMerged:
+		return status, nil
+	default:
+		return "", fmt.Errorf("RestoreBackup: produto %d com situação de moderação inválida %q", record.ID, record.Status)
+	}
+}
+
+// catalogWritable indica se a restauração pode alterar os códigos e PLUs do produto local: numa
+// restauração feita por admin, qualquer produto; nas demais, só as sugestões criadas por ela
+func (state *restoreState) catalogWritable(productID uint) bool {
+	return state.proposedBy == nil || state.proposedProducts[productID]
+}
+
+// restoreProductBarcodes recria os códigos adicionais dos produtos. Códigos que já levam a um
+// produto local são mantidos como estão.
+func (state *restoreState) restoreProductBarcodes() error {
+	counts := state.report.Entities[backup.EntityProductBarcodes]
+	return state.archive.Each(backup.EntityProductBarcodes, func(decode func(any) error) error {
+		var record backup.ProductBarcodeRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		productID, err := lookup(state.products, record.ProductID, "código de barras", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+		code, err := barcode.Normalize(record.Code)
+		if err != nil || !state.catalogWritable(productID) {
+			counts.Skipped++
+			return nil
+		}
+
+		existing, err := state.repo.FindProductBarcode(code)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			owner, err := state.repo.FindProductByBarcode(code)
+			if err != nil {
+				return err
+			}
+			if owner != nil {
+				existing = &models.ProductBarcode{ProductID: owner.ID}
+			}
+		}
+		if existing != nil {
+			if existing.ProductID == productID && !existing.DeletedAt.Valid {
+				counts.Matched++
+			} else {
+				counts.Skipped++
+			}
+			return nil
+		}
+
+		unitsPerPack := record.UnitsPerPack
+		if unitsPerPack < 1 {
+			unitsPerPack = 1
+		}
+		productBarcode := models.ProductBarcode{
+			Model:        restoredModel(record.Base),
+			ProductID:    productID,
+			Code:         code,
+			UnitsPerPack: unitsPerPack,
+			Description:  record.Description,
+		}
+		if err := state.repo.Create(&productBarcode); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+// restoreProductRedirects recria, numa restauração feita por admin, os produtos mesclados (já
+// removidos) com o seu código de barras e o redirecionamento ao produto que os absorveu, para que o
+// código antigo continue levando a ele. Sem código não há o que preservar, pois os IDs mudam.
+func (state *restoreState) restoreProductRedirects() error {
+	counts := state.report.Entities[backup.EntityProductRedirects]
+	return state.archive.Each(backup.EntityProductRedirects, func(decode func(any) error) error {
+		var record backup.ProductRedirectRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		productID, err := lookup(state.products, record.ProductID, "redirecionamento", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+		if state.proposedBy != nil || record.FromBarcode == nil {
+			counts.Skipped++
+			return nil
+		}
+		code := *record.FromBarcode
+		if normalized, err := barcode.Normalize(code); err == nil {
+			code = normalized
+		}
+
+		existing, err := state.repo.FindProductByBarcode(code)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			counts.Matched++
+			return nil
+		}
+
+		merged := models.Product{
+			Model:   restoredModel(record.Base),
+			Name:    record.FromName,
+			Barcode: &code,
+			Status:  models.ProductMerged,
+		}
+		merged.DeletedAt = gorm.DeletedAt{Time: record.UpdatedAt, Valid: true}
+		if err := state.repo.Create(&merged); err != nil {
+			return err
+		}
+		redirect := models.ProductRedirect{Model: restoredModel(record.Base), FromProductID: merged.ID, ProductID: productID}
+		if err := state.repo.Create(&redirect); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+// findProductByBarcode busca o produto local com o código. Um produto removido leva ao produto que o
+// absorveu, quando foi mesclado; sem isso, o código é liberado para o produto restaurado.
+func (state *restoreState) findProductByBarcode(code string) (*models.Product, error) {
+	product, err := state.repo.FindProductByBarcode(code)
+	if product == nil || err != nil || !product.DeletedAt.Valid {
+		return product, err
+	}
+	redirect, err := state.repo.FindProductRedirect(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		target, err := state.repo.FindProductByID(redirect.ProductID)
+		if target != nil || err != nil {
+			return target, err
+		}
+	}
+	return nil, state.repo.ReleaseProductBarcode(product.ID)
+}
+
+// restoreStores casa os estabelecimentos pelo CNPJ ou pelo nome, como na digitação das compras;
+// as redes são casadas pelo nome
+func (state *restoreState) restoreStores() error {
+	counts := state.report.Entities[backup.EntityStores]
+	return state.archive.Each(backup.EntityStores, func(decode func(any) error) error {
+		var record backup.StoreRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+
+		cnpj := ""
+		if record.CNPJ != nil {
+			cnpj = digitsOnly(*record.CNPJ)
+		}
+		if len(cnpj) != 14 {
+			cnpj = ""
+		}
+		ownerID := state.storeOwner(record, cnpj)
+		matcher := state.storeMatcher(ownerID)
+		normalizedName := utils.NormalizeText(record.Name)
+		existing, _, err := matcher.match(normalizedName, cnpj)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			state.stores[record.ID] = existing.ID
+			counts.Matched++
+			return nil
+		}
+
+		store := &models.Store{
+			Model:          restoredModel(record.Base),
+			Name:           record.Name,
+			NormalizedName: normalizedName,
+			BranchName:     record.BranchName,
+			Address:        record.Address,
+			Latitude:       record.Latitude,
+			Longitude:      record.Longitude,
+		}
+		if ownerID != 0 {
+			store.OwnerID = &ownerID
+		} else if cnpj != "" {
+			store.CNPJ = &cnpj
+		}
+		chain, err := resolveChain(state.storeRepository, record.Chain)
+		if err != nil {
+			return err
+		}
+		setStoreChain(store, chain)
+		if err := state.storeRepository.CreateStore(store); err != nil {
+			return err
+		}
+		matcher.add(store)
+		state.stores[record.ID] = store.ID
+		counts.Created++
+		return nil
+	})
+}
+
+// storeOwner decide o dono local de um estabelecimento do arquivo (0 = compartilhado): numa restauração
+// feita por admin, o dono do arquivo, e estabelecimentos com CNPJ são compartilhados; nas demais, os
+// estabelecimentos criados são sempre do usuário que restaura
+func (state *restoreState) storeOwner(record backup.StoreRecord, cnpj string) uint {
+	if state.proposedBy != nil {
+		return state.targetUserID
+	}
+	if record.OwnerID == nil || cnpj != "" {
+		return 0
+	}
+	return state.users[*record.OwnerID]
+}
+
+// storeMatcher devolve o matcher dos estabelecimentos visíveis ao usuário (0 = só os compartilhados)
+func (state *restoreState) storeMatcher(userID uint) *storeMatcher {
+	matcher, found := state.storeMatchers[userID]
+	if !found {
+		matcher = newStoreMatcher(state.storeRepository, userID)
+		state.storeMatchers[userID] = matcher
+	}
+	return matcher
+}
+
+// restoreProductPLUs recria os PLUs de balança dos produtos. PLUs já cadastrados no mesmo
+// estabelecimento são mantidos como estão.
+func (state *restoreState) restoreProductPLUs() error {
+	counts := state.report.Entities[backup.EntityProductPLUs]
+	return state.archive.Each(backup.EntityProductPLUs, func(decode func(any) error) error {
+		var record backup.ProductPLURecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		productID, err := lookup(state.products, record.ProductID, "PLU", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+		var storeID *uint
+		if record.StoreID != nil {
+			localStoreID, err := lookup(state.stores, *record.StoreID, "PLU", "estabelecimento", record.ID)
+			if err != nil {
+				return err
+			}
+			storeID = &localStoreID
+		}
+		if !state.catalogWritable(productID) {
+			counts.Skipped++
+			return nil
+		}
+
+		existing, err := state.repo.FindPLU(record.PLU, storeID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.ProductID == productID {
+				counts.Matched++
+			} else {
+				counts.Skipped++
+			}
+			return nil
+		}
+
+		productPLU := models.ProductPLU{Model: restoredModel(record.Base), ProductID: productID, StoreID: storeID, PLU: record.PLU}
+		if err := state.repo.Create(&productPLU); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+// resolveStoreID devolve o estabelecimento local de uma compra ou preço do arquivo; sem ele, o local
+// digitado é casado (ou criado) entre os estabelecimentos do usuário
+func (state *restoreState) resolveStoreID(
+	archiveStoreID *uint, location string, userID uint, entity string, recordID uint) (*uint, error) {
+	if archiveStoreID != nil {
+		storeID, err := lookup(state.stores, *archiveStoreID, entity, "estabelecimento", recordID)
+		if err != nil {
+			return nil, err
+		}
+		return &storeID, nil
+	}
+	store, err := resolveStore(state.storeRepository, state.storeMatcher(userID), location, "")
+	if err != nil || store == nil {
+		return nil, err
+	}
+	return &store.ID, nil
+}
+
+// restorePurchases ignora compras que o usuário já tem (mesma nota fiscal, ou mesma data e local)
+func (state *restoreState) restorePurchases() error {
+	counts := state.report.Entities[backup.EntityPurchases]
+	return state.archive.Each(backup.EntityPurchases, func(decode func(any) error) error {
+		var record backup.PurchaseRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		userID, err := lookup(state.users, record.UserID, "compra", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := state.repo.FindPurchase(userID, record.InvoiceKey, record.PurchaseDate, record.PurchaseLocation)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			state.purchases[record.ID] = 0
+			counts.Skipped++
+			return nil
+		}
+		storeID, err := state.resolveStoreID(record.StoreID, record.PurchaseLocation, userID, "compra", record.ID)
+		if err != nil {
+			return err
+		}
+
+		purchase := models.Purchase{
+			Model:            restoredModel(record.Base),
+			PurchaseDate:     record.PurchaseDate,
+			PurchaseLocation: record.PurchaseLocation,
+			StoreID:          storeID,
+			UserID:           userID,
+			InvoiceKey:       record.InvoiceKey,
+			Total:            record.Total,
+		}
+		if err := state.repo.Create(&purchase); err != nil {
+			return err
+		}
+		state.purchases[record.ID] = purchase.ID
+		counts.Created++
+		return nil
+	})
+}
+
+func (state *restoreState) restorePurchaseItems() error {
+	counts := state.report.Entities[backup.EntityPurchaseItems]
+	return state.archive.Each(backup.EntityPurchaseItems, func(decode func(any) error) error {
+		var record backup.PurchaseItemRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		purchaseID, err := lookup(state.purchases, record.PurchaseID, "item de compra", "compra", record.ID)
+		if err != nil {
+			return err
+		}
+		if purchaseID == 0 {
+			counts.Skipped++
+			return nil
+		}
+		productID, err := lookup(state.products, record.ProductID, "item de compra", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+
+		item := models.PurchaseItem{
+			Model:      restoredModel(record.Base),
+			PurchaseID: purchaseID,
+			ProductID:  productID,
+			Quantity:   record.Quantity,
+			Unit:       record.Unit, // Vazio fica com o padrão da coluna (un)
+			UnitPrice:  record.UnitPrice,
+			TotalPrice: record.TotalPrice,
+		}
+		if err := state.repo.Create(&item); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+func (state *restoreState) restorePriceHistory() error {
+	counts := state.report.Entities[backup.EntityPriceHistory]
+	return state.archive.Each(backup.EntityPriceHistory, func(decode func(any) error) error {
+		var record backup.PriceHistoryRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		userID, err := lookup(state.users, record.UserID, "histórico de preço", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+		productID, err := lookup(state.products, record.ProductID, "histórico de preço", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+
+		storeID, err := state.resolveStoreID(record.StoreID, record.PurchasePlace, userID, "histórico de preço", record.ID)
+		if err != nil {
+			return err
+		}
+
+		entry := models.PriceHistory{
+			Model:         restoredModel(record.Base),
+			ProductID:     productID,
+			UserID:        userID,
+			PurchaseDate:  record.PurchaseDate,
+			PurchasePlace: record.PurchasePlace,
+			StoreID:       storeID,
+			PricePaid:     record.PricePaid,
+			Quantity:      record.Quantity,
+			Unit:          record.Unit,
+		}
+		exists, err := state.repo.PriceHistoryExists(&entry)
+		if err != nil {
+			return err
+		}
+		if exists {
+			counts.Skipped++
+			return nil
+		}
+		if err := state.repo.Create(&entry); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+func (state *restoreState) restoreUserCategoryProducts() error {
+	counts := state.report.Entities[backup.EntityUserCategoryProducts]
+	return state.archive.Each(backup.EntityUserCategoryProducts, func(decode func(any) error) error {
+		var record backup.UserCategoryProductRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		userID, err := lookup(state.users, record.UserID, "vínculo de categoria", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+		categoryID, err := lookup(state.categories, record.CategoryID, "vínculo de categoria", "categoria", record.ID)
+		if err != nil {
+			return err
+		}
+		productID, err := lookup(state.products, record.ProductID, "vínculo de categoria", "produto", record.ID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := state.repo.UserCategoryProductExists(userID, categoryID, productID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			counts.Skipped++
+			return nil
+		}
+		ucp := models.UserCategoryProduct{
+			Model:      restoredModel(record.Base),
+			UserID:     userID,
+			CategoryID: categoryID,
+			ProductID:  productID,
+		}
+		if err := state.repo.Create(&ucp); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
+
+func (state *restoreState) restoreBudgets() error {
+	counts := state.report.Entities[backup.EntityBudgets]
+	return state.archive.Each(backup.EntityBudgets, func(decode func(any) error) error {
+		var record backup.BudgetRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		userID, err := lookup(state.users, record.UserID, "orçamento", "usuário", record.ID)
+		if err != nil {
+			return err
+		}
+		var categoryID *uint
+		if record.CategoryID != nil {
+			localCategoryID, err := lookup(state.categories, *record.CategoryID, "orçamento", "categoria", record.ID)
+			if err != nil {
+				return err
+			}
+			categoryID = &localCategoryID
+		}
+
+		exists, err := state.repo.BudgetExists(userID, categoryID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			counts.Skipped++
+			return nil
+		}
+		budget := models.Budget{
+			Model:             restoredModel(record.Base),
+			UserID:            userID,
+			CategoryID:        categoryID,
+			MonthlyLimit:      record.MonthlyLimit,
+			WarningThreshold:  record.WarningThreshold,
+			RolloverEnabled:   record.RolloverEnabled,
+			RolloverMaxMonths: record.RolloverMaxMonths,
+			LastAlertPeriod:   record.LastAlertPeriod,
+			LastAlertLevel:    record.LastAlertLevel,
+		}
+		if err := state.repo.Create(&budget); err != nil {
+			return err
+		}
+		counts.Created++
+		return nil
+	})
+}
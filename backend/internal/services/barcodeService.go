@@ -0,0 +1,356 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/units"
+	"gorm.io/gorm"
+)
+
+// Erros da leitura de códigos de barras
+var (
+	// ErrUnknownBarcode indica um código válido que não corresponde a nenhum produto ou PLU cadastrado
+	ErrUnknownBarcode = errors.New("código de barras não cadastrado")
+	// ErrLayoutMismatch indica uma etiqueta de balança que não segue o leiaute do estabelecimento
+	ErrLayoutMismatch = errors.New("etiqueta de balança não corresponde ao leiaute")
+)
+
+// BarcodeReading é a interpretação de um código lido: o GTIN normalizado e, nas etiquetas de balança,
+// o PLU e o preço ou o peso impressos
+type BarcodeReading struct {
+	Code            string // GTIN-14
+	VariableMeasure bool
+	Layout          string
+	PLU             string
+	Price           *float64        // Preço total da etiqueta
+	Weight          *float64        // Peso em kg da etiqueta
+	Product         *models.Product // nil quando não cadastrado
+	UnitsPerPack    int             // Unidades do produto na embalagem lida (código adicional de embalagem múltipla)
+}
+
+// BarcodeService interpreta códigos de barras e mantém os PLUs das balanças
+type BarcodeService struct {
+	productService  *ProductService
+	storeRepository *repositories.StoreRepository
+	defaultLayout   barcode.Layout
+}
+
+// NewBarcodeService cria uma nova instância de BarcodeService; defaultLayout é usado pelos
+// estabelecimentos sem leiaute de etiqueta próprio
+func NewBarcodeService(
+	productService *ProductService,
+	storeRepository *repositories.StoreRepository,
+	defaultLayout barcode.Layout) *BarcodeService {
+	return &BarcodeService{
+		productService:  productService,
+		storeRepository: storeRepository,
+		defaultLayout:   defaultLayout,
+	}
+}
+
+// ReadBarcode interpreta um código com o leiaute e os PLUs do estabelecimento (storeID 0 = padrão)
+func (service *BarcodeService) ReadBarcode(code string, storeID uint, userID uint) (*BarcodeReading, error) {
+	var store *models.Store
+	if storeID != 0 {
+		var err error
+		store, err = service.storeRepository.GetVisibleStoreByID(storeID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ReadBarcode: estabelecimento não encontrado")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	reading, err := service.read(service.productService.productRepo, code, store, userID)
+	if err != nil {
+		return nil, errors.New("ReadBarcode: " + err.Error())
+	}
+	return reading, nil
+}
+
+// read normaliza o código e procura o produto: pelo GTIN-14 nos códigos comuns (o principal e depois
+// os adicionais) e pelo PLU (do estabelecimento e depois o geral) nas etiquetas de balança. Sugestões
+// pendentes de outros usuários contam como produto não cadastrado.
+func (service *BarcodeService) read(
+	productRepository *repositories.ProductRepository, code string, store *models.Store, userID uint) (*BarcodeReading, error) {
+	reading, err := service.lookup(productRepository, code, store)
+	if err != nil {
+		return nil, err
+	}
+	if reading.Product != nil && !productUsableBy(reading.Product, userID) {
+		reading.Product = nil
+		reading.UnitsPerPack = 1
+	}
+	return reading, nil
+}
+
+func (service *BarcodeService) lookup(
+	productRepository *repositories.ProductRepository, code string, store *models.Store) (*BarcodeReading, error) {
+	normalized, err := barcode.Normalize(code)
+	if err != nil {
+		return nil, err
+	}
+	reading := &BarcodeReading{Code: normalized, UnitsPerPack: 1}
+
+	if !barcode.IsVariableMeasure(normalized) {
+		product, err := productRepository.GetProductByBarcode(normalized)
+		if err == nil {
+			reading.Product = product
+			return reading, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		productBarcode, err := productRepository.GetProductBarcodeByCode(normalized)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if productBarcode != nil {
+			reading.Product = productBarcode.Product
+			reading.UnitsPerPack = productBarcode.UnitsPerPack
+		}
+		return reading, nil
+	}
+
+	layout := service.layoutFor(store)
+	measure, ok := layout.Decode(normalized)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrLayoutMismatch, layout.Pattern)
+	}
+	reading.VariableMeasure = true
+	reading.Layout = layout.Pattern
+	reading.PLU = measure.PLU
+	reading.Price = measure.Price
+	reading.Weight = measure.Weight
+
+	var storeID *uint
+	if store != nil {
+		storeID = &store.ID
+	}
+	productPLU, err := productRepository.FindPLU(measure.PLU, storeID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if productPLU != nil {
+		reading.Product = productPLU.Product
+	}
+	return reading, nil
+}
+
+// readImportedCode interpreta o código de um item importado (CSV, NFC-e ou cupom) com o leiaute do
+// estabelecimento da compra, já resolvido por quem importa. Códigos inválidos ou fora do leiaute
+// retornam nil, para que o item seja procurado pelo nome. O repositório pode estar em uma transação.
+func (service *BarcodeService) readImportedCode(
+	productRepository *repositories.ProductRepository, code string, store *models.Store, userID uint) (*BarcodeReading, error) {
+	if barcode.Validate(strings.TrimSpace(code)) != nil {
+		return nil, nil
+	}
+	reading, err := service.read(productRepository, code, store, userID)
+	if errors.Is(err, ErrLayoutMismatch) {
+		return nil, nil
+	}
+	return reading, err
+}
+
+// readPurchaseItem interpreta o código de um item de compra com o leiaute do estabelecimento da compra;
+// códigos sem produto cadastrado são recusados
+func (service *BarcodeService) readPurchaseItem(
+	tx *gorm.DB, code string, store *models.Store, userID uint) (*BarcodeReading, error) {
+	reading, err := service.read(service.productService.productRepo.WithTx(tx), code, store, userID)
+	if err != nil {
+		return nil, err
+	}
+	if reading.Product == nil {
+		if reading.VariableMeasure {
+			return nil, fmt.Errorf("%w: PLU %s", ErrUnknownBarcode, reading.PLU)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBarcode, reading.Code)
+	}
+	return reading, nil
+}
+
+// layoutFor retorna o leiaute de etiqueta do estabelecimento ou, sem um próprio, o padrão
+func (service *BarcodeService) layoutFor(store *models.Store) barcode.Layout {
+	if store != nil && store.BarcodeLayout != "" {
+		if layout, err := barcode.ParseLayout(store.BarcodeLayout); err == nil {
+			return layout
+		}
+	}
+	return service.defaultLayout
+}
+
+// applyBarcodeReading completa o item da compra com o produto e os valores da etiqueta de balança.
+// Quantidade e preço informados prevalecem: uma etiqueta de preço sem quantidade vira 1 un pelo preço
+// total, e com a quantidade (o peso) dá o preço por kg; uma etiqueta de peso dá a quantidade em kg.
+// Códigos de embalagem múltipla convertem o item para unidades do produto base.
+func applyBarcodeReading(itemDTO *dto.PurchaseItemDTO, reading *BarcodeReading) {
+	itemDTO.ProductID = reading.Product.ID
+	applyUnitsPerPack(itemDTO, reading.UnitsPerPack)
+	switch {
+	case reading.Weight != nil && itemDTO.Quantity == 0:
+		itemDTO.Quantity = *reading.Weight
+		itemDTO.Unit = string(units.Kilogram)
+	case reading.Price != nil && itemDTO.UnitPrice == 0:
+		if itemDTO.Quantity == 0 {
+			itemDTO.Quantity = 1
+			itemDTO.Unit = string(units.Each)
+		}
+		itemDTO.UnitPrice = *reading.Price / itemDTO.Quantity
+	}
+}
+
+// applyUnitsPerPack converte um item lido pelo código de uma embalagem múltipla para unidades do
+// produto base: 2 fardos de 6 a R$ 24,00 viram 12 un a R$ 4,00
+func applyUnitsPerPack(itemDTO *dto.PurchaseItemDTO, unitsPerPack int) {
+	if unitsPerPack <= 1 {
+		return
+	}
+	itemDTO.Quantity *= float64(unitsPerPack)
+	itemDTO.UnitPrice /= float64(unitsPerPack)
+	itemDTO.Unit = string(units.Each)
+}
+
+// CreatePLU cadastra o PLU de balança de um produto (apenas admin)
+func (service *BarcodeService) CreatePLU(pluDTO dto.CreateProductPLUDTO, userRole string) (*models.ProductPLU, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("CreatePLU: permissão negada: apenas administradores podem cadastrar PLUs")
+	}
+	plu := strings.TrimLeft(strings.TrimSpace(pluDTO.PLU), "0")
+	if plu == "" {
+		return nil, errors.New("CreatePLU: PLU inválido")
+	}
+
+	product, err := service.productService.productRepo.GetProductByID(pluDTO.ProductID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("CreatePLU: produto não encontrado")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pluDTO.StoreID != nil {
+		// PLUs valem para todos os usuários: só estabelecimentos compartilhados (userID 0) têm leiaute próprio
+		if _, err := service.storeRepository.GetVisibleStoreByID(*pluDTO.StoreID, 0); errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("CreatePLU: estabelecimento não encontrado")
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := service.productService.productRepo.GetPLU(plu, pluDTO.StoreID)
+	if err == nil {
+		return nil, fmt.Errorf("CreatePLU: PLU %s já cadastrado para o produto %d", plu, existing.ProductID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	productPLU := &models.ProductPLU{ProductID: product.ID, StoreID: pluDTO.StoreID, PLU: plu}
+	if err := service.productService.productRepo.CreatePLU(productPLU); err != nil {
+		return nil, err
+	}
+	productPLU.Product = product
+	return productPLU, nil
+}
+
+// GetPLUs lista os PLUs cadastrados, opcionalmente de um produto ou de um estabelecimento
+func (service *BarcodeService) GetPLUs(queryDTO dto.ProductPLUQueryDTO) ([]*models.ProductPLU, error) {
+	return service.productService.productRepo.GetPLUs(queryDTO.ProductID, queryDTO.StoreID)
+}
+
+// DeletePLU remove um PLU (apenas admin)
+func (service *BarcodeService) DeletePLU(id uint, userRole string) error {
+	if userRole != string(models.RoleAdmin) {
+		return errors.New("DeletePLU: permissão negada: apenas administradores podem remover PLUs")
+	}
+	if _, err := service.productService.productRepo.GetPLUByID(id); errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("DeletePLU: PLU não encontrado")
+	} else if err != nil {
+		return err
+	}
+	return service.productService.productRepo.DeletePLU(id)
+}
+
+// NormalizeBarcodes converte para GTIN-14 os códigos de barras válidos gravados antes da normalização.
+// Códigos inválidos ficam como estão; um código cujo GTIN-14 já pertence a outro produto é mantido e
+// registrado no log para revisão. Retorna quantos produtos foram alterados.
+func (service *BarcodeService) NormalizeBarcodes() (int, error) {
+	products, err := service.productService.productRepo.GetProductsWithBarcode()
+	if err != nil {
+		return 0, err
+	}
+
+	owners := make(map[string]uint, len(products))
+	for _, product := range products {
+		owners[*product.Barcode] = product.ID
+	}
+
+	normalizedCount := 0
+	for _, product := range products {
+		normalized, err := barcode.Normalize(*product.Barcode)
+		if err != nil || normalized == *product.Barcode {
+			continue
+		}
+		if owner, taken := owners[normalized]; taken && owner != product.ID {
+			log.Printf("código de barras %s do produto %d não normalizado: %s pertence ao produto %d",
+				*product.Barcode, product.ID, normalized, owner)
+			continue
+		}
+		if err := service.productService.productRepo.UpdateProductBarcode(product.ID, normalized); err != nil {
+			return normalizedCount, err
+		}
+		delete(owners, *product.Barcode)
+		owners[normalized] = product.ID
+		normalizedCount++
+	}
+	return normalizedCount, nil
+}
+
+// ToBarcodeReadingDTO converts a BarcodeReading to BarcodeReadingDTO
+func (service *BarcodeService) ToBarcodeReadingDTO(reading *BarcodeReading) dto.BarcodeReadingDTO {
+	readingDTO := dto.BarcodeReadingDTO{
+		Code:            reading.Code,
+		VariableMeasure: reading.VariableMeasure,
+		Layout:          reading.Layout,
+		PLU:             reading.PLU,
+		Price:           reading.Price,
+		Weight:          reading.Weight,
+		UnitsPerPack:    reading.UnitsPerPack,
+	}
+	if reading.Product != nil {
+		productDTO := service.productService.ToProductResponseDTO(reading.Product)
+		readingDTO.Product = &productDTO
+	}
+	return readingDTO
+}
+
+// ToProductPLUResponseDTO converts a ProductPLU model to ProductPLUResponseDTO
+func (service *BarcodeService) ToProductPLUResponseDTO(plu *models.ProductPLU) dto.ProductPLUResponseDTO {
+	pluDTO := dto.ProductPLUResponseDTO{
+		ID:        plu.ID,
+		ProductID: plu.ProductID,
+		StoreID:   plu.StoreID,
+		PLU:       plu.PLU,
+		CreatedAt: plu.CreatedAt.Format(time.RFC3339),
+	}
+	if plu.Product != nil {
+		pluDTO.ProductName = plu.Product.Name
+	}
+	return pluDTO
+}
+
+// ToProductPLUResponseDTOList converts a list of ProductPLU models to ProductPLUResponseDTOs
+func (service *BarcodeService) ToProductPLUResponseDTOList(plus []*models.ProductPLU) []dto.ProductPLUResponseDTO {
+	dtos := make([]dto.ProductPLUResponseDTO, len(plus))
+	for i, plu := range plus {
+		dtos[i] = service.ToProductPLUResponseDTO(plu)
+	}
+	return dtos
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Situações possíveis de um orçamento no mês
+const (
+	BudgetStatusOK       = "ok"
+	BudgetStatusWarning  = "warning"
+	BudgetStatusExceeded = "exceeded"
+)
+
+// budgetPeriodLayout é o formato usado para identificar o mês de um orçamento
+const budgetPeriodLayout = "2006-01"
+
+// BudgetService handles business logic for monthly budgets
+type BudgetService struct {
+	budgetRepository *repositories.BudgetRepository
+	categoryService  *CategoryService
+	notifier         Notifier
+}
+
+// NewBudgetService creates a new instance of BudgetService
+func NewBudgetService(
+	budgetRepo *repositories.BudgetRepository,
+	categoryService *CategoryService,
+	notifier Notifier) *BudgetService {
+	return &BudgetService{
+		budgetRepository: budgetRepo,
+		categoryService:  categoryService,
+		notifier:         notifier,
+	}
+}
+
+// CreateBudget creates a new monthly budget for a category or for the total
+func (service *BudgetService) CreateBudget(createDTO dto.CreateBudgetDTO, userID uint) (*models.Budget, error) {
+	if createDTO.CategoryID != nil {
+		category, err := service.categoryService.GetCategoryByID(*createDTO.CategoryID)
+		if err != nil {
+			return nil, errors.New("CreateBudget: categoria não encontrada")
+		}
+		if category.UserID != userID {
+			return nil, errors.New("CreateBudget: esta categoria não pertence ao usuário")
+		}
+	}
+
+	// Apenas um orçamento por categoria (ou um orçamento total) por usuário
+	_, err := service.budgetRepository.GetBudgetByUserAndCategory(userID, createDTO.CategoryID)
+	if err == nil {
+		return nil, errors.New("CreateBudget: já existe um orçamento para esta categoria")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	budget := &models.Budget{
+		UserID:            userID,
+		CategoryID:        createDTO.CategoryID,
+		MonthlyLimit:      utils.FormatDecimal(createDTO.MonthlyLimit),
+		WarningThreshold:  80,
+		RolloverEnabled:   createDTO.RolloverEnabled,
+		RolloverMaxMonths: 1,
+	}
+	if createDTO.WarningThreshold != nil {
+		budget.WarningThreshold = *createDTO.WarningThreshold
+	}
+	if createDTO.RolloverMaxMonths != nil {
+		budget.RolloverMaxMonths = *createDTO.RolloverMaxMonths
+	}
+
+	if err := service.budgetRepository.CreateBudget(budget); err != nil {
+		return nil, err
+	}
+
+	return service.budgetRepository.GetBudgetByID(budget.ID)
+}
+
+// GetBudgetByID retrieves a budget by its ID
+func (service *BudgetService) GetBudgetByID(budgetID uint, userID uint, userRole string) (*models.Budget, error) {
+	budget, err := service.budgetRepository.GetBudgetByID(budgetID)
+	if err != nil {
+		return nil, errors.New("GetBudgetByID: orçamento não encontrado")
+	}
+
+	if budget.UserID != userID && userRole != string(models.RoleAdmin) {
+		return nil, errors.New("GetBudgetByID: permissão negada: você não pode visualizar orçamentos de outros usuários")
+	}
+
+	return budget, nil
+}
+
+// GetBudgetsByUserID retrieves all budgets for a specific user
+func (service *BudgetService) GetBudgetsByUserID(userID uint) ([]*models.Budget, error) {
+	return service.budgetRepository.GetBudgetsByUserID(userID)
+}
+
+// UpdateBudget updates the limit, warning threshold and rollover settings of a budget
+func (service *BudgetService) UpdateBudget(
+	budgetID uint, updateDTO dto.UpdateBudgetDTO, userID uint, userRole string) (*models.Budget, error) {
+
+	budget, err := service.budgetRepository.GetBudgetByID(budgetID)
+	if err != nil {
+		return nil, errors.New("UpdateBudget: orçamento não encontrado")
+	}
+
+	if budget.UserID != userID && userRole != string(models.RoleAdmin) {
+		return nil, errors.New("UpdateBudget: permissão negada: você não pode atualizar orçamentos de outros usuários")
+	}
+
+	if updateDTO.MonthlyLimit != nil {
+		budget.MonthlyLimit = utils.FormatDecimal(*updateDTO.MonthlyLimit)
+	}
+	if updateDTO.WarningThreshold != nil {
+		budget.WarningThreshold = *updateDTO.WarningThreshold
+	}
+	if updateDTO.RolloverEnabled != nil {
+		budget.RolloverEnabled = *updateDTO.RolloverEnabled
+	}
+	if updateDTO.RolloverMaxMonths != nil {
+		budget.RolloverMaxMonths = *updateDTO.RolloverMaxMonths
+	}
+
+	// Limite ou limiar alterados: permite que o alerta seja reavaliado no mês corrente
+	budget.LastAlertPeriod = ""
+	budget.LastAlertLevel = ""
+
+	if err := service.budgetRepository.UpdateBudget(budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget
+func (service *BudgetService) DeleteBudget(budgetID uint, userID uint, userRole string) error {
+	budget, err := service.budgetRepository.GetBudgetByID(budgetID)
+	if err != nil {
+		return errors.New("DeleteBudget: orçamento não encontrado")
+	}
+
+	if budget.UserID != userID && userRole != string(models.RoleAdmin) {
+		return errors.New("DeleteBudget: permissão negada: você não pode excluir orçamentos de outros usuários")
+	}
+
+	return service.budgetRepository.DeleteBudget(budgetID)
+}
+
+// ParseBudgetPeriod converte "YYYY-MM" no primeiro instante do mês; string vazia resulta no mês corrente
+func ParseBudgetPeriod(period string) (time.Time, error) {
+	if period == "" {
+		return startOfMonth(time.Now()), nil
+	}
+	month, err := time.ParseInLocation(budgetPeriodLayout, period, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("ParseBudgetPeriod: período inválido, use o formato YYYY-MM")
+	}
+	return month, nil
+}
+
+// GetBudgetStatus calcula orçamento vs gasto real de um orçamento no mês informado
+func (service *BudgetService) GetBudgetStatus(
+	budgetID uint, month time.Time, userID uint, userRole string) (*dto.BudgetStatusDTO, error) {
+
+	budget, err := service.GetBudgetByID(budgetID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.calculateBudgetStatus(budget, startOfMonth(month), time.Now())
+}
+
+// GetBudgetStatusesByUserID calcula a situação de todos os orçamentos do usuário no mês informado
+func (service *BudgetService) GetBudgetStatusesByUserID(userID uint, month time.Time) ([]dto.BudgetStatusDTO, error) {
+	budgets, err := service.budgetRepository.GetBudgetsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]dto.BudgetStatusDTO, 0, len(budgets))
+	for _, budget := range budgets {
+		status, err := service.calculateBudgetStatus(budget, startOfMonth(month), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// CheckBudgetAlerts reavalia os orçamentos do usuário no mês da compra e notifica quando
+// o limiar de aviso ou o limite forem atingidos. Cada nível é notificado uma única vez por mês.
+func (service *BudgetService) CheckBudgetAlerts(userID uint, purchaseDate time.Time) error {
+	if service.notifier == nil {
+		return nil
+	}
+
+	month := startOfMonth(purchaseDate)
+	period := month.Format(budgetPeriodLayout)
+
+	budgets, err := service.budgetRepository.GetBudgetsByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, budget := range budgets {
+		status, err := service.calculateBudgetStatus(budget, month, time.Now())
+		if err != nil {
+			return err
+		}
+		if status.Status == BudgetStatusOK {
+			continue
+		}
+
+		// Já notificado neste mês com o mesmo nível (ou mais grave)
+		if budget.LastAlertPeriod == period && budgetAlertRank(budget.LastAlertLevel) >= budgetAlertRank(status.Status) {
+			continue
+		}
+
+		if err := service.notifier.Notify(service.buildBudgetNotification(budget, status)); err != nil {
+			return err
+		}
+
+		budget.LastAlertPeriod = period
+		budget.LastAlertLevel = status.Status
+		if err := service.budgetRepository.UpdateBudget(budget); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calculateBudgetStatus aplica rollover, soma os gastos do mês e projeta o gasto de fim de mês
+func (service *BudgetService) calculateBudgetStatus(
+	budget *models.Budget, month time.Time, now time.Time) (*dto.BudgetStatusDTO, error) {
+
+	monthEnd := month.AddDate(0, 1, 0)
+
+	// Meses anteriores só são considerados quando o rollover está ativo
+	from := month
+	if budget.RolloverEnabled && budget.RolloverMaxMonths > 0 {
+		from = month.AddDate(0, -budget.RolloverMaxMonths, 0)
+		if createdMonth := startOfMonth(budget.CreatedAt); from.Before(createdMonth) {
+			from = createdMonth
+		}
+		if from.After(month) {
+			from = month
+		}
+	}
+
+	spending, err := service.budgetRepository.GetSpendingByPurchase(budget.UserID, budget.CategoryID, from, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	spentByMonth := make(map[string]float64)
+	for _, row := range spending {
+		spentByMonth[row.PurchaseDate.In(time.Local).Format(budgetPeriodLayout)] += row.Total
+	}
+
+	// Saldo não utilizado é carregado mês a mês dentro da janela de rollover
+	var rollover float64 = 0
+	for current := from; current.Before(month); current = current.AddDate(0, 1, 0) {
+		available := budget.MonthlyLimit + rollover
+		rollover = available - spentByMonth[current.Format(budgetPeriodLayout)]
+		if rollover < 0 {
+			rollover = 0
+		}
+	}
+
+	available := budget.MonthlyLimit + rollover
+	spent := spentByMonth[month.Format(budgetPeriodLayout)]
+
+	// Projeção linear com base no ritmo de gasto do mês
+	var projected float64
+	switch {
+	case now.Before(month):
+		projected = 0
+	case !now.Before(monthEnd):
+		projected = spent
+	default:
+		elapsedDays := now.Sub(month).Hours() / 24
+		if elapsedDays < 1 {
+			elapsedDays = 1
+		}
+		totalDays := monthEnd.Sub(month).Hours() / 24
+		projected = spent / elapsedDays * totalDays
+	}
+
+	var percentUsed float64 = 0
+	if available > 0 {
+		percentUsed = spent / available * 100
+	}
+
+	status := BudgetStatusOK
+	if spent > available {
+		status = BudgetStatusExceeded
+	} else if percentUsed >= budget.WarningThreshold {
+		status = BudgetStatusWarning
+	}
+
+	return &dto.BudgetStatusDTO{
+		Budget:           service.ToBudgetResponseDTO(budget),
+		Period:           month.Format(budgetPeriodLayout),
+		RolloverAmount:   utils.FormatForDisplay(rollover),
+		AvailableAmount:  utils.FormatForDisplay(available),
+		SpentAmount:      utils.FormatForDisplay(spent),
+		RemainingAmount:  utils.FormatForDisplay(available - spent),
+		PercentUsed:      utils.FormatForDisplay(percentUsed),
+		ProjectedSpend:   utils.FormatForDisplay(projected),
+		ProjectedOverrun: projected > available,
+		Status:           status,
+	}, nil
+}
+
+// buildBudgetNotification monta a notificação de alerta de orçamento
+func (service *BudgetService) buildBudgetNotification(budget *models.Budget, status *dto.BudgetStatusDTO) Notification {
+	notificationType := NotificationBudgetWarning
+	title := "Orçamento próximo do limite"
+	if status.Status == BudgetStatusExceeded {
+		notificationType = NotificationBudgetExceeded
+		title = "Orçamento estourado"
+	}
+
+	return Notification{
+		UserID: budget.UserID,
+		Type:   notificationType,
+		Title:  title,
+		Message: fmt.Sprintf("%s: R$ %.2f de R$ %.2f utilizados em %s (%.0f%%)",
+			status.Budget.CategoryName, status.SpentAmount, status.AvailableAmount, status.Period, status.PercentUsed),
+		Data: map[string]interface{}{
+			"budgetId":        budget.ID,
+			"categoryId":      budget.CategoryID,
+			"period":          status.Period,
+			"spentAmount":     status.SpentAmount,
+			"availableAmount": status.AvailableAmount,
+			"projectedSpend":  status.ProjectedSpend,
+		},
+	}
+}
+
+// budgetAlertRank ordena os níveis de alerta por gravidade
+func budgetAlertRank(level string) int {
+	switch level {
+	case BudgetStatusWarning:
+		return 1
+	case BudgetStatusExceeded:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// startOfMonth retorna o primeiro instante do mês da data informada (fuso do servidor)
+func startOfMonth(date time.Time) time.Time {
+	local := date.In(time.Local)
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+// ToBudgetResponseDTO converts a Budget model to BudgetResponseDTO
+func (service *BudgetService) ToBudgetResponseDTO(budget *models.Budget) dto.BudgetResponseDTO {
+	categoryName := "Total"
+	if budget.Category != nil {
+		categoryName = budget.Category.Name
+	}
+
+	return dto.BudgetResponseDTO{
+		ID:                budget.ID,
+		UserID:            budget.UserID,
+		CategoryID:        budget.CategoryID,
+		CategoryName:      categoryName,
+		MonthlyLimit:      utils.FormatForDisplay(budget.MonthlyLimit),
+		WarningThreshold:  utils.FormatForDisplay(budget.WarningThreshold),
+		RolloverEnabled:   budget.RolloverEnabled,
+		RolloverMaxMonths: budget.RolloverMaxMonths,
+		CreatedAt:         budget.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         budget.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToBudgetResponseDTOList converts a list of Budget models to BudgetResponseDTOs
+func (service *BudgetService) ToBudgetResponseDTOList(budgets []*models.Budget) []dto.BudgetResponseDTO {
+	dtos := make([]dto.BudgetResponseDTO, len(budgets))
+	for i, budget := range budgets {
+		dtos[i] = service.ToBudgetResponseDTO(budget)
+	}
+	return dtos
+}
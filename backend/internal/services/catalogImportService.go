@@ -0,0 +1,512 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/openfoodfacts"
+	"github.com/Parron01/AppMercado/backend/pkg/units"
+	"gorm.io/gorm"
+)
+
+const (
+	// ImportKindOpenFoodFacts identifica as importações de catálogo do Open Food Facts
+	ImportKindOpenFoodFacts = "openfoodfacts"
+
+	defaultCatalogBatchSize = 1000
+	catalogImportJobsLimit  = 50
+	maxCatalogErrorLength   = 1000
+
+	// Duração da concessão de execução e intervalo de renovação enquanto a importação roda
+	catalogImportLease        = 2 * time.Minute
+	catalogImportLeaseRenewal = 30 * time.Second
+)
+
+// ErrCatalogImportRunning é retornado quando já há uma importação do catálogo em execução, nesta
+// instância, em outra ou no cmd/offimport: duas importações simultâneas disputariam os mesmos produtos
+var ErrCatalogImportRunning = errors.New("já existe uma importação do catálogo em execução")
+
+// ProgressFunc recebe o estado da importação após cada lote gravado
+type ProgressFunc func(job *models.ImportJob)
+
+// CatalogImportService imports products in bulk from Open Food Facts dumps.
+// Jobs are checkpointed after every batch and can be resumed after a failure or restart.
+type CatalogImportService struct {
+	importJobRepository *repositories.ImportJobRepository
+	productRepository   *repositories.ProductRepository
+	importDir           string
+
+	mutex   sync.Mutex
+	running map[uint]context.CancelFunc
+}
+
+// NewCatalogImportService creates a new instance of CatalogImportService.
+// importDir restricts which files can be imported through the API.
+func NewCatalogImportService(
+	importJobRepo *repositories.ImportJobRepository,
+	productRepo *repositories.ProductRepository,
+	importDir string) *CatalogImportService {
+	return &CatalogImportService{
+		importJobRepository: importJobRepo,
+		productRepository:   productRepo,
+		importDir:           importDir,
+		running:             make(map[uint]context.CancelFunc),
+	}
+}
+
+// StartOpenFoodFactsImport cria a importação de um dump do diretório de importação e a executa em segundo plano
+func (service *CatalogImportService) StartOpenFoodFactsImport(
+	startDTO dto.StartCatalogImportDTO, userID uint, userRole string) (*models.ImportJob, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("StartOpenFoodFactsImport: permissão negada: apenas administradores podem importar o catálogo")
+	}
+
+	// O arquivo deve estar dentro do diretório configurado (nada de caminhos absolutos ou "..")
+	name := filepath.Clean(startDTO.File)
+	if filepath.IsAbs(name) || name == "." || strings.HasPrefix(name, "..") {
+		return nil, errors.New("StartOpenFoodFactsImport: arquivo inválido: informe o nome relativo ao diretório de importação")
+	}
+
+	if err := service.checkNoActiveImport("StartOpenFoodFactsImport"); err != nil {
+		return nil, err
+	}
+	job, err := service.CreateOpenFoodFactsJob(filepath.Join(service.importDir, name), startDTO.Format, startDTO.Country, startDTO.BatchSize, userID)
+	if err != nil {
+		return nil, err
+	}
+	service.runInBackground(job.ID)
+	return job, nil
+}
+
+// CreateOpenFoodFactsJob registra uma nova importação pendente (usado pela API e pela linha de comando)
+func (service *CatalogImportService) CreateOpenFoodFactsJob(
+	source string, format string, country string, batchSize int, userID uint) (*models.ImportJob, error) {
+	if format == "" {
+		detected, err := openfoodfacts.DetectFormat(source)
+		if err != nil {
+			return nil, errors.New("CreateOpenFoodFactsJob: " + err.Error())
+		}
+		format = string(detected)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultCatalogBatchSize
+	}
+
+	// Falha cedo se o arquivo não existir ou não puder ser lido
+	reader, err := openfoodfacts.OpenDump(source, openfoodfacts.Format(format), 0)
+	if err != nil {
+		return nil, errors.New("CreateOpenFoodFactsJob: " + err.Error())
+	}
+	totalBytes := reader.Size()
+	reader.Close()
+
+	job := &models.ImportJob{
+		Kind:        ImportKindOpenFoodFacts,
+		Source:      source,
+		Format:      format,
+		Country:     strings.TrimSpace(country),
+		BatchSize:   batchSize,
+		Status:      models.ImportJobPending,
+		CreatedByID: userID,
+		TotalBytes:  totalBytes,
+	}
+	if err := service.importJobRepository.CreateImportJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ResumeImportJob retoma uma importação interrompida, com falha ou cancelada a partir do último checkpoint
+func (service *CatalogImportService) ResumeImportJob(jobID uint, userRole string) (*models.ImportJob, error) {
+	job, err := service.GetImportJob(jobID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case models.ImportJobFailed, models.ImportJobInterrupted, models.ImportJobCancelled:
+	default:
+		return nil, errors.New("ResumeImportJob: apenas importações interrompidas, com falha ou canceladas podem ser retomadas")
+	}
+	if err := service.checkNoActiveImport("ResumeImportJob"); err != nil {
+		return nil, err
+	}
+
+	job.Status = models.ImportJobPending
+	if err := service.importJobRepository.UpdateImportJob(job); err != nil {
+		return nil, err
+	}
+	service.runInBackground(job.ID)
+	return job, nil
+}
+
+// CancelImportJob interrompe uma importação em execução; o último lote gravado é mantido
+func (service *CatalogImportService) CancelImportJob(jobID uint, userRole string) error {
+	if userRole != string(models.RoleAdmin) {
+		return errors.New("CancelImportJob: permissão negada: apenas administradores podem cancelar importações")
+	}
+
+	service.mutex.Lock()
+	cancel, ok := service.running[jobID]
+	service.mutex.Unlock()
+	if !ok {
+		return errors.New("CancelImportJob: importação não está em execução")
+	}
+	cancel()
+	return nil
+}
+
+// GetImportJob retorna uma importação (apenas admin)
+func (service *CatalogImportService) GetImportJob(jobID uint, userRole string) (*models.ImportJob, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("GetImportJob: permissão negada: apenas administradores podem consultar importações")
+	}
+	job, err := service.importJobRepository.GetImportJobByID(jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job.Kind != ImportKindOpenFoodFacts) {
+		return nil, errors.New("GetImportJob: importação não encontrada")
+	}
+	return job, err
+}
+
+// GetImportJobs lista as importações mais recentes (apenas admin)
+func (service *CatalogImportService) GetImportJobs(userRole string) ([]models.ImportJob, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("GetImportJobs: permissão negada: apenas administradores podem consultar importações")
+	}
+	return service.importJobRepository.GetImportJobs(ImportKindOpenFoodFacts, catalogImportJobsLimit)
+}
+
+// checkNoActiveImport recusa iniciar uma importação enquanto outra está em execução. É só uma
+// verificação antecipada, para responder com erro: a exclusão é garantida por ClaimImportJob.
+func (service *CatalogImportService) checkNoActiveImport(funcName string) error {
+	active, err := service.importJobRepository.CountActiveImportJobs(ImportKindOpenFoodFacts, time.Now())
+	if err != nil {
+		return err
+	}
+	if active > 0 {
+		return fmt.Errorf("%s: %w", funcName, ErrCatalogImportRunning)
+	}
+	return nil
+}
+
+// runInBackground executa a importação em uma goroutine; o erro fica registrado no próprio job.
+// Sem a concessão (outra importação começou antes), o job pendente é marcado como falho.
+func (service *CatalogImportService) runInBackground(jobID uint) {
+	go func() {
+		err := service.RunImportJob(context.Background(), jobID, nil)
+		if !errors.Is(err, ErrCatalogImportRunning) {
+			return
+		}
+		job, getErr := service.importJobRepository.GetImportJobByID(jobID)
+		if getErr != nil || job.Status != models.ImportJobPending {
+			return
+		}
+		job.Status = models.ImportJobFailed
+		job.LastError = err.Error()
+		_ = service.importJobRepository.UpdateImportJob(job)
+	}()
+}
+
+// RunImportJob executa (ou retoma) a importação de forma síncrona até o fim do arquivo, um erro
+// ou o cancelamento do contexto. Cada lote é gravado com upsert pelo código de barras e o checkpoint
+// é salvo logo em seguida, de modo que a retomada nunca perde nem repete um lote inteiro.
+func (service *CatalogImportService) RunImportJob(ctx context.Context, jobID uint, progress ProgressFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	service.mutex.Lock()
+	if _, running := service.running[jobID]; running {
+		service.mutex.Unlock()
+		return errors.New("RunImportJob: a importação já está em execução")
+	}
+	service.running[jobID] = cancel
+	service.mutex.Unlock()
+	defer func() {
+		service.mutex.Lock()
+		delete(service.running, jobID)
+		service.mutex.Unlock()
+	}()
+
+	// A concessão impede que a API e o cmd/offimport (ou duas instâncias) importem ao mesmo tempo
+	now := time.Now()
+	claimed, err := service.importJobRepository.ClaimImportJob(jobID, ImportKindOpenFoodFacts, now, now.Add(catalogImportLease))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("RunImportJob: importação não encontrada")
+	}
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("RunImportJob: %w", ErrCatalogImportRunning)
+	}
+	stopRenewal := service.renewLease(ctx, jobID)
+	defer stopRenewal()
+
+	job, err := service.importJobRepository.GetImportJobByID(jobID)
+	if err != nil {
+		return err
+	}
+
+	job.Status = models.ImportJobRunning
+	job.LastError = ""
+	job.FinishedAt = nil
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	if err := service.importJobRepository.UpdateImportJob(job); err != nil {
+		return err
+	}
+
+	runErr := service.importRecords(ctx, job, progress)
+	stopRenewal()
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.LeaseExpiresAt = nil
+	switch {
+	case runErr == nil:
+		job.Status = models.ImportJobCompleted
+	case errors.Is(runErr, context.Canceled):
+		job.Status = models.ImportJobCancelled
+	default:
+		job.Status = models.ImportJobFailed
+		job.LastError = truncateText(runErr.Error(), maxCatalogErrorLength)
+	}
+	if err := service.importJobRepository.UpdateImportJob(job); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(job)
+	}
+	return runErr
+}
+
+// renewLease prorroga a concessão da importação em segundo plano até a função retornada ser chamada
+// (ou o contexto acabar). Uma renovação que falha é tentada de novo no próximo intervalo.
+func (service *CatalogImportService) renewLease(ctx context.Context, jobID uint) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(catalogImportLeaseRenewal)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				_ = service.importJobRepository.RenewImportJobLease(jobID, now.Add(catalogImportLease))
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// importRecords lê o dump a partir do checkpoint e grava os produtos em lotes
+func (service *CatalogImportService) importRecords(ctx context.Context, job *models.ImportJob, progress ProgressFunc) error {
+	format := openfoodfacts.Format(job.Format)
+
+	// Arquivos não compactados retomam pela posição; compactados descartam os registros já processados
+	resumeOffset := int64(0)
+	compressed := strings.HasSuffix(strings.ToLower(job.Source), ".gz")
+	if !compressed {
+		resumeOffset = job.ResumeOffset
+	}
+	reader, err := openfoodfacts.OpenDump(job.Source, format, resumeOffset)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if compressed && job.ProcessedRows > 0 {
+		if err := reader.Skip(job.ProcessedRows); err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	batch := make([]models.Product, 0, job.BatchSize)
+	positions := make(map[string]int, job.BatchSize) // Código de barras -> posição no lote
+	var pendingRows, pendingSkipped, pendingErrors int64
+
+	flush := func() error {
+		// Códigos já cadastrados como adicionais de um produto não viram produtos novos, e os de
+		// sugestões pendentes ficam com o moderador: a importação não aprova nem altera a sugestão
+		codes := make([]string, len(batch))
+		for i := range batch {
+			codes[i] = *batch[i].Barcode
+		}
+		registered, err := service.productRepository.GetRegisteredBarcodes(codes)
+		if err != nil {
+			return err
+		}
+		pendingProposals, err := service.productRepository.GetPendingProposalBarcodes(codes)
+		if err != nil {
+			return err
+		}
+		if len(registered) > 0 || len(pendingProposals) > 0 {
+			kept := batch[:0]
+			for _, product := range batch {
+				if registered[*product.Barcode] || pendingProposals[*product.Barcode] {
+					pendingSkipped++
+					continue
+				}
+				kept = append(kept, product)
+			}
+			batch = kept
+		}
+
+		imported, err := service.productRepository.UpsertProductsByBarcode(batch)
+		if err != nil {
+			return err
+		}
+		job.ProcessedRows += pendingRows
+		job.SkippedRows += pendingSkipped
+		job.ErrorRows += pendingErrors
+		job.ImportedRows += imported
+		job.ResumeOffset = reader.Offset()
+		job.ProcessedBytes = reader.BytesRead()
+		leaseUntil := time.Now().Add(catalogImportLease) // O checkpoint também renova a concessão
+		job.LeaseExpiresAt = &leaseUntil
+		if err := service.importJobRepository.UpdateImportJob(job); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(job)
+		}
+
+		batch = batch[:0]
+		clear(positions)
+		pendingRows, pendingSkipped, pendingErrors = 0, 0, 0
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, openfoodfacts.ErrMalformedRecord) {
+			// Linha malformada: é contada e ignorada, a leitura segue na próxima
+			pendingRows++
+			pendingErrors++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pendingRows++
+
+		product, ok := catalogProductFromRecord(record, job.Country)
+		if !ok {
+			pendingSkipped++
+			continue
+		}
+
+		// O mesmo código duas vezes no lote quebraria o ON CONFLICT: vale o último registro
+		if position, duplicated := positions[*product.Barcode]; duplicated {
+			batch[position] = product
+			pendingSkipped++
+		} else {
+			positions[*product.Barcode] = len(batch)
+			batch = append(batch, product)
+		}
+
+		if len(batch) >= job.BatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	job.ProcessedBytes = job.TotalBytes
+	return flush()
+}
+
+// catalogProductFromRecord converte um registro do dump, com o código em GTIN-14; registros sem GTIN
+// válido, com código de etiqueta de balança, sem nome ou de outros países são ignorados
+func catalogProductFromRecord(record *openfoodfacts.Record, country string) (models.Product, bool) {
+	code, err := barcode.Normalize(record.Barcode)
+	if err != nil || barcode.IsVariableMeasure(code) {
+		return models.Product{}, false
+	}
+	if record.Name == "" || !record.MatchesCountry(country) {
+		return models.Product{}, false
+	}
+
+	product := models.Product{
+		Name:         truncateText(record.Name, 255),
+		Barcode:      &code,
+		Brand:        truncateText(record.Brand, 255),
+		PackageLabel: truncateText(record.Quantity, 100),
+		ImageURL:     truncateText(record.ImageURL, 500),
+		Status:       models.ProductApproved,
+	}
+	if quantity, unit, ok := units.ParseLabel(record.Quantity); ok {
+		product.PackageQuantity, product.Unit = &quantity, string(unit)
+	}
+	return product, true
+}
+
+// truncateText limita o texto ao tamanho da coluna sem cortar caracteres multibyte ao meio
+func truncateText(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(runes[:maxLength])
+}
+
+// ToImportJobResponseDTO converts an ImportJob model to ImportJobResponseDTO
+func (service *CatalogImportService) ToImportJobResponseDTO(job *models.ImportJob) dto.ImportJobResponseDTO {
+	response := dto.ImportJobResponseDTO{
+		ID:            job.ID,
+		Kind:          job.Kind,
+		Source:        filepath.Base(job.Source),
+		Format:        job.Format,
+		Country:       job.Country,
+		BatchSize:     job.BatchSize,
+		Status:        string(job.Status),
+		ProcessedRows: job.ProcessedRows,
+		ImportedRows:  job.ImportedRows,
+		SkippedRows:   job.SkippedRows,
+		ErrorRows:     job.ErrorRows,
+		LastError:     job.LastError,
+		CreatedAt:     job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.TotalBytes > 0 {
+		response.Progress = float64(job.ProcessedBytes*10000/job.TotalBytes) / 100
+	}
+	if job.StartedAt != nil {
+		response.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if job.FinishedAt != nil {
+		response.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// ToImportJobResponseDTOList converts a list of ImportJob models to ImportJobResponseDTOs
+func (service *CatalogImportService) ToImportJobResponseDTOList(jobs []models.ImportJob) []dto.ImportJobResponseDTO {
+	dtos := make([]dto.ImportJobResponseDTO, len(jobs))
+	for i := range jobs {
+		dtos[i] = service.ToImportJobResponseDTO(&jobs[i])
+	}
+	return dtos
+}
@@ -2,20 +2,34 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Parron01/AppMercado/backend/internal/dto"
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
 )
 
+// maxCategoryDepth é o número máximo de níveis da árvore de categorias (a raiz é o nível 1)
+const maxCategoryDepth = 5
+
 type CategoryService struct {
 	categoryRepository *repositories.CategoryRepository
+	budgetRepository   *repositories.BudgetRepository
+	transactionManager *repositories.TransactionManager
 }
 
-func NewCategoryService(categoryRepo *repositories.CategoryRepository) *CategoryService {
+func NewCategoryService(
+	categoryRepo *repositories.CategoryRepository,
+	budgetRepo *repositories.BudgetRepository,
+	transactionManager *repositories.TransactionManager) *CategoryService {
 	return &CategoryService{
 		categoryRepository: categoryRepo,
+		budgetRepository:   budgetRepo,
+		transactionManager: transactionManager,
 	}
 }
 
@@ -25,17 +39,50 @@ func (service *CategoryService) CreateCategory(categoryDTO dto.CreateCategoryDTO
 		return nil, errors.New("CreateCategory: nome é obrigatório")
 	}
 
-	// Criar categoria
-	newCategory := &models.Category{
-		Name:   categoryDTO.Name,
-		UserID: userID,
-	}
+	var newCategory *models.Category
+	err := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		categoryRepository := service.categoryRepository.WithTx(tx)
 
-	// Salvar no banco
-	if err := service.categoryRepository.CreateCategory(newCategory); err != nil {
+		// Com a árvore travada, a profundidade validada e a posição calculada não mudam até o commit
+		if err := categoryRepository.LockCategoryTree(userID); err != nil {
+			return err
+		}
+
+		// Subcategorias só podem ficar sob categorias do próprio usuário, respeitando a profundidade máxima
+		if categoryDTO.ParentID != nil {
+			parent, err := categoryRepository.GetCategoryByID(*categoryDTO.ParentID)
+			if err != nil || parent.UserID != userID {
+				return errors.New("CreateCategory: categoria-pai não encontrada")
+			}
+			ancestorIDs, err := categoryRepository.GetCategoryAncestorIDs(parent.ID)
+			if err != nil {
+				return err
+			}
+			if err := checkCategoryParent(0, ancestorIDs, 0); err != nil {
+				return errors.New("CreateCategory: " + err.Error())
+			}
+		}
+
+		// A nova categoria entra no fim das irmãs
+		position, err := categoryRepository.NextCategoryPosition(userID, categoryDTO.ParentID)
+		if err != nil {
+			return err
+		}
+
+		// Criar categoria
+		newCategory = &models.Category{
+			Name:     categoryDTO.Name,
+			UserID:   userID,
+			ParentID: categoryDTO.ParentID,
+			Position: position,
+		}
+
+		// Salvar no banco
+		return categoryRepository.CreateCategory(newCategory)
+	})
+	if err != nil {
 		return nil, err
 	}
-
 	return newCategory, nil
 }
 
@@ -82,8 +129,347 @@ func (service *CategoryService) DeleteCategory(categoryID uint, userID uint, use
 		return errors.New("DeleteCategory: permissão negada: você não pode deletar categorias de outros usuários")
 	}
 
-	// Deletar do banco
-	return service.categoryRepository.DeleteCategory(categoryID)
+	// As subcategorias sobem um nível, no fim das irmãs da categoria removida; os orçamentos da
+	// categoria são removidos junto, para não ficarem apontando para uma categoria apagada
+	return service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		categoryRepository := service.categoryRepository.WithTx(tx)
+		children, err := categoryRepository.GetChildCategories(category.UserID, &category.ID)
+		if err != nil {
+			return err
+		}
+		position, err := categoryRepository.NextCategoryPosition(category.UserID, category.ParentID)
+		if err != nil {
+			return err
+		}
+		for i, child := range children {
+			if err := categoryRepository.UpdateCategoryPlacement(child.ID, category.ParentID, position+i); err != nil {
+				return err
+			}
+		}
+
+		if err := service.budgetRepository.WithTx(tx).DeleteBudgetsByCategoryID(category.ID); err != nil {
+			return err
+		}
+
+		// Deletar do banco
+		return categoryRepository.DeleteCategory(categoryID)
+	})
+}
+
+// MoveCategory muda a categoria (com suas subcategorias) de categoria-pai e/ou de posição entre as irmãs.
+// Recusa mover a categoria para dentro de si mesma ou de uma de suas subcategorias.
+func (service *CategoryService) MoveCategory(
+	categoryID uint, moveDTO dto.MoveCategoryDTO, userID uint, userRole string) (*models.Category, error) {
+	category, err := service.categoryRepository.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, errors.New("MoveCategory: categoria não encontrada")
+	}
+
+	// Verificar permissão: apenas o próprio usuário ou admin pode mover
+	if category.UserID != userID && userRole != string(models.RoleAdmin) {
+		return nil, errors.New("MoveCategory: permissão negada: você não pode mover categorias de outros usuários")
+	}
+
+	ownerID := category.UserID
+	err = service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		categoryRepository := service.categoryRepository.WithTx(tx)
+
+		// As validações de ciclo e profundidade só valem com a árvore travada; a categoria é relida
+		// porque um movimento concorrente pode tê-la mudado de lugar antes do bloqueio
+		if err := categoryRepository.LockCategoryTree(ownerID); err != nil {
+			return err
+		}
+		category, err = categoryRepository.GetCategoryByID(categoryID)
+		if err != nil {
+			return errors.New("MoveCategory: categoria não encontrada")
+		}
+		return moveCategory(categoryRepository, "MoveCategory", category, moveDTO.ParentID, moveDTO.Position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return service.categoryRepository.GetCategoryByID(category.ID)
+}
+
+// moveCategory coloca a categoria (com suas subcategorias) sob parentID, na posição informada entre as
+// irmãs (nil = no fim), e fecha o espaço deixado entre as irmãs antigas. A árvore do dono da categoria
+// deve estar travada (LockCategoryTree) na transação do repositório. Também usada na restauração de backups,
+// para que a hierarquia do arquivo passe pelas mesmas validações.
+func moveCategory(
+	categoryRepository *repositories.CategoryRepository,
+	operation string, category *models.Category, parentID *uint, position *int) error {
+	if parentID != nil {
+		parent, err := categoryRepository.GetCategoryByID(*parentID)
+		if err != nil || parent.UserID != category.UserID {
+			return errors.New(operation + ": categoria-pai não encontrada")
+		}
+		ancestorIDs, err := categoryRepository.GetCategoryAncestorIDs(parent.ID)
+		if err != nil {
+			return err
+		}
+		height, err := categoryRepository.GetSubtreeHeight(category.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkCategoryParent(category.ID, ancestorIDs, height); err != nil {
+			return errors.New(operation + ": " + err.Error())
+		}
+	}
+
+	// Fecha o espaço deixado entre as irmãs antigas
+	if !sameParent(category.ParentID, parentID) {
+		oldSiblings, err := categoryRepository.GetChildCategories(category.UserID, category.ParentID)
+		if err != nil {
+			return err
+		}
+		if err := placeCategories(categoryRepository, withoutCategory(oldSiblings, category.ID), category.ParentID); err != nil {
+			return err
+		}
+	}
+
+	siblings, err := categoryRepository.GetChildCategories(category.UserID, parentID)
+	if err != nil {
+		return err
+	}
+	return placeCategories(categoryRepository, insertCategory(withoutCategory(siblings, category.ID), category, position), parentID)
+}
+
+// checkCategoryParent valida a categoria-pai pela lista de IDs dela e de seus ancestrais (ver
+// GetCategoryAncestorIDs): recusa colocar a categoria dentro de si mesma ou de uma subcategoria sua e
+// árvores com mais de maxCategoryDepth níveis, contando os subtreeHeight níveis abaixo da categoria.
+// Na criação, categoryID e subtreeHeight são zero.
+func checkCategoryParent(categoryID uint, parentAncestorIDs []uint, subtreeHeight int) error {
+	if categoryID != 0 && slices.Contains(parentAncestorIDs, categoryID) {
+		return errors.New("uma categoria não pode ser movida para dentro de si mesma ou de suas subcategorias")
+	}
+	if len(parentAncestorIDs)+1+subtreeHeight > maxCategoryDepth {
+		return fmt.Errorf("a árvore de categorias admite no máximo %d níveis", maxCategoryDepth)
+	}
+	return nil
+}
+
+// insertCategory insere a categoria entre as irmãs na posição informada; sem posição, ou com uma posição
+// além da última, ela vai para o fim
+func insertCategory(siblings []*models.Category, category *models.Category, position *int) []*models.Category {
+	index := len(siblings)
+	if position != nil && *position < index {
+		index = max(*position, 0)
+	}
+	return slices.Insert(siblings, index, category)
+}
+
+// ReorderCategories redefine a ordem das subcategorias diretas de parentId (ou das categorias raiz do usuário).
+// A lista deve conter todas as irmãs, cada uma uma única vez.
+func (service *CategoryService) ReorderCategories(
+	reorderDTO dto.ReorderCategoriesDTO, userID uint, userRole string) ([]*models.Category, error) {
+	ownerID := userID
+	if reorderDTO.ParentID != nil {
+		parent, err := service.categoryRepository.GetCategoryByID(*reorderDTO.ParentID)
+		if err != nil {
+			return nil, errors.New("ReorderCategories: categoria-pai não encontrada")
+		}
+		if parent.UserID != userID && userRole != string(models.RoleAdmin) {
+			return nil, errors.New("ReorderCategories: permissão negada: você não pode reordenar categorias de outros usuários")
+		}
+		ownerID = parent.UserID
+	}
+
+	var ordered []*models.Category
+	err := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		categoryRepository := service.categoryRepository.WithTx(tx)
+
+		// Sem a árvore travada, uma irmã criada ou movida durante a reordenação ficaria fora da lista
+		if err := categoryRepository.LockCategoryTree(ownerID); err != nil {
+			return err
+		}
+		siblings, err := categoryRepository.GetChildCategories(ownerID, reorderDTO.ParentID)
+		if err != nil {
+			return err
+		}
+		ordered, err = orderCategories(siblings, reorderDTO.CategoryIDs)
+		if err != nil {
+			return errors.New("ReorderCategories: " + err.Error())
+		}
+		return placeCategories(categoryRepository, ordered, reorderDTO.ParentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// orderCategories ordena as irmãs pela lista de IDs, que deve conter todas elas, cada uma uma única vez
+func orderCategories(siblings []*models.Category, categoryIDs []uint) ([]*models.Category, error) {
+	if len(categoryIDs) != len(siblings) {
+		return nil, errors.New("informe todas as subcategorias diretas, cada uma uma única vez")
+	}
+	siblingsByID := make(map[uint]*models.Category, len(siblings))
+	for _, sibling := range siblings {
+		siblingsByID[sibling.ID] = sibling
+	}
+	ordered := make([]*models.Category, 0, len(siblings))
+	for _, id := range categoryIDs {
+		sibling, found := siblingsByID[id]
+		if !found {
+			return nil, errors.New("informe todas as subcategorias diretas, cada uma uma única vez")
+		}
+		delete(siblingsByID, id)
+		ordered = append(ordered, sibling)
+	}
+	return ordered, nil
+}
+
+// placeCategories grava as categorias sob parentID nas posições 0..n-1, na ordem recebida,
+// atualizando apenas as que mudaram de lugar
+func placeCategories(categoryRepository *repositories.CategoryRepository, categories []*models.Category, parentID *uint) error {
+	for _, category := range repackCategories(categories, parentID) {
+		if err := categoryRepository.UpdateCategoryPlacement(category.ID, parentID, category.Position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repackCategories coloca as categorias sob parentID nas posições 0..n-1, na ordem recebida, e retorna
+// as que mudaram de lugar
+func repackCategories(categories []*models.Category, parentID *uint) []*models.Category {
+	var moved []*models.Category
+	for position, category := range categories {
+		if category.Position == position && sameParent(category.ParentID, parentID) {
+			continue
+		}
+		category.ParentID, category.Position = parentID, position
+		moved = append(moved, category)
+	}
+	return moved
+}
+
+func sameParent(first, second *uint) bool {
+	if first == nil || second == nil {
+		return first == nil && second == nil
+	}
+	return *first == *second
+}
+
+func withoutCategory(categories []*models.Category, id uint) []*models.Category {
+	filtered := make([]*models.Category, 0, len(categories))
+	for _, category := range categories {
+		if category.ID != id {
+			filtered = append(filtered, category)
+		}
+	}
+	return filtered
+}
+
+// GetCategoryTree monta a árvore de categorias do usuário (ou a subárvore de rootId) a partir de uma única
+// consulta, com a contagem de produtos e o gasto do mês de cada categoria. Os totais de cada nó acumulam os
+// das subcategorias, contando uma única vez o produto vinculado a mais de uma delas.
+func (service *CategoryService) GetCategoryTree(
+	queryDTO dto.CategoryTreeQueryDTO, month time.Time, userID uint) (*dto.CategoryTreeDTO, error) {
+	if queryDTO.RootID != nil {
+		root, err := service.categoryRepository.GetCategoryByID(*queryDTO.RootID)
+		if err != nil || root.UserID != userID {
+			return nil, errors.New("GetCategoryTree: categoria não encontrada")
+		}
+	}
+
+	categories, err := service.categoryRepository.GetCategoryTree(userID, queryDTO.RootID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIDs := make([]uint, len(categories))
+	for i, category := range categories {
+		categoryIDs[i] = category.ID
+	}
+
+	month = startOfMonth(month)
+	spending, err := service.categoryRepository.GetCategoryProductSpending(userID, categoryIDs, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	return service.buildCategoryTree(categories, spending, month), nil
+}
+
+// buildCategoryTree monta a árvore a partir das categorias em pré-ordem e do gasto de cada produto
+// vinculado a elas no mês
+func (service *CategoryService) buildCategoryTree(
+	categories []*models.Category, spending []repositories.CategoryProductSpending, month time.Time) *dto.CategoryTreeDTO {
+	inTree := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		inTree[category.ID] = true
+	}
+
+	spentByCategory := make(map[uint]map[uint]float64)
+	for _, row := range spending {
+		if spentByCategory[row.CategoryID] == nil {
+			spentByCategory[row.CategoryID] = make(map[uint]float64)
+		}
+		spentByCategory[row.CategoryID][row.ProductID] = row.Total
+	}
+
+	// A consulta já vem em pré-ordem, então cada lista de filhos sai ordenada por posição
+	var roots []*models.Category
+	childrenOf := make(map[uint][]*models.Category)
+	for _, category := range categories {
+		if category.ParentID != nil && inTree[*category.ParentID] {
+			childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var buildNode func(category *models.Category, depth int) (dto.CategoryTreeNodeDTO, map[uint]float64)
+	buildNode = func(category *models.Category, depth int) (dto.CategoryTreeNodeDTO, map[uint]float64) {
+		node := dto.CategoryTreeNodeDTO{
+			CategoryResponseDTO: service.ToCategoryResponseDTO(category),
+			Depth:               depth,
+			Children:            []dto.CategoryTreeNodeDTO{},
+		}
+		products := make(map[uint]float64, len(spentByCategory[category.ID]))
+		for productID, spent := range spentByCategory[category.ID] {
+			products[productID] = spent
+			node.SpentAmount += spent
+		}
+		node.ProductCount = len(products)
+
+		for _, child := range childrenOf[category.ID] {
+			childNode, childProducts := buildNode(child, depth+1)
+			node.Children = append(node.Children, childNode)
+			for productID, spent := range childProducts {
+				products[productID] = spent
+			}
+		}
+		node.TotalProductCount = len(products)
+		node.TotalSpentAmount = utils.FormatForDisplay(sumSpending(products))
+		node.SpentAmount = utils.FormatForDisplay(node.SpentAmount)
+		return node, products
+	}
+
+	tree := &dto.CategoryTreeDTO{Period: month.Format(budgetPeriodLayout), Categories: []dto.CategoryTreeNodeDTO{}}
+	treeProducts := make(map[uint]float64)
+	for _, root := range roots {
+		node, products := buildNode(root, 0)
+		tree.Categories = append(tree.Categories, node)
+		for productID, spent := range products {
+			treeProducts[productID] = spent
+		}
+	}
+	tree.TotalSpentAmount = utils.FormatForDisplay(sumSpending(treeProducts))
+	return tree
+}
+
+func sumSpending(spentByProduct map[uint]float64) float64 {
+	var total float64 = 0
+	for _, spent := range spentByProduct {
+		total += spent
+	}
+	return total
+}
+
+// GetSubtreeCategoryIDs retorna, para cada categoria, o próprio ID e os de todas as suas subcategorias
+func (service *CategoryService) GetSubtreeCategoryIDs(categoryIDs []uint) (map[uint][]uint, error) {
+	return service.categoryRepository.GetSubtreeCategoryIDs(categoryIDs)
 }
 
 func (service *CategoryService) GetAllCategories(userRole string) ([]*models.Category, error) {
@@ -100,6 +486,8 @@ func (service *CategoryService) ToCategoryResponseDTO(category *models.Category)
 		ID:        category.ID,
 		Name:      category.Name,
 		UserID:    category.UserID,
+		ParentID:  category.ParentID,
+		Position:  category.Position,
 		CreatedAt: category.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: category.UpdatedAt.Format(time.RFC3339),
 	}
@@ -112,3 +500,36 @@ func (service *CategoryService) ToCategoryResponseDTOList(categories []*models.C
 	}
 	return dtos
 }
+
+// GetCategoriesByIDs busca de uma vez as categorias; apenas as do próprio usuário (ou todas, para admin) são retornadas
+func (service *CategoryService) GetCategoriesByIDs(ids []uint, userID uint, userRole string) (map[uint]*models.Category, error) {
+	categories, err := service.categoryRepository.GetCategoriesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	categoriesByID := make(map[uint]*models.Category, len(categories))
+	for _, category := range categories {
+		if category.UserID == userID || userRole == string(models.RoleAdmin) {
+			categoriesByID[category.ID] = category
+		}
+	}
+	return categoriesByID, nil
+}
+
+// GetCategoriesByUserIDs busca de uma vez as categorias de vários usuários, agrupadas por usuário.
+// Usuários comuns só recebem as próprias categorias.
+func (service *CategoryService) GetCategoriesByUserIDs(
+	userIDs []uint, userID uint, userRole string) (map[uint][]*models.Category, error) {
+	if userRole != string(models.RoleAdmin) {
+		userIDs = filterIDs(userIDs, userID)
+	}
+	categories, err := service.categoryRepository.GetCategoriesByUserIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	categoriesByUser := make(map[uint][]*models.Category, len(userIDs))
+	for _, category := range categories {
+		categoriesByUser[category.UserID] = append(categoriesByUser[category.UserID], category)
+	}
+	return categoriesByUser, nil
+}
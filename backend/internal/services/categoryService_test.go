@@ -0,0 +1,224 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+)
+
+func TestCheckCategoryParent(t *testing.T) {
+	tests := []struct {
+		name              string
+		categoryID        uint
+		parentAncestorIDs []uint // Categoria-pai seguida dos seus ancestrais
+		subtreeHeight     int
+		wantError         string
+	}{
+		{name: "raiz sob raiz", categoryID: 2, parentAncestorIDs: []uint{1}},
+		{name: "dentro de si mesma", categoryID: 1, parentAncestorIDs: []uint{1}, wantError: "dentro de si mesma"},
+		{name: "dentro de uma subcategoria", categoryID: 1, parentAncestorIDs: []uint{4, 3, 1}, wantError: "dentro de si mesma"},
+		{name: "criação no último nível", parentAncestorIDs: []uint{4, 3, 2, 1}},
+		{name: "criação além do último nível", parentAncestorIDs: []uint{5, 4, 3, 2, 1}, wantError: "no máximo 5 níveis"},
+		{name: "subárvore que cabe", categoryID: 9, parentAncestorIDs: []uint{2, 1}, subtreeHeight: 2},
+		{name: "subárvore funda demais", categoryID: 9, parentAncestorIDs: []uint{3, 2, 1}, subtreeHeight: 2, wantError: "no máximo 5 níveis"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkCategoryParent(test.categoryID, test.parentAncestorIDs, test.subtreeHeight)
+			if test.wantError == "" && err != nil {
+				t.Fatalf("erro = %v, esperado nenhum", err)
+			}
+			if test.wantError != "" && (err == nil || !strings.Contains(err.Error(), test.wantError)) {
+				t.Fatalf("erro = %v, esperado conter %q", err, test.wantError)
+			}
+		})
+	}
+}
+
+// categoriesAt monta irmãs sob parentID com as posições informadas (IDs 1, 2, ...)
+func categoriesAt(parentID *uint, positions ...int) []*models.Category {
+	categories := make([]*models.Category, len(positions))
+	for i, position := range positions {
+		categories[i] = &models.Category{ParentID: parentID, Position: position}
+		categories[i].ID = uint(i + 1)
+	}
+	return categories
+}
+
+func categoryIDs(categories []*models.Category) []uint {
+	ids := make([]uint, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	return ids
+}
+
+func sameIDs(got, want []uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestMoveCategoryPlacement confere a posição de uma categoria movida entre as novas irmãs e o
+// reempacotamento das posições, gravando só as categorias que mudaram de lugar
+func TestMoveCategoryPlacement(t *testing.T) {
+	parentID := uint(10)
+	position := func(value int) *int { return &value }
+
+	tests := []struct {
+		name      string
+		siblings  []int // Posições atuais das irmãs de destino (IDs 1, 2, ...)
+		position  *int
+		wantOrder []uint
+		wantMoved []uint
+	}{
+		{name: "no fim", siblings: []int{0, 1, 2}, wantOrder: []uint{1, 2, 3, 99}, wantMoved: []uint{99}},
+		{name: "no início", siblings: []int{0, 1, 2}, position: position(0), wantOrder: []uint{99, 1, 2, 3}, wantMoved: []uint{99, 1, 2, 3}},
+		{name: "no meio", siblings: []int{0, 1, 2}, position: position(1), wantOrder: []uint{1, 99, 2, 3}, wantMoved: []uint{99, 2, 3}},
+		{name: "posição além do fim", siblings: []int{0, 1}, position: position(7), wantOrder: []uint{1, 2, 99}, wantMoved: []uint{99}},
+		{name: "posição negativa", siblings: []int{0}, position: position(-1), wantOrder: []uint{99, 1}, wantMoved: []uint{99, 1}},
+		{name: "fecha lacunas", siblings: []int{0, 3, 8}, wantOrder: []uint{1, 2, 3, 99}, wantMoved: []uint{2, 3, 99}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			category := &models.Category{Position: 0} // Vem das raízes
+			category.ID = 99
+			ordered := insertCategory(categoriesAt(&parentID, test.siblings...), category, test.position)
+			if got := categoryIDs(ordered); !sameIDs(got, test.wantOrder) {
+				t.Fatalf("ordem = %v, esperado %v", got, test.wantOrder)
+			}
+
+			moved := repackCategories(ordered, &parentID)
+			if got := categoryIDs(moved); !sameIDs(got, test.wantMoved) {
+				t.Errorf("gravadas = %v, esperado %v", got, test.wantMoved)
+			}
+			for i, sibling := range ordered {
+				if sibling.Position != i || sibling.ParentID == nil || *sibling.ParentID != parentID {
+					t.Errorf("categoria %d em %v/%d, esperado %d/%d", sibling.ID, sibling.ParentID, sibling.Position, parentID, i)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderCategories(t *testing.T) {
+	tests := []struct {
+		name        string
+		categoryIDs []uint
+		wantOrder   []uint
+	}{
+		{name: "nova ordem", categoryIDs: []uint{3, 1, 2}, wantOrder: []uint{3, 1, 2}},
+		{name: "faltando uma irmã", categoryIDs: []uint{3, 1}},
+		{name: "irmã repetida", categoryIDs: []uint{3, 1, 1}},
+		{name: "categoria de fora", categoryIDs: []uint{3, 1, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, err := orderCategories(categoriesAt(nil, 0, 1, 2), test.categoryIDs)
+			if test.wantOrder == nil {
+				if err == nil || !strings.Contains(err.Error(), "cada uma uma única vez") {
+					t.Fatalf("erro = %v, esperado recusar a lista", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("orderCategories: %v", err)
+			}
+			if got := categoryIDs(ordered); !sameIDs(got, test.wantOrder) {
+				t.Errorf("ordem = %v, esperado %v", got, test.wantOrder)
+			}
+		})
+	}
+
+	// Reordenar as irmãs grava só as que mudaram de posição
+	siblings := categoriesAt(nil, 0, 1, 2)
+	ordered, err := orderCategories(siblings, []uint{1, 3, 2})
+	if err != nil {
+		t.Fatalf("orderCategories: %v", err)
+	}
+	if moved := categoryIDs(repackCategories(ordered, nil)); !sameIDs(moved, []uint{3, 2}) {
+		t.Errorf("gravadas = %v, esperado [3 2]", moved)
+	}
+}
+
+// TestBuildCategoryTree confere a árvore e os totais acumulados, com o produto presente em mais de uma
+// subcategoria contado uma única vez
+func TestBuildCategoryTree(t *testing.T) {
+	id := func(value uint) *uint { return &value }
+	newCategory := func(categoryID uint, parentID *uint, name string) *models.Category {
+		category := &models.Category{Name: name, ParentID: parentID}
+		category.ID = categoryID
+		return category
+	}
+	// Pré-ordem: Bebidas > (Refrigerantes, Sucos > Integrais); Limpeza
+	categories := []*models.Category{
+		newCategory(1, nil, "Bebidas"),
+		newCategory(2, id(1), "Refrigerantes"),
+		newCategory(3, id(1), "Sucos"),
+		newCategory(4, id(3), "Integrais"),
+		newCategory(5, nil, "Limpeza"),
+	}
+	spending := []repositories.CategoryProductSpending{
+		{CategoryID: 1, ProductID: 100, Total: 10},
+		{CategoryID: 2, ProductID: 200, Total: 7.5},
+		{CategoryID: 3, ProductID: 300, Total: 4},
+		{CategoryID: 4, ProductID: 300, Total: 4}, // O mesmo suco em duas categorias da subárvore
+		{CategoryID: 4, ProductID: 400, Total: 0},
+		{CategoryID: 5, ProductID: 200, Total: 7.5}, // E o refrigerante também em Limpeza
+	}
+
+	service := &CategoryService{}
+	tree := service.buildCategoryTree(categories, spending, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if tree.Period != "2025-03" || tree.TotalSpentAmount != 21.5 {
+		t.Errorf("árvore = %s/%v, esperado 2025-03/21.5", tree.Period, tree.TotalSpentAmount)
+	}
+
+	type nodeTotals struct {
+		depth, products, totalProducts int
+		spent, totalSpent              float64
+		children                       int
+	}
+	want := map[uint]nodeTotals{
+		1: {depth: 0, products: 1, totalProducts: 4, spent: 10, totalSpent: 21.5, children: 2},
+		2: {depth: 1, products: 1, totalProducts: 1, spent: 7.5, totalSpent: 7.5},
+		3: {depth: 1, products: 1, totalProducts: 2, spent: 4, totalSpent: 4, children: 1},
+		4: {depth: 2, products: 2, totalProducts: 2, spent: 4, totalSpent: 4},
+		5: {depth: 0, products: 1, totalProducts: 1, spent: 7.5, totalSpent: 7.5},
+	}
+	var visited []uint
+	var check func(nodes []dto.CategoryTreeNodeDTO)
+	check = func(nodes []dto.CategoryTreeNodeDTO) {
+		for _, node := range nodes {
+			visited = append(visited, node.ID)
+			got := nodeTotals{node.Depth, node.ProductCount, node.TotalProductCount, node.SpentAmount, node.TotalSpentAmount, len(node.Children)}
+			if got != want[node.ID] {
+				t.Errorf("categoria %d = %+v, esperado %+v", node.ID, got, want[node.ID])
+			}
+			check(node.Children)
+		}
+	}
+	check(tree.Categories)
+	if !sameIDs(visited, []uint{1, 2, 3, 4, 5}) {
+		t.Errorf("pré-ordem = %v, esperado [1 2 3 4 5]", visited)
+	}
+
+	// Na subárvore de Sucos, a raiz é a própria categoria, mesmo tendo categoria-pai
+	subtree := service.buildCategoryTree(categories[2:4], spending[2:5], time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if len(subtree.Categories) != 1 || subtree.Categories[0].ID != 3 || subtree.Categories[0].Depth != 0 ||
+		len(subtree.Categories[0].Children) != 1 || subtree.TotalSpentAmount != 4 {
+		t.Errorf("subárvore = %+v", subtree)
+	}
+}
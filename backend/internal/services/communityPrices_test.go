@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+)
+
+func TestBucketCommunityPrices(t *testing.T) {
+	record := func(userID uint, date string, price float64, unit string) *models.PriceHistory {
+		purchaseDate, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &models.PriceHistory{UserID: userID, PurchaseDate: purchaseDate, PricePaid: price, Unit: unit, PurchasePlace: "Mercado"}
+	}
+
+	priceHistories := []*models.PriceHistory{
+		// Semana de 13/01/2025: três usuários em "un" (domingo 19/01 ainda é da mesma semana)
+		record(1, "2025-01-13T09:00:00Z", 4.00, "un"),
+		record(2, "2025-01-15T18:00:00Z", 5.00, "un"),
+		record(2, "2025-01-16T18:00:00Z", 6.00, "un"),
+		record(3, "2025-01-19T23:00:00Z", 9.00, "un"),
+		// 23h de domingo em Brasília já é segunda em UTC: cai na semana seguinte
+		record(4, "2025-01-19T23:30:00-03:00", 5.50, "un"),
+		// A mesma semana em kg fica em outro grupo, com só dois usuários
+		record(1, "2025-01-14T09:00:00Z", 30.00, "kg"),
+		record(2, "2025-01-14T10:00:00Z", 32.00, "kg"),
+		// Semana de 20/01: três usuários
+		record(5, "2025-01-21T12:00:00Z", 5.00, "un"),
+		record(6, "2025-01-22T12:00:00Z", 5.20, "un"),
+	}
+
+	buckets, hidden := bucketCommunityPrices(priceHistories, 3)
+	want := []dto.CommunityPriceBucketDTO{
+		{WeekStart: "2025-01-13", Unit: "un", RecordsCount: 4, MedianPrice: 5.5, LowestPrice: 4, HighestPrice: 9},
+		{WeekStart: "2025-01-20", Unit: "un", RecordsCount: 3, MedianPrice: 5.2, LowestPrice: 5, HighestPrice: 5.5},
+	}
+	if hidden != 1 {
+		t.Errorf("semanas omitidas = %d, esperado 1 (kg)", hidden)
+	}
+	if len(buckets) != len(want) {
+		t.Fatalf("semanas = %+v, esperado %+v", buckets, want)
+	}
+	for i := range want {
+		if buckets[i] != want[i] {
+			t.Errorf("semana %d = %+v, esperado %+v", i, buckets[i], want[i])
+		}
+	}
+
+	if buckets, hidden := bucketCommunityPrices(nil, 3); len(buckets) != 0 || buckets == nil || hidden != 0 {
+		t.Errorf("sem registros: %v/%d, esperado lista vazia", buckets, hidden)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// Tipos de evento entregues aos webhooks
+const (
+	EventPurchaseCreated     = "purchase.created"
+	EventPurchaseDeleted     = "purchase.deleted"
+	EventProductUpdated      = "product.updated"
+	EventPriceHistoryCreated = "price_history.created"
+	EventPriceAlert          = "price.alert" // Preço fora da faixa esperada em uma compra registrada
+	EventBudgetWarning       = NotificationBudgetWarning
+	EventBudgetExceeded      = NotificationBudgetExceeded
+)
+
+// WebhookEventTypes lista os tipos de evento que podem ser assinados
+var WebhookEventTypes = []string{
+	EventPurchaseCreated, EventPurchaseDeleted, EventProductUpdated, EventPriceHistoryCreated,
+	EventPriceAlert, EventBudgetWarning, EventBudgetExceeded,
+}
+
+// EventPublisher grava eventos de domínio na outbox. Publish deve receber a transação da alteração,
+// de modo que o evento só exista se a alteração for confirmada (e nunca se perca quando ela for).
+type EventPublisher struct {
+	outboxRepository   *repositories.OutboxRepository
+	transactionManager *repositories.TransactionManager
+}
+
+// NewEventPublisher creates a new instance of EventPublisher
+func NewEventPublisher(outboxRepo *repositories.OutboxRepository, transactionManager *repositories.TransactionManager) *EventPublisher {
+	return &EventPublisher{
+		outboxRepository:   outboxRepo,
+		transactionManager: transactionManager,
+	}
+}
+
+// Publish grava o evento na outbox dentro de tx. userID nil indica um evento global.
+// É seguro chamar com um EventPublisher nil (eventos desativados).
+func (publisher *EventPublisher) Publish(tx *gorm.DB, eventType string, userID *uint, data any) error {
+	if publisher == nil {
+		return nil
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event := &models.OutboxEvent{EventType: eventType, UserID: userID, Payload: string(payload)}
+	return publisher.outboxRepository.WithTx(tx).CreateEvent(event)
+}
+
+// Transaction executa fn em uma transação, para serviços que não têm um TransactionManager próprio
+func (publisher *EventPublisher) Transaction(fn func(tx *gorm.DB) error) error {
+	return publisher.transactionManager.Transaction(fn)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"io"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/export"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Colunas de cada exportação (cabeçalho do CSV/XLSX e chaves do NDJSON)
+var (
+	purchaseExportColumns = []string{
+		"purchaseId", "purchaseDate", "purchaseLocation", "productId", "productName", "barcode",
+		"quantity", "unit", "unitPrice", "totalPrice",
+	}
+	priceHistoryExportColumns = []string{
+		"id", "purchaseDate", "purchasePlace", "productId", "productName", "barcode", "pricePaid", "quantity", "unit",
+	}
+	categoryExportColumns = []string{
+		"categoryId", "categoryName", "productId", "productName", "barcode", "createdAt",
+	}
+)
+
+// ExportService streams the user's purchases, price history and category mappings as files
+type ExportService struct {
+	purchaseRepository            *repositories.PurchaseRepository
+	priceHistoryRepository        *repositories.PriceHistoryRepository
+	userCategoryProductRepository *repositories.UserCategoryProductRepository
+}
+
+// NewExportService creates a new instance of ExportService
+func NewExportService(
+	purchaseRepo *repositories.PurchaseRepository,
+	priceHistoryRepo *repositories.PriceHistoryRepository,
+	ucpRepo *repositories.UserCategoryProductRepository) *ExportService {
+	return &ExportService{
+		purchaseRepository:            purchaseRepo,
+		priceHistoryRepository:        priceHistoryRepo,
+		userCategoryProductRepository: ucpRepo,
+	}
+}
+
+// ParseExportQuery valida o formato e o intervalo de datas da exportação
+func (service *ExportService) ParseExportQuery(queryDTO dto.ExportQueryDTO) (export.Format, repositories.DateRangeFilter, error) {
+	format, err := export.ParseFormat(queryDTO.Format)
+	if err != nil {
+		return "", repositories.DateRangeFilter{}, err
+	}
+
+	var filter repositories.DateRangeFilter
+	if !queryDTO.StartDate.IsZero() {
+		filter.StartDate = &queryDTO.StartDate
+	}
+	if !queryDTO.EndDate.IsZero() {
+		filter.EndDate = &queryDTO.EndDate
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return "", repositories.DateRangeFilter{}, errors.New("ParseExportQuery: endDate deve ser posterior a startDate")
+	}
+	return format, filter, nil
+}
+
+// ExportPurchases grava os itens das compras do usuário (uma linha por item) no formato informado
+func (service *ExportService) ExportPurchases(
+	output io.Writer, format export.Format, userID uint, filter repositories.DateRangeFilter) error {
+	return writeExport(output, format, purchaseExportColumns, "Compras", func(writer export.RowWriter) error {
+		return service.purchaseRepository.StreamPurchaseItemsByUserID(userID, filter,
+			func(row *repositories.PurchaseExportRow) error {
+				return writer.WriteRow(export.Row{
+					row.PurchaseID, row.PurchaseDate, row.PurchaseLocation, row.ProductID, row.ProductName, row.Barcode,
+					utils.FormatDecimal(row.Quantity), row.Unit, utils.FormatDecimal(row.UnitPrice), utils.FormatDecimal(row.TotalPrice),
+				})
+			})
+	})
+}
+
+// ExportPriceHistory grava o histórico de preços do usuário no formato informado
+func (service *ExportService) ExportPriceHistory(
+	output io.Writer, format export.Format, userID uint, filter repositories.DateRangeFilter) error {
+	return writeExport(output, format, priceHistoryExportColumns, "Historico", func(writer export.RowWriter) error {
+		return service.priceHistoryRepository.StreamPriceHistoryByUserID(userID, filter,
+			func(row *repositories.PriceHistoryExportRow) error {
+				return writer.WriteRow(export.Row{
+					row.ID, row.PurchaseDate, row.PurchasePlace, row.ProductID, row.ProductName, row.Barcode,
+					utils.FormatDecimal(row.PricePaid), utils.FormatDecimal(row.Quantity), row.Unit,
+				})
+			})
+	})
+}
+
+// ExportCategories grava as associações categoria-produto do usuário no formato informado
+func (service *ExportService) ExportCategories(
+	output io.Writer, format export.Format, userID uint, filter repositories.DateRangeFilter) error {
+	return writeExport(output, format, categoryExportColumns, "Categorias", func(writer export.RowWriter) error {
+		return service.userCategoryProductRepository.StreamCategoryProductsByUserID(userID, filter,
+			func(row *repositories.CategoryProductExportRow) error {
+				return writer.WriteRow(export.Row{
+					row.CategoryID, row.CategoryName, row.ProductID, row.ProductName, row.Barcode, row.CreatedAt,
+				})
+			})
+	})
+}
+
+// writeExport cria o RowWriter, executa a consulta em streaming e finaliza o arquivo
+func writeExport(
+	output io.Writer, format export.Format, columns []string, sheetName string, stream func(writer export.RowWriter) error) error {
+	writer, err := export.NewRowWriter(format, output, columns, sheetName)
+	if err != nil {
+		return err
+	}
+	if err := stream(writer); err != nil {
+		return err
+	}
+	return writer.Close()
+}
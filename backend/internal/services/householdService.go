@@ -0,0 +1,159 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+)
+
+// HouseholdService handles business logic for households (usuários que compartilham compras)
+type HouseholdService struct {
+	householdRepository *repositories.HouseholdRepository
+	userService         *UserService
+}
+
+// NewHouseholdService creates a new instance of HouseholdService
+func NewHouseholdService(householdRepo *repositories.HouseholdRepository, userService *UserService) *HouseholdService {
+	return &HouseholdService{
+		householdRepository: householdRepo,
+		userService:         userService,
+	}
+}
+
+// CreateHousehold cria um domicílio tendo o usuário como dono e primeiro membro
+func (service *HouseholdService) CreateHousehold(createDTO dto.CreateHouseholdDTO, userID uint) (*models.Household, error) {
+	user, err := service.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("CreateHousehold: usuário não encontrado")
+	}
+	if user.HouseholdID != nil {
+		return nil, errors.New("CreateHousehold: usuário já pertence a um domicílio")
+	}
+
+	inviteCode, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	household := &models.Household{
+		Name:       createDTO.Name,
+		OwnerID:    userID,
+		InviteCode: inviteCode,
+	}
+	if err := service.householdRepository.CreateHousehold(household); err != nil {
+		return nil, err
+	}
+
+	return service.householdRepository.GetHouseholdByID(household.ID)
+}
+
+// JoinHousehold adiciona o usuário ao domicílio dono do código de convite
+func (service *HouseholdService) JoinHousehold(joinDTO dto.JoinHouseholdDTO, userID uint) (*models.Household, error) {
+	user, err := service.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("JoinHousehold: usuário não encontrado")
+	}
+	if user.HouseholdID != nil {
+		return nil, errors.New("JoinHousehold: usuário já pertence a um domicílio")
+	}
+
+	household, err := service.householdRepository.GetHouseholdByInviteCode(joinDTO.InviteCode)
+	if err != nil {
+		return nil, errors.New("JoinHousehold: código de convite inválido")
+	}
+
+	if err := service.householdRepository.SetUserHousehold(userID, &household.ID); err != nil {
+		return nil, err
+	}
+
+	return service.householdRepository.GetHouseholdByID(household.ID)
+}
+
+// LeaveHousehold remove o usuário do seu domicílio. Se o dono sair, a posse passa para outro
+// membro; o último membro a sair encerra o domicílio.
+func (service *HouseholdService) LeaveHousehold(userID uint) error {
+	household, err := service.GetHouseholdByUserID(userID)
+	if err != nil {
+		return errors.New("LeaveHousehold: " + err.Error())
+	}
+
+	if len(household.Members) <= 1 {
+		return service.householdRepository.DeleteHousehold(household.ID)
+	}
+
+	if household.OwnerID == userID {
+		for _, member := range household.Members {
+			if member.ID != userID {
+				household.OwnerID = member.ID
+				break
+			}
+		}
+		if err := service.householdRepository.UpdateHousehold(household); err != nil {
+			return err
+		}
+	}
+
+	return service.householdRepository.SetUserHousehold(userID, nil)
+}
+
+// GetHouseholdByUserID retorna o domicílio do qual o usuário faz parte
+func (service *HouseholdService) GetHouseholdByUserID(userID uint) (*models.Household, error) {
+	user, err := service.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	if user.HouseholdID == nil {
+		return nil, errors.New("usuário não pertence a um domicílio")
+	}
+
+	household, err := service.householdRepository.GetHouseholdByID(*user.HouseholdID)
+	if err != nil {
+		return nil, errors.New("domicílio não encontrado")
+	}
+	return household, nil
+}
+
+// GetHouseholdMemberIDs retorna os IDs dos membros do domicílio do usuário
+// (apenas o próprio usuário quando ele não pertence a nenhum domicílio)
+func (service *HouseholdService) GetHouseholdMemberIDs(userID uint) ([]uint, error) {
+	user, err := service.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("GetHouseholdMemberIDs: usuário não encontrado")
+	}
+	if user.HouseholdID == nil {
+		return []uint{userID}, nil
+	}
+	return service.householdRepository.GetMemberIDs(*user.HouseholdID)
+}
+
+// generateInviteCode gera um código de convite aleatório
+func generateInviteCode() (string, error) {
+	buffer := make([]byte, 10)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+// ToHouseholdResponseDTO converts a Household model to HouseholdResponseDTO
+func (service *HouseholdService) ToHouseholdResponseDTO(household *models.Household) dto.HouseholdResponseDTO {
+	members := make([]dto.HouseholdMemberDTO, len(household.Members))
+	for i, member := range household.Members {
+		members[i] = dto.HouseholdMemberDTO{ID: member.ID, Name: member.Name}
+	}
+
+	return dto.HouseholdResponseDTO{
+		ID:         household.ID,
+		Name:       household.Name,
+		OwnerID:    household.OwnerID,
+		InviteCode: household.InviteCode,
+		Members:    members,
+		CreatedAt:  household.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  household.UpdatedAt.Format(time.RFC3339),
+	}
+}
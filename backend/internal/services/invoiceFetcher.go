@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/pkg/nfe"
+)
+
+// Tamanho máximo do documento baixado da SEFAZ (2 MB)
+const maxFetchedInvoiceSize = 2 << 20
+
+// FetchedInvoice é o documento retornado pela consulta pública: XML da nota ou a página HTML
+type FetchedInvoice struct {
+	Content     []byte
+	ContentType string
+}
+
+// IsXML indica se o documento é o XML da nota (e não a página HTML de consulta)
+func (document *FetchedInvoice) IsXML() bool {
+	if strings.Contains(document.ContentType, "xml") {
+		return true
+	}
+	prefix := strings.TrimSpace(string(document.Content[:min(len(document.Content), 512)]))
+	return strings.HasPrefix(prefix, "<?xml") || strings.HasPrefix(prefix, "<nfeProc") || strings.HasPrefix(prefix, "<NFe")
+}
+
+// InvoiceFetcher obtém o documento de uma NFC-e a partir do QR Code.
+// A implementação HTTP consulta a SEFAZ; a de fixtures lê arquivos locais (testes e desenvolvimento offline).
+type InvoiceFetcher interface {
+	Fetch(ctx context.Context, qrCode *nfe.QRCode) (*FetchedInvoice, error)
+}
+
+// HTTPInvoiceFetcher consulta a URL do QR Code no portal da SEFAZ
+type HTTPInvoiceFetcher struct {
+	client *http.Client
+}
+
+// NewHTTPInvoiceFetcher cria um fetcher HTTP com o timeout informado
+func NewHTTPInvoiceFetcher(timeout time.Duration) *HTTPInvoiceFetcher {
+	return &HTTPInvoiceFetcher{client: &http.Client{Timeout: timeout}}
+}
+
+// Fetch baixa o documento da URL do QR Code. Redirecionamentos só são seguidos para outros portais
+// de consulta da mesma UF, para que a lista de domínios aceitos não seja contornada.
+func (fetcher *HTTPInvoiceFetcher) Fetch(ctx context.Context, qrCode *nfe.QRCode) (*FetchedInvoice, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, qrCode.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "text/html,application/xml;q=0.9")
+	request.Header.Set("User-Agent", "AppMercado/1.0")
+
+	client := *fetcher.client
+	client.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+		return checkInvoiceRedirect(qrCode, redirect, via)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, errors.New("Fetch: falha ao consultar a SEFAZ: " + err.Error())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Fetch: a SEFAZ respondeu com status %d", response.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxFetchedInvoiceSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxFetchedInvoiceSize {
+		return nil, errors.New("Fetch: documento retornado pela SEFAZ excede o tamanho máximo")
+	}
+	return &FetchedInvoice{Content: content, ContentType: response.Header.Get("Content-Type")}, nil
+}
+
+// maxInvoiceRedirects limita os redirecionamentos seguidos numa consulta
+const maxInvoiceRedirects = 5
+
+// checkInvoiceRedirect recusa redirecionamentos para fora dos portais de consulta da UF da nota
+func checkInvoiceRedirect(qrCode *nfe.QRCode, redirect *http.Request, via []*http.Request) error {
+	if len(via) >= maxInvoiceRedirects {
+		return errors.New("Fetch: redirecionamentos demais na consulta à SEFAZ")
+	}
+	if (redirect.URL.Scheme != "http" && redirect.URL.Scheme != "https") || !qrCode.AllowsHost(redirect.URL.Hostname()) {
+		return errors.New("Fetch: a SEFAZ redirecionou para um domínio fora dos portais de consulta de " + qrCode.State)
+	}
+	return nil
+}
+
+// FixtureInvoiceFetcher lê o documento de <diretório>/<chave>.xml ou <diretório>/<chave>.html
+type FixtureInvoiceFetcher struct {
+	directory string
+}
+
+// NewFixtureInvoiceFetcher cria um fetcher que lê documentos salvos no diretório informado
+func NewFixtureInvoiceFetcher(directory string) *FixtureInvoiceFetcher {
+	return &FixtureInvoiceFetcher{directory: directory}
+}
+
+// Fetch lê o documento salvo para a chave de acesso do QR Code
+func (fetcher *FixtureInvoiceFetcher) Fetch(ctx context.Context, qrCode *nfe.QRCode) (*FetchedInvoice, error) {
+	for _, extension := range []string{".xml", ".html"} {
+		content, err := os.ReadFile(filepath.Join(fetcher.directory, qrCode.AccessKey+extension))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		contentType := "text/html"
+		if extension == ".xml" {
+			contentType = "application/xml"
+		}
+		return &FetchedInvoice{Content: content, ContentType: contentType}, nil
+	}
+	return nil, errors.New("Fetch: nenhum documento de fixture para a chave " + qrCode.AccessKey)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/nfe"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Situação de cada item da nota em relação ao catálogo de produtos
+const (
+	InvoiceItemMatched  = "matched"
+	InvoiceItemCreated  = "created"
+	InvoiceItemProposed = "proposed"
+	InvoiceItemSkipped  = "skipped"
+)
+
+// ErrUnknownInvoiceItems é retornado quando há itens sem produto correspondente e eles não podem ser ignorados
+var ErrUnknownInvoiceItems = errors.New("ImportInvoice: há itens sem produto correspondente; cadastre os produtos propostos ou use skipUnknownItems")
+
+// InvoiceFetchError indica falha ao obter o documento da SEFAZ (serviço externo indisponível ou resposta inválida)
+type InvoiceFetchError struct {
+	Err error
+}
+
+func (e *InvoiceFetchError) Error() string {
+	return "ImportInvoiceURL: " + e.Err.Error()
+}
+
+func (e *InvoiceFetchError) Unwrap() error {
+	return e.Err
+}
+
+// InvoiceImportService imports purchases from Brazilian electronic invoices (NF-e/NFC-e)
+type InvoiceImportService struct {
+	purchaseService    *PurchaseService
+	transactionManager *repositories.TransactionManager
+	invoiceFetcher     InvoiceFetcher
+}
+
+// NewInvoiceImportService creates a new instance of InvoiceImportService
+func NewInvoiceImportService(
+	purchaseService *PurchaseService,
+	transactionManager *repositories.TransactionManager,
+	invoiceFetcher InvoiceFetcher) *InvoiceImportService {
+	return &InvoiceImportService{
+		purchaseService:    purchaseService,
+		transactionManager: transactionManager,
+		invoiceFetcher:     invoiceFetcher,
+	}
+}
+
+// ImportInvoiceXML lê o XML de uma NF-e/NFC-e e registra a compra correspondente
+func (service *InvoiceImportService) ImportInvoiceXML(
+	reader io.Reader, options dto.InvoiceImportOptionsDTO, userID uint, userRole string) (*dto.InvoiceImportReportDTO, error) {
+	invoice, err := nfe.Parse(reader)
+	if err != nil {
+		return nil, errors.New("ImportInvoiceXML: " + err.Error())
+	}
+	return service.ImportInvoice(invoice, options, userID, userRole)
+}
+
+// ImportInvoiceURL valida a URL do QR Code de uma NFC-e, obtém o documento pelo InvoiceFetcher
+// (XML da nota ou página HTML de consulta) e registra a compra
+func (service *InvoiceImportService) ImportInvoiceURL(
+	ctx context.Context, qrCodeURL string, options dto.InvoiceImportOptionsDTO, userID uint, userRole string) (*dto.InvoiceImportReportDTO, error) {
+	invoice, err := service.fetchInvoice(ctx, qrCodeURL)
+	if err != nil {
+		return nil, err
+	}
+	return service.ImportInvoice(invoice, options, userID, userRole)
+}
+
+// fetchInvoice valida a URL do QR Code e converte o documento obtido pelo InvoiceFetcher, sem acessar o banco
+func (service *InvoiceImportService) fetchInvoice(ctx context.Context, qrCodeURL string) (*nfe.Invoice, error) {
+	qrCode, err := nfe.ParseQRCodeURL(qrCodeURL)
+	if err != nil {
+		return nil, errors.New("ImportInvoiceURL: " + err.Error())
+	}
+
+	document, err := service.invoiceFetcher.Fetch(ctx, qrCode)
+	if err != nil {
+		return nil, &InvoiceFetchError{Err: err}
+	}
+
+	var invoice *nfe.Invoice
+	if document.IsXML() {
+		invoice, err = nfe.Parse(bytes.NewReader(document.Content))
+	} else {
+		invoice, err = nfe.ParseConsultationHTML(document.Content)
+	}
+	if err != nil {
+		return nil, errors.New("ImportInvoiceURL: " + err.Error())
+	}
+
+	// A página pode omitir a chave; se trouxer, deve ser a mesma do QR Code
+	if invoice.AccessKey == "" {
+		invoice.AccessKey = qrCode.AccessKey
+	} else if invoice.AccessKey != qrCode.AccessKey {
+		return nil, errors.New("ImportInvoiceURL: a chave de acesso do documento não corresponde à do QR Code")
+	}
+	return invoice, nil
+}
+
+// ImportInvoice registra a compra de uma nota já convertida. Os itens são casados com produtos pelo
+// código de barras (GTIN) e, sem GTIN, pelo nome; itens desconhecidos viram propostas de produto.
+// A nota é recusada se a chave de acesso já estiver em outra compra do usuário.
+func (service *InvoiceImportService) ImportInvoice(
+	invoice *nfe.Invoice, options dto.InvoiceImportOptionsDTO, userID uint, userRole string) (*dto.InvoiceImportReportDTO, error) {
+
+	// Recusa duplicatas antes de qualquer trabalho, inclusive em dry-run
+	existing, err := service.purchaseService.purchaseRepository.GetPurchaseByInvoiceKey(invoice.AccessKey, userID)
+	if err == nil {
+		return nil, &DuplicateInvoiceError{PurchaseID: existing.ID}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	report := &dto.InvoiceImportReportDTO{
+		DryRun:           options.DryRun,
+		AccessKey:        invoice.AccessKey,
+		StoreName:        invoice.StoreName,
+		StoreCNPJ:        invoice.StoreCNPJ,
+		IssuedAt:         invoice.IssuedAt.Format(time.RFC3339),
+		Total:            utils.FormatForDisplay(invoice.Total),
+		Discount:         utils.FormatForDisplay(invoice.Discount),
+		Items:            make([]dto.InvoiceItemReportDTO, len(invoice.Items)),
+		ProposedProducts: []dto.ProposedProductDTO{},
+		ProductsCreated:  []string{},
+		Warnings:         []dto.PriceWarningDTO{},
+	}
+
+	var purchase *models.Purchase
+	txErr := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		creation := newImportProductCreation(options.CreateMissingProducts, userID, userRole)
+		purchaseDTO, itemIndexes, err := service.matchInvoiceItems(tx, invoice, options, creation, report)
+		if err != nil {
+			return err
+		}
+
+		var warnings []dto.PriceWarningDTO
+		purchase, warnings, err = service.purchaseService.createPurchaseInTx(tx, purchaseDTO, userID)
+		if err != nil {
+			return err
+		}
+		// Os avisos referenciam a posição do item na nota, não na compra
+		for _, warning := range warnings {
+			warning.ItemIndex = itemIndexes[warning.ItemIndex]
+			report.Warnings = append(report.Warnings, warning)
+		}
+
+		if options.DryRun {
+			return errImportRollback
+		}
+		return nil
+	})
+	if errors.Is(txErr, errImportRollback) {
+		return report, nil
+	}
+	if errors.Is(txErr, ErrUnknownInvoiceItems) {
+		return report, txErr
+	}
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	report.Committed = true
+	purchaseResponse := service.purchaseService.ToPurchaseResponseDTO(purchase)
+	report.Purchase = &purchaseResponse
+
+	service.purchaseService.checkBudgetAlertsForDates(userID, []time.Time{purchase.PurchaseDate})
+	return report, nil
+}
+
+// matchInvoiceItems resolve o produto de cada item da nota e monta a compra.
+// Retorna também, para cada item da compra, o índice do item correspondente na nota.
+func (service *InvoiceImportService) matchInvoiceItems(
+	tx *gorm.DB,
+	invoice *nfe.Invoice,
+	options dto.InvoiceImportOptionsDTO,
+	creation importProductCreation,
+	report *dto.InvoiceImportReportDTO) (dto.CreatePurchaseDTO, []int, error) {
+
+	productRepository := service.purchaseService.productService.productRepo.WithTx(tx)
+	productCache := make(map[string]*models.Product)
+
+	purchaseDTO := dto.CreatePurchaseDTO{
+		PurchaseDate:     invoice.IssuedAt,
+		PurchaseLocation: invoice.StoreName,
+		StoreCNPJ:        invoice.StoreCNPJ,
+		InvoiceKey:       invoice.AccessKey,
+	}
+	// O emitente é resolvido uma vez para a nota inteira, e não a cada item
+	store, err := resolveImportStore(service.purchaseService.newImportStoreMatcher(tx, creation.userID), invoice.StoreName, invoice.StoreCNPJ)
+	if err != nil {
+		return dto.CreatePurchaseDTO{}, nil, err
+	}
+	if store != nil {
+		purchaseDTO.StoreID = &store.ID
+	}
+	var itemIndexes []int
+	unresolved := 0
+
+	for i, item := range invoice.Items {
+		itemReport := dto.InvoiceItemReportDTO{
+			Number:      item.Number,
+			Barcode:     item.Barcode,
+			Description: item.Description,
+			Quantity:    utils.FormatDecimal(item.Quantity),
+			Unit:        item.Unit,
+			UnitPrice:   utils.FormatForDisplay(item.NetUnitPrice()),
+			Discount:    utils.FormatForDisplay(item.Discount),
+			Total:       utils.FormatForDisplay(item.NetTotal()),
+		}
+
+		row := importRow{ProductName: item.Description, Barcode: item.Barcode}
+		product, unitsPerPack, created, err := service.purchaseService.resolveImportItemProduct(
+			productRepository, productCache, row, store, creation)
+		switch {
+		case errors.Is(err, errImportProductNotFound):
+			if options.SkipUnknownItems {
+				itemReport.Status = InvoiceItemSkipped
+			} else {
+				itemReport.Status = InvoiceItemProposed
+				unresolved++
+			}
+			report.ProposedProducts = append(report.ProposedProducts, dto.ProposedProductDTO{
+				ItemNumber: item.Number,
+				Name:       item.Description,
+				Barcode:    item.Barcode,
+			})
+			report.Items[i] = itemReport
+			continue
+		case err != nil:
+			return dto.CreatePurchaseDTO{}, nil, err
+		}
+
+		itemReport.Status = InvoiceItemMatched
+		if created {
+			itemReport.Status = InvoiceItemCreated
+			report.ProductsCreated = append(report.ProductsCreated, product.Name)
+		}
+		itemReport.ProductID = product.ID
+		itemReport.ProductName = product.Name
+		report.Items[i] = itemReport
+
+		itemDTO := dto.PurchaseItemDTO{
+			ProductID: product.ID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.NetUnitPrice(),
+			Unit:      importedItemUnit(product, item.Unit),
+		}
+		applyUnitsPerPack(&itemDTO, unitsPerPack)
+		purchaseDTO.Items = append(purchaseDTO.Items, itemDTO)
+		itemIndexes = append(itemIndexes, i)
+	}
+
+	if unresolved > 0 {
+		return dto.CreatePurchaseDTO{}, nil, ErrUnknownInvoiceItems
+	}
+	if len(purchaseDTO.Items) == 0 {
+		return dto.CreatePurchaseDTO{}, nil, errors.New("ImportInvoice: nenhum item da nota corresponde a um produto cadastrado")
+	}
+	return purchaseDTO, itemIndexes, nil
+}
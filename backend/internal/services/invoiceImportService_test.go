@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/pkg/nfe"
+)
+
+// Chave de acesso (SP, modelo 65) da nota salva em testdata
+const fixtureAccessKey = "35250112345678000195650010000001231123456786"
+
+// newTestInvoiceImportService monta o serviço só com o fetcher de testdata: os testes abaixo param
+// antes de ImportInvoice, e o banco fica atrás dos repositórios
+func newTestInvoiceImportService() *InvoiceImportService {
+	return NewInvoiceImportService(nil, nil, NewFixtureInvoiceFetcher("testdata"))
+}
+
+func qrCodeURL(host, accessKey string) string {
+	return "https://" + host + "/NFCeConsultaPublica/Paginas/ConsultaQRCode.aspx?p=" + accessKey + "|2|1|1|0A1B2C3D4E"
+}
+
+// TestFetchInvoiceWithFixture lê pelo QR Code a nota de testdata e confere a conversão do XML
+func TestFetchInvoiceWithFixture(t *testing.T) {
+	service := newTestInvoiceImportService()
+
+	invoice, err := service.fetchInvoice(context.Background(), qrCodeURL("www.nfce.fazenda.sp.gov.br", fixtureAccessKey))
+	if err != nil {
+		t.Fatalf("fetchInvoice: %v", err)
+	}
+	if invoice.AccessKey != fixtureAccessKey || invoice.StoreName != "Bom Preço" || invoice.StoreCNPJ != "12345678000195" {
+		t.Errorf("nota = %s/%s/%s", invoice.AccessKey, invoice.StoreName, invoice.StoreCNPJ)
+	}
+	if want := time.Date(2025, 1, 15, 18, 42, 10, 0, time.FixedZone("", -3*60*60)); !invoice.IssuedAt.Equal(want) {
+		t.Errorf("IssuedAt = %v, esperado %v", invoice.IssuedAt, want)
+	}
+	if invoice.Discount != 1.9 || invoice.Total != 34.48 {
+		t.Errorf("desconto/total = %v/%v, esperado 1.9/34.48", invoice.Discount, invoice.Total)
+	}
+
+	want := []nfe.Item{
+		{Barcode: "7891000100103", Quantity: 2, UnitPrice: 4.99, GrossTotal: 9.98},
+		{Barcode: "7891000200209", Quantity: 1, UnitPrice: 18.9, GrossTotal: 18.9, Discount: 1.9},
+		{Barcode: "", Description: "BANANA PRATA", Quantity: 1.25, Unit: "KG", UnitPrice: 6, GrossTotal: 7.5},
+	}
+	if len(invoice.Items) != len(want) {
+		t.Fatalf("nota com %d itens, esperado %d", len(invoice.Items), len(want))
+	}
+	for i, item := range invoice.Items {
+		if item.Barcode != want[i].Barcode || item.Quantity != want[i].Quantity || item.UnitPrice != want[i].UnitPrice ||
+			item.GrossTotal != want[i].GrossTotal || item.Discount != want[i].Discount {
+			t.Errorf("item %d = %+v, esperado %+v", i+1, item, want[i])
+		}
+	}
+	if banana := invoice.Items[2]; banana.Description != want[2].Description || banana.Unit != want[2].Unit {
+		t.Errorf("item sem GTIN = %s/%s, esperado %s/%s", banana.Description, banana.Unit, want[2].Description, want[2].Unit)
+	}
+}
+
+// TestImportInvoiceURLRejectsQRCode confere que URLs inválidas são recusadas antes de consultar o documento
+func TestImportInvoiceURLRejectsQRCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantError string
+	}{
+		{
+			name:      "domínio fora da lista da UF",
+			url:       qrCodeURL("www.sefaz.rs.gov.br", fixtureAccessKey),
+			wantError: "o domínio www.sefaz.rs.gov.br não é um portal de consulta de NFC-e de SP",
+		},
+		{
+			name:      "domínio que apenas contém o da SEFAZ",
+			url:       qrCodeURL("nfce.fazenda.sp.gov.br.exemplo.com", fixtureAccessKey),
+			wantError: "não é um portal de consulta de NFC-e de SP",
+		},
+		{
+			name:      "dígito verificador errado",
+			url:       qrCodeURL("www.nfce.fazenda.sp.gov.br", fixtureAccessKey[:43]+"7"),
+			wantError: "chave de acesso inválida: dígito verificador não confere",
+		},
+	}
+
+	service := newTestInvoiceImportService()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := service.ImportInvoiceURL(context.Background(), test.url, dto.InvoiceImportOptionsDTO{}, 1, string(models.RoleStandard))
+			if err == nil || !strings.Contains(err.Error(), test.wantError) {
+				t.Fatalf("erro = %v, esperado conter %q", err, test.wantError)
+			}
+			var fetchError *InvoiceFetchError
+			if report != nil || errors.As(err, &fetchError) {
+				t.Errorf("a URL deveria ser recusada antes da consulta: report = %+v, erro = %v", report, err)
+			}
+		})
+	}
+}
+
+// TestHTTPInvoiceFetcherRedirects confere que os redirecionamentos também passam pela lista de domínios da UF
+func TestHTTPInvoiceFetcherRedirects(t *testing.T) {
+	qrCode, err := nfe.ParseQRCodeURL(qrCodeURL("www.nfce.fazenda.sp.gov.br", fixtureAccessKey))
+	if err != nil {
+		t.Fatalf("ParseQRCodeURL: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		wantError string
+	}{
+		{"outro portal da UF", "https://homologacao.nfce.fazenda.sp.gov.br/consulta", ""},
+		{"domínio de fora", "http://169.254.169.254/latest/meta-data", "fora dos portais de consulta de SP"},
+		{"sufixo enganoso", "https://nfce.fazenda.sp.gov.br.exemplo.com/", "fora dos portais de consulta de SP"},
+		{"esquema", "ftp://www.nfce.fazenda.sp.gov.br/", "fora dos portais de consulta de SP"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			redirect := httptest.NewRequest(http.MethodGet, test.target, nil)
+			err := checkInvoiceRedirect(qrCode, redirect, []*http.Request{httptest.NewRequest(http.MethodGet, qrCode.URL, nil)})
+			if test.wantError == "" && err != nil {
+				t.Errorf("erro = %v, esperado nenhum", err)
+			}
+			if test.wantError != "" && (err == nil || !strings.Contains(err.Error(), test.wantError)) {
+				t.Errorf("erro = %v, esperado conter %q", err, test.wantError)
+			}
+		})
+	}
+
+	// O cliente de verdade para no primeiro redirecionamento recusado, sem consultar o destino
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "http://metadata.google.internal/computeMetadata/v1/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	fixtureQRCode := *qrCode
+	fixtureQRCode.URL = server.URL
+	_, err = NewHTTPInvoiceFetcher(5*time.Second).Fetch(context.Background(), &fixtureQRCode)
+	if err == nil || !strings.Contains(err.Error(), "fora dos portais de consulta de SP") {
+		t.Errorf("Fetch: erro = %v, esperado recusar o redirecionamento", err)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de notificação emitidos pelos serviços
+const (
+	NotificationBudgetWarning  = "budget.warning"
+	NotificationBudgetExceeded = "budget.exceeded"
+)
+
+// Notification representa um aviso gerado pela regra de negócio para um usuário
+type Notification struct {
+	UserID  uint
+	Type    string
+	Title   string
+	Message string
+	Data    map[string]interface{}
+}
+
+// Notifier entrega notificações geradas pelos serviços (log, webhooks, push, etc.)
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// LogNotifier é a implementação padrão: apenas registra a notificação no log do servidor
+type LogNotifier struct{}
+
+// NewLogNotifier cria uma nova instância de LogNotifier
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify registra a notificação no log
+func (n *LogNotifier) Notify(notification Notification) error {
+	log.Printf("[notificação] usuário=%d tipo=%s %s: %s",
+		notification.UserID, notification.Type, notification.Title, notification.Message)
+	return nil
+}
+
+// OutboxNotifier publica as notificações como eventos de webhook e as repassa ao próximo Notifier
+type OutboxNotifier struct {
+	publisher *EventPublisher
+	next      Notifier
+}
+
+// NewOutboxNotifier cria uma nova instância de OutboxNotifier; next pode ser nil
+func NewOutboxNotifier(publisher *EventPublisher, next Notifier) *OutboxNotifier {
+	return &OutboxNotifier{publisher: publisher, next: next}
+}
+
+// Notify grava o evento na outbox e repassa a notificação
+func (n *OutboxNotifier) Notify(notification Notification) error {
+	userID := notification.UserID
+	data := map[string]interface{}{
+		"title":   notification.Title,
+		"message": notification.Message,
+		"data":    notification.Data,
+	}
+	err := n.publisher.Transaction(func(tx *gorm.DB) error {
+		return n.publisher.Publish(tx, notification.Type, &userID, data)
+	})
+	if err != nil {
+		return err
+	}
+	if n.next != nil {
+		return n.next.Notify(notification)
+	}
+	return nil
+}
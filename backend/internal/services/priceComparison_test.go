@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+)
+
+func comparisonGroup(groupID uint, unit string, count int64) repositories.GroupPriceStatistics {
+	group := repositories.GroupPriceStatistics{GroupID: groupID, Unit: unit}
+	group.Count = count
+	return group
+}
+
+func TestComparisonUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []repositories.GroupPriceStatistics
+		want   string
+	}{
+		{name: "sem registros", want: ""},
+		{
+			name:   "unidade com mais registros somando os grupos",
+			groups: []repositories.GroupPriceStatistics{comparisonGroup(1, "un", 4), comparisonGroup(1, "kg", 3), comparisonGroup(2, "kg", 2)},
+			want:   "kg",
+		},
+		{
+			name:   "empate decidido pelo nome",
+			groups: []repositories.GroupPriceStatistics{comparisonGroup(1, "un", 2), comparisonGroup(2, "kg", 2)},
+			want:   "kg",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := comparisonUnit(test.groups); got != test.want {
+				t.Errorf("comparisonUnit = %q, esperado %q", got, test.want)
+			}
+		})
+	}
+}
@@ -2,55 +2,69 @@ package services
 
 import (
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Parron01/AppMercado/backend/internal/dto"
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/config"
+	"github.com/Parron01/AppMercado/backend/pkg/units"
 	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
 )
 
+// Escopos de visibilidade dos dados de preço
+const (
+	PriceScopePersonal  = "personal"  // Apenas registros do próprio usuário
+	PriceScopeHousehold = "household" // Registros dos membros do domicílio do usuário
+	PriceScopeCommunity = "community" // Agregado anônimo de todos os usuários
+)
+
+// ErrCommunityRecords recusa listar registros de preço da comunidade: mesmo sem identificar o usuário, a
+// data e o local de cada compra permitem reconhecê-lo. A comunidade só é exposta em agregados.
+var ErrCommunityRecords = errors.New("o histórico da comunidade só está disponível agregado por semana; use scope=community em /price-history/product/:id ou as estatísticas")
+
+// ErrInsufficientContributors indica que o agregado da comunidade não atinge o limiar de k-anonimato
+var ErrInsufficientContributors = errors.New("dados insuficientes: poucos usuários contribuíram com preços deste produto para exibir dados da comunidade")
+
 // PriceHistoryService handles business logic for price history
 type PriceHistoryService struct {
-	priceHistoryRepository *repositories.PriceHistoryRepository
-	productService         *ProductService
-	userService            *UserService
+	priceHistoryRepository   *repositories.PriceHistoryRepository
+	productService           *ProductService
+	userService              *UserService
+	householdService         *HouseholdService
+	storeService             *StoreService
+	communityMinContributors int
 }
 
+// Agrupamentos da comparação de preços
+const (
+	PriceGroupByChain  = "chain"  // Uma linha por rede, somando as filiais
+	PriceGroupByBranch = "branch" // Uma linha por estabelecimento
+)
+
 // NewPriceHistoryService creates a new instance of PriceHistoryService
 func NewPriceHistoryService(
 	priceHistoryRepo *repositories.PriceHistoryRepository,
 	productService *ProductService,
-	userService *UserService) *PriceHistoryService {
-	return &PriceHistoryService{
-		priceHistoryRepository: priceHistoryRepo,
-		productService:         productService,
-		userService:            userService,
+	userService *UserService,
+	householdService *HouseholdService,
+	storeService *StoreService,
+	appConfig *config.Config) *PriceHistoryService {
+	minContributors := appConfig.CommunityMinContributors
+	if minContributors < 2 {
+		minContributors = 2 // Um único contribuinte nunca é anônimo
 	}
-}
-
-// createPriceHistory creates a new price history record (internal use only)
-func (service *PriceHistoryService) createPriceHistory(productID uint, userID uint, purchaseDate time.Time, purchasePlace string, pricePaid float64) (*models.PriceHistory, error) {
-	// Verify if product exists
-	product, err := service.productService.GetProductByID(productID)
-	if err != nil {
-		return nil, errors.New("CreatePriceHistory: produto não encontrado: " + err.Error())
-	}
-
-	// Create price history entry
-	priceHistory := &models.PriceHistory{
-		ProductID:     product.ID,
-		UserID:        userID,
-		PurchaseDate:  purchaseDate,
-		PurchasePlace: purchasePlace,
-		PricePaid:     utils.FormatDecimal(pricePaid),
-	}
-
-	if err := service.priceHistoryRepository.CreatePriceHistory(priceHistory); err != nil {
-		return nil, err
+	return &PriceHistoryService{
+		priceHistoryRepository:   priceHistoryRepo,
+		productService:           productService,
+		userService:              userService,
+		householdService:         householdService,
+		storeService:             storeService,
+		communityMinContributors: minContributors,
 	}
-
-	return priceHistory, nil
 }
 
 // GetPriceHistoryByID retrieves a price history record by its ID
@@ -73,21 +87,232 @@ func (service *PriceHistoryService) GetPriceHistoryByID(priceHistoryID uint, use
 // GetPriceHistoryByProductID retrieves price history for a specific product
 func (service *PriceHistoryService) GetPriceHistoryByProductID(productID uint) ([]*models.PriceHistory, error) {
 	// Verify if product exists
-	_, err := service.productService.GetProductByID(productID)
+	product, err := service.productService.GetProductByID(productID)
 	if err != nil {
 		return nil, errors.New("GetPriceHistoryByProductID: produto não encontrado: " + err.Error())
 	}
+	productID = product.ID
 
 	return service.priceHistoryRepository.GetPriceHistoryByProductID(productID)
 }
 
+// GetPriceHistoryByProductScoped retrieves price history for a product visible in the given scope.
+// Registros da comunidade nunca saem um a um: use GetCommunityPriceHistory.
+func (service *PriceHistoryService) GetPriceHistoryByProductScoped(
+	productID uint, scope string, userID uint, startDate, endDate *time.Time) ([]*models.PriceHistory, error) {
+	if scope == PriceScopeCommunity {
+		return nil, ErrCommunityRecords
+	}
+
+	// Verify if product exists
+	product, err := service.productService.GetProductByID(productID)
+	if err != nil {
+		return nil, errors.New("GetPriceHistoryByProductScoped: produto não encontrado: " + err.Error())
+	}
+	productID = product.ID
+
+	filter, err := service.buildScopedFilter(productID, scope, userID,
+		repositories.PriceHistoryFilter{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, err
+	}
+
+	return service.priceHistoryRepository.GetPriceHistoryByProductFiltered(productID, filter)
+}
+
+// GetCommunityPriceHistory resume o histórico de preços da comunidade por semana e unidade, sem usuários,
+// locais nem datas exatas. O produto todo precisa do mínimo de contribuintes no período, e cada semana
+// também: as que não atingem o limiar são omitidas e contadas em hiddenBuckets.
+func (service *PriceHistoryService) GetCommunityPriceHistory(
+	productID uint, userID uint, startDate, endDate *time.Time) (*dto.CommunityPriceHistoryDTO, error) {
+	product, err := service.productService.GetProductByID(productID)
+	if err != nil {
+		return nil, errors.New("GetCommunityPriceHistory: produto não encontrado: " + err.Error())
+	}
+
+	filter, err := service.buildScopedFilter(product.ID, PriceScopeCommunity, userID,
+		repositories.PriceHistoryFilter{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, err
+	}
+	priceHistories, err := service.priceHistoryRepository.GetPriceHistoryByProductFiltered(product.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, hidden := bucketCommunityPrices(priceHistories, service.communityMinContributors)
+	return &dto.CommunityPriceHistoryDTO{
+		Scope:         PriceScopeCommunity,
+		ProductID:     product.ID,
+		ProductName:   product.Name,
+		Buckets:       buckets,
+		HiddenBuckets: hidden,
+	}, nil
+}
+
+// bucketCommunityPrices agrupa os registros por semana (a partir de segunda-feira, em UTC) e unidade, da mais
+// antiga para a mais recente, omitindo as semanas com menos de minContributors usuários distintos
+func bucketCommunityPrices(priceHistories []*models.PriceHistory, minContributors int) ([]dto.CommunityPriceBucketDTO, int) {
+	type bucketKey struct {
+		weekStart time.Time
+		unit      string
+	}
+	type bucket struct {
+		prices       []float64
+		contributors map[uint]bool
+	}
+
+	buckets := make(map[bucketKey]*bucket)
+	var keys []bucketKey
+	for _, priceHistory := range priceHistories {
+		day := priceHistory.PurchaseDate.UTC().Truncate(24 * time.Hour)
+		key := bucketKey{
+			weekStart: day.AddDate(0, 0, -(int(day.Weekday())+6)%7),
+			unit:      priceHistory.Unit,
+		}
+		current, found := buckets[key]
+		if !found {
+			current = &bucket{contributors: make(map[uint]bool)}
+			buckets[key] = current
+			keys = append(keys, key)
+		}
+		current.prices = append(current.prices, priceHistory.PricePaid)
+		current.contributors[priceHistory.UserID] = true
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].weekStart.Equal(keys[j].weekStart) {
+			return keys[i].weekStart.Before(keys[j].weekStart)
+		}
+		return keys[i].unit < keys[j].unit
+	})
+
+	result := []dto.CommunityPriceBucketDTO{}
+	hidden := 0
+	for _, key := range keys {
+		current := buckets[key]
+		if len(current.contributors) < minContributors {
+			hidden++
+			continue
+		}
+		result = append(result, dto.CommunityPriceBucketDTO{
+			WeekStart:    key.weekStart.Format("2006-01-02"),
+			Unit:         key.unit,
+			RecordsCount: len(current.prices),
+			MedianPrice:  utils.FormatForDisplay(utils.Quantile(current.prices, 0.5)),
+			LowestPrice:  utils.FormatForDisplay(utils.Quantile(current.prices, 0)),
+			HighestPrice: utils.FormatForDisplay(utils.Quantile(current.prices, 1)),
+		})
+	}
+	return result, hidden
+}
+
+// buildScopedFilter restringe o filtro (período, estabelecimento, área) aos usuários do escopo. Para a
+// comunidade, conta os contribuintes distintos (k-anonimato) com o próprio filtro, para que estreitar o
+// período ou o local não exponha os preços de poucos usuários.
+func (service *PriceHistoryService) buildScopedFilter(
+	productID uint, scope string, userID uint, filter repositories.PriceHistoryFilter) (repositories.PriceHistoryFilter, error) {
+	return service.scopedFilter(service.priceHistoryRepository, productID, scope, userID, filter)
+}
+
+// scopedFilter é buildScopedFilter com o repositório informado (o transacional, dentro de uma transação)
+func (service *PriceHistoryService) scopedFilter(
+	priceHistoryRepository *repositories.PriceHistoryRepository,
+	productID uint, scope string, userID uint, filter repositories.PriceHistoryFilter) (repositories.PriceHistoryFilter, error) {
+
+	switch scope {
+	case "", PriceScopePersonal:
+		filter.UserIDs = []uint{userID}
+		return filter, nil
+	case PriceScopeHousehold:
+		memberIDs, err := service.householdService.GetHouseholdMemberIDs(userID)
+		if err != nil {
+			return repositories.PriceHistoryFilter{}, err
+		}
+		filter.UserIDs = memberIDs
+		return filter, nil
+	case PriceScopeCommunity:
+		contributors, err := priceHistoryRepository.CountDistinctContributors(productID, filter)
+		if err != nil {
+			return repositories.PriceHistoryFilter{}, err
+		}
+		if contributors < int64(service.communityMinContributors) {
+			return repositories.PriceHistoryFilter{}, ErrInsufficientContributors
+		}
+		return filter, nil
+	default:
+		return repositories.PriceHistoryFilter{}, errors.New("escopo inválido: use personal, household ou community")
+	}
+}
+
+// GetPriceHistoryByProductsScoped busca de uma vez o histórico de vários produtos no escopo pessoal ou do
+// domicílio, agrupado por produto. Registros da comunidade nunca saem um a um.
+func (service *PriceHistoryService) GetPriceHistoryByProductsScoped(
+	productIDs []uint, scope string, userID uint, startDate, endDate *time.Time,
+) (historiesByProduct map[uint][]*models.PriceHistory, restricted map[uint]error, err error) {
+	if scope == PriceScopeCommunity {
+		return nil, nil, ErrCommunityRecords
+	}
+
+	filter, restricted, allowedIDs, err := service.buildScopedFilterForProducts(productIDs, scope, userID,
+		repositories.PriceHistoryFilter{StartDate: startDate, EndDate: endDate})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	priceHistories, err := service.priceHistoryRepository.GetPriceHistoryByProductIDsFiltered(allowedIDs, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	historiesByProduct = make(map[uint][]*models.PriceHistory, len(allowedIDs))
+	for _, priceHistory := range priceHistories {
+		historiesByProduct[priceHistory.ProductID] = append(historiesByProduct[priceHistory.ProductID], priceHistory)
+	}
+	return historiesByProduct, restricted, nil
+}
+
+// buildScopedFilterForProducts é a versão em lote de buildScopedFilter: retorna o filtro do escopo, os
+// produtos bloqueados pelo k-anonimato (contados com o mesmo filtro) e os que podem ser consultados
+func (service *PriceHistoryService) buildScopedFilterForProducts(
+	productIDs []uint, scope string, userID uint, filter repositories.PriceHistoryFilter,
+) (repositories.PriceHistoryFilter, map[uint]error, []uint, error) {
+
+	restricted := make(map[uint]error)
+	if scope != PriceScopeCommunity {
+		filter, err := service.buildScopedFilter(0, scope, userID, filter)
+		return filter, restricted, productIDs, err
+	}
+	allowedIDs, err := service.filterByContributors(productIDs, filter, restricted)
+	return filter, restricted, allowedIDs, err
+}
+
+// filterByContributors mantém apenas os produtos com contribuintes distintos suficientes para o filtro;
+// os demais são marcados em restricted
+func (service *PriceHistoryService) filterByContributors(
+	productIDs []uint, filter repositories.PriceHistoryFilter, restricted map[uint]error) ([]uint, error) {
+
+	contributors, err := service.priceHistoryRepository.CountDistinctContributorsByProductIDs(productIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+	allowedIDs := make([]uint, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if contributors[productID] < int64(service.communityMinContributors) {
+			restricted[productID] = ErrInsufficientContributors
+			continue
+		}
+		allowedIDs = append(allowedIDs, productID)
+	}
+	return allowedIDs, nil
+}
+
 // GetPriceHistoryByProductAndDateRange retrieves price history for a product in a date range
 func (service *PriceHistoryService) GetPriceHistoryByProductAndDateRange(productID uint, startDate, endDate time.Time) ([]*models.PriceHistory, error) {
 	// Verify if product exists
-	_, err := service.productService.GetProductByID(productID)
+	product, err := service.productService.GetProductByID(productID)
 	if err != nil {
 		return nil, errors.New("GetPriceHistoryByProductAndDateRange: produto não encontrado: " + err.Error())
 	}
+	productID = product.ID
 
 	return service.priceHistoryRepository.GetPriceHistoryByProductAndDateRange(productID, startDate, endDate)
 }
@@ -120,24 +345,44 @@ func (service *PriceHistoryService) GetAllPriceHistory(userRole string) ([]*mode
 
 // RegisterPurchaseInPriceHistory creates price history entries for all items in a purchase
 func (service *PriceHistoryService) RegisterPurchaseInPriceHistory(purchase *models.Purchase) error {
+	_, err := service.registerPurchaseInPriceHistory(service.priceHistoryRepository, purchase)
+	return err
+}
+
+// registerPurchaseInPriceHistory creates the price history entries using the given repository,
+// which may be bound to the transaction that created the purchase, and returns the created entries
+func (service *PriceHistoryService) registerPurchaseInPriceHistory(
+	repository *repositories.PriceHistoryRepository, purchase *models.Purchase) ([]*models.PriceHistory, error) {
+	entries := make([]*models.PriceHistory, 0, len(purchase.Items))
 	for _, item := range purchase.Items {
-		_, err := service.createPriceHistory(
-			item.ProductID,
-			purchase.UserID,
-			purchase.PurchaseDate,
-			purchase.PurchaseLocation,
-			item.UnitPrice,
-		)
+		priceHistory := &models.PriceHistory{
+			ProductID:     item.ProductID,
+			UserID:        purchase.UserID,
+			PurchaseDate:  purchase.PurchaseDate,
+			PurchasePlace: purchase.PurchaseLocation,
+			StoreID:       purchase.StoreID,
+			PricePaid:     utils.FormatDecimal(item.UnitPrice),
+			Quantity:      utils.FormatDecimal(item.Quantity),
+			Unit:          item.Unit,
+		}
 
-		if err != nil {
-			return err
+		if err := repository.CreatePriceHistory(priceHistory); err != nil {
+			return nil, err
 		}
+		priceHistory.Product = item.Product
+		entries = append(entries, priceHistory)
 	}
 
-	return nil
+	return entries, nil
+}
+
+// BackfillQuantities preenche a quantidade dos registros de preço anteriores à média ponderada a partir
+// dos itens de compra. Retorna a quantidade de registros atualizados.
+func (service *PriceHistoryService) BackfillQuantities() (int64, error) {
+	return service.priceHistoryRepository.BackfillQuantities()
 }
 
-// CalculateAveragePriceForProduct calcula o preço médio de um produto baseado em todos os registros de histórico
+// CalculateAveragePriceForProduct calcula o preço médio (ponderado pela quantidade) de um produto
 func (service *PriceHistoryService) CalculateAveragePriceForProduct(productID uint) (float64, error) {
 	// Calcular o preço médio usando o repositório
 	avgPrice, err := service.priceHistoryRepository.CalculateAveragePriceForProduct(productID)
@@ -149,58 +394,407 @@ func (service *PriceHistoryService) CalculateAveragePriceForProduct(productID ui
 	return utils.FormatDecimal(avgPrice), nil
 }
 
-// GetProductPriceStatistics retrieves price statistics for a product
-func (service *PriceHistoryService) GetProductPriceStatistics(productID uint) (*dto.PriceHistoryStatisticsDTO, error) {
+// GetProductPriceStatistics retrieves price statistics for a product in the requested scope
+// (personal, household or community), optionally filtered by date range and store
+func (service *PriceHistoryService) GetProductPriceStatistics(
+	productID uint, queryDTO dto.PriceStatisticsQueryDTO, userID uint) (*dto.PriceHistoryStatisticsDTO, error) {
 	// Verify if product exists
 	product, err := service.productService.GetProductByID(productID)
 	if err != nil {
 		return nil, errors.New("GetProductPriceStatistics: produto não encontrado: " + err.Error())
 	}
+	productID = product.ID
 
-	// Get statistics
-	lowestPrice, highestPrice, firstDate, lastDate, count, err := service.priceHistoryRepository.GetPriceStatisticsByProductID(productID)
+	filter := repositories.PriceHistoryFilter{Store: strings.TrimSpace(queryDTO.Store)}
+	setStoreFilter(&filter, queryDTO.StoreID, queryDTO.ChainID)
+	if !queryDTO.StartDate.IsZero() {
+		filter.StartDate = &queryDTO.StartDate
+	}
+	if !queryDTO.EndDate.IsZero() {
+		filter.EndDate = &queryDTO.EndDate
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, errors.New("GetProductPriceStatistics: data final anterior à data inicial")
+	}
+
+	filter, err = service.buildScopedFilter(productID, queryDTO.Scope, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all statistics in a single aggregate query
+	statistics, err := service.priceHistoryRepository.GetPriceStatisticsByProductID(productID, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calcular o preço médio atual usando o método centralizado
-	avgPrice, err := service.CalculateAveragePriceForProduct(productID)
+	unitStatistics, err := service.priceHistoryRepository.GetUnitPriceStatisticsByProductIDs([]uint{productID}, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	statisticsDTO := service.toPriceHistoryStatisticsDTO(product, statistics, unitStatistics[productID])
+	statisticsDTO.Scope = queryDTO.Scope
+	if statisticsDTO.Scope == "" {
+		statisticsDTO.Scope = PriceScopePersonal
+	}
+	return statisticsDTO, nil
+}
+
+// GetProductsPriceStatistics calcula de uma vez as estatísticas de vários produtos no escopo informado,
+// com as mesmas regras de GetProductPriceStatistics. Produtos bloqueados pelo k-anonimato recebem
+// ErrInsufficientContributors em restricted.
+func (service *PriceHistoryService) GetProductsPriceStatistics(
+	products []*models.Product, queryDTO dto.PriceStatisticsQueryDTO, userID uint,
+) (statisticsByProduct map[uint]*dto.PriceHistoryStatisticsDTO, restricted map[uint]error, err error) {
+
+	productIDs := make([]uint, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	filter := repositories.PriceHistoryFilter{Store: strings.TrimSpace(queryDTO.Store)}
+	setStoreFilter(&filter, queryDTO.StoreID, queryDTO.ChainID)
+	if !queryDTO.StartDate.IsZero() {
+		filter.StartDate = &queryDTO.StartDate
+	}
+	if !queryDTO.EndDate.IsZero() {
+		filter.EndDate = &queryDTO.EndDate
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, nil, errors.New("GetProductsPriceStatistics: data final anterior à data inicial")
+	}
+
+	filter, restricted, allowedIDs, err := service.buildScopedFilterForProducts(productIDs, queryDTO.Scope, userID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Todas as estatísticas em uma única consulta agrupada por produto
+	statistics, err := service.priceHistoryRepository.GetPriceStatisticsByProductIDs(allowedIDs, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	unitStatistics, err := service.priceHistoryRepository.GetUnitPriceStatisticsByProductIDs(allowedIDs, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scope := queryDTO.Scope
+	if scope == "" {
+		scope = PriceScopePersonal
+	}
+	statisticsByProduct = make(map[uint]*dto.PriceHistoryStatisticsDTO, len(allowedIDs))
+	for _, product := range products {
+		if _, blocked := restricted[product.ID]; blocked {
+			continue
+		}
+		productStatistics, found := statistics[product.ID]
+		if !found {
+			productStatistics = &repositories.PriceStatistics{}
+		}
+		statisticsDTO := service.toPriceHistoryStatisticsDTO(product, productStatistics, unitStatistics[product.ID])
+		statisticsDTO.Scope = scope
+		statisticsByProduct[product.ID] = statisticsDTO
+	}
+	return statisticsByProduct, restricted, nil
+}
+
+// setStoreFilter restringe o filtro a uma filial ou às filiais de uma rede (0 = sem restrição)
+func setStoreFilter(filter *repositories.PriceHistoryFilter, storeID uint, chainID uint) {
+	if storeID != 0 {
+		filter.StoreID = &storeID
+	}
+	if chainID != 0 {
+		filter.ChainID = &chainID
+	}
+}
+
+// GetProductPriceComparison compara os preços do produto entre redes (groupBy=chain, padrão) ou
+// filiais (groupBy=branch), da mediana mais barata para a mais cara. Os preços são convertidos para
+// kg, L ou unidade e só os da unidade com mais registros são comparados; os demais são contados em
+// otherUnitRecords. Na comunidade, grupos com menos contribuintes que o limiar de k-anonimato são
+// omitidos e contados em hiddenGroups.
+func (service *PriceHistoryService) GetProductPriceComparison(
+	productID uint, queryDTO dto.PriceComparisonQueryDTO, userID uint) (*dto.PriceComparisonDTO, error) {
+	product, err := service.productService.GetProductByID(productID)
+	if err != nil {
+		return nil, errors.New("GetProductPriceComparison: produto não encontrado: " + err.Error())
+	}
+	productID = product.ID
+
+	var filter repositories.PriceHistoryFilter
+	setStoreFilter(&filter, 0, queryDTO.ChainID)
+	if filter.Area, err = newGeoRadius(queryDTO.Lat, queryDTO.Lng, queryDTO.Radius); err != nil {
+		return nil, errors.New("GetProductPriceComparison: " + err.Error())
+	}
+	if !queryDTO.StartDate.IsZero() {
+		filter.StartDate = &queryDTO.StartDate
+	}
+	if !queryDTO.EndDate.IsZero() {
+		filter.EndDate = &queryDTO.EndDate
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, errors.New("GetProductPriceComparison: data final anterior à data inicial")
+	}
+
+	filter, err = service.buildScopedFilter(productID, queryDTO.Scope, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy := queryDTO.GroupBy
+	if groupBy == "" {
+		groupBy = PriceGroupByChain
+	}
+	scope := queryDTO.Scope
+	if scope == "" {
+		scope = PriceScopePersonal
+	}
+
+	groups, err := service.priceHistoryRepository.GetPriceStatisticsByStoreGroup(productID, filter, groupBy == PriceGroupByChain)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &dto.PriceComparisonDTO{
+		Scope:       scope,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		GroupBy:     groupBy,
+		Unit:        comparisonUnit(groups),
+		Groups:      []dto.PriceGroupStatisticsDTO{},
+	}
+	visible := make([]repositories.GroupPriceStatistics, 0, len(groups))
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		if group.Unit != comparison.Unit {
+			comparison.OtherUnitRecords += int(group.Count)
+			continue
+		}
+		if filter.UserIDs == nil && group.Contributors < int64(service.communityMinContributors) {
+			comparison.HiddenGroups++
+			continue
+		}
+		visible = append(visible, group)
+		groupIDs = append(groupIDs, group.GroupID)
+	}
+
+	names, err := service.storeGroupNames(groupBy, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	distances, err := service.storeService.storeGroupDistances(groupBy, filter.Area, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range visible {
+		groupDTO := names[group.GroupID]
+		groupDTO.ID = group.GroupID
+		groupDTO.RecordsCount = int(group.Count)
+		groupDTO.CurrentAvgPrice = utils.FormatForDisplay(group.AvgPrice)
+		groupDTO.WeightedAvgPrice = utils.FormatForDisplay(group.WeightedAvgPrice)
+		groupDTO.MedianPrice = utils.FormatForDisplay(group.MedianPrice)
+		groupDTO.LowestPrice = utils.FormatForDisplay(group.LowestPrice)
+		groupDTO.HighestPrice = utils.FormatForDisplay(group.HighestPrice)
+		groupDTO.LastPricePaid = utils.FormatForDisplay(group.LastPricePaid)
+		if group.LastDate != nil {
+			groupDTO.LastRecordDate = group.LastDate.Format(time.RFC3339)
+		}
+		if distance, ok := distances[group.GroupID]; ok {
+			distance = utils.FormatForDisplay(distance)
+			groupDTO.DistanceKm = &distance
+		}
+		comparison.Groups = append(comparison.Groups, groupDTO)
+	}
+
+	sort.SliceStable(comparison.Groups, func(i, j int) bool {
+		return comparison.Groups[i].MedianPrice < comparison.Groups[j].MedianPrice
+	})
+	if len(comparison.Groups) > 0 && comparison.Groups[0].MedianPrice > 0 {
+		cheapest := comparison.Groups[0].MedianPrice
+		for i := range comparison.Groups {
+			comparison.Groups[i].DifferenceFromCheapest =
+				utils.FormatForDisplay((comparison.Groups[i].MedianPrice - cheapest) / cheapest * 100)
+		}
+	}
+	return comparison, nil
+}
+
+// comparisonUnit escolhe a unidade base em que os grupos são comparados: a com mais registros,
+// com empate decidido pelo nome da unidade (ordem determinística)
+func comparisonUnit(groups []repositories.GroupPriceStatistics) string {
+	records := make(map[string]int64)
+	for _, group := range groups {
+		records[group.Unit] += group.Count
+	}
+	unit := ""
+	for candidate, count := range records {
+		if unit == "" || count > records[unit] || (count == records[unit] && candidate < unit) {
+			unit = candidate
+		}
+	}
+	return unit
+}
+
+// storeGroupNames busca os nomes das redes ou filiais comparadas
+func (service *PriceHistoryService) storeGroupNames(groupBy string, groupIDs []uint) (map[uint]dto.PriceGroupStatisticsDTO, error) {
+	names := make(map[uint]dto.PriceGroupStatisticsDTO, len(groupIDs))
+	if groupBy == PriceGroupByChain {
+		chains, err := service.storeService.storeRepository.GetChainsByIDs(groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, chain := range chains {
+			names[chain.ID] = dto.PriceGroupStatisticsDTO{Name: chain.Name}
+		}
+		return names, nil
+	}
+
+	stores, err := service.storeService.storeRepository.GetStoresByIDs(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, store := range stores {
+		groupDTO := dto.PriceGroupStatisticsDTO{Name: store.Name, ChainID: store.ChainID}
+		if store.Chain != nil {
+			groupDTO.ChainName = store.Chain.Name
+		}
+		names[store.ID] = groupDTO
+	}
+	return names, nil
+}
+
+// toPriceHistoryStatisticsDTO converts aggregated statistics to PriceHistoryStatisticsDTO
+func (service *PriceHistoryService) toPriceHistoryStatisticsDTO(product *models.Product,
+	statistics *repositories.PriceStatistics, unitStatistics []repositories.UnitPriceStatistics) *dto.PriceHistoryStatisticsDTO {
+
 	// Calculate price variation as percentage
 	var priceVariation float64 = 0
-	if lowestPrice > 0 {
-		priceVariation = ((highestPrice - lowestPrice) / lowestPrice) * 100
+	if statistics.LowestPrice > 0 {
+		priceVariation = ((statistics.HighestPrice - statistics.LowestPrice) / statistics.LowestPrice) * 100
 	}
 
 	// Format dates
 	firstDateStr := ""
 	lastDateStr := ""
-	if !firstDate.IsZero() {
-		firstDateStr = firstDate.Format(time.RFC3339)
-	}
-	if !lastDate.IsZero() {
-		lastDateStr = lastDate.Format(time.RFC3339)
-	}
-
-	return &dto.PriceHistoryStatisticsDTO{
-		ProductID:       product.ID,
-		ProductName:     product.Name,
-		CurrentAvgPrice: utils.FormatForDisplay(avgPrice), // Preço médio calculado do histórico
-		LowestPrice:     utils.FormatForDisplay(lowestPrice),
-		HighestPrice:    utils.FormatForDisplay(highestPrice),
-		PriceVariation:  utils.FormatForDisplay(priceVariation),
-		RecordsCount:    int(count),
-		FirstRecordDate: firstDateStr,
-		LastRecordDate:  lastDateStr,
-	}, nil
+	if statistics.FirstDate != nil && !statistics.FirstDate.IsZero() {
+		firstDateStr = statistics.FirstDate.Format(time.RFC3339)
+	}
+	if statistics.LastDate != nil && !statistics.LastDate.IsZero() {
+		lastDateStr = statistics.LastDate.Format(time.RFC3339)
+	}
+
+	statisticsDTO := &dto.PriceHistoryStatisticsDTO{
+		ProductID:        product.ID,
+		ProductName:      product.Name,
+		CurrentAvgPrice:  utils.FormatForDisplay(statistics.AvgPrice), // Preço médio calculado do histórico
+		LowestPrice:      utils.FormatForDisplay(statistics.LowestPrice),
+		HighestPrice:     utils.FormatForDisplay(statistics.HighestPrice),
+		PriceVariation:   utils.FormatForDisplay(priceVariation),
+		RecordsCount:     int(statistics.Count),
+		FirstRecordDate:  firstDateStr,
+		LastRecordDate:   lastDateStr,
+		WeightedAvgPrice: utils.FormatForDisplay(statistics.WeightedAvgPrice),
+		MedianPrice:      utils.FormatForDisplay(statistics.MedianPrice),
+		StdDevPrice:      utils.FormatForDisplay(statistics.StdDevPrice),
+		P10Price:         utils.FormatForDisplay(statistics.P10Price),
+		P90Price:         utils.FormatForDisplay(statistics.P90Price),
+		LastPricePaid:    utils.FormatForDisplay(statistics.LastPricePaid),
+	}
+
+	if unitStatistics := preferredUnitStatistics(product, unitStatistics); unitStatistics != nil {
+		statisticsDTO.UnitPrices = &dto.UnitPriceStatisticsDTO{
+			Unit:             unitStatistics.Unit,
+			CurrentAvgPrice:  utils.FormatForDisplay(unitStatistics.AvgPrice),
+			WeightedAvgPrice: utils.FormatForDisplay(unitStatistics.WeightedAvgPrice),
+			MedianPrice:      utils.FormatForDisplay(unitStatistics.MedianPrice),
+			LowestPrice:      utils.FormatForDisplay(unitStatistics.LowestPrice),
+			HighestPrice:     utils.FormatForDisplay(unitStatistics.HighestPrice),
+			LastPricePaid:    utils.FormatForDisplay(unitStatistics.LastPricePaid),
+		}
+	}
+	return statisticsDTO
+}
+
+// preferredUnitStatistics escolhe entre as estatísticas normalizadas a da unidade base do produto ou,
+// sem embalagem cadastrada, a unidade com mais registros
+func preferredUnitStatistics(
+	product *models.Product, unitStatistics []repositories.UnitPriceStatistics) *repositories.UnitPriceStatistics {
+	var preferred *repositories.UnitPriceStatistics
+	productUnit, hasUnit := units.Parse(product.Unit)
+	for i := range unitStatistics {
+		candidate := &unitStatistics[i]
+		if hasUnit && candidate.Unit == string(productUnit.Base()) {
+			return candidate
+		}
+		if preferred == nil || candidate.Count > preferred.Count {
+			preferred = candidate
+		}
+	}
+	return preferred
+}
+
+// CheckPriceOutlier compara o preço unitário informado com o histórico do produto. Usa os dados da
+// comunidade quando há contribuintes suficientes; caso contrário, apenas os do domicílio do usuário.
+// A consulta roda na transação da compra, de modo que os preços gravados antes nela (como os das
+// compras anteriores de uma importação) entram na comparação.
+func (service *PriceHistoryService) CheckPriceOutlier(
+	tx *gorm.DB, productID uint, userID uint, unitPrice float64, unit string) (priceOutlierResult, error) {
+	priceHistoryRepository := service.priceHistoryRepository.WithTx(tx)
+
+	// Preços por kg não se comparam com preços por embalagem
+	unitFilter := repositories.PriceHistoryFilter{Unit: unit}
+	filter, err := service.scopedFilter(priceHistoryRepository, productID, PriceScopeCommunity, userID, unitFilter)
+	if errors.Is(err, ErrInsufficientContributors) {
+		filter, err = service.scopedFilter(priceHistoryRepository, productID, PriceScopeHousehold, userID, unitFilter)
+	}
+	if err != nil {
+		return priceOutlierResult{}, err
+	}
+
+	prices, err := priceHistoryRepository.GetRecentPricesForProduct(productID, filter, outlierHistoryLimit)
+	if err != nil {
+		return priceOutlierResult{}, err
+	}
+
+	return detectPriceOutlier(prices, unitPrice), nil
+}
+
+// GetPriceOutlierReport lists existing price history rows outside the IQR fences of their product (admin only)
+func (service *PriceHistoryService) GetPriceOutlierReport(productID uint, userRole string) ([]dto.PriceOutlierReportDTO, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("GetPriceOutlierReport: permissão negada: apenas administradores podem consultar preços atípicos")
+	}
+
+	rows, err := service.priceHistoryRepository.GetPriceOutliers(
+		productID, outlierMinIQRSamples, outlierIQRMultiplier, outlierMinMarginRatio)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]dto.PriceOutlierReportDTO, len(rows))
+	for i, row := range rows {
+		report[i] = dto.PriceOutlierReportDTO{
+			PriceHistoryID: row.ID,
+			ProductID:      row.ProductID,
+			ProductName:    row.ProductName,
+			UserID:         row.UserID,
+			PurchaseDate:   row.PurchaseDate.Format(time.RFC3339),
+			PurchasePlace:  row.PurchasePlace,
+			PricePaid:      utils.FormatForDisplay(row.PricePaid),
+			MedianPrice:    utils.FormatForDisplay(row.MedianPrice),
+			LowerFence:     utils.FormatForDisplay(row.LowerFence),
+			UpperFence:     utils.FormatForDisplay(row.UpperFence),
+			SampleSize:     row.SampleSize,
+		}
+	}
+	return report, nil
 }
 
 // ToPriceHistoryResponseDTO converts a PriceHistory model to PriceHistoryResponseDTO
 func (service *PriceHistoryService) ToPriceHistoryResponseDTO(priceHistory *models.PriceHistory) dto.PriceHistoryResponseDTO {
-	return dto.PriceHistoryResponseDTO{
+	responseDTO := dto.PriceHistoryResponseDTO{
 		ID:            priceHistory.ID,
 		ProductID:     priceHistory.ProductID,
 		ProductName:   priceHistory.Product.Name,
@@ -208,10 +802,17 @@ func (service *PriceHistoryService) ToPriceHistoryResponseDTO(priceHistory *mode
 		UserName:      priceHistory.User.Name,
 		PurchaseDate:  priceHistory.PurchaseDate.Format(time.RFC3339),
 		PurchasePlace: priceHistory.PurchasePlace,
+		StoreID:       priceHistory.StoreID,
 		PricePaid:     utils.FormatForDisplay(priceHistory.PricePaid), // Formatar para exibição
+		Unit:          priceHistory.Unit,
 		CreatedAt:     priceHistory.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:     priceHistory.UpdatedAt.Format(time.RFC3339),
 	}
+	if unitPrice, unit, ok := normalizedUnitPrice(&priceHistory.Product, priceHistory.PricePaid, priceHistory.Unit); ok {
+		unitPrice = utils.FormatForDisplay(unitPrice)
+		responseDTO.UnitPrice, responseDTO.UnitPriceUnit = &unitPrice, string(unit)
+	}
+	return responseDTO
 }
 
 // ToPriceHistoryResponseDTOList converts a list of PriceHistory models to PriceHistoryResponseDTOs
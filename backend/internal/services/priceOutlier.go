@@ -0,0 +1,70 @@
+package services
+
+import (
+	"math"
+
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Parâmetros da detecção de preços atípicos
+const (
+	outlierIQRMultiplier   = 1.5 // Cercas de Tukey: Q1 - 1.5*IQR e Q3 + 1.5*IQR
+	outlierMinMarginRatio  = 0.1 // Margem mínima de 10% da mediana quando o IQR é muito pequeno
+	outlierMinIQRSamples   = 5   // Abaixo disso o IQR não é confiável e usamos a razão com a mediana
+	outlierMinRatioSamples = 2
+	outlierRatioFactor     = 3.0 // Preço 3x acima ou abaixo da mediana é considerado atípico
+	outlierHistoryLimit    = 200 // Quantidade de registros recentes usados como referência
+)
+
+// priceOutlierResult descreve a comparação de um preço com o histórico do produto
+type priceOutlierResult struct {
+	IsOutlier      bool
+	Method         string
+	Median         float64
+	ExpectedMin    float64
+	ExpectedMax    float64
+	SampleSize     int
+	SuggestedPrice *float64
+}
+
+// detectPriceOutlier compara o preço com o histórico usando cercas IQR (com amostra suficiente)
+// ou a razão com a mediana (amostras pequenas). É determinística e não acessa o banco.
+func detectPriceOutlier(history []float64, price float64) priceOutlierResult {
+	result := priceOutlierResult{SampleSize: len(history)}
+	if len(history) < outlierMinRatioSamples {
+		return result
+	}
+
+	result.Median = utils.Quantile(history, 0.5)
+
+	if len(history) >= outlierMinIQRSamples {
+		q1 := utils.Quantile(history, 0.25)
+		q3 := utils.Quantile(history, 0.75)
+		margin := math.Max(outlierIQRMultiplier*(q3-q1), outlierMinMarginRatio*result.Median)
+		result.Method = "iqr"
+		result.ExpectedMin = math.Max(q1-margin, 0)
+		result.ExpectedMax = q3 + margin
+	} else {
+		result.Method = "ratio"
+		result.ExpectedMin = result.Median / outlierRatioFactor
+		result.ExpectedMax = result.Median * outlierRatioFactor
+	}
+
+	result.IsOutlier = price < result.ExpectedMin || price > result.ExpectedMax
+	if result.IsOutlier {
+		result.SuggestedPrice = suggestPriceCorrection(price, result.ExpectedMin, result.ExpectedMax)
+	}
+	return result
+}
+
+// suggestPriceCorrection procura um erro de digitação comum (vírgula deslocada) que traga o preço
+// para dentro da faixa esperada, ex.: 59.90 digitado no lugar de 5.99
+func suggestPriceCorrection(price, expectedMin, expectedMax float64) *float64 {
+	for _, factor := range []float64{0.1, 10, 0.01, 100} {
+		candidate := utils.FormatForDisplay(price * factor)
+		if candidate >= expectedMin && candidate <= expectedMax {
+			return &candidate
+		}
+	}
+	return nil
+}
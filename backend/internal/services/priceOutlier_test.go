@@ -0,0 +1,94 @@
+package services
+
+import "testing"
+
+func TestDetectPriceOutlier(t *testing.T) {
+	suggested := func(price float64) *float64 { return &price }
+
+	tests := []struct {
+		name        string
+		history     []float64
+		price       float64
+		method      string
+		outlier     bool
+		median      float64
+		expectedMin float64
+		expectedMax float64
+		suggestion  *float64
+	}{
+		{name: "sem histórico", history: nil, price: 10},
+		{name: "um único registro não basta", history: []float64{5}, price: 500},
+
+		// Menos de 5 registros: razão com a mediana
+		{
+			name: "razão: preço dentro da faixa", history: []float64{5, 6, 7}, price: 10,
+			method: "ratio", median: 6, expectedMin: 2, expectedMax: 18,
+		},
+		{
+			name: "razão: vírgula deslocada", history: []float64{5, 6, 7}, price: 30,
+			method: "ratio", outlier: true, median: 6, expectedMin: 2, expectedMax: 18, suggestion: suggested(3),
+		},
+		{
+			name: "razão: quatro registros ainda não usam IQR", history: []float64{4, 5, 6, 7}, price: 17,
+			method: "ratio", outlier: true, median: 5.5, expectedMin: 5.5 / 3, expectedMax: 16.5,
+		},
+		{
+			name: "razão: valores iguais aceitam até o limite", history: []float64{3, 3}, price: 9,
+			method: "ratio", median: 3, expectedMin: 1, expectedMax: 9,
+		},
+		{
+			name: "razão: valores iguais, acima do limite", history: []float64{3, 3}, price: 9.01,
+			method: "ratio", outlier: true, median: 3, expectedMin: 1, expectedMax: 9,
+		},
+
+		// A partir de 5 registros: cercas de Tukey
+		{
+			name: "IQR: preço dentro das cercas", history: []float64{5, 5.2, 5.5, 5.8, 6}, price: 6.5,
+			method: "iqr", median: 5.5, expectedMin: 4.3, expectedMax: 6.7,
+		},
+		{
+			name: "IQR: vírgula deslocada", history: []float64{6, 5.8, 5.5, 5.2, 5}, price: 59.9,
+			method: "iqr", outlier: true, median: 5.5, expectedMin: 4.3, expectedMax: 6.7, suggestion: suggested(5.99),
+		},
+		{
+			// IQR zero: a margem mínima de 10% da mediana evita marcar qualquer variação como atípica
+			name: "IQR: valores iguais usam a margem mínima", history: []float64{4.99, 4.99, 4.99, 4.99, 4.99, 4.99}, price: 5.4,
+			method: "iqr", median: 4.99, expectedMin: 4.491, expectedMax: 5.489,
+		},
+		{
+			name: "IQR: valores iguais, fora da margem e sem correção", history: []float64{4.99, 4.99, 4.99, 4.99, 4.99, 4.99}, price: 5.6,
+			method: "iqr", outlier: true, median: 4.99, expectedMin: 4.491, expectedMax: 5.489,
+		},
+		{
+			name: "IQR: valores iguais com vírgula deslocada", history: []float64{4.99, 4.99, 4.99, 4.99, 4.99, 4.99}, price: 49.9,
+			method: "iqr", outlier: true, median: 4.99, expectedMin: 4.491, expectedMax: 5.489, suggestion: suggested(4.99),
+		},
+		{
+			name: "IQR: cerca inferior não fica negativa", history: []float64{1, 1, 1, 10, 10}, price: 0.01,
+			method: "iqr", median: 1, expectedMin: 0, expectedMax: 23.5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := detectPriceOutlier(test.history, test.price)
+			if result.SampleSize != len(test.history) {
+				t.Errorf("SampleSize = %d, esperado %d", result.SampleSize, len(test.history))
+			}
+			if result.Method != test.method || result.IsOutlier != test.outlier {
+				t.Fatalf("método/atípico = %q/%v, esperado %q/%v", result.Method, result.IsOutlier, test.method, test.outlier)
+			}
+			if !almostEqual(result.Median, test.median) ||
+				!almostEqual(result.ExpectedMin, test.expectedMin) || !almostEqual(result.ExpectedMax, test.expectedMax) {
+				t.Errorf("mediana e faixa = %v [%v, %v], esperado %v [%v, %v]", result.Median,
+					result.ExpectedMin, result.ExpectedMax, test.median, test.expectedMin, test.expectedMax)
+			}
+			switch {
+			case test.suggestion == nil && result.SuggestedPrice != nil:
+				t.Errorf("SuggestedPrice = %v, esperado nenhum", *result.SuggestedPrice)
+			case test.suggestion != nil && (result.SuggestedPrice == nil || *result.SuggestedPrice != *test.suggestion):
+				t.Errorf("SuggestedPrice = %v, esperado %v", result.SuggestedPrice, *test.suggestion)
+			}
+		})
+	}
+}
@@ -2,23 +2,37 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Parron01/AppMercado/backend/internal/dto"
 	"github.com/Parron01/AppMercado/backend/internal/models"
 	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/units"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
 	"gorm.io/gorm"
 )
 
 // ProductService define a interface para a lógica de negócios de produtos
 type ProductService struct {
 	productRepo         *repositories.ProductRepository
+	transactionManager  *repositories.TransactionManager
 	priceHistoryService *PriceHistoryService // Adicionado para acessar estatísticas
+	eventPublisher      *EventPublisher
 }
 
 // NewProductService cria uma nova instância de ProductService
-func NewProductService(productRepo *repositories.ProductRepository) *ProductService {
-	return &ProductService{productRepo: productRepo}
+func NewProductService(
+	productRepo *repositories.ProductRepository,
+	transactionManager *repositories.TransactionManager) *ProductService {
+	return &ProductService{productRepo: productRepo, transactionManager: transactionManager}
 }
 
 // SetPriceHistoryService configura o serviço de histórico de preços para evitar dependência circular
@@ -26,15 +40,51 @@ func (s *ProductService) SetPriceHistoryService(priceHistoryService *PriceHistor
 	s.priceHistoryService = priceHistoryService
 }
 
+// SetEventPublisher configura a publicação de eventos de webhook (product.updated)
+func (s *ProductService) SetEventPublisher(eventPublisher *EventPublisher) {
+	s.eventPublisher = eventPublisher
+}
+
 // CreateProduct cria um novo produto
 func (s *ProductService) CreateProduct(createDTO dto.CreateProductDTO) (*models.Product, error) {
+	return s.createProduct(createDTO, nil)
+}
+
+// maxPendingProposals limita as sugestões de um usuário aguardando moderação
+const maxPendingProposals = 50
+
+// ProposeProduct registra a sugestão de um produto novo por um usuário. A sugestão fica pendente de
+// moderação e já pode ser usada nas compras de quem sugeriu, mas não aparece para os demais.
+func (s *ProductService) ProposeProduct(createDTO dto.CreateProductDTO, userID uint) (*models.Product, error) {
+	pending, err := s.productRepo.CountPendingProposals(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= maxPendingProposals {
+		return nil, fmt.Errorf("ProposeProduct: limite de %d sugestões aguardando moderação atingido", maxPendingProposals)
+	}
+	return s.createProduct(createDTO, &userID)
+}
+
+// createProduct cria um produto aprovado ou, com proposedBy, a sugestão pendente do usuário
+func (s *ProductService) createProduct(createDTO dto.CreateProductDTO, proposedBy *uint) (*models.Product, error) {
 	var barcodeToSave *string
 
 	if createDTO.Barcode != "" {
+		code, err := normalizeProductBarcode(createDTO.Barcode)
+		if err != nil {
+			return nil, errors.New("CreateProduct: " + err.Error())
+		}
+		createDTO.Barcode = code
+
+		if err := s.checkAdditionalBarcode(createDTO.Barcode); err != nil {
+			return nil, errors.New("CreateProduct: " + err.Error())
+		}
+
 		// Verificar se já existe um produto com o mesmo código de barras (se não for vazio)
-		_, err := s.productRepo.GetProductByBarcode(createDTO.Barcode)
+		existing, err := s.productRepo.GetProductByBarcode(createDTO.Barcode)
 		if err == nil { // Se err for nil, significa que um produto foi encontrado
-			return nil, errors.New("produto com este código de barras já existe")
+			return nil, barcodeConflictError(existing, proposedBy)
 		}
 		if !errors.Is(err, gorm.ErrRecordNotFound) { // Se o erro não for 'registro não encontrado', é outro erro
 			return nil, err
@@ -45,8 +95,19 @@ func (s *ProductService) CreateProduct(createDTO dto.CreateProductDTO) (*models.
 	} // Se createDTO.Barcode for "", barcodeToSave permanece nil, resultando em NULL no banco
 
 	product := &models.Product{
-		Name:    createDTO.Name,
-		Barcode: barcodeToSave,
+		Name:         createDTO.Name,
+		Barcode:      barcodeToSave,
+		Brand:        createDTO.Brand,
+		PackageLabel: createDTO.PackageLabel,
+		ImageURL:     createDTO.ImageURL,
+		Status:       models.ProductApproved,
+		ProposedByID: proposedBy,
+	}
+	if proposedBy != nil {
+		product.Status = models.ProductPending
+	}
+	if err := applyProductPackage(product, createDTO.PackageQuantity, createDTO.Unit); err != nil {
+		return nil, err
 	}
 
 	if err := s.productRepo.CreateProduct(product); err != nil {
@@ -55,9 +116,113 @@ func (s *ProductService) CreateProduct(createDTO dto.CreateProductDTO) (*models.
 	return product, nil
 }
 
-// GetProductByID busca um produto pelo ID
+// barcodeConflictError descreve o conflito com o produto que já tem o código de barras. Uma sugestão
+// pendente segura o código até a moderação: o admin é orientado a aprová-la ou recusá-la, quem sugeriu
+// recebe o ID dela e os demais usuários não ficam sabendo de qual produto se trata.
+func barcodeConflictError(existing *models.Product, proposedBy *uint) error {
+	if existing.Status != models.ProductPending {
+		return errors.New("produto com este código de barras já existe")
+	}
+	switch {
+	case proposedBy == nil:
+		return fmt.Errorf("CreateProduct: o código de barras está na sugestão %d, aguardando moderação; aprove ou recuse a sugestão", existing.ID)
+	case productUsableBy(existing, *proposedBy):
+		return fmt.Errorf("CreateProduct: você já sugeriu o produto %d com este código de barras", existing.ID)
+	default:
+		return errors.New("CreateProduct: um produto com este código de barras já foi sugerido e aguarda moderação")
+	}
+}
+
+// normalizeProductBarcode valida o dígito verificador do código de barras e o converte para GTIN-14.
+// Etiquetas de balança (prefixo 2) mudam a cada pesagem e não identificam o produto: o código interno
+// da balança é cadastrado como PLU.
+func normalizeProductBarcode(code string) (string, error) {
+	normalized, err := barcode.Normalize(code)
+	if err != nil {
+		return "", err
+	}
+	if barcode.IsVariableMeasure(normalized) {
+		return "", errors.New("código com prefixo 2 é uma etiqueta de balança; cadastre o PLU do produto em /plus/create")
+	}
+	return normalized, nil
+}
+
+// checkAdditionalBarcode recusa um código (GTIN-14) já cadastrado como código adicional de um produto
+func (s *ProductService) checkAdditionalBarcode(code string) error {
+	productBarcode, err := s.productRepo.GetProductBarcodeByCode(code)
+	if err == nil {
+		return fmt.Errorf("código de barras já cadastrado como código adicional do produto %d", productBarcode.ProductID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// AddProductBarcode cadastra um código de barras adicional do produto. Em embalagens múltiplas
+// (unitsPerPack > 1), o código leva ao produto base e as quantidades lidas são multiplicadas.
+func (s *ProductService) AddProductBarcode(productID uint, barcodeDTO dto.CreateProductBarcodeDTO) (*models.ProductBarcode, error) {
+	product, err := s.GetProductByID(productID)
+	if err != nil {
+		return nil, errors.New("AddProductBarcode: " + err.Error())
+	}
+	productID = product.ID
+	code, err := normalizeProductBarcode(barcodeDTO.Code)
+	if err != nil {
+		return nil, errors.New("AddProductBarcode: " + err.Error())
+	}
+
+	existing, err := s.productRepo.GetProductByBarcode(code)
+	if err == nil {
+		return nil, fmt.Errorf("AddProductBarcode: código de barras já é o código principal do produto %d", existing.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.checkAdditionalBarcode(code); err != nil {
+		return nil, errors.New("AddProductBarcode: " + err.Error())
+	}
+
+	unitsPerPack := barcodeDTO.UnitsPerPack
+	if unitsPerPack == 0 {
+		unitsPerPack = 1
+	}
+	productBarcode := &models.ProductBarcode{
+		ProductID:    productID,
+		Code:         code,
+		UnitsPerPack: unitsPerPack,
+		Description:  strings.TrimSpace(barcodeDTO.Description),
+	}
+	if err := s.productRepo.CreateProductBarcode(productBarcode); err != nil {
+		return nil, err
+	}
+	return productBarcode, nil
+}
+
+// GetProductBarcodes lista os códigos de barras adicionais do produto
+func (s *ProductService) GetProductBarcodes(productID uint) ([]*models.ProductBarcode, error) {
+	product, err := s.GetProductByID(productID)
+	if err != nil {
+		return nil, errors.New("GetProductBarcodes: " + err.Error())
+	}
+	return s.productRepo.GetProductBarcodes(product.ID)
+}
+
+// RemoveProductBarcode remove um código de barras adicional do produto
+func (s *ProductService) RemoveProductBarcode(productID uint, barcodeID uint) error {
+	removed, err := s.productRepo.DeleteProductBarcode(productID, barcodeID)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return errors.New("RemoveProductBarcode: código de barras não encontrado neste produto")
+	}
+	return nil
+}
+
+// GetProductByID busca um produto pelo ID; o ID de um produto mesclado leva ao produto que o absorveu
 func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
-	product, err := s.productRepo.GetProductByID(id)
+	product, err := findProduct(s.productRepo, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("produto não encontrado")
@@ -67,9 +232,44 @@ func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
 	return product, nil
 }
 
-// GetAllProducts retorna todos os produtos
-func (s *ProductService) GetAllProducts() ([]models.Product, error) {
-	return s.productRepo.GetAllProducts()
+// GetVisibleProductByID busca pelo ID um produto visível ao usuário: sugestões pendentes de outros
+// usuários só são encontradas por admins, que as moderam
+func (s *ProductService) GetVisibleProductByID(id uint, userID uint, userRole string) (*models.Product, error) {
+	product, err := s.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if userRole != string(models.RoleAdmin) && !productUsableBy(product, userID) {
+		return nil, errors.New("produto não encontrado")
+	}
+	return product, nil
+}
+
+// findProduct busca o produto pelo ID, seguindo o redirecionamento de um produto mesclado
+func findProduct(productRepository *repositories.ProductRepository, id uint) (*models.Product, error) {
+	product, err := productRepository.GetProductByID(id)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return product, err
+	}
+	redirect, redirectErr := productRepository.GetProductRedirect(id)
+	if errors.Is(redirectErr, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if redirectErr != nil {
+		return nil, redirectErr
+	}
+	return productRepository.GetProductByID(redirect.ProductID)
+}
+
+// GetAllProducts retorna os produtos aprovados e as sugestões pendentes do usuário
+func (s *ProductService) GetAllProducts(userID uint) ([]models.Product, error) {
+	return s.productRepo.GetVisibleProducts(userID)
+}
+
+// productUsableBy indica se o usuário pode usar o produto em compras e categorias: sugestões pendentes
+// só podem ser usadas por quem sugeriu
+func productUsableBy(product *models.Product, userID uint) bool {
+	return product.Status != models.ProductPending || (product.ProposedByID != nil && *product.ProposedByID == userID)
 }
 
 // UpdateProduct atualiza um produto existente
@@ -81,10 +281,43 @@ func (s *ProductService) UpdateProduct(id uint, updateDTO dto.UpdateProductDTO)
 		}
 		return nil, err
 	}
+	if err := s.applyProductUpdate(product, updateDTO); err != nil {
+		return nil, err
+	}
+	if err := s.saveProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
 
+// applyProductUpdate aplica ao produto os campos informados na atualização
+func (s *ProductService) applyProductUpdate(product *models.Product, updateDTO dto.UpdateProductDTO) error {
 	if updateDTO.Name != nil {
 		product.Name = *updateDTO.Name
 	}
+	if updateDTO.Brand != nil {
+		product.Brand = *updateDTO.Brand
+	}
+	if updateDTO.PackageLabel != nil {
+		product.PackageLabel = *updateDTO.PackageLabel
+	}
+	if updateDTO.ImageURL != nil {
+		product.ImageURL = *updateDTO.ImageURL
+	}
+	if updateDTO.Unit != nil && strings.TrimSpace(*updateDTO.Unit) == "" {
+		product.PackageQuantity, product.Unit = nil, ""
+	} else if updateDTO.Unit != nil || updateDTO.PackageQuantity != nil || updateDTO.PackageLabel != nil {
+		unit, quantity := product.Unit, product.PackageQuantity
+		if updateDTO.Unit != nil {
+			unit = *updateDTO.Unit
+		}
+		if updateDTO.PackageQuantity != nil {
+			quantity = updateDTO.PackageQuantity
+		}
+		if err := applyProductPackage(product, quantity, unit); err != nil {
+			return err
+		}
+	}
 
 	// Lógica para atualizar o barcode
 	if updateDTO.Barcode != nil { // Se o campo barcode foi fornecido na atualização (não é nil o ponteiro do DTO)
@@ -93,6 +326,15 @@ func (s *ProductService) UpdateProduct(id uint, updateDTO dto.UpdateProductDTO)
 		if newBarcodeValueFromDTO == "" { // Cliente quer definir o barcode como nulo/vazio
 			product.Barcode = nil
 		} else { // Cliente quer definir um barcode não vazio
+			code, err := normalizeProductBarcode(newBarcodeValueFromDTO)
+			if err != nil {
+				return errors.New("UpdateProduct: " + err.Error())
+			}
+			newBarcodeValueFromDTO = code
+			if err := s.checkAdditionalBarcode(code); err != nil {
+				return errors.New("UpdateProduct: " + err.Error())
+			}
+
 			// Verificar se o novo barcode é diferente do atual (ou se o atual era nil)
 			// e se é único, antes de atribuir.
 			isCurrentBarcodeNil := product.Barcode == nil
@@ -106,20 +348,31 @@ func (s *ProductService) UpdateProduct(id uint, updateDTO dto.UpdateProductDTO)
 			if newBarcodeValueFromDTO != currentBarcodeValue || (isCurrentBarcodeNil && newBarcodeValueFromDTO != "") {
 				existingProduct, err := s.productRepo.GetProductByBarcode(newBarcodeValueFromDTO)
 				if err == nil && existingProduct.ID != product.ID { // Encontrou outro produto com o mesmo barcode
-					return nil, errors.New("novo código de barras já está em uso por outro produto")
+					return errors.New("novo código de barras já está em uso por outro produto")
 				}
 				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) { // Erro inesperado ao buscar barcode
-					return nil, err
+					return err
 				}
 			}
 			product.Barcode = &newBarcodeValueFromDTO // Atribui o novo barcode não vazio
 		}
 	} // Se updateDTO.Barcode for nil (campo omitido no JSON), não fazemos nada com o barcode do produto
+	return nil
+}
 
-	if err := s.productRepo.UpdateProduct(product); err != nil {
-		return nil, err
+// saveProduct grava o produto e, quando ele está no catálogo, o evento product.updated
+func (s *ProductService) saveProduct(product *models.Product) error {
+	if s.eventPublisher == nil || product.Status == models.ProductPending {
+		return s.productRepo.UpdateProduct(product)
 	}
-	return product, nil
+
+	// Produto e evento product.updated (global) são gravados na mesma transação
+	return s.eventPublisher.Transaction(func(tx *gorm.DB) error {
+		if err := s.productRepo.WithTx(tx).UpdateProduct(product); err != nil {
+			return err
+		}
+		return s.eventPublisher.Publish(tx, EventProductUpdated, nil, s.ToProductResponseDTO(product))
+	})
 }
 
 // DeleteProduct remove um produto
@@ -134,8 +387,8 @@ func (s *ProductService) DeleteProduct(id uint) error {
 	return s.productRepo.DeleteProduct(id)
 }
 
-// GetProductStatistics retorna estatísticas de preço de um produto
-func (s *ProductService) GetProductStatistics(productID uint) (*dto.PriceHistoryStatisticsDTO, error) {
+// GetProductStatistics retorna estatísticas de preço de um produto, com filtros opcionais
+func (s *ProductService) GetProductStatistics(productID uint, queryDTO dto.PriceStatisticsQueryDTO, userID uint) (*dto.PriceHistoryStatisticsDTO, error) {
 	// Verificar se o serviço de histórico de preços está configurado
 	if s.priceHistoryService == nil {
 		return nil, errors.New("serviço de estatísticas de preço não disponível")
@@ -148,7 +401,682 @@ func (s *ProductService) GetProductStatistics(productID uint) (*dto.PriceHistory
 	}
 
 	// Usar o serviço de histórico de preços para obter as estatísticas
-	return s.priceHistoryService.GetProductPriceStatistics(product.ID)
+	return s.priceHistoryService.GetProductPriceStatistics(product.ID, queryDTO, userID)
+}
+
+// GetProductPriceComparison compara os preços de um produto entre redes ou filiais
+func (s *ProductService) GetProductPriceComparison(
+	productID uint, queryDTO dto.PriceComparisonQueryDTO, userID uint) (*dto.PriceComparisonDTO, error) {
+	if s.priceHistoryService == nil {
+		return nil, errors.New("serviço de estatísticas de preço não disponível")
+	}
+	return s.priceHistoryService.GetProductPriceComparison(productID, queryDTO, userID)
+}
+
+const (
+	productDuplicateThreshold = 0.85 // Semelhança mínima padrão entre os nomes de produtos duplicados
+	productDuplicateLimit     = 50   // Quantidade padrão de grupos de duplicados retornados
+)
+
+// packageSizePattern reconhece o tamanho da embalagem num nome normalizado ("leite integral 1l",
+// "arroz 5 kg", "suco 6 x 200ml"), ignorado na comparação dos nomes
+var packageSizePattern = regexp.MustCompile(
+	`\b(?:\d+\s?x\s?)?\d+(?:\s\d+)?\s?(?:kg|kgs|g|gr|grs|mg|ml|l|lt|lts|litro|litros|un|und|unid)\b`)
+
+// productNameKey normaliza o nome para a busca por duplicados, sem o tamanho da embalagem
+func productNameKey(name string) string {
+	return strings.Join(strings.Fields(packageSizePattern.ReplaceAllString(utils.NormalizeText(name), " ")), " ")
+}
+
+// productDuplicateLink liga dois produtos (posições na lista) que parecem ser o mesmo
+type productDuplicateLink struct {
+	first   int
+	second  int
+	score   float64
+	barcode bool
+}
+
+// productDuplicateGroup reúne os produtos ligados, direta ou indiretamente
+type productDuplicateGroup struct {
+	members []*models.Product
+	score   float64 // Menor semelhança entre os produtos ligados
+	barcode bool
+}
+
+// FindDuplicateProducts procura produtos que parecem ser o mesmo: códigos de barras do mesmo item (o
+// mesmo GTIN, ou variantes de embalagem que só diferem no indicador do GTIN-14), inclusive os códigos
+// adicionais, e nomes parecidos sem o tamanho da embalagem. Produtos com conteúdo de embalagem diferente
+// não são ligados pelo nome. Os grupos ligados por código vêm primeiro, depois os de maior semelhança.
+func (s *ProductService) FindDuplicateProducts(queryDTO dto.ProductDuplicatesQueryDTO) ([]dto.ProductDuplicateGroupDTO, error) {
+	threshold := queryDTO.Threshold
+	if threshold == 0 {
+		threshold = productDuplicateThreshold
+	}
+	limit := queryDTO.Limit
+	if limit == 0 {
+		limit = productDuplicateLimit
+	}
+
+	products, err := s.productRepo.GetAllProducts()
+	if err != nil {
+		return nil, err
+	}
+	productBarcodes, err := s.productRepo.GetAllProductBarcodes()
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[uint]int, len(products))
+	for i, product := range products {
+		positions[product.ID] = i
+	}
+
+	var links []productDuplicateLink
+
+	// Códigos do mesmo item: o GTIN-14 sem o indicador de embalagem e sem o dígito verificador
+	itemCodes := make(map[string][]int)
+	addCode := func(position int, code string) {
+		normalized, err := barcode.Normalize(code)
+		if err != nil || barcode.IsVariableMeasure(normalized) {
+			return
+		}
+		key := normalized[1 : barcode.GTIN14Length-1]
+		if !slices.Contains(itemCodes[key], position) {
+			itemCodes[key] = append(itemCodes[key], position)
+		}
+	}
+	for i, product := range products {
+		if product.Barcode != nil {
+			addCode(i, *product.Barcode)
+		}
+	}
+	for _, productBarcode := range productBarcodes {
+		if position, found := positions[productBarcode.ProductID]; found {
+			addCode(position, productBarcode.Code)
+		}
+	}
+	for _, group := range itemCodes {
+		for _, other := range group[1:] {
+			links = append(links, productDuplicateLink{first: group[0], second: other, score: 1, barcode: true})
+		}
+	}
+
+	// Nomes parecidos, comparados entre os produtos com a mesma primeira palavra
+	keys := make([]string, len(products))
+	blocks := make(map[string][]int)
+	for i, product := range products {
+		keys[i] = productNameKey(product.Name)
+		if fields := strings.Fields(keys[i]); len(fields) > 0 {
+			blocks[fields[0]] = append(blocks[fields[0]], i)
+		}
+	}
+	for _, block := range blocks {
+		for a := 0; a < len(block); a++ {
+			for b := a + 1; b < len(block); b++ {
+				first, second := block[a], block[b]
+				if !samePackageContent(&products[first], &products[second]) {
+					continue
+				}
+				// A diferença de tamanho já limita a semelhança possível
+				shorter, longer := utf8.RuneCountInString(keys[first]), utf8.RuneCountInString(keys[second])
+				if shorter > longer {
+					shorter, longer = longer, shorter
+				}
+				if float64(shorter)/float64(longer) < threshold {
+					continue
+				}
+				if score := utils.Similarity(keys[first], keys[second]); score >= threshold {
+					links = append(links, productDuplicateLink{first: first, second: second, score: score})
+				}
+			}
+		}
+	}
+
+	// Grupos: produtos ligados direta ou indiretamente
+	parents := make([]int, len(products))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	for _, link := range links {
+		parents[find(link.first)] = find(link.second)
+	}
+	groupsByRoot := make(map[int]*productDuplicateGroup)
+	for _, link := range links {
+		root := find(link.first)
+		group, found := groupsByRoot[root]
+		if !found {
+			group = &productDuplicateGroup{score: 1}
+			groupsByRoot[root] = group
+		}
+		group.score = min(group.score, link.score)
+		group.barcode = group.barcode || link.barcode
+	}
+	groups := make([]*productDuplicateGroup, 0, len(groupsByRoot))
+	for i := range products {
+		if group, found := groupsByRoot[find(i)]; found {
+			if len(group.members) == 0 {
+				groups = append(groups, group)
+			}
+			group.members = append(group.members, &products[i])
+		}
+	}
+	for _, group := range groups {
+		sort.Slice(group.members, func(i, j int) bool { return group.members[i].ID < group.members[j].ID })
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].barcode != groups[j].barcode {
+			return groups[i].barcode
+		}
+		if groups[i].score != groups[j].score {
+			return groups[i].score > groups[j].score
+		}
+		return groups[i].members[0].ID < groups[j].members[0].ID
+	})
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+
+	groupDTOs := make([]dto.ProductDuplicateGroupDTO, len(groups))
+	for i, group := range groups {
+		productDTOs := make([]dto.ProductResponseDTO, len(group.members))
+		for j, product := range group.members {
+			productDTOs[j] = s.ToProductResponseDTO(product)
+		}
+		reason := "name"
+		if group.barcode {
+			reason = "barcode"
+		}
+		groupDTOs[i] = dto.ProductDuplicateGroupDTO{
+			Reason:            reason,
+			Score:             utils.FormatForDisplay(group.score),
+			SuggestedTargetID: suggestedMergeTarget(group.members).ID,
+			Products:          productDTOs,
+		}
+	}
+	return groupDTOs, nil
+}
+
+// samePackageContent indica se as embalagens podem ser a mesma: conteúdos informados nos dois produtos
+// precisam ser iguais na unidade base
+func samePackageContent(first *models.Product, second *models.Product) bool {
+	if first.PackageQuantity == nil || second.PackageQuantity == nil {
+		return true
+	}
+	firstUnit, secondUnit := units.Unit(first.Unit), units.Unit(second.Unit)
+	if !units.Compatible(firstUnit, secondUnit) {
+		return false
+	}
+	return math.Abs(firstUnit.ToBase(*first.PackageQuantity)-secondUnit.ToBase(*second.PackageQuantity)) < 0.0005
+}
+
+// suggestedMergeTarget escolhe o produto que deve receber os demais: um do catálogo (não uma sugestão
+// pendente), de preferência com o código de barras da unidade (indicador 0 no GTIN-14), depois com algum
+// código e, por fim, o mais antigo (os membros estão em ordem de ID)
+func suggestedMergeTarget(members []*models.Product) *models.Product {
+	best, bestRank := members[0], -1
+	for _, product := range members {
+		rank := 0
+		if product.Barcode != nil {
+			rank = 1
+			if code, err := barcode.Normalize(*product.Barcode); err == nil && code[0] == '0' {
+				rank = 2
+			}
+		}
+		if product.Status != models.ProductPending {
+			rank += 3
+		}
+		if rank > bestRank {
+			best, bestRank = product, rank
+		}
+	}
+	return best
+}
+
+// GetProductProposals lista as sugestões de produto: para o admin, a fila de moderação (por padrão, as
+// pendentes); para os demais usuários, as próprias sugestões em qualquer situação
+func (s *ProductService) GetProductProposals(
+	queryDTO dto.ProductProposalQueryDTO, userID uint, userRole string) ([]*models.Product, error) {
+	status := models.ProductStatus(queryDTO.Status)
+	if userRole != string(models.RoleAdmin) {
+		return s.productRepo.GetProposals(status, userID)
+	}
+	if status == "" {
+		status = models.ProductPending
+	}
+	return s.productRepo.GetProposals(status, 0)
+}
+
+// pendingProposal busca uma sugestão aguardando moderação
+func pendingProposal(productRepository *repositories.ProductRepository, id uint) (*models.Product, error) {
+	product, err := productRepository.GetProductByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("sugestão não encontrada")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != models.ProductPending {
+		return nil, errors.New("o produto não é uma sugestão aguardando moderação")
+	}
+	return product, nil
+}
+
+// ApproveProductProposal aprova uma sugestão com as correções do moderador; o produto entra no catálogo
+func (s *ProductService) ApproveProductProposal(id uint, approveDTO dto.ApproveProductProposalDTO) (*models.Product, error) {
+	product, err := pendingProposal(s.productRepo, id)
+	if err != nil {
+		return nil, errors.New("ApproveProductProposal: " + err.Error())
+	}
+	if err := s.applyProductUpdate(product, approveDTO.UpdateProductDTO); err != nil {
+		return nil, err
+	}
+	product.Status = models.ProductApproved
+	product.ModerationNote = strings.TrimSpace(approveDTO.Note)
+	if err := s.saveProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// MergeProductProposal aceita uma sugestão como duplicata de um produto do catálogo: compras, preços e
+// categorias passam ao produto existente, que também recebe o código de barras da sugestão
+func (s *ProductService) MergeProductProposal(id uint, mergeDTO dto.MergeProductProposalDTO) (*MergeProductsResult, error) {
+	if mergeDTO.TargetID == id {
+		return nil, errors.New("MergeProductProposal: o produto de destino não pode ser a própria sugestão")
+	}
+
+	var result *MergeProductsResult
+	err := s.transactionManager.Transaction(func(tx *gorm.DB) error {
+		productRepository := s.productRepo.WithTx(tx)
+
+		proposal, err := pendingProposal(productRepository, id)
+		if err != nil {
+			return errors.New("MergeProductProposal: " + err.Error())
+		}
+		target, err := mergeTarget(productRepository, mergeDTO.TargetID)
+		if err != nil {
+			return errors.New("MergeProductProposal: " + err.Error())
+		}
+
+		proposal.ModerationNote = strings.TrimSpace(mergeDTO.Note)
+		result, err = s.mergeProducts(tx, target, []*models.Product{proposal}, nil, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RejectProductProposal recusa uma sugestão. O que já usa a sugestão (itens de compra, preços e
+// categorias) migra para o produto substituto, obrigatório nesse caso, e o ID da sugestão passa a levar
+// a ele; o código de barras da sugestão é liberado.
+func (s *ProductService) RejectProductProposal(id uint, rejectDTO dto.RejectProductProposalDTO) (*models.Product, error) {
+	if rejectDTO.ReplacementID == id {
+		return nil, errors.New("RejectProductProposal: o produto substituto não pode ser a própria sugestão")
+	}
+
+	var proposal *models.Product
+	err := s.transactionManager.Transaction(func(tx *gorm.DB) error {
+		productRepository := s.productRepo.WithTx(tx)
+
+		var err error
+		proposal, err = pendingProposal(productRepository, id)
+		if err != nil {
+			return errors.New("RejectProductProposal: " + err.Error())
+		}
+		proposal.Status = models.ProductRejected
+		proposal.ModerationNote = strings.TrimSpace(rejectDTO.Reason)
+
+		if rejectDTO.ReplacementID != 0 {
+			replacement, err := mergeTarget(productRepository, rejectDTO.ReplacementID)
+			if err != nil {
+				return errors.New("RejectProductProposal: " + err.Error())
+			}
+			_, err = s.mergeProducts(tx, replacement, []*models.Product{proposal}, nil, false)
+			return err
+		}
+
+		references, err := productRepository.CountProductReferences(proposal.ID)
+		if err != nil {
+			return err
+		}
+		if references > 0 {
+			return fmt.Errorf("RejectProductProposal: a sugestão possui %d compras, preços ou categorias vinculados; "+
+				"informe replacementId para migrá-los", references)
+		}
+		proposal.Barcode = nil
+		if err := productRepository.UpdateProduct(proposal); err != nil {
+			return err
+		}
+		return productRepository.DeleteProduct(proposal.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// MergeProductsResult resume uma mesclagem de produtos
+type MergeProductsResult struct {
+	Product                     *models.Product
+	MergedProductIDs            []uint
+	PurchaseItemsUpdated        int64
+	PriceHistoriesUpdated       int64
+	UserCategoryProductsUpdated int64
+}
+
+// MergeProducts funde produtos duplicados no de destino, em uma única transação: itens de compra,
+// registros de preço, associações com categorias, códigos adicionais e PLUs passam ao destino; o código
+// de barras de cada origem vira o código do destino (quando ele não tem) ou um código adicional; os dados
+// que faltam ao destino são completados pelas origens, que são removidas e redirecionadas ao destino.
+func (s *ProductService) MergeProducts(mergeDTO dto.MergeProductsDTO) (*MergeProductsResult, error) {
+	sourceIDs := make([]uint, 0, len(mergeDTO.SourceIDs))
+	seen := make(map[uint]bool, len(mergeDTO.SourceIDs))
+	for _, sourceID := range mergeDTO.SourceIDs {
+		if sourceID == mergeDTO.TargetID {
+			return nil, errors.New("MergeProducts: o produto de destino não pode estar entre as origens")
+		}
+		if !seen[sourceID] {
+			seen[sourceID] = true
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return nil, errors.New("MergeProducts: informe ao menos um produto de origem")
+	}
+	for sourceID, unitsPerPack := range mergeDTO.UnitsPerPack {
+		if !seen[sourceID] {
+			return nil, fmt.Errorf("MergeProducts: unitsPerPack informado para um produto que não está entre as origens: %d", sourceID)
+		}
+		if unitsPerPack < 1 || unitsPerPack > 1000 {
+			return nil, fmt.Errorf("MergeProducts: unitsPerPack do produto %d deve estar entre 1 e 1000", sourceID)
+		}
+	}
+
+	var result *MergeProductsResult
+	err := s.transactionManager.Transaction(func(tx *gorm.DB) error {
+		productRepository := s.productRepo.WithTx(tx)
+
+		target, err := mergeTarget(productRepository, mergeDTO.TargetID)
+		if err != nil {
+			return errors.New("MergeProducts: " + err.Error())
+		}
+		sources, err := productRepository.GetProductsByIDs(sourceIDs)
+		if err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return errors.New("MergeProducts: um ou mais produtos de origem não foram encontrados")
+		}
+
+		result, err = s.mergeProducts(tx, target, sources, mergeDTO.UnitsPerPack, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeTarget busca o produto que vai receber outros numa mesclagem; sugestões pendentes não podem receber
+func mergeTarget(productRepository *repositories.ProductRepository, targetID uint) (*models.Product, error) {
+	target, err := productRepository.GetProductByID(targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("produto não encontrado: %d", targetID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target.Status == models.ProductPending {
+		return nil, fmt.Errorf("o produto %d é uma sugestão pendente; aprove-a antes de usá-la como destino", targetID)
+	}
+	return target, nil
+}
+
+// mergeProducts funde as origens no destino dentro da transação. Com absorb, o destino recebe os códigos de
+// barras e os dados que faltam a ele, e as sugestões pendentes entre as origens ficam como mescladas; sem
+// absorb (sugestões recusadas), os dados das origens são descartados e apenas as referências migram.
+func (s *ProductService) mergeProducts(
+	tx *gorm.DB, target *models.Product, sources []*models.Product, unitsPerPack map[uint]int, absorb bool) (*MergeProductsResult, error) {
+	productRepository := s.productRepo.WithTx(tx)
+	result := &MergeProductsResult{}
+
+	for _, source := range sources {
+		result.MergedProductIDs = append(result.MergedProductIDs, source.ID)
+		sourceUnits := max(unitsPerPack[source.ID], 1)
+
+		// O código é único: sai da origem antes de ir para o destino
+		code := source.Barcode
+		source.Barcode = nil
+		if absorb && source.Status == models.ProductPending {
+			source.Status = models.ProductMerged
+		}
+		if err := productRepository.UpdateProduct(source); err != nil {
+			return nil, err
+		}
+		if !absorb {
+			continue
+		}
+
+		if code != nil {
+			if target.Barcode == nil && sourceUnits == 1 {
+				target.Barcode = code
+			} else {
+				productBarcode := &models.ProductBarcode{
+					ProductID:    target.ID,
+					Code:         *code,
+					UnitsPerPack: sourceUnits,
+					Description:  truncateText(source.Name, 100),
+				}
+				if err := productRepository.CreateProductBarcode(productBarcode); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if sourceUnits == 1 {
+			fillMissingProductData(target, source)
+		}
+	}
+
+	repointed, err := productRepository.RepointProducts(result.MergedProductIDs, target.ID, unitsPerPack)
+	if err != nil {
+		return nil, err
+	}
+	result.PurchaseItemsUpdated = repointed.PurchaseItems
+	result.PriceHistoriesUpdated = repointed.PriceHistories
+	result.UserCategoryProductsUpdated = repointed.UserCategoryProducts
+	for _, source := range sources {
+		if err := productRepository.DeleteProduct(source.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := productRepository.UpdateProduct(target); err != nil {
+		return nil, err
+	}
+	result.Product = target
+	if s.eventPublisher != nil {
+		if err := s.eventPublisher.Publish(tx, EventProductUpdated, nil, s.ToProductResponseDTO(target)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// fillMissingProductData completa os campos vazios do destino com os da origem
+func fillMissingProductData(target *models.Product, source *models.Product) {
+	if target.Brand == "" {
+		target.Brand = source.Brand
+	}
+	if target.PackageLabel == "" {
+		target.PackageLabel = source.PackageLabel
+	}
+	if target.PackageQuantity == nil && source.PackageQuantity != nil {
+		target.PackageQuantity, target.Unit = source.PackageQuantity, source.Unit
+	}
+	if target.ImageURL == "" {
+		target.ImageURL = source.ImageURL
+	}
+}
+
+// GetProductsByIDs busca de uma vez os produtos com os IDs informados, dentre os visíveis ao usuário
+// (todos, para admins). Como em GetVisibleProductByID, o ID de um produto mesclado leva ao produto
+// que o absorveu.
+func (s *ProductService) GetProductsByIDs(ids []uint, userID uint, userRole string) (map[uint]*models.Product, error) {
+	productsByID, err := s.getProductsByIDs(ids, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []uint
+	for _, id := range ids {
+		if _, found := productsByID[id]; !found {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return productsByID, nil
+	}
+	redirects, err := s.productRepo.GetProductRedirects(missing)
+	if err != nil || len(redirects) == 0 {
+		return productsByID, err
+	}
+	targetIDs := make([]uint, len(redirects))
+	for i, redirect := range redirects {
+		targetIDs[i] = redirect.ProductID
+	}
+	targets, err := s.getProductsByIDs(targetIDs, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	for _, redirect := range redirects {
+		if target, found := targets[redirect.ProductID]; found {
+			productsByID[redirect.FromProductID] = target
+		}
+	}
+	return productsByID, nil
+}
+
+// getProductsByIDs busca os produtos visíveis ao usuário pelos IDs, sem seguir redirecionamentos
+func (s *ProductService) getProductsByIDs(ids []uint, userID uint, userRole string) (map[uint]*models.Product, error) {
+	var products []*models.Product
+	var err error
+	if userRole == string(models.RoleAdmin) {
+		products, err = s.productRepo.GetProductsByIDs(ids)
+	} else {
+		products, err = s.productRepo.GetVisibleProductsByIDs(ids, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	productsByID := make(map[uint]*models.Product, len(products))
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+	return productsByID, nil
+}
+
+// SearchProducts lista os produtos visíveis ao usuário em páginas, opcionalmente filtrando por trecho do nome ou da marca
+func (s *ProductService) SearchProducts(search string, limit, offset int, userID uint) ([]*models.Product, error) {
+	return s.productRepo.SearchProducts(strings.TrimSpace(search), limit, offset, userID)
+}
+
+// applyProductPackage valida e grava a quantidade e a unidade da embalagem; sem nenhuma das duas,
+// tenta lê-las do rótulo da embalagem ("500 g", "6 x 200 ml")
+func applyProductPackage(product *models.Product, quantity *float64, unitName string) error {
+	unitName = strings.TrimSpace(unitName)
+	if unitName == "" && quantity == nil {
+		if labelQuantity, unit, ok := units.ParseLabel(product.PackageLabel); ok {
+			product.PackageQuantity, product.Unit = &labelQuantity, string(unit)
+		}
+		return nil
+	}
+	if unitName == "" {
+		return errors.New("unidade de medida é obrigatória quando a quantidade da embalagem é informada")
+	}
+	unit, ok := units.Parse(unitName)
+	if !ok {
+		return errors.New("unidade de medida inválida: use " + units.Names())
+	}
+	if quantity == nil {
+		if unit != units.Each {
+			return errors.New("quantidade da embalagem é obrigatória para a unidade " + string(unit))
+		}
+		single := 1.0
+		quantity = &single
+	}
+	if *quantity <= 0 {
+		return errors.New("quantidade da embalagem deve ser maior que zero")
+	}
+	product.PackageQuantity, product.Unit = quantity, string(unit)
+	return nil
+}
+
+// productUnitPrice converte o preço da embalagem em preço por kg, L ou unidade; false quando o
+// produto não tem a embalagem cadastrada
+func productUnitPrice(product *models.Product, price float64) (float64, units.Unit, bool) {
+	if product == nil || product.PackageQuantity == nil {
+		return 0, "", false
+	}
+	unit, ok := units.Parse(product.Unit)
+	if !ok {
+		return 0, "", false
+	}
+	unitPrice, ok := units.PricePerBase(price, *product.PackageQuantity, unit)
+	return unitPrice, unit.Base(), ok
+}
+
+// normalizedUnitPrice converte o preço pago por unidade do item em preço por kg, L ou unidade: itens
+// medidos (g, kg, ml, L) pela própria unidade e itens contados (un) pela embalagem do produto
+func normalizedUnitPrice(product *models.Product, price float64, itemUnit string) (float64, units.Unit, bool) {
+	if unit, ok := units.Parse(itemUnit); ok && unit.Measured() {
+		return price / unit.ToBase(1), unit.Base(), true
+	}
+	return productUnitPrice(product, price)
+}
+
+// resolveItemUnit valida a unidade de um item comprado contra a unidade de medida do produto: itens
+// contados (un, o padrão) servem para qualquer produto; itens pesados ou medidos precisam de unidade
+// compatível com a do produto (g/kg ou ml/L), e produtos vendidos por unidade só aceitam un
+func resolveItemUnit(product *models.Product, unitName string) (units.Unit, error) {
+	if strings.TrimSpace(unitName) == "" {
+		return units.Each, nil
+	}
+	unit, ok := units.Parse(unitName)
+	if !ok {
+		return "", errors.New("unidade inválida: use " + units.Names())
+	}
+	if unit == units.Each {
+		return unit, nil
+	}
+	productUnit, ok := units.Parse(product.Unit)
+	if !ok {
+		return unit, nil // Produto sem unidade de medida cadastrada: não há com o que comparar
+	}
+	if !units.Compatible(unit, productUnit) {
+		return "", fmt.Errorf("unidade %s incompatível com a do produto %s (%s)", unit, product.Name, productUnit)
+	}
+	return unit, nil
+}
+
+// importedItemUnit usa a unidade lida de uma nota fiscal ou cupom quando ela é reconhecida e
+// compatível com o produto; caso contrário, o item é registrado como contado (un)
+func importedItemUnit(product *models.Product, unitName string) string {
+	if _, ok := units.Parse(unitName); !ok {
+		return string(units.Each)
+	}
+	unit, err := resolveItemUnit(product, unitName)
+	if err != nil {
+		return string(units.Each)
+	}
+	return string(unit)
 }
 
 // ToProductResponseDTO converte um modelo Product para ProductResponseDTO
@@ -159,9 +1087,52 @@ func (s *ProductService) ToProductResponseDTO(product *models.Product) dto.Produ
 		Barcode:   product.Barcode,
 		CreatedAt: product.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: product.UpdatedAt.Format(time.RFC3339),
+
+		Brand:        product.Brand,
+		PackageLabel: product.PackageLabel,
+		ImageURL:     product.ImageURL,
+
+		PackageQuantity: product.PackageQuantity,
+		Unit:            product.Unit,
+
+		Status:         string(product.Status),
+		ProposedByID:   product.ProposedByID,
+		ModerationNote: product.ModerationNote,
+	}
+}
+
+// ToMergeProductsResultDTO converte um MergeProductsResult para MergeProductsResultDTO
+func (s *ProductService) ToMergeProductsResultDTO(result *MergeProductsResult) dto.MergeProductsResultDTO {
+	return dto.MergeProductsResultDTO{
+		Product:                     s.ToProductResponseDTO(result.Product),
+		MergedProductIDs:            result.MergedProductIDs,
+		PurchaseItemsUpdated:        result.PurchaseItemsUpdated,
+		PriceHistoriesUpdated:       result.PriceHistoriesUpdated,
+		UserCategoryProductsUpdated: result.UserCategoryProductsUpdated,
 	}
 }
 
+// ToProductBarcodeResponseDTO converte um código de barras adicional para ProductBarcodeResponseDTO
+func (s *ProductService) ToProductBarcodeResponseDTO(productBarcode *models.ProductBarcode) dto.ProductBarcodeResponseDTO {
+	return dto.ProductBarcodeResponseDTO{
+		ID:           productBarcode.ID,
+		ProductID:    productBarcode.ProductID,
+		Code:         productBarcode.Code,
+		UnitsPerPack: productBarcode.UnitsPerPack,
+		Description:  productBarcode.Description,
+		CreatedAt:    productBarcode.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToProductBarcodeResponseDTOList converte uma lista de códigos de barras adicionais
+func (s *ProductService) ToProductBarcodeResponseDTOList(productBarcodes []*models.ProductBarcode) []dto.ProductBarcodeResponseDTO {
+	responseDTOs := make([]dto.ProductBarcodeResponseDTO, len(productBarcodes))
+	for i, productBarcode := range productBarcodes {
+		responseDTOs[i] = s.ToProductBarcodeResponseDTO(productBarcode)
+	}
+	return responseDTOs
+}
+
 // ToProductResponseDTOList converte uma lista de modelos Product para uma lista de ProductResponseDTO
 func (s *ProductService) ToProductResponseDTOList(products []models.Product) []dto.ProductResponseDTO {
 	responseDTOs := make([]dto.ProductResponseDTO, len(products))
@@ -0,0 +1,622 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/units"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// errImportRollback desfaz a transação de importação em dry-run ou quando há erros
+	errImportRollback = errors.New("importação desfeita")
+	// errImportProductNotFound indica um produto que não foi encontrado e não pôde ser criado
+	errImportProductNotFound = errors.New("produto não encontrado")
+)
+
+// Campos que podem ser mapeados para colunas do CSV
+const (
+	importFieldDate      = "date"
+	importFieldStore     = "store"
+	importFieldProduct   = "product"
+	importFieldBarcode   = "barcode"
+	importFieldQuantity  = "quantity"
+	importFieldUnitPrice = "unitPrice"
+	importFieldUnit      = "unit" // Opcional: g, kg, ml, L ou un (padrão)
+)
+
+// importFieldOrder define a ordem padrão das colunas quando o arquivo não tem cabeçalho
+var importFieldOrder = []string{
+	importFieldDate, importFieldStore, importFieldProduct, importFieldBarcode, importFieldQuantity, importFieldUnitPrice,
+	importFieldUnit,
+}
+
+// importRow é uma linha do CSV já convertida e validada
+type importRow struct {
+	Line        int
+	Date        time.Time
+	Store       string
+	ProductName string
+	Barcode     string
+	Quantity    float64
+	UnitPrice   float64
+	Unit        string
+}
+
+// importGroup reúne as linhas que formam uma única compra (mesma data e local)
+type importGroup struct {
+	Date  time.Time
+	Store string
+	Rows  []importRow
+}
+
+// PurchaseImportService handles bulk import of purchases from CSV files
+type PurchaseImportService struct {
+	purchaseService    *PurchaseService
+	transactionManager *repositories.TransactionManager
+}
+
+// NewPurchaseImportService creates a new instance of PurchaseImportService
+func NewPurchaseImportService(
+	purchaseService *PurchaseService,
+	transactionManager *repositories.TransactionManager) *PurchaseImportService {
+	return &PurchaseImportService{
+		purchaseService:    purchaseService,
+		transactionManager: transactionManager,
+	}
+}
+
+// ImportPurchasesCSV importa compras de um CSV. As linhas são agrupadas em compras por data e local,
+// os produtos são resolvidos por código de barras ou nome, e cada compra passa pela mesma validação de
+// CreatePurchase. Tudo roda em uma única transação: só é confirmada quando não há nenhum erro e não é dry-run.
+func (service *PurchaseImportService) ImportPurchasesCSV(
+	reader io.Reader, options dto.PurchaseImportOptionsDTO, userID uint, userRole string) (*dto.PurchaseImportReportDTO, error) {
+
+	creation := newImportProductCreation(options.CreateMissingProducts, userID, userRole)
+
+	rows, totalRows, rowErrors, err := parsePurchaseCSV(reader, options)
+	if err != nil {
+		return nil, errors.New("ImportPurchasesCSV: " + err.Error())
+	}
+
+	report := &dto.PurchaseImportReportDTO{
+		DryRun:          options.DryRun,
+		TotalRows:       totalRows,
+		Purchases:       []dto.ImportPurchaseSummaryDTO{},
+		ProductsCreated: []string{},
+		Errors:          rowErrors,
+		Warnings:        []dto.ImportRowMessageDTO{},
+	}
+
+	groups := groupImportRows(rows)
+
+	txErr := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		productRepository := service.purchaseService.productService.productRepo.WithTx(tx)
+		productCache := make(map[string]*models.Product)
+		storeMatcher := service.purchaseService.newImportStoreMatcher(tx, userID)
+
+		for index, group := range groups {
+			summary, ok, err := service.importGroup(tx, productRepository, productCache, storeMatcher, index, group, creation, userID, report)
+			if err != nil {
+				return err
+			}
+			if ok {
+				report.Purchases = append(report.Purchases, summary)
+				report.ValidRows += len(group.Rows)
+			}
+		}
+
+		if options.DryRun || len(report.Errors) > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+	if txErr != nil && !errors.Is(txErr, errImportRollback) {
+		return nil, txErr
+	}
+
+	report.Committed = txErr == nil
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+	if !report.Committed {
+		// IDs gerados dentro de uma transação desfeita não existem
+		for i := range report.Purchases {
+			report.Purchases[i].PurchaseID = 0
+		}
+		return report, nil
+	}
+
+	service.purchaseService.checkBudgetAlertsForDates(userID, groupDates(groups))
+	return report, nil
+}
+
+// importGroup resolve os produtos e cria a compra de um grupo dentro de um savepoint, para que
+// um erro em uma compra não impeça a validação das demais. Falhas do próprio savepoint deixariam a
+// transação em estado desconhecido e são retornadas como erro, abortando a importação.
+func (service *PurchaseImportService) importGroup(
+	tx *gorm.DB,
+	productRepository *repositories.ProductRepository,
+	productCache map[string]*models.Product,
+	storeMatcher *storeMatcher,
+	index int,
+	group importGroup,
+	creation importProductCreation,
+	userID uint,
+	report *dto.PurchaseImportReportDTO) (dto.ImportPurchaseSummaryDTO, bool, error) {
+
+	savepoint := fmt.Sprintf("import_group_%d", index)
+	if err := tx.SavePoint(savepoint).Error; err != nil {
+		return dto.ImportPurchaseSummaryDTO{}, false, errors.New("importGroup: falha ao criar o savepoint: " + err.Error())
+	}
+	rollback := func() error {
+		clear(productCache) // Produtos e estabelecimentos criados neste grupo foram desfeitos junto com o savepoint
+		if storeMatcher != nil {
+			storeMatcher.reset()
+		}
+		if err := tx.RollbackTo(savepoint).Error; err != nil {
+			return errors.New("importGroup: falha ao desfazer o savepoint: " + err.Error())
+		}
+		return nil
+	}
+
+	purchaseDTO := dto.CreatePurchaseDTO{
+		PurchaseDate:     group.Date,
+		PurchaseLocation: group.Store,
+	}
+	lines := make([]int, len(group.Rows))
+	groupOK := true
+
+	// O estabelecimento é resolvido uma vez por compra, e não a cada linha
+	store, err := resolveImportStore(storeMatcher, group.Store, "")
+	if err != nil {
+		return dto.ImportPurchaseSummaryDTO{}, false, err
+	}
+	if store != nil {
+		purchaseDTO.StoreID = &store.ID
+	}
+
+	for i, row := range group.Rows {
+		lines[i] = row.Line
+		product, unitsPerPack, created, err := service.purchaseService.resolveImportItemProduct(
+			productRepository, productCache, row, store, creation)
+		if err != nil {
+			report.Errors = append(report.Errors, dto.ImportRowMessageDTO{Row: row.Line, Field: importFieldProduct, Message: err.Error()})
+			groupOK = false
+			continue
+		}
+		if created {
+			report.ProductsCreated = append(report.ProductsCreated, product.Name)
+		}
+		itemDTO := dto.PurchaseItemDTO{
+			ProductID: product.ID,
+			Quantity:  row.Quantity,
+			UnitPrice: row.UnitPrice,
+			Unit:      row.Unit,
+		}
+		applyUnitsPerPack(&itemDTO, unitsPerPack)
+		purchaseDTO.Items = append(purchaseDTO.Items, itemDTO)
+	}
+
+	if !groupOK {
+		return dto.ImportPurchaseSummaryDTO{}, false, rollback()
+	}
+
+	purchase, warnings, err := service.purchaseService.createPurchaseInTx(tx, purchaseDTO, userID)
+	if err != nil {
+		for _, line := range lines {
+			report.Errors = append(report.Errors, dto.ImportRowMessageDTO{Row: line, Message: err.Error()})
+		}
+		return dto.ImportPurchaseSummaryDTO{}, false, rollback()
+	}
+
+	for _, warning := range warnings {
+		report.Warnings = append(report.Warnings, dto.ImportRowMessageDTO{
+			Row:     lines[warning.ItemIndex],
+			Field:   importFieldUnitPrice,
+			Message: warning.Message,
+		})
+	}
+
+	return dto.ImportPurchaseSummaryDTO{
+		Rows:             lines,
+		PurchaseID:       purchase.ID,
+		PurchaseDate:     purchase.PurchaseDate.Format(time.RFC3339),
+		PurchaseLocation: purchase.PurchaseLocation,
+		ItemsCount:       len(purchase.Items),
+		Total:            utils.FormatForDisplay(purchase.Total),
+	}, true, nil
+}
+
+// resolveImportItemProduct resolve o produto de um item importado pelo código lido (o principal, um
+// adicional ou, nas etiquetas de balança, o PLU com o leiaute do estabelecimento da compra) e, sem
+// correspondência, por resolveImportProduct. Também retorna as unidades por embalagem do código lido.
+func (service *PurchaseService) resolveImportItemProduct(
+	productRepository *repositories.ProductRepository,
+	cache map[string]*models.Product,
+	row importRow,
+	store *models.Store,
+	creation importProductCreation) (*models.Product, int, bool, error) {
+
+	if service.barcodeService != nil && row.Barcode != "" {
+		reading, err := service.barcodeService.readImportedCode(productRepository, row.Barcode, store, creation.userID)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if reading != nil && reading.Product != nil {
+			return reading.Product, reading.UnitsPerPack, false, nil
+		}
+	}
+	product, created, err := resolveImportProduct(productRepository, cache, row, creation)
+	return product, 1, created, err
+}
+
+// importProductCreation indica quem importa (os produtos procurados são os visíveis a ele), se a
+// importação pode criar os produtos que não encontrar e, quando quem importa não é admin, o usuário que
+// sugere os produtos criados (pendentes de moderação)
+type importProductCreation struct {
+	userID     uint
+	allowed    bool
+	proposedBy *uint
+}
+
+func newImportProductCreation(allowed bool, userID uint, userRole string) importProductCreation {
+	creation := importProductCreation{userID: userID, allowed: allowed}
+	if userRole != string(models.RoleAdmin) {
+		creation.proposedBy = &userID
+	}
+	return creation
+}
+
+// resolveImportProduct encontra o produto da linha pelo código de barras e depois pelo nome, dentre os
+// visíveis a quem importa. Produtos não encontrados são criados apenas quando permitido: por um admin, no
+// catálogo; pelos demais usuários, como sugestões pendentes. O cache evita criar o mesmo produto duas vezes.
+func resolveImportProduct(
+	productRepository *repositories.ProductRepository,
+	cache map[string]*models.Product,
+	row importRow,
+	creation importProductCreation) (*models.Product, bool, error) {
+
+	// Só GTINs válidos identificam o produto, na forma GTIN-14; etiquetas de balança (resolvidas antes
+	// pelo PLU) nunca viram o código de um produto novo
+	code, err := barcode.Normalize(row.Barcode)
+	if err != nil || barcode.IsVariableMeasure(code) {
+		code = ""
+	}
+
+	cacheKey := "name:" + strings.ToLower(row.ProductName)
+	if code != "" {
+		cacheKey = "barcode:" + code
+	}
+	if product, ok := cache[cacheKey]; ok {
+		return product, false, nil
+	}
+
+	var product *models.Product
+	err = gorm.ErrRecordNotFound
+	barcodeTaken := false
+	if code != "" {
+		product, err = productRepository.GetProductByBarcode(code)
+		// A sugestão pendente de outro usuário não é usada nem revelada, mas continua com o código
+		if err == nil && !productUsableBy(product, creation.userID) {
+			product, err, barcodeTaken = nil, gorm.ErrRecordNotFound, true
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && row.ProductName != "" {
+		product, err = productRepository.GetProductByName(row.ProductName, creation.userID)
+	}
+	if err == nil {
+		cache[cacheKey] = product
+		return product, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if !creation.allowed {
+		return nil, false, fmt.Errorf("%w: %s", errImportProductNotFound, describeImportProduct(row))
+	}
+	if row.ProductName == "" {
+		return nil, false, fmt.Errorf("nome do produto é obrigatório para criar o produto %s", row.Barcode)
+	}
+
+	product = &models.Product{Name: row.ProductName, Status: models.ProductApproved, ProposedByID: creation.proposedBy}
+	if creation.proposedBy != nil {
+		product.Status = models.ProductPending
+	}
+	if code != "" && !barcodeTaken {
+		product.Barcode = &code
+	}
+	if err := productRepository.CreateProduct(product); err != nil {
+		return nil, false, err
+	}
+	cache[cacheKey] = product
+	return product, true, nil
+}
+
+// describeImportProduct monta a descrição do produto da linha para mensagens de erro
+func describeImportProduct(row importRow) string {
+	switch {
+	case row.ProductName != "" && row.Barcode != "":
+		return fmt.Sprintf("%s (%s)", row.ProductName, row.Barcode)
+	case row.Barcode != "":
+		return row.Barcode
+	default:
+		return row.ProductName
+	}
+}
+
+// parsePurchaseCSV lê o CSV, resolve o mapeamento de colunas e converte cada linha.
+// Erros de linha são acumulados no relatório; o erro retornado indica um problema no arquivo como um todo.
+func parsePurchaseCSV(
+	reader io.Reader, options dto.PurchaseImportOptionsDTO) ([]importRow, int, []dto.ImportRowMessageDTO, error) {
+
+	buffered := bufio.NewReader(reader)
+	delimiter, err := resolveImportDelimiter(buffered, options.Delimiter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	csvReader := csv.NewReader(buffered)
+	csvReader.Comma = delimiter
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+
+	var header []string
+	if !options.NoHeader {
+		header, err = csvReader.Read()
+		if err == io.EOF {
+			return nil, 0, nil, errors.New("arquivo vazio")
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+	}
+
+	columns, err := resolveImportColumns(header, options)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	dateLayouts := utils.DefaultDateLayouts
+	if options.DateFormat != "" {
+		dateLayouts = []string{options.DateFormat}
+	}
+
+	var rows []importRow
+	var rowErrors []dto.ImportRowMessageDTO
+	totalRows := 0
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		if err != nil {
+			totalRows++
+			rowErrors = append(rowErrors, dto.ImportRowMessageDTO{Row: line, Message: err.Error()})
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		totalRows++
+
+		row, errs := convertImportRecord(record, line, columns, dateLayouts)
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if rowErrors == nil {
+		rowErrors = []dto.ImportRowMessageDTO{}
+	}
+	return rows, totalRows, rowErrors, nil
+}
+
+// resolveImportDelimiter usa o delimitador informado ou detecta ";", tab ou "," pela primeira linha
+func resolveImportDelimiter(reader *bufio.Reader, delimiter string) (rune, error) {
+	switch delimiter {
+	case ",", ";", "|":
+		return rune(delimiter[0]), nil
+	case "\\t", "\t", "tab":
+		return '\t', nil
+	case "":
+	default:
+		return 0, errors.New("delimitador inválido: use , ; | ou tab")
+	}
+
+	firstLine, err := reader.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return 0, err
+	}
+	sample := string(firstLine)
+	if newline := strings.IndexByte(sample, '\n'); newline >= 0 {
+		sample = sample[:newline]
+	}
+
+	best, bestCount := ',', strings.Count(sample, ",")
+	for _, candidate := range []rune{';', '\t', '|'} {
+		if count := strings.Count(sample, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best, nil
+}
+
+// resolveImportColumns converte o mapeamento de colunas (nome do cabeçalho ou índice) em índices
+func resolveImportColumns(header []string, options dto.PurchaseImportOptionsDTO) (map[string]int, error) {
+	references := map[string]string{
+		importFieldDate:      options.DateColumn,
+		importFieldStore:     options.StoreColumn,
+		importFieldProduct:   options.ProductColumn,
+		importFieldBarcode:   options.BarcodeColumn,
+		importFieldQuantity:  options.QuantityColumn,
+		importFieldUnitPrice: options.UnitPriceColumn,
+		importFieldUnit:      options.UnitColumn,
+	}
+
+	columns := make(map[string]int)
+	for position, field := range importFieldOrder {
+		reference := strings.TrimSpace(references[field])
+		explicit := reference != ""
+		if !explicit {
+			if header == nil {
+				reference = strconv.Itoa(position + 1)
+			} else {
+				reference = field
+			}
+		}
+
+		index := findImportColumn(header, reference)
+		if index < 0 {
+			if explicit {
+				return nil, fmt.Errorf("coluna %q (%s) não encontrada", reference, field)
+			}
+			continue
+		}
+		columns[field] = index
+	}
+
+	for _, required := range []string{importFieldDate, importFieldStore, importFieldQuantity, importFieldUnitPrice} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("mapeamento obrigatório ausente: %s", required)
+		}
+	}
+	_, hasProduct := columns[importFieldProduct]
+	_, hasBarcode := columns[importFieldBarcode]
+	if !hasProduct && !hasBarcode {
+		return nil, errors.New("mapeie ao menos uma coluna de produto (nome ou código de barras)")
+	}
+	return columns, nil
+}
+
+// findImportColumn procura a coluna pelo índice (1-based) ou pelo nome do cabeçalho
+func findImportColumn(header []string, reference string) int {
+	if index, err := strconv.Atoi(reference); err == nil {
+		if index < 1 || (header != nil && index > len(header)) {
+			return -1
+		}
+		return index - 1
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), reference) {
+			return i
+		}
+	}
+	return -1
+}
+
+// convertImportRecord converte os campos de uma linha, acumulando um erro por campo inválido
+func convertImportRecord(
+	record []string, line int, columns map[string]int, dateLayouts []string) (importRow, []dto.ImportRowMessageDTO) {
+
+	row := importRow{Line: line}
+	var errs []dto.ImportRowMessageDTO
+	value := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	fail := func(field, message string) {
+		errs = append(errs, dto.ImportRowMessageDTO{Row: line, Field: field, Message: message})
+	}
+
+	date, err := utils.ParseDate(value(importFieldDate), dateLayouts)
+	if err != nil {
+		fail(importFieldDate, err.Error())
+	}
+	row.Date = date
+
+	row.Store = value(importFieldStore)
+	if row.Store == "" {
+		fail(importFieldStore, "local da compra é obrigatório")
+	}
+
+	row.ProductName = value(importFieldProduct)
+	row.Barcode = value(importFieldBarcode)
+	if row.ProductName == "" && row.Barcode == "" {
+		fail(importFieldProduct, "informe o nome ou o código de barras do produto")
+	}
+
+	row.Quantity, err = utils.ParseDecimal(value(importFieldQuantity))
+	if err != nil {
+		fail(importFieldQuantity, err.Error())
+	} else if row.Quantity <= 0 {
+		fail(importFieldQuantity, "quantidade deve ser maior que zero")
+	}
+
+	row.UnitPrice, err = utils.ParseDecimal(value(importFieldUnitPrice))
+	if err != nil {
+		fail(importFieldUnitPrice, err.Error())
+	} else if row.UnitPrice <= 0 {
+		fail(importFieldUnitPrice, "preço unitário deve ser maior que zero")
+	}
+
+	if unitName := value(importFieldUnit); unitName != "" {
+		if unit, ok := units.Parse(unitName); ok {
+			row.Unit = string(unit)
+		} else {
+			fail(importFieldUnit, "unidade inválida: use "+units.Names())
+		}
+	}
+
+	return row, errs
+}
+
+// groupImportRows agrupa as linhas em compras por data e local (ignorando maiúsculas e espaços extras),
+// preservando a ordem de aparição no arquivo
+func groupImportRows(rows []importRow) []importGroup {
+	var groups []importGroup
+	indexByKey := make(map[string]int)
+	for _, row := range rows {
+		key := row.Date.Format(time.RFC3339) + "|" + strings.ToLower(strings.Join(strings.Fields(row.Store), " "))
+		index, ok := indexByKey[key]
+		if !ok {
+			index = len(groups)
+			indexByKey[key] = index
+			groups = append(groups, importGroup{Date: row.Date, Store: row.Store})
+		}
+		groups[index].Rows = append(groups[index].Rows, row)
+	}
+	return groups
+}
+
+// groupDates retorna as datas das compras importadas
+func groupDates(groups []importGroup) []time.Time {
+	dates := make([]time.Time, len(groups))
+	for i, group := range groups {
+		dates[i] = group.Date
+	}
+	return dates
+}
+
+// isBlankRecord indica se todos os campos da linha estão vazios
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/Parron01/AppMercado/backend/internal/dto"
@@ -11,19 +14,52 @@ import (
 	"gorm.io/gorm"
 )
 
+// PriceOutlierError é retornado no modo estrito quando há itens com preço atípico não confirmados
+type PriceOutlierError struct {
+	Warnings []dto.PriceWarningDTO
+}
+
+func (e *PriceOutlierError) Error() string {
+	return fmt.Sprintf("CreatePurchase: %d item(ns) com preço atípico precisam ser confirmados", len(e.Warnings))
+}
+
+// DuplicateInvoiceError é retornado quando a nota fiscal já foi registrada em outra compra do usuário
+type DuplicateInvoiceError struct {
+	PurchaseID uint
+}
+
+func (e *DuplicateInvoiceError) Error() string {
+	return fmt.Sprintf("CreatePurchase: nota fiscal já registrada na compra %d", e.PurchaseID)
+}
+
+// invoiceKeyOrNil converte a chave vazia em NULL (o índice único ignora NULLs)
+func invoiceKeyOrNil(invoiceKey string) *string {
+	if invoiceKey == "" {
+		return nil
+	}
+	return &invoiceKey
+}
+
 // PurchaseService handles business logic for purchases
 type PurchaseService struct {
 	purchaseRepository  *repositories.PurchaseRepository
+	transactionManager  *repositories.TransactionManager
 	productService      *ProductService
 	priceHistoryService *PriceHistoryService // Added reference to priceHistoryService
+	budgetService       *BudgetService
+	eventPublisher      *EventPublisher
+	storeService        *StoreService
+	barcodeService      *BarcodeService
 }
 
 // NewPurchaseService creates a new instance of PurchaseService
 func NewPurchaseService(
 	purchaseRepo *repositories.PurchaseRepository,
+	transactionManager *repositories.TransactionManager,
 	productService *ProductService) *PurchaseService {
 	return &PurchaseService{
 		purchaseRepository: purchaseRepo,
+		transactionManager: transactionManager,
 		productService:     productService,
 		// priceHistoryService will be set later to avoid circular dependency
 	}
@@ -34,25 +70,111 @@ func (service *PurchaseService) SetPriceHistoryService(priceHistoryService *Pric
 	service.priceHistoryService = priceHistoryService
 }
 
-// CreatePurchase creates a new purchase with its items
-func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO, userID uint) (*models.Purchase, error) {
+// SetBudgetService sets the BudgetService used to raise budget alerts after a purchase
+func (service *PurchaseService) SetBudgetService(budgetService *BudgetService) {
+	service.budgetService = budgetService
+}
+
+// SetEventPublisher sets the EventPublisher used to emit webhook events
+func (service *PurchaseService) SetEventPublisher(eventPublisher *EventPublisher) {
+	service.eventPublisher = eventPublisher
+}
+
+// SetStoreService sets the StoreService used to link purchases to stores
+func (service *PurchaseService) SetStoreService(storeService *StoreService) {
+	service.storeService = storeService
+}
+
+// SetBarcodeService sets the BarcodeService used to read the barcodes of purchase items
+func (service *PurchaseService) SetBarcodeService(barcodeService *BarcodeService) {
+	service.barcodeService = barcodeService
+}
+
+// CreatePurchase creates a new purchase with its items. Items whose unit price looks like an outlier
+// are returned as warnings; in strict mode, unconfirmed outliers reject the whole purchase.
+// The purchase and its price history entries are written in a single transaction.
+func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO, userID uint) (*models.Purchase, []dto.PriceWarningDTO, error) {
+	var purchase *models.Purchase
+	var warnings []dto.PriceWarningDTO
+	err := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		var txErr error
+		purchase, warnings, txErr = service.createPurchaseInTx(tx, purchaseDTO, userID)
+		return txErr
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	// Check budget alerts for the month of the purchase
+	service.checkBudgetAlertsForDates(userID, []time.Time{purchase.PurchaseDate})
+
+	return purchase, warnings, nil
+}
+
+// checkBudgetAlertsForDates raises budget alerts once for each month touched by the given purchase dates
+func (service *PurchaseService) checkBudgetAlertsForDates(userID uint, dates []time.Time) {
+	if service.budgetService == nil {
+		return
+	}
+
+	checkedMonths := make(map[time.Time]bool)
+	for _, date := range dates {
+		month := startOfMonth(date)
+		if checkedMonths[month] {
+			continue
+		}
+		checkedMonths[month] = true
+		// Budget alerts must not block the purchase
+		if err := service.budgetService.CheckBudgetAlerts(userID, date); err != nil {
+			log.Printf("falha ao verificar alertas de orçamento do usuário %d (%s): %v",
+				userID, month.Format(budgetPeriodLayout), err)
+		}
+	}
+}
+
+// createPurchaseInTx validates and stores a purchase and its price history using the given transaction.
+// Shared by CreatePurchase and the bulk importers so every entry path applies the same rules.
+func (service *PurchaseService) createPurchaseInTx(
+	tx *gorm.DB, purchaseDTO dto.CreatePurchaseDTO, userID uint) (*models.Purchase, []dto.PriceWarningDTO, error) {
+	purchaseRepository := service.purchaseRepository.WithTx(tx)
+	productRepository := service.productService.productRepo.WithTx(tx)
+
 	// Basic validation
 	if len(purchaseDTO.Items) == 0 {
-		return nil, errors.New("CreatePurchase: pelo menos um item é necessário")
+		return nil, nil, errors.New("CreatePurchase: pelo menos um item é necessário")
+	}
+
+	// Uma nota fiscal só pode ser registrada uma vez
+	if purchaseDTO.InvoiceKey != "" {
+		existing, err := purchaseRepository.GetPurchaseByInvoiceKey(purchaseDTO.InvoiceKey, userID)
+		if err == nil {
+			return nil, nil, &DuplicateInvoiceError{PurchaseID: existing.ID}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	// Vincula a compra ao estabelecimento; o local digitado é mantido e, sem ele, passa a ser o nome cadastrado
+	store, err := service.resolvePurchaseStore(tx, purchaseDTO, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if store != nil && strings.TrimSpace(purchaseDTO.PurchaseLocation) == "" {
+		purchaseDTO.PurchaseLocation = store.Name
 	}
 
 	// Verificar se já existe uma compra com mesmo local e data
-	_, err := service.purchaseRepository.GetPurchaseByDateAndLocation(
+	_, err = purchaseRepository.GetPurchaseByDateAndLocation(
 		purchaseDTO.PurchaseDate,
 		purchaseDTO.PurchaseLocation,
 		userID)
 
 	if err == nil {
 		// Se não houver erro, significa que encontramos uma compra com os mesmos dados
-		return nil, errors.New("CreatePurchase: já existe uma compra com o mesmo local e data")
+		return nil, nil, errors.New("CreatePurchase: já existe uma compra com o mesmo local e data")
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		// Se o erro não for "registro não encontrado", é um erro de banco de dados
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Create the purchase
@@ -60,9 +182,13 @@ func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO
 		PurchaseDate:     purchaseDTO.PurchaseDate,
 		PurchaseLocation: purchaseDTO.PurchaseLocation,
 		UserID:           userID,
+		InvoiceKey:       invoiceKeyOrNil(purchaseDTO.InvoiceKey),
 		Items:            make([]models.PurchaseItem, len(purchaseDTO.Items)),
 		Total:            0,
 	}
+	if store != nil {
+		purchase.StoreID = &store.ID
+	}
 
 	// Map para acumular valores para atualização de preço médio
 	productPriceUpdates := make(map[uint]struct {
@@ -72,11 +198,49 @@ func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO
 
 	// Add items to the purchase
 	var total float64 = 0
+	var warnings []dto.PriceWarningDTO
+	unconfirmedOutliers := 0
 	for i, itemDTO := range purchaseDTO.Items {
-		// Get product to check if it exists
-		_, err := service.productService.GetProductByID(itemDTO.ProductID)
+		// Items identified by barcode: scale labels also fill the quantity or the price
+		if itemDTO.ProductID == 0 {
+			if service.barcodeService == nil {
+				return nil, nil, errors.New("CreatePurchase: leitura de código de barras não disponível")
+			}
+			reading, err := service.barcodeService.readPurchaseItem(tx, itemDTO.Barcode, store, userID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("CreatePurchase: item %d: %w", i+1, err)
+			}
+			applyBarcodeReading(&itemDTO, reading)
+		}
+		if itemDTO.Quantity <= 0 || itemDTO.UnitPrice <= 0 {
+			return nil, nil, fmt.Errorf("CreatePurchase: item %d: quantidade e preço unitário devem ser maiores que zero", i+1)
+		}
+
+		// Get product to check if it exists (the ID of a merged product leads to the surviving one);
+		// another user's pending proposal counts as not found
+		product, err := findProduct(productRepository, itemDTO.ProductID)
+		if err == nil && !productUsableBy(product, userID) {
+			err = gorm.ErrRecordNotFound
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("CreatePurchase: produto não encontrado: %d", itemDTO.ProductID)
+		}
 		if err != nil {
-			return nil, errors.New("CreatePurchase: produto não encontrado: " + err.Error())
+			return nil, nil, err
+		}
+		itemDTO.ProductID = product.ID
+		unit, err := resolveItemUnit(product, itemDTO.Unit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("CreatePurchase: item %d: %w", i+1, err)
+		}
+		itemDTO.Unit = string(unit)
+
+		// Compare the unit price with the product's history
+		if warning := service.checkItemPrice(tx, i, product, itemDTO, userID); warning != nil {
+			warnings = append(warnings, *warning)
+			if !warning.Confirmed {
+				unconfirmedOutliers++
+			}
 		}
 
 		// Usar o preço informado pelo usuário como preço unitário (com alta precisão)
@@ -88,6 +252,7 @@ func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO
 		purchase.Items[i] = models.PurchaseItem{
 			ProductID:  itemDTO.ProductID,
 			Quantity:   utils.FormatDecimal(itemDTO.Quantity),
+			Unit:       itemDTO.Unit,
 			UnitPrice:  unitPrice,
 			TotalPrice: totalPrice,
 		}
@@ -110,21 +275,132 @@ func (service *PurchaseService) CreatePurchase(purchaseDTO dto.CreatePurchaseDTO
 		}
 	}
 
+	// Strict mode: suspicious prices must be confirmed by the client
+	if purchaseDTO.StrictPriceCheck && unconfirmedOutliers > 0 {
+		return nil, warnings, &PriceOutlierError{Warnings: warnings}
+	}
+
 	purchase.Total = utils.FormatDecimal(total)
 
 	// Save to database
-	if err := service.purchaseRepository.CreatePurchase(purchase); err != nil {
-		return nil, err
+	if err := purchaseRepository.CreatePurchase(purchase); err != nil {
+		return nil, nil, err
 	}
 
-	// Register the purchase in price history
+	// Register the purchase in price history (same transaction: both are kept or discarded together)
+	var priceHistories []*models.PriceHistory
 	if service.priceHistoryService != nil {
-		if err := service.priceHistoryService.RegisterPurchaseInPriceHistory(purchase); err != nil {
-			// We don't want to fail the purchase creation if price history fails
+		priceHistoryRepository := service.priceHistoryService.priceHistoryRepository.WithTx(tx)
+		priceHistories, err = service.priceHistoryService.registerPurchaseInPriceHistory(priceHistoryRepository, purchase)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	return purchase, nil
+	if err := service.publishPurchaseCreated(tx, purchase, priceHistories, warnings); err != nil {
+		return nil, nil, err
+	}
+
+	return purchase, warnings, nil
+}
+
+// resolvePurchaseStore retorna o estabelecimento informado em storeId (compartilhado ou do usuário) ou,
+// sem ele, o que casa com o local e o CNPJ digitados (criando-o quando nenhum casa)
+func (service *PurchaseService) resolvePurchaseStore(tx *gorm.DB, purchaseDTO dto.CreatePurchaseDTO, userID uint) (*models.Store, error) {
+	if service.storeService == nil {
+		return nil, nil
+	}
+	storeRepository := service.storeService.storeRepository.WithTx(tx)
+
+	if purchaseDTO.StoreID != nil {
+		store, err := storeRepository.GetVisibleStoreByID(*purchaseDTO.StoreID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("CreatePurchase: estabelecimento não encontrado: %d", *purchaseDTO.StoreID)
+		}
+		return store, err
+	}
+
+	return resolveStore(storeRepository, newStoreMatcher(storeRepository, userID), purchaseDTO.PurchaseLocation, purchaseDTO.StoreCNPJ)
+}
+
+// newImportStoreMatcher cria o matcher de estabelecimentos de uma importação, usado por todas as compras
+// dela; nil quando o serviço não vincula estabelecimentos
+func (service *PurchaseService) newImportStoreMatcher(tx *gorm.DB, userID uint) *storeMatcher {
+	if service.storeService == nil {
+		return nil
+	}
+	return newStoreMatcher(service.storeService.storeRepository.WithTx(tx), userID)
+}
+
+// resolveImportStore resolve (ou cria) o estabelecimento de uma compra importada com o matcher da importação
+func resolveImportStore(matcher *storeMatcher, location string, cnpj string) (*models.Store, error) {
+	if matcher == nil {
+		return nil, nil
+	}
+	return resolveStore(matcher.storeRepository, matcher, location, cnpj)
+}
+
+// publishPurchaseCreated grava na outbox os eventos da compra criada, dos preços registrados
+// e dos alertas de preço, na mesma transação da compra
+func (service *PurchaseService) publishPurchaseCreated(
+	tx *gorm.DB, purchase *models.Purchase, priceHistories []*models.PriceHistory, warnings []dto.PriceWarningDTO) error {
+	if service.eventPublisher == nil {
+		return nil
+	}
+	userID := purchase.UserID
+
+	if err := service.eventPublisher.Publish(tx, EventPurchaseCreated, &userID, service.ToPurchaseResponseDTO(purchase)); err != nil {
+		return err
+	}
+	for _, priceHistory := range priceHistories {
+		data := service.priceHistoryService.ToPriceHistoryResponseDTO(priceHistory)
+		if err := service.eventPublisher.Publish(tx, EventPriceHistoryCreated, &userID, data); err != nil {
+			return err
+		}
+	}
+	for _, warning := range warnings {
+		data := map[string]interface{}{"purchaseId": purchase.ID, "warning": warning}
+		if err := service.eventPublisher.Publish(tx, EventPriceAlert, &userID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkItemPrice returns a warning when the item's unit price is an outlier for the product.
+// The history is read inside the purchase transaction.
+func (service *PurchaseService) checkItemPrice(
+	tx *gorm.DB, index int, product *models.Product, itemDTO dto.PurchaseItemDTO, userID uint) *dto.PriceWarningDTO {
+	if service.priceHistoryService == nil {
+		return nil
+	}
+
+	result, err := service.priceHistoryService.CheckPriceOutlier(tx, product.ID, userID, itemDTO.UnitPrice, itemDTO.Unit)
+	if err != nil || !result.IsOutlier {
+		// Outlier detection is advisory; failures must not block the purchase
+		return nil
+	}
+
+	message := fmt.Sprintf("Preço de %s (R$ %.2f) fora da faixa esperada (R$ %.2f a R$ %.2f)",
+		product.Name, itemDTO.UnitPrice, result.ExpectedMin, result.ExpectedMax)
+	if result.SuggestedPrice != nil {
+		message += fmt.Sprintf(". Você quis dizer R$ %.2f?", *result.SuggestedPrice)
+	}
+
+	return &dto.PriceWarningDTO{
+		ItemIndex:      index,
+		ProductID:      product.ID,
+		ProductName:    product.Name,
+		UnitPrice:      utils.FormatForDisplay(itemDTO.UnitPrice),
+		MedianPrice:    utils.FormatForDisplay(result.Median),
+		ExpectedMin:    utils.FormatForDisplay(result.ExpectedMin),
+		ExpectedMax:    utils.FormatForDisplay(result.ExpectedMax),
+		Method:         result.Method,
+		SampleSize:     result.SampleSize,
+		SuggestedPrice: result.SuggestedPrice,
+		Confirmed:      itemDTO.ConfirmedPrice,
+		Message:        message,
+	}
 }
 
 // GetPurchaseByID retrieves a purchase by its ID
@@ -184,12 +460,17 @@ func (service *PurchaseService) DeletePurchase(purchaseID uint, userID uint, use
 		}
 	}
 
-	// Delete from database
-	if err := service.purchaseRepository.DeletePurchase(purchaseID); err != nil {
-		return err
-	}
-
-	return nil
+	// Delete from database (the webhook event is written in the same transaction)
+	return service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		if err := service.purchaseRepository.WithTx(tx).DeletePurchase(purchaseID); err != nil {
+			return err
+		}
+		if service.eventPublisher == nil {
+			return nil
+		}
+		ownerID := purchase.UserID
+		return service.eventPublisher.Publish(tx, EventPurchaseDeleted, &ownerID, service.ToPurchaseResponseDTO(purchase))
+	})
 }
 
 // GetAllPurchases retrieves all purchases (admin only)
@@ -202,18 +483,91 @@ func (service *PurchaseService) GetAllPurchases(userRole string) ([]*models.Purc
 	return service.purchaseRepository.GetAllPurchases()
 }
 
+// GetPurchasesByUserIDs busca as compras de vários usuários, sem os itens, agrupadas por usuário e
+// paginadas por usuário (limit <= 0 não limita). Usuários comuns só recebem as próprias compras.
+func (service *PurchaseService) GetPurchasesByUserIDs(
+	userIDs []uint, limit, offset int, userID uint, userRole string) (map[uint][]*models.Purchase, error) {
+	if userRole != string(models.RoleAdmin) {
+		userIDs = filterIDs(userIDs, userID)
+	}
+
+	// Com um único usuário a paginação vai direto para o banco; com vários, é aplicada por usuário
+	queryLimit, queryOffset := limit, offset
+	if len(userIDs) > 1 {
+		queryLimit, queryOffset = 0, 0
+	}
+	purchases, err := service.purchaseRepository.GetPurchasesByUserIDs(userIDs, queryLimit, queryOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	purchasesByUser := make(map[uint][]*models.Purchase, len(userIDs))
+	for _, purchase := range purchases {
+		purchasesByUser[purchase.UserID] = append(purchasesByUser[purchase.UserID], purchase)
+	}
+	if len(userIDs) > 1 && limit > 0 {
+		for id, userPurchases := range purchasesByUser {
+			start := min(offset, len(userPurchases))
+			purchasesByUser[id] = userPurchases[start:min(start+limit, len(userPurchases))]
+		}
+	}
+	return purchasesByUser, nil
+}
+
+// GetPurchasesByIDs busca de uma vez as compras, sem os itens; apenas as do próprio usuário (ou todas, para admin) são retornadas
+func (service *PurchaseService) GetPurchasesByIDs(ids []uint, userID uint, userRole string) (map[uint]*models.Purchase, error) {
+	purchases, err := service.purchaseRepository.GetPurchasesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	purchasesByID := make(map[uint]*models.Purchase, len(purchases))
+	for _, purchase := range purchases {
+		if purchase.UserID == userID || userRole == string(models.RoleAdmin) {
+			purchasesByID[purchase.ID] = purchase
+		}
+	}
+	return purchasesByID, nil
+}
+
+// GetPurchaseItems busca de uma vez os itens das compras informadas, agrupados por compra.
+// Itens de compras de outros usuários não são carregados (exceto para admin).
+func (service *PurchaseService) GetPurchaseItems(
+	purchases []*models.Purchase, userID uint, userRole string) (map[uint][]*models.PurchaseItem, error) {
+	purchaseIDs := make([]uint, 0, len(purchases))
+	for _, purchase := range purchases {
+		if purchase.UserID == userID || userRole == string(models.RoleAdmin) {
+			purchaseIDs = append(purchaseIDs, purchase.ID)
+		}
+	}
+	items, err := service.purchaseRepository.GetPurchaseItemsByPurchaseIDs(purchaseIDs)
+	if err != nil {
+		return nil, err
+	}
+	itemsByPurchase := make(map[uint][]*models.PurchaseItem, len(purchaseIDs))
+	for _, item := range items {
+		itemsByPurchase[item.PurchaseID] = append(itemsByPurchase[item.PurchaseID], item)
+	}
+	return itemsByPurchase, nil
+}
+
 // ToPurchaseItemResponseDTO converts a PurchaseItem model to PurchaseItemResponseDTO
 func (service *PurchaseService) ToPurchaseItemResponseDTO(item models.PurchaseItem) dto.PurchaseItemResponseDTO {
-	return dto.PurchaseItemResponseDTO{
+	itemDTO := dto.PurchaseItemResponseDTO{
 		ID:          item.ID,
 		ProductID:   item.ProductID,
 		ProductName: item.Product.Name,
-		Quantity:    utils.FormatForDisplay(item.Quantity),   // Formatar para exibição
+		Quantity:    utils.FormatForDisplay(item.Quantity), // Formatar para exibição
+		Unit:        item.Unit,
 		UnitPrice:   utils.FormatForDisplay(item.UnitPrice),  // Formatar para exibição
 		TotalPrice:  utils.FormatForDisplay(item.TotalPrice), // Formatar para exibição
 		CreatedAt:   item.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   item.UpdatedAt.Format(time.RFC3339),
 	}
+	if normalizedPrice, unit, ok := normalizedUnitPrice(&item.Product, item.UnitPrice, item.Unit); ok {
+		normalizedPrice = utils.FormatForDisplay(normalizedPrice)
+		itemDTO.NormalizedUnitPrice, itemDTO.NormalizedUnit = &normalizedPrice, string(unit)
+	}
+	return itemDTO
 }
 
 // ToPurchaseResponseDTO converts a Purchase model to PurchaseResponseDTO
@@ -227,7 +581,9 @@ func (service *PurchaseService) ToPurchaseResponseDTO(purchase *models.Purchase)
 		ID:               purchase.ID,
 		PurchaseDate:     purchase.PurchaseDate.Format(time.RFC3339),
 		PurchaseLocation: purchase.PurchaseLocation,
+		StoreID:          purchase.StoreID,
 		UserID:           purchase.UserID,
+		InvoiceKey:       purchase.InvoiceKey,
 		Items:            itemDTOs,
 		Total:            utils.FormatForDisplay(purchase.Total), // Formatar para exibição
 		CreatedAt:        purchase.CreatedAt.Format(time.RFC3339),
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/pkg/receipt"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Formas de associação de um item do cupom a um produto
+const (
+	ReceiptMatchBarcode = "barcode"
+	ReceiptMatchPLU     = "plu" // Etiqueta de balança, pelo PLU
+	ReceiptMatchName    = "name"
+)
+
+// ErrNoReceiptItems é retornado quando o texto não tem nenhum item reconhecível (erro de validação do
+// texto enviado; os demais erros de ParseReceiptText são falhas internas)
+var ErrNoReceiptItems = errors.New("ParseReceiptText: nenhum item reconhecido no texto do cupom")
+
+// ReceiptService turns plain-text receipts into purchase drafts
+type ReceiptService struct {
+	productService *ProductService
+	barcodeService *BarcodeService
+	parser         *receipt.Parser
+}
+
+// NewReceiptService creates a new instance of ReceiptService using the given receipt parser
+func NewReceiptService(productService *ProductService, parser *receipt.Parser) *ReceiptService {
+	return &ReceiptService{productService: productService, parser: parser}
+}
+
+// SetBarcodeService sets the BarcodeService used to read item codes, including scale labels
+func (service *ReceiptService) SetBarcodeService(barcodeService *BarcodeService) {
+	service.barcodeService = barcodeService
+}
+
+// ParseReceiptText lê o texto de um cupom e monta um rascunho de compra. Nada é gravado:
+// o usuário revisa o rascunho (e as confianças de cada campo) antes de criar a compra.
+func (service *ReceiptService) ParseReceiptText(text string, userID uint) (*dto.ReceiptDraftDTO, error) {
+	parsed := service.parser.Parse(text)
+	if len(parsed.Items) == 0 {
+		return nil, ErrNoReceiptItems
+	}
+
+	draft := &dto.ReceiptDraftDTO{
+		Layout:   parsed.Layout,
+		Store:    toReceiptTextField(parsed.Store),
+		CNPJ:     toReceiptTextField(parsed.CNPJ),
+		Total:    toReceiptNumberField(parsed.Total, utils.FormatForDisplay),
+		Items:    make([]dto.ReceiptItemDraftDTO, len(parsed.Items)),
+		Warnings: append([]string{}, parsed.Warnings...),
+		Purchase: dto.CreatePurchaseDTO{
+			PurchaseLocation: parsed.Store.Value,
+			PurchaseDate:     parsed.Date.Value,
+			Items:            []dto.PurchaseItemDTO{},
+		},
+	}
+	if parsed.Date.Confidence > 0 {
+		draft.Date = dto.ReceiptTextFieldDTO{Value: parsed.Date.Value.Format(time.RFC3339), Confidence: parsed.Date.Confidence}
+	}
+
+	// O estabelecimento do cupom (para o leiaute das etiquetas de balança) é casado uma única vez
+	var store *models.Store
+	if service.barcodeService != nil {
+		var err error
+		matcher := newStoreMatcher(service.barcodeService.storeRepository, userID)
+		store, _, err = matcher.match(utils.NormalizeText(parsed.Store.Value), digitsOnly(parsed.CNPJ.Value))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, item := range parsed.Items {
+		itemDraft := dto.ReceiptItemDraftDTO{
+			Line:        item.Line,
+			Code:        toReceiptTextField(item.Code),
+			Description: toReceiptTextField(item.Description),
+			Quantity:    toReceiptNumberField(item.Quantity, utils.FormatDecimal),
+			Unit:        toReceiptTextField(item.Unit),
+			UnitPrice:   toReceiptNumberField(item.UnitPrice, utils.FormatDecimal),
+			Total:       toReceiptNumberField(item.Total, utils.FormatForDisplay),
+			Confidence:  item.Confidence,
+		}
+
+		product, unitsPerPack, matchedBy, err := service.matchReceiptItem(item, store, userID)
+		if err != nil {
+			return nil, err
+		}
+		if product != nil {
+			itemDraft.ProductID = product.ID
+			itemDraft.ProductName = product.Name
+			itemDraft.MatchedBy = matchedBy
+			itemDTO := dto.PurchaseItemDTO{
+				ProductID: product.ID,
+				Quantity:  utils.FormatDecimal(item.Quantity.Value),
+				UnitPrice: utils.FormatDecimal(item.UnitPrice.Value),
+				Unit:      importedItemUnit(product, item.Unit.Value),
+			}
+			applyUnitsPerPack(&itemDTO, unitsPerPack)
+			draft.Purchase.Items = append(draft.Purchase.Items, itemDTO)
+		} else {
+			draft.UnmatchedItems++
+		}
+		draft.Items[i] = itemDraft
+	}
+
+	return draft, nil
+}
+
+// matchReceiptItem procura, dentre os produtos visíveis ao usuário, o produto pelo código (quando é um GTIN
+// válido, inclusive etiquetas de balança pelo PLU) e depois pela descrição; também retorna as unidades por
+// embalagem do código lido
+func (service *ReceiptService) matchReceiptItem(
+	item receipt.Item, store *models.Store, userID uint) (*models.Product, int, string, error) {
+	repository := service.productService.productRepo
+	if service.barcodeService != nil {
+		reading, err := service.barcodeService.readImportedCode(repository, item.Code.Value, store, userID)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		if reading != nil && reading.Product != nil {
+			if reading.VariableMeasure {
+				return reading.Product, 1, ReceiptMatchPLU, nil
+			}
+			return reading.Product, reading.UnitsPerPack, ReceiptMatchBarcode, nil
+		}
+	}
+
+	product, err := repository.GetProductByName(item.Description.Value, userID)
+	if err == nil {
+		return product, 1, ReceiptMatchName, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, "", err
+	}
+	return nil, 0, "", nil
+}
+
+func toReceiptTextField(field receipt.Field[string]) dto.ReceiptTextFieldDTO {
+	return dto.ReceiptTextFieldDTO{Value: field.Value, Confidence: field.Confidence}
+}
+
+func toReceiptNumberField(field receipt.Field[float64], format func(float64) float64) dto.ReceiptNumberFieldDTO {
+	return dto.ReceiptNumberFieldDTO{Value: format(field.Value), Confidence: field.Confidence}
+}
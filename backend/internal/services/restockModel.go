@@ -0,0 +1,153 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Parâmetros do modelo de consumo. Todas as funções deste arquivo são puras e determinísticas:
+// recebem o histórico e o instante de referência (now) e não acessam banco nem relógio.
+const (
+	restockMinPurchaseDays  = 3    // Mínimo de dias com compra (2 intervalos) para considerar o produto recorrente
+	restockStaleFactor      = 2.0  // Atraso maior que 2 intervalos médios indica que o usuário deixou de comprar
+	buyAgainHalfLifeDays    = 30.0 // Meia-vida do peso de recência no ranking "comprar novamente"
+	hoursPerDay             = 24.0
+	restockConfidenceDigits = 100.0
+)
+
+// ConsumptionEvent representa uma compra de um produto (data e quantidade)
+type ConsumptionEvent struct {
+	Date     time.Time
+	Quantity float64
+}
+
+// ConsumptionEstimate é o resultado do modelo de consumo de um produto recorrente
+type ConsumptionEstimate struct {
+	PurchaseDays        int       // Dias distintos com compra do produto
+	MeanIntervalDays    float64   // Intervalo médio entre compras
+	IntervalStdDevDays  float64   // Desvio padrão dos intervalos
+	QuantityPerDay      float64   // Consumo diário estimado
+	QuantityPerPurchase float64   // Mediana da quantidade comprada por vez
+	LastPurchaseDate    time.Time // Data da última compra
+	LastQuantity        float64   // Quantidade comprada na última vez
+	ExpectedRunOutDate  time.Time // Quando o estoque da última compra deve acabar
+	Confidence          float64   // 0..1: regularidade dos intervalos x tamanho da amostra
+}
+
+// BuyAgainScore é a pontuação de um produto no ranking "comprar novamente"
+type BuyAgainScore struct {
+	PurchaseDays     int
+	LastPurchaseDate time.Time
+	DaysSinceLast    float64
+	Score            float64
+}
+
+// mergeEventsByDay soma compras do mesmo dia e ordena cronologicamente. O dia é o do fuso das datas
+// recebidas; quem monta os eventos converte as datas para o fuso desejado.
+func mergeEventsByDay(events []ConsumptionEvent) []ConsumptionEvent {
+	byDay := make(map[time.Time]float64)
+	for _, event := range events {
+		year, month, day := event.Date.Date()
+		byDay[time.Date(year, month, day, 0, 0, 0, 0, event.Date.Location())] += event.Quantity
+	}
+
+	merged := make([]ConsumptionEvent, 0, len(byDay))
+	for day, quantity := range byDay {
+		merged = append(merged, ConsumptionEvent{Date: day, Quantity: quantity})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Date.Before(merged[j].Date) })
+	return merged
+}
+
+// EstimateConsumption estima intervalo e ritmo de consumo de um produto a partir do histórico de compras.
+// Retorna false quando o produto não tem compras suficientes para ser considerado recorrente.
+func EstimateConsumption(events []ConsumptionEvent) (ConsumptionEstimate, bool) {
+	days := mergeEventsByDay(events)
+	if len(days) < restockMinPurchaseDays {
+		return ConsumptionEstimate{}, false
+	}
+
+	intervals := make([]float64, 0, len(days)-1)
+	quantities := make([]float64, 0, len(days))
+	for i, day := range days {
+		quantities = append(quantities, day.Quantity)
+		if i > 0 {
+			intervals = append(intervals, day.Date.Sub(days[i-1].Date).Hours()/hoursPerDay)
+		}
+	}
+
+	first := days[0]
+	last := days[len(days)-1]
+	spanDays := last.Date.Sub(first.Date).Hours() / hoursPerDay
+
+	// Tudo o que foi comprado antes da última compra foi consumido ao longo do período observado
+	var consumedQuantity float64
+	for _, day := range days[:len(days)-1] {
+		consumedQuantity += day.Quantity
+	}
+
+	estimate := ConsumptionEstimate{
+		PurchaseDays:        len(days),
+		MeanIntervalDays:    utils.Mean(intervals),
+		IntervalStdDevDays:  utils.StdDev(intervals),
+		QuantityPerPurchase: utils.Quantile(quantities, 0.5),
+		LastPurchaseDate:    last.Date,
+		LastQuantity:        last.Quantity,
+	}
+	if spanDays > 0 {
+		estimate.QuantityPerDay = consumedQuantity / spanDays
+	}
+
+	// Duração do estoque da última compra: pela taxa de consumo, ou pelo intervalo médio como fallback
+	coverageDays := estimate.MeanIntervalDays
+	if estimate.QuantityPerDay > 0 {
+		coverageDays = last.Quantity / estimate.QuantityPerDay
+	}
+	estimate.ExpectedRunOutDate = last.Date.Add(time.Duration(coverageDays * hoursPerDay * float64(time.Hour)))
+
+	// Confiança: intervalos regulares (baixo coeficiente de variação) e mais observações
+	regularity := 0.0
+	if estimate.MeanIntervalDays > 0 {
+		regularity = 1 / (1 + estimate.IntervalStdDevDays/estimate.MeanIntervalDays)
+	}
+	sampleFactor := 1 - 1/float64(len(intervals)+1)
+	estimate.Confidence = math.Round(regularity*sampleFactor*restockConfidenceDigits) / restockConfidenceDigits
+
+	return estimate, true
+}
+
+// RestockConfidenceAt ajusta a confiança da estimativa para o instante now: previsões muito atrasadas
+// perdem confiança (o usuário pode ter parado de comprar) e são descartadas além do limite.
+func RestockConfidenceAt(estimate ConsumptionEstimate, now time.Time) (float64, bool) {
+	overdueDays := now.Sub(estimate.ExpectedRunOutDate).Hours() / hoursPerDay
+	if overdueDays <= 0 || estimate.MeanIntervalDays <= 0 {
+		return estimate.Confidence, true
+	}
+	if overdueDays > restockStaleFactor*estimate.MeanIntervalDays {
+		return 0, false
+	}
+	decay := 1 - overdueDays/(restockStaleFactor*estimate.MeanIntervalDays)
+	return math.Round(estimate.Confidence*decay*restockConfidenceDigits) / restockConfidenceDigits, true
+}
+
+// ScoreBuyAgain pontua um produto por frequência (dias com compra) e recência (decaimento exponencial)
+func ScoreBuyAgain(events []ConsumptionEvent, now time.Time) BuyAgainScore {
+	days := mergeEventsByDay(events)
+	if len(days) == 0 {
+		return BuyAgainScore{}
+	}
+
+	last := days[len(days)-1].Date
+	daysSinceLast := math.Max(now.Sub(last).Hours()/hoursPerDay, 0)
+	recency := math.Exp(-math.Ln2 * daysSinceLast / buyAgainHalfLifeDays)
+
+	return BuyAgainScore{
+		PurchaseDays:     len(days),
+		LastPurchaseDate: last,
+		DaysSinceLast:    daysSinceLast,
+		Score:            math.Round(float64(len(days))*recency*1000) / 1000,
+	}
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var restockEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// restockDay retorna o instante do dia n do histórico de teste, opcionalmente com horas a mais
+func restockDay(n int, hours ...int) time.Time {
+	date := restockEpoch.AddDate(0, 0, n)
+	for _, h := range hours {
+		date = date.Add(time.Duration(h) * time.Hour)
+	}
+	return date
+}
+
+// restockHistory monta o histórico: pares (dia, quantidade)
+func restockHistory(pairs ...float64) []ConsumptionEvent {
+	events := make([]ConsumptionEvent, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		events = append(events, ConsumptionEvent{Date: restockDay(int(pairs[i])), Quantity: pairs[i+1]})
+	}
+	return events
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEstimateConsumption(t *testing.T) {
+	tests := []struct {
+		name         string
+		events       []ConsumptionEvent
+		wantOK       bool
+		purchaseDays int
+		meanInterval float64
+		stdDev       float64
+		perDay       float64
+		perPurchase  float64
+		runOutDay    time.Time
+		confidence   float64
+		lastPurchase time.Time
+		lastQuantity float64
+	}{
+		{name: "sem compras", events: nil},
+		{name: "uma compra", events: restockHistory(0, 2)},
+		{name: "duas compras não formam recorrência", events: restockHistory(0, 2, 7, 2)},
+		{
+			name: "compras repetidas no mesmo dia contam como um dia",
+			events: []ConsumptionEvent{
+				{Date: restockDay(0, 9), Quantity: 1},
+				{Date: restockDay(0, 18), Quantity: 1},
+				{Date: restockDay(0, 20), Quantity: 1},
+			},
+		},
+		{
+			name:         "intervalos regulares",
+			events:       restockHistory(0, 2, 7, 2, 14, 2, 21, 2),
+			wantOK:       true,
+			purchaseDays: 4,
+			meanInterval: 7,
+			perDay:       2.0 / 7,
+			perPurchase:  2,
+			runOutDay:    restockDay(28),
+			confidence:   0.75, // regularidade 1 x amostra 3/4
+			lastPurchase: restockDay(21),
+			lastQuantity: 2,
+		},
+		{
+			name: "repetições no mesmo dia são somadas",
+			events: []ConsumptionEvent{
+				{Date: restockDay(20), Quantity: 2}, // Fora de ordem
+				{Date: restockDay(0, 8), Quantity: 1},
+				{Date: restockDay(0, 19), Quantity: 1},
+				{Date: restockDay(10), Quantity: 2},
+			},
+			wantOK:       true,
+			purchaseDays: 3,
+			meanInterval: 10,
+			perDay:       0.2, // 4 unidades consumidas em 20 dias
+			perPurchase:  2,
+			runOutDay:    restockDay(30),
+			confidence:   0.67,
+			lastPurchase: restockDay(20),
+			lastQuantity: 2,
+		},
+		{
+			name:         "intervalos irregulares reduzem a confiança",
+			events:       restockHistory(0, 1, 3, 1, 13, 1, 15, 1),
+			wantOK:       true,
+			purchaseDays: 4,
+			meanInterval: 5,             // (3 + 10 + 2) / 3
+			stdDev:       math.Sqrt(19), // amostral
+			perDay:       0.2,
+			perPurchase:  1,
+			runOutDay:    restockDay(20),
+			confidence:   0.4, // 1/(1 + 4,36/5) x 3/4
+			lastPurchase: restockDay(15),
+			lastQuantity: 1,
+		},
+		{
+			name:         "sem quantidade usa o intervalo médio",
+			events:       restockHistory(0, 0, 5, 0, 10, 0),
+			wantOK:       true,
+			purchaseDays: 3,
+			meanInterval: 5,
+			runOutDay:    restockDay(15),
+			confidence:   0.67,
+			lastPurchase: restockDay(10),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate, ok := EstimateConsumption(test.events)
+			if ok != test.wantOK {
+				t.Fatalf("ok = %v, esperado %v (%+v)", ok, test.wantOK, estimate)
+			}
+			if !ok {
+				if estimate != (ConsumptionEstimate{}) {
+					t.Errorf("estimativa = %+v, esperado valor zero", estimate)
+				}
+				return
+			}
+			if estimate.PurchaseDays != test.purchaseDays {
+				t.Errorf("PurchaseDays = %d, esperado %d", estimate.PurchaseDays, test.purchaseDays)
+			}
+			if !almostEqual(estimate.MeanIntervalDays, test.meanInterval) {
+				t.Errorf("MeanIntervalDays = %v, esperado %v", estimate.MeanIntervalDays, test.meanInterval)
+			}
+			if !almostEqual(estimate.IntervalStdDevDays, test.stdDev) {
+				t.Errorf("IntervalStdDevDays = %v, esperado %v", estimate.IntervalStdDevDays, test.stdDev)
+			}
+			if !almostEqual(estimate.QuantityPerDay, test.perDay) {
+				t.Errorf("QuantityPerDay = %v, esperado %v", estimate.QuantityPerDay, test.perDay)
+			}
+			if !almostEqual(estimate.QuantityPerPurchase, test.perPurchase) {
+				t.Errorf("QuantityPerPurchase = %v, esperado %v", estimate.QuantityPerPurchase, test.perPurchase)
+			}
+			if !estimate.ExpectedRunOutDate.Equal(test.runOutDay) {
+				t.Errorf("ExpectedRunOutDate = %v, esperado %v", estimate.ExpectedRunOutDate, test.runOutDay)
+			}
+			if estimate.Confidence != test.confidence {
+				t.Errorf("Confidence = %v, esperado %v", estimate.Confidence, test.confidence)
+			}
+			if !estimate.LastPurchaseDate.Equal(test.lastPurchase) || estimate.LastQuantity != test.lastQuantity {
+				t.Errorf("última compra = %v (%v), esperado %v (%v)",
+					estimate.LastPurchaseDate, estimate.LastQuantity, test.lastPurchase, test.lastQuantity)
+			}
+		})
+	}
+}
+
+func TestRestockConfidenceAt(t *testing.T) {
+	// Compra semanal com confiança 0,75 e estoque acabando no dia 28
+	weekly, ok := EstimateConsumption(restockHistory(0, 2, 7, 2, 14, 2, 21, 2))
+	if !ok {
+		t.Fatal("histórico semanal deveria ser recorrente")
+	}
+
+	tests := []struct {
+		name           string
+		estimate       ConsumptionEstimate
+		now            time.Time
+		wantConfidence float64
+		wantOK         bool
+	}{
+		{"antes de acabar", weekly, restockDay(27), 0.75, true},
+		{"no dia previsto", weekly, restockDay(28), 0.75, true},
+		{"meio caminho do limite de atraso", weekly, restockDay(35), 0.38, true}, // 0,75 x (1 - 7/14)
+		{"exatamente no limite", weekly, restockDay(42), 0, true},
+		{"além do limite é descartado", weekly, restockDay(43), 0, false},
+		{"sem intervalo médio não decai", ConsumptionEstimate{Confidence: 0.5, ExpectedRunOutDate: restockDay(0)}, restockDay(100), 0.5, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			confidence, ok := RestockConfidenceAt(test.estimate, test.now)
+			if confidence != test.wantConfidence || ok != test.wantOK {
+				t.Fatalf("RestockConfidenceAt = (%v, %v), esperado (%v, %v)", confidence, ok, test.wantConfidence, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestScoreBuyAgain(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []ConsumptionEvent
+		now    time.Time
+		want   BuyAgainScore
+	}{
+		{name: "sem compras", events: nil, now: restockDay(10)},
+		{
+			name:   "uma compra hoje",
+			events: restockHistory(10, 1),
+			now:    restockDay(10),
+			want:   BuyAgainScore{PurchaseDays: 1, LastPurchaseDate: restockDay(10), Score: 1},
+		},
+		{
+			name: "repetições no mesmo dia contam uma vez",
+			events: []ConsumptionEvent{
+				{Date: restockDay(0, 9), Quantity: 1},
+				{Date: restockDay(0, 17), Quantity: 3},
+				{Date: restockDay(5), Quantity: 1},
+			},
+			now:  restockDay(5),
+			want: BuyAgainScore{PurchaseDays: 2, LastPurchaseDate: restockDay(5), Score: 2},
+		},
+		{
+			name:   "uma meia-vida depois vale a metade",
+			events: restockHistory(0, 1, 7, 1, 14, 1),
+			now:    restockDay(44),
+			want:   BuyAgainScore{PurchaseDays: 3, LastPurchaseDate: restockDay(14), DaysSinceLast: 30, Score: 1.5},
+		},
+		{
+			name:   "intervalos irregulares só pesam pela frequência",
+			events: restockHistory(0, 1, 1, 1, 40, 1, 41, 1),
+			now:    restockDay(101),
+			want:   BuyAgainScore{PurchaseDays: 4, LastPurchaseDate: restockDay(41), DaysSinceLast: 60, Score: 1},
+		},
+		{
+			name:   "compra com data futura não gera recência negativa",
+			events: restockHistory(0, 1, 20, 1),
+			now:    restockDay(10),
+			want:   BuyAgainScore{PurchaseDays: 2, LastPurchaseDate: restockDay(20), Score: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ScoreBuyAgain(test.events, test.now)
+			if got.PurchaseDays != test.want.PurchaseDays || !got.LastPurchaseDate.Equal(test.want.LastPurchaseDate) ||
+				!almostEqual(got.DaysSinceLast, test.want.DaysSinceLast) || got.Score != test.want.Score {
+				t.Fatalf("ScoreBuyAgain = %+v, esperado %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestMergeEventsByDayLocation confere que o dia da compra é o do fuso das datas: 20h e 22h de um dia
+// em São Paulo caem em dias UTC diferentes, mas são o mesmo dia no fuso local
+func TestMergeEventsByDayLocation(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	evening := time.Date(2025, 3, 5, 20, 0, 0, 0, saoPaulo)
+	lateNight := time.Date(2025, 3, 5, 22, 0, 0, 0, saoPaulo)
+
+	utcDays := mergeEventsByDay([]ConsumptionEvent{
+		{Date: evening.UTC(), Quantity: 1},
+		{Date: lateNight.UTC(), Quantity: 1},
+	})
+	if len(utcDays) != 2 {
+		t.Errorf("%d dias em UTC, esperado 2", len(utcDays))
+	}
+
+	localDays := mergeEventsByDay([]ConsumptionEvent{
+		{Date: evening, Quantity: 1},
+		{Date: lateNight, Quantity: 1},
+	})
+	if len(localDays) != 1 || localDays[0].Quantity != 2 {
+		t.Fatalf("dias no fuso local = %+v, esperado um dia com quantidade 2", localDays)
+	}
+	if want := time.Date(2025, 3, 5, 0, 0, 0, 0, saoPaulo); !localDays[0].Date.Equal(want) {
+		t.Errorf("dia = %v, esperado %v", localDays[0].Date, want)
+	}
+}
@@ -0,0 +1,939 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/barcode"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// storeMatchThreshold é a semelhança mínima para considerar que dois nomes são o mesmo estabelecimento
+// ("Supermercado Pão de Açúcar" e "supermercado pao de acucar." casam; "Extra" e "Extra Hiper" não)
+const storeMatchThreshold = 0.85
+
+// storeSuggestionThreshold é a semelhança mínima para sugerir um estabelecimento em /stores/match
+const storeSuggestionThreshold = 0.5
+
+// DuplicateStoreError é retornado ao criar um estabelecimento que já existe com nome parecido ou mesmo CNPJ
+type DuplicateStoreError struct {
+	StoreID uint
+}
+
+func (e *DuplicateStoreError) Error() string {
+	return fmt.Sprintf("CreateStore: estabelecimento já cadastrado (%d)", e.StoreID)
+}
+
+// StoreMatch é um estabelecimento candidato para um nome digitado
+type StoreMatch struct {
+	Store *models.Store
+	Score float64
+}
+
+// Padrões da busca por proximidade
+const (
+	defaultNearbyRadiusKm = 5.0
+	defaultNearbyLimit    = 20
+)
+
+// NearbyStore é um estabelecimento próximo a um ponto
+type NearbyStore struct {
+	Store      *models.Store
+	DistanceKm float64
+}
+
+// newGeoRadius monta a área de busca a partir das coordenadas informadas (radiusKm 0 = padrão)
+func newGeoRadius(lat, lng *float64, radiusKm float64) (*repositories.GeoRadius, error) {
+	if lat == nil && lng == nil {
+		return nil, nil
+	}
+	if lat == nil || lng == nil {
+		return nil, errors.New("lat e lng devem ser informados juntos")
+	}
+	if radiusKm == 0 {
+		radiusKm = defaultNearbyRadiusKm
+	}
+	return &repositories.GeoRadius{Latitude: *lat, Longitude: *lng, RadiusKm: radiusKm}, nil
+}
+
+// StoreService handles business logic for stores and store chains
+type StoreService struct {
+	storeRepository    *repositories.StoreRepository
+	transactionManager *repositories.TransactionManager
+}
+
+// NewStoreService creates a new instance of StoreService
+func NewStoreService(
+	storeRepo *repositories.StoreRepository,
+	transactionManager *repositories.TransactionManager) *StoreService {
+	return &StoreService{
+		storeRepository:    storeRepo,
+		transactionManager: transactionManager,
+	}
+}
+
+// storeMatcher casa nomes digitados e CNPJs com os estabelecimentos e redes já cadastrados. Os
+// candidatos de cada nome são buscados no banco (pelo CNPJ, pelos nomes alternativos e pela semelhança
+// de trigramas) e guardados, de modo que uma importação consulte cada local uma única vez.
+type storeMatcher struct {
+	storeRepository *repositories.StoreRepository
+	userID          uint                       // Os estabelecimentos próprios do usuário também casam; 0 = só os compartilhados
+	candidates      map[string][]*models.Store // Nome normalizado -> candidatos já buscados
+}
+
+func newStoreMatcher(storeRepository *repositories.StoreRepository, userID uint) *storeMatcher {
+	return &storeMatcher{
+		storeRepository: storeRepository,
+		userID:          userID,
+		candidates:      make(map[string][]*models.Store),
+	}
+}
+
+// visible indica se o estabelecimento é compartilhado ou do usuário do matcher
+func (matcher *storeMatcher) visible(store *models.Store) bool {
+	return store.OwnerID == nil || (matcher.userID != 0 && *store.OwnerID == matcher.userID)
+}
+
+// match procura pelo CNPJ, depois pelo nome normalizado exato (ou um nome alternativo) e por fim
+// pelo nome mais parecido. Um estabelecimento com outro CNPJ nunca casa pelo nome: são filiais ou
+// empresas diferentes.
+func (matcher *storeMatcher) match(normalizedName string, cnpj string) (*models.Store, float64, error) {
+	if cnpj != "" {
+		store, err := matcher.storeRepository.FindStoreByCNPJ(cnpj)
+		if err != nil {
+			return nil, 0, err
+		}
+		if store != nil && matcher.visible(store) {
+			return store, 1, nil
+		}
+	}
+	if normalizedName == "" {
+		return nil, 0, nil
+	}
+
+	store, err := matcher.storeRepository.FindStoreByAlias(normalizedName, matcher.userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if store != nil && (cnpj == "" || store.CNPJ == nil) {
+		return store, 1, nil
+	}
+
+	candidates, err := matcher.rank(normalizedName, storeMatchThreshold)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, candidate := range candidates {
+		if cnpj != "" && candidate.Store.CNPJ != nil {
+			continue
+		}
+		return candidate.Store, candidate.Score, nil
+	}
+	return nil, 0, nil
+}
+
+// rank retorna os estabelecimentos com semelhança mínima ao nome, do mais parecido para o menos
+func (matcher *storeMatcher) rank(normalizedName string, threshold float64) ([]StoreMatch, error) {
+	var candidates []StoreMatch
+	if normalizedName == "" {
+		return candidates, nil
+	}
+	stores, found := matcher.candidates[normalizedName]
+	if !found {
+		var err error
+		if stores, err = matcher.storeRepository.GetStoreCandidates(normalizedName, matcher.userID); err != nil {
+			return nil, err
+		}
+		matcher.candidates[normalizedName] = stores
+	}
+	for _, store := range stores {
+		score := utils.Similarity(normalizedName, store.NormalizedName)
+		if score >= threshold {
+			candidates = append(candidates, StoreMatch{Store: store, Score: score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// inferChain devolve a rede cujo nome inicia o nome do estabelecimento ("Carrefour Centro" pertence
+// à rede "Carrefour"), preferindo o nome de rede mais longo
+func (matcher *storeMatcher) inferChain(normalizedName string) (*models.StoreChain, error) {
+	if normalizedName == "" {
+		return nil, nil
+	}
+	return matcher.storeRepository.FindChainPrefixOf(normalizedName)
+}
+
+// add inclui um estabelecimento criado entre os candidatos já buscados (rank recalcula a semelhança)
+func (matcher *storeMatcher) add(store *models.Store) {
+	for name, stores := range matcher.candidates {
+		matcher.candidates[name] = append(stores, store)
+	}
+}
+
+// reset descarta os candidatos guardados, depois que a transação desfez estabelecimentos criados
+func (matcher *storeMatcher) reset() {
+	clear(matcher.candidates)
+}
+
+// resolveChain devolve a rede com o nome informado, criando-a quando não existe. Retorna nil para nome vazio.
+func resolveChain(storeRepository *repositories.StoreRepository, name string) (*models.StoreChain, error) {
+	name = strings.TrimSpace(name)
+	normalizedName := utils.NormalizeText(name)
+	if normalizedName == "" {
+		return nil, nil
+	}
+	chain, err := storeRepository.FindChainByName(normalizedName)
+	if chain != nil || err != nil {
+		return chain, err
+	}
+
+	chain = &models.StoreChain{Name: name, NormalizedName: normalizedName}
+	if err := storeRepository.CreateChain(chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// setStoreChain vincula o estabelecimento à rede (nil remove o vínculo)
+func setStoreChain(store *models.Store, chain *models.StoreChain) {
+	store.Chain = chain
+	store.ChainID = nil
+	if chain != nil {
+		store.ChainID = &chain.ID
+	}
+}
+
+// resolveStore devolve o estabelecimento correspondente ao nome e CNPJ digitados, criando um novo
+// quando nenhum casa: compartilhado quando há CNPJ e, sem ele, do usuário do matcher, para que um
+// local digitado não apareça para os demais usuários. Retorna nil quando não há nome nem CNPJ.
+func resolveStore(
+	storeRepository *repositories.StoreRepository, matcher *storeMatcher, name string, cnpj string) (*models.Store, error) {
+	name = strings.TrimSpace(name)
+	cnpj = digitsOnly(cnpj)
+	if len(cnpj) != 14 {
+		cnpj = ""
+	}
+	normalizedName := utils.NormalizeText(name)
+	if normalizedName == "" && cnpj == "" {
+		return nil, nil
+	}
+
+	store, _, err := matcher.match(normalizedName, cnpj)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil {
+		// Completa o CNPJ de estabelecimentos compartilhados criados a partir de nomes digitados
+		if cnpj != "" && store.CNPJ == nil && store.OwnerID == nil {
+			store.CNPJ = &cnpj
+			if err := storeRepository.UpdateStore(store); err != nil {
+				return nil, err
+			}
+		}
+		return store, nil
+	}
+
+	store = &models.Store{
+		Name:           name,
+		NormalizedName: normalizedName,
+	}
+	chain, err := matcher.inferChain(normalizedName)
+	if err != nil {
+		return nil, err
+	}
+	setStoreChain(store, chain)
+	if name == "" {
+		store.Name = cnpj
+		store.NormalizedName = cnpj
+	}
+	if cnpj != "" {
+		store.CNPJ = &cnpj
+	} else if matcher.userID != 0 {
+		ownerID := matcher.userID
+		store.OwnerID = &ownerID
+	}
+	if err := storeRepository.CreateStore(store); err != nil {
+		return nil, err
+	}
+	matcher.add(store)
+	return store, nil
+}
+
+// digitsOnly remove a pontuação de documentos como o CNPJ ("12.345.678/0001-90" -> "12345678000190")
+func digitsOnly(value string) string {
+	return strings.Map(func(char rune) rune {
+		if char >= '0' && char <= '9' {
+			return char
+		}
+		return -1
+	}, value)
+}
+
+// chainForStore resolve a rede informada por ID ou por nome
+func (service *StoreService) chainForStore(chainID *uint, chainName string) (*models.StoreChain, error) {
+	if chainID != nil {
+		chain, err := service.storeRepository.GetChainByID(*chainID)
+		if err != nil {
+			return nil, fmt.Errorf("rede não encontrada: %d", *chainID)
+		}
+		return chain, nil
+	}
+	return resolveChain(service.storeRepository, chainName)
+}
+
+// CreateStore cadastra um estabelecimento. Nomes parecidos com um já existente (ou o mesmo CNPJ)
+// são recusados com DuplicateStoreError, indicando o estabelecimento a usar. Sem rede informada,
+// a rede é deduzida do início do nome.
+func (service *StoreService) CreateStore(storeDTO dto.CreateStoreDTO) (*models.Store, error) {
+	normalizedName := utils.NormalizeText(storeDTO.Name)
+	if normalizedName == "" {
+		return nil, errors.New("CreateStore: nome é obrigatório")
+	}
+
+	matcher := newStoreMatcher(service.storeRepository, 0)
+	existing, _, err := matcher.match(normalizedName, storeDTO.CNPJ)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, &DuplicateStoreError{StoreID: existing.ID}
+	}
+
+	store := &models.Store{
+		Name:           strings.TrimSpace(storeDTO.Name),
+		NormalizedName: normalizedName,
+		BranchName:     strings.TrimSpace(storeDTO.BranchName),
+		Address:        strings.TrimSpace(storeDTO.Address),
+		Latitude:       storeDTO.Latitude,
+		Longitude:      storeDTO.Longitude,
+	}
+	chain, err := service.chainForStore(storeDTO.ChainID, storeDTO.Chain)
+	if err != nil {
+		return nil, errors.New("CreateStore: " + err.Error())
+	}
+	if chain == nil {
+		if chain, err = matcher.inferChain(normalizedName); err != nil {
+			return nil, err
+		}
+	}
+	setStoreChain(store, chain)
+	if storeDTO.CNPJ != "" {
+		cnpj := storeDTO.CNPJ
+		store.CNPJ = &cnpj
+	}
+	if store.BarcodeLayout, err = storeBarcodeLayout(storeDTO.BarcodeLayout); err != nil {
+		return nil, errors.New("CreateStore: " + err.Error())
+	}
+
+	if err := service.storeRepository.CreateStore(store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetStoreByID busca um estabelecimento compartilhado ou do próprio usuário
+func (service *StoreService) GetStoreByID(storeID uint, userID uint) (*models.Store, error) {
+	return service.storeRepository.GetVisibleStoreByID(storeID, userID)
+}
+
+// GetStores lista os estabelecimentos compartilhados e os do usuário, opcionalmente filtrados por um
+// trecho do nome
+func (service *StoreService) GetStores(search string, userID uint) ([]*models.Store, error) {
+	return service.storeRepository.SearchStores(utils.NormalizeText(search), userID)
+}
+
+// MatchStores sugere, dentre os estabelecimentos compartilhados e os do usuário, os mais parecidos
+// com um nome digitado
+func (service *StoreService) MatchStores(name string, limit int, userID uint) ([]StoreMatch, error) {
+	candidates, err := newStoreMatcher(service.storeRepository, userID).rank(utils.NormalizeText(name), storeSuggestionThreshold)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// GetNearbyStores lista os estabelecimentos visíveis ao usuário com coordenadas dentro do raio, do mais
+// próximo ao mais distante
+func (service *StoreService) GetNearbyStores(queryDTO dto.NearbyStoresQueryDTO, userID uint) ([]NearbyStore, error) {
+	area, err := newGeoRadius(queryDTO.Lat, queryDTO.Lng, queryDTO.Radius)
+	if err != nil {
+		return nil, errors.New("GetNearbyStores: " + err.Error())
+	}
+	limit := queryDTO.Limit
+	if limit == 0 {
+		limit = defaultNearbyLimit
+	}
+
+	distances, err := service.storeRepository.GetStoresNearby(*area, limit, userID)
+	if err != nil {
+		return nil, err
+	}
+	storeIDs := make([]uint, len(distances))
+	for i, distance := range distances {
+		storeIDs[i] = distance.StoreID
+	}
+	stores, err := service.storeRepository.GetStoresByIDs(storeIDs)
+	if err != nil {
+		return nil, err
+	}
+	storesByID := make(map[uint]*models.Store, len(stores))
+	for _, store := range stores {
+		storesByID[store.ID] = store
+	}
+
+	nearby := make([]NearbyStore, 0, len(distances))
+	for _, distance := range distances {
+		if store, ok := storesByID[distance.StoreID]; ok {
+			nearby = append(nearby, NearbyStore{Store: store, DistanceKm: distance.DistanceKm})
+		}
+	}
+	return nearby, nil
+}
+
+// storeGroupDistances calcula a distância de cada filial visível ao usuário até o centro da área ou, no
+// agrupamento por rede, a da filial mais próxima de cada rede; vazio sem área
+func (service *StoreService) storeGroupDistances(groupBy string, area *repositories.GeoRadius, userID uint) (map[uint]float64, error) {
+	distances := make(map[uint]float64)
+	if area == nil {
+		return distances, nil
+	}
+	nearby, err := service.storeRepository.GetStoresNearby(*area, 0, userID)
+	if err != nil {
+		return nil, err
+	}
+	if groupBy == PriceGroupByBranch {
+		for _, store := range nearby {
+			distances[store.StoreID] = store.DistanceKm
+		}
+		return distances, nil
+	}
+
+	storeIDs := make([]uint, len(nearby))
+	for i, store := range nearby {
+		storeIDs[i] = store.StoreID
+	}
+	stores, err := service.storeRepository.GetStoresByIDs(storeIDs)
+	if err != nil {
+		return nil, err
+	}
+	chainByStore := make(map[uint]uint, len(stores))
+	for _, store := range stores {
+		if store.ChainID != nil {
+			chainByStore[store.ID] = *store.ChainID
+		}
+	}
+	// nearby vem do mais próximo para o mais distante: a primeira filial de cada rede é a mais próxima
+	for _, store := range nearby {
+		chainID, ok := chainByStore[store.StoreID]
+		if _, seen := distances[chainID]; ok && !seen {
+			distances[chainID] = store.DistanceKm
+		}
+	}
+	return distances, nil
+}
+
+// UpdateStore altera os dados de um estabelecimento (apenas admin, pois é compartilhado entre os usuários)
+func (service *StoreService) UpdateStore(storeID uint, storeDTO dto.UpdateStoreDTO, userRole string) (*models.Store, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("UpdateStore: permissão negada: apenas administradores podem alterar estabelecimentos")
+	}
+
+	store, err := service.storeRepository.GetStoreByID(storeID)
+	if err != nil {
+		return nil, errors.New("UpdateStore: estabelecimento não encontrado")
+	}
+
+	if storeDTO.Name != nil {
+		normalizedName := utils.NormalizeText(*storeDTO.Name)
+		if normalizedName == "" {
+			return nil, errors.New("UpdateStore: nome é obrigatório")
+		}
+		store.Name = strings.TrimSpace(*storeDTO.Name)
+		store.NormalizedName = normalizedName
+	}
+	switch {
+	case storeDTO.ChainID != nil && *storeDTO.ChainID == 0:
+		setStoreChain(store, nil)
+	case storeDTO.ChainID != nil || storeDTO.Chain != nil:
+		chainName := ""
+		if storeDTO.Chain != nil {
+			chainName = *storeDTO.Chain
+		}
+		chain, err := service.chainForStore(storeDTO.ChainID, chainName)
+		if err != nil {
+			return nil, errors.New("UpdateStore: " + err.Error())
+		}
+		setStoreChain(store, chain)
+	}
+	if storeDTO.BranchName != nil {
+		store.BranchName = strings.TrimSpace(*storeDTO.BranchName)
+	}
+	if storeDTO.Address != nil {
+		store.Address = strings.TrimSpace(*storeDTO.Address)
+	}
+	if storeDTO.CNPJ != nil {
+		if *storeDTO.CNPJ == "" {
+			store.CNPJ = nil
+		} else {
+			cnpj := *storeDTO.CNPJ
+			store.CNPJ = &cnpj
+		}
+	}
+	if storeDTO.Latitude != nil {
+		store.Latitude = storeDTO.Latitude
+	}
+	if storeDTO.Longitude != nil {
+		store.Longitude = storeDTO.Longitude
+	}
+	if storeDTO.BarcodeLayout != nil {
+		if store.BarcodeLayout, err = storeBarcodeLayout(*storeDTO.BarcodeLayout); err != nil {
+			return nil, errors.New("UpdateStore: " + err.Error())
+		}
+	}
+
+	if err := service.storeRepository.UpdateStore(store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// DeleteStore remove um estabelecimento sem compras nem preços vinculados (apenas admin)
+func (service *StoreService) DeleteStore(storeID uint, userRole string) error {
+	if userRole != string(models.RoleAdmin) {
+		return errors.New("DeleteStore: permissão negada: apenas administradores podem remover estabelecimentos")
+	}
+
+	if _, err := service.storeRepository.GetStoreByID(storeID); err != nil {
+		return errors.New("DeleteStore: estabelecimento não encontrado")
+	}
+
+	references, err := service.storeRepository.CountStoreReferences(storeID)
+	if err != nil {
+		return err
+	}
+	if references > 0 {
+		return fmt.Errorf("DeleteStore: estabelecimento possui %d compras ou preços vinculados; use a mesclagem", references)
+	}
+
+	return service.storeRepository.DeleteStore(storeID)
+}
+
+// MergeStoresResult resume uma mesclagem de estabelecimentos
+type MergeStoresResult struct {
+	Store                 *models.Store
+	MergedStoreIDs        []uint
+	PurchasesUpdated      int64
+	PriceHistoriesUpdated int64
+}
+
+// MergeStores funde estabelecimentos duplicados no de destino (apenas admin), em uma única transação:
+// compras e registros de preço passam a apontar para o destino, os nomes das origens viram nomes
+// alternativos do destino (para que continuem casando na digitação) e os dados que faltam ao destino
+// (rede, filial, endereço, CNPJ e coordenadas) são completados pelas origens, que são removidas.
+func (service *StoreService) MergeStores(mergeDTO dto.MergeStoresDTO, userRole string) (*MergeStoresResult, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("MergeStores: permissão negada: apenas administradores podem mesclar estabelecimentos")
+	}
+
+	sourceIDs := make([]uint, 0, len(mergeDTO.SourceIDs))
+	seen := make(map[uint]bool, len(mergeDTO.SourceIDs))
+	for _, sourceID := range mergeDTO.SourceIDs {
+		if sourceID == mergeDTO.TargetID {
+			return nil, errors.New("MergeStores: o estabelecimento de destino não pode estar entre as origens")
+		}
+		if !seen[sourceID] {
+			seen[sourceID] = true
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return nil, errors.New("MergeStores: informe ao menos um estabelecimento de origem")
+	}
+
+	result := &MergeStoresResult{MergedStoreIDs: sourceIDs}
+	err := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		storeRepository := service.storeRepository.WithTx(tx)
+
+		target, err := storeRepository.GetStoreByID(mergeDTO.TargetID)
+		if err != nil {
+			return fmt.Errorf("MergeStores: estabelecimento não encontrado: %d", mergeDTO.TargetID)
+		}
+		sources, err := storeRepository.GetStoresByIDs(sourceIDs)
+		if err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return errors.New("MergeStores: um ou mais estabelecimentos de origem não foram encontrados")
+		}
+
+		result.PurchasesUpdated, result.PriceHistoriesUpdated, err = storeRepository.RepointStores(sourceIDs, target)
+		if err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			exists, err := storeRepository.AliasExists(source.NormalizedName)
+			if err != nil {
+				return err
+			}
+			if !exists && source.NormalizedName != target.NormalizedName {
+				alias := &models.StoreAlias{StoreID: target.ID, NormalizedName: source.NormalizedName}
+				if err := storeRepository.CreateAlias(alias); err != nil {
+					return err
+				}
+			}
+
+			// O CNPJ é único: sai da origem antes de ir para o destino
+			sourceCNPJ := source.CNPJ
+			if sourceCNPJ != nil {
+				source.CNPJ = nil
+				if err := storeRepository.UpdateStore(source); err != nil {
+					return err
+				}
+			}
+			fillMissingStoreData(target, source, sourceCNPJ)
+
+			if err := storeRepository.DeleteStore(source.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := storeRepository.UpdateStore(target); err != nil {
+			return err
+		}
+		result.Store = target
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// storeBarcodeLayout valida o leiaute de etiqueta de balança informado ("" usa o padrão)
+func storeBarcodeLayout(pattern string) (string, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return "", nil
+	}
+	layout, err := barcode.ParseLayout(pattern)
+	if err != nil {
+		return "", err
+	}
+	return layout.Pattern, nil
+}
+
+// fillMissingStoreData completa os campos vazios do destino com os da origem
+func fillMissingStoreData(target *models.Store, source *models.Store, sourceCNPJ *string) {
+	if target.ChainID == nil && source.ChainID != nil {
+		setStoreChain(target, source.Chain)
+	}
+	if target.BranchName == "" {
+		target.BranchName = source.BranchName
+	}
+	if target.Address == "" {
+		target.Address = source.Address
+	}
+	if target.CNPJ == nil {
+		target.CNPJ = sourceCNPJ
+	}
+	if target.Latitude == nil || target.Longitude == nil {
+		target.Latitude, target.Longitude = source.Latitude, source.Longitude
+	}
+	if target.BarcodeLayout == "" {
+		target.BarcodeLayout = source.BarcodeLayout
+	}
+}
+
+// CreateChain cadastra uma rede de estabelecimentos (apenas admin)
+func (service *StoreService) CreateChain(chainDTO dto.CreateStoreChainDTO, userRole string) (*models.StoreChain, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("CreateChain: permissão negada: apenas administradores podem cadastrar redes")
+	}
+	normalizedName := utils.NormalizeText(chainDTO.Name)
+	if normalizedName == "" {
+		return nil, errors.New("CreateChain: nome é obrigatório")
+	}
+
+	existing, err := service.storeRepository.FindChainByName(normalizedName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("CreateChain: rede já cadastrada (%d)", existing.ID)
+	}
+
+	chain, err := resolveChain(service.storeRepository, chainDTO.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Estabelecimentos sem rede cujo nome começa pelo da rede passam a ser filiais dela, a menos que
+	// outra rede de nome mais longo também o inicie
+	stores, err := service.storeRepository.GetUnchainedStoresByPrefix(normalizedName)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newStoreMatcher(service.storeRepository, 0)
+	for _, store := range stores {
+		inferred, err := matcher.inferChain(store.NormalizedName)
+		if err != nil {
+			return nil, err
+		}
+		if inferred != nil && inferred.ID == chain.ID {
+			setStoreChain(store, chain)
+			if err := service.storeRepository.UpdateStore(store); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return chain, nil
+}
+
+// GetChains lista as redes com a quantidade de filiais de cada uma visíveis ao usuário
+func (service *StoreService) GetChains(userID uint) ([]*models.StoreChain, map[uint]int64, error) {
+	chains, err := service.storeRepository.GetAllChains()
+	if err != nil {
+		return nil, nil, err
+	}
+	branchCounts, err := service.storeRepository.CountStoresByChain(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chains, branchCounts, nil
+}
+
+// GetChainWithBranches busca uma rede e suas filiais visíveis ao usuário
+func (service *StoreService) GetChainWithBranches(chainID uint, userID uint) (*models.StoreChain, []*models.Store, error) {
+	chain, err := service.storeRepository.GetChainByID(chainID)
+	if err != nil {
+		return nil, nil, errors.New("GetChainWithBranches: rede não encontrada")
+	}
+	branches, err := service.storeRepository.GetVisibleStoresByChainID(chainID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, branch := range branches {
+		branch.Chain = chain
+	}
+	return chain, branches, nil
+}
+
+// UpdateChain renomeia uma rede (apenas admin)
+func (service *StoreService) UpdateChain(chainID uint, chainDTO dto.UpdateStoreChainDTO, userRole string) (*models.StoreChain, error) {
+	if userRole != string(models.RoleAdmin) {
+		return nil, errors.New("UpdateChain: permissão negada: apenas administradores podem alterar redes")
+	}
+	chain, err := service.storeRepository.GetChainByID(chainID)
+	if err != nil {
+		return nil, errors.New("UpdateChain: rede não encontrada")
+	}
+	normalizedName := utils.NormalizeText(chainDTO.Name)
+	if normalizedName == "" {
+		return nil, errors.New("UpdateChain: nome é obrigatório")
+	}
+
+	existing, err := service.storeRepository.FindChainByName(normalizedName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != chain.ID {
+		return nil, fmt.Errorf("UpdateChain: rede já cadastrada (%d)", existing.ID)
+	}
+
+	chain.Name = strings.TrimSpace(chainDTO.Name)
+	chain.NormalizedName = normalizedName
+	if err := service.storeRepository.UpdateChain(chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// DeleteChain remove uma rede sem filiais (apenas admin)
+func (service *StoreService) DeleteChain(chainID uint, userRole string) error {
+	if userRole != string(models.RoleAdmin) {
+		return errors.New("DeleteChain: permissão negada: apenas administradores podem remover redes")
+	}
+	if _, err := service.storeRepository.GetChainByID(chainID); err != nil {
+		return errors.New("DeleteChain: rede não encontrada")
+	}
+	branches, err := service.storeRepository.GetStoresByChainID(chainID)
+	if err != nil {
+		return err
+	}
+	if len(branches) > 0 {
+		return fmt.Errorf("DeleteChain: rede possui %d filiais", len(branches))
+	}
+	return service.storeRepository.DeleteChain(chainID)
+}
+
+// BackfillStores agrupa os locais digitados nas compras e no histórico de preços que ainda não
+// apontam para um estabelecimento, cria (ou reaproveita) os estabelecimentos e vincula os registros.
+// Cada usuário casa com os estabelecimentos compartilhados e os seus, e os criados são dele, como na
+// digitação das compras. Os nomes mais usados são processados primeiro, para que as variações casem
+// com a grafia mais comum. Também converte a rede gravada como texto nos estabelecimentos em
+// registros de rede. Retorna a quantidade de nomes vinculados.
+func (service *StoreService) BackfillStores() (int, error) {
+	linked := 0
+	err := service.transactionManager.Transaction(func(tx *gorm.DB) error {
+		storeRepository := service.storeRepository.WithTx(tx)
+
+		if err := migrateLegacyChains(storeRepository); err != nil {
+			return err
+		}
+
+		locations, err := storeRepository.GetUnlinkedLocations()
+		if err != nil {
+			return err
+		}
+
+		type storeOfUser struct{ storeID, userID uint }
+		matchers := make(map[uint]*storeMatcher)
+		namesByStore := make(map[storeOfUser][]string)
+		var keys []storeOfUser
+		for _, location := range locations {
+			matcher, found := matchers[location.UserID]
+			if !found {
+				matcher = newStoreMatcher(storeRepository, location.UserID)
+				matchers[location.UserID] = matcher
+			}
+			store, err := resolveStore(storeRepository, matcher, location.Name, "")
+			if err != nil {
+				return err
+			}
+			if store == nil {
+				continue
+			}
+			key := storeOfUser{storeID: store.ID, userID: location.UserID}
+			if _, seen := namesByStore[key]; !seen {
+				keys = append(keys, key)
+			}
+			namesByStore[key] = append(namesByStore[key], location.Name)
+		}
+
+		for _, key := range keys {
+			if err := storeRepository.LinkLocations(key.storeID, key.userID, namesByStore[key]); err != nil {
+				return err
+			}
+			linked += len(namesByStore[key])
+		}
+		return nil
+	})
+	return linked, err
+}
+
+// migrateLegacyChains cria as redes a partir da antiga coluna de texto chain e a remove
+func migrateLegacyChains(storeRepository *repositories.StoreRepository) error {
+	legacyChains, err := storeRepository.GetLegacyChainNames()
+	if err != nil {
+		return err
+	}
+	storeIDs := make([]uint, 0, len(legacyChains))
+	for storeID := range legacyChains {
+		storeIDs = append(storeIDs, storeID)
+	}
+	stores, err := storeRepository.GetStoresByIDs(storeIDs)
+	if err != nil {
+		return err
+	}
+	for _, store := range stores {
+		chain, err := resolveChain(storeRepository, legacyChains[store.ID])
+		if err != nil {
+			return err
+		}
+		setStoreChain(store, chain)
+		if err := storeRepository.UpdateStore(store); err != nil {
+			return err
+		}
+	}
+	return storeRepository.DropLegacyChainColumn()
+}
+
+// ToStoreResponseDTO converts a Store model to StoreResponseDTO
+func (service *StoreService) ToStoreResponseDTO(store *models.Store) dto.StoreResponseDTO {
+	storeDTO := dto.StoreResponseDTO{
+		ID:            store.ID,
+		Name:          store.Name,
+		ChainID:       store.ChainID,
+		BranchName:    store.BranchName,
+		Address:       store.Address,
+		CNPJ:          store.CNPJ,
+		Latitude:      store.Latitude,
+		Longitude:     store.Longitude,
+		BarcodeLayout: store.BarcodeLayout,
+		OwnerID:       store.OwnerID,
+		CreatedAt:     store.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     store.UpdatedAt.Format(time.RFC3339),
+	}
+	if store.Chain != nil {
+		storeDTO.Chain = store.Chain.Name
+	}
+	return storeDTO
+}
+
+// ToStoreResponseDTOList converts a list of Store models to StoreResponseDTOs
+func (service *StoreService) ToStoreResponseDTOList(stores []*models.Store) []dto.StoreResponseDTO {
+	dtos := make([]dto.StoreResponseDTO, len(stores))
+	for i, store := range stores {
+		dtos[i] = service.ToStoreResponseDTO(store)
+	}
+	return dtos
+}
+
+// ToStoreMatchDTOList converts store candidates to StoreMatchDTOs
+func (service *StoreService) ToStoreMatchDTOList(matches []StoreMatch) []dto.StoreMatchDTO {
+	dtos := make([]dto.StoreMatchDTO, len(matches))
+	for i, match := range matches {
+		dtos[i] = dto.StoreMatchDTO{
+			Store: service.ToStoreResponseDTO(match.Store),
+			Score: utils.FormatForDisplay(match.Score),
+		}
+	}
+	return dtos
+}
+
+// ToNearbyStoreDTOList converts nearby stores to NearbyStoreDTOs
+func (service *StoreService) ToNearbyStoreDTOList(stores []NearbyStore) []dto.NearbyStoreDTO {
+	dtos := make([]dto.NearbyStoreDTO, len(stores))
+	for i, nearby := range stores {
+		dtos[i] = dto.NearbyStoreDTO{
+			Store:      service.ToStoreResponseDTO(nearby.Store),
+			DistanceKm: utils.FormatForDisplay(nearby.DistanceKm),
+		}
+	}
+	return dtos
+}
+
+// ToStoreChainResponseDTO converts a StoreChain model to StoreChainResponseDTO
+func (service *StoreService) ToStoreChainResponseDTO(chain *models.StoreChain, branchCount int64) dto.StoreChainResponseDTO {
+	return dto.StoreChainResponseDTO{
+		ID:          chain.ID,
+		Name:        chain.Name,
+		BranchCount: branchCount,
+		CreatedAt:   chain.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   chain.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToMergeStoresResultDTO converts a MergeStoresResult to MergeStoresResultDTO
+func (service *StoreService) ToMergeStoresResultDTO(result *MergeStoresResult) dto.MergeStoresResultDTO {
+	return dto.MergeStoresResultDTO{
+		Store:                 service.ToStoreResponseDTO(result.Store),
+		MergedStoreIDs:        result.MergedStoreIDs,
+		PurchasesUpdated:      result.PurchasesUpdated,
+		PriceHistoriesUpdated: result.PriceHistoriesUpdated,
+	}
+}
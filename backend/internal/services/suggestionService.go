@@ -0,0 +1,168 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Janela de histórico considerada pelas sugestões
+const suggestionLookbackDays = 365
+
+// SuggestionService handles restock predictions and "buy again" suggestions
+type SuggestionService struct {
+	purchaseRepository *repositories.PurchaseRepository
+	location           *time.Location // Fuso em que as compras são agrupadas por dia
+}
+
+// NewSuggestionService creates a new instance of SuggestionService.
+// Purchases are grouped by UTC day until SetLocation sets the users' time zone.
+func NewSuggestionService(purchaseRepo *repositories.PurchaseRepository) *SuggestionService {
+	return &SuggestionService{purchaseRepository: purchaseRepo, location: time.UTC}
+}
+
+// SetLocation define o fuso em que as datas das compras são agrupadas por dia
+func (service *SuggestionService) SetLocation(location *time.Location) {
+	service.location = location
+}
+
+// productHistory agrupa os eventos de compra de um produto
+type productHistory struct {
+	ProductID   uint
+	ProductName string
+	Unit        string // Unidade base das quantidades; vazia quando as unidades foram misturadas
+	Events      []ConsumptionEvent
+}
+
+// loadProductHistories carrega o histórico de itens do usuário agrupado por produto. Com byUnit, cada
+// unidade base do produto forma um histórico próprio, para que quantidades em kg e em unidades não
+// sejam somadas; sem ele, as quantidades não têm significado.
+func (service *SuggestionService) loadProductHistories(userID uint, now time.Time, byUnit bool) ([]*productHistory, error) {
+	rows, err := service.purchaseRepository.GetPurchaseItemHistoryByUserID(userID, now.AddDate(0, 0, -suggestionLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	// As linhas já vêm ordenadas por produto e unidade
+	var histories []*productHistory
+	for _, row := range rows {
+		unit := ""
+		if byUnit {
+			unit = row.Unit
+		}
+		if len(histories) == 0 || histories[len(histories)-1].ProductID != row.ProductID ||
+			histories[len(histories)-1].Unit != unit {
+			histories = append(histories, &productHistory{ProductID: row.ProductID, ProductName: row.ProductName, Unit: unit})
+		}
+		current := histories[len(histories)-1]
+		// O dia da compra é o do fuso configurado, e não o da sessão do banco
+		current.Events = append(current.Events, ConsumptionEvent{Date: row.PurchaseDate.In(service.location), Quantity: row.Quantity})
+	}
+	if !byUnit {
+		// Sem separar por unidade os eventos de um produto podem vir fora de ordem
+		for _, history := range histories {
+			sort.SliceStable(history.Events, func(i, j int) bool {
+				return history.Events[i].Date.Before(history.Events[j].Date)
+			})
+		}
+	}
+	return histories, nil
+}
+
+// GetRestockSuggestions lista produtos recorrentes cujo estoque estimado acaba em até horizonDays dias
+func (service *SuggestionService) GetRestockSuggestions(userID uint, horizonDays int, now time.Time) ([]dto.RestockSuggestionDTO, error) {
+	histories, err := service.loadProductHistories(userID, now, true)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]dto.RestockSuggestionDTO, 0)
+	for _, history := range histories {
+		estimate, recurring := EstimateConsumption(history.Events)
+		if !recurring {
+			continue
+		}
+
+		daysUntilRunOut := estimate.ExpectedRunOutDate.Sub(now).Hours() / hoursPerDay
+		if daysUntilRunOut > float64(horizonDays) {
+			continue
+		}
+
+		confidence, stillRelevant := RestockConfidenceAt(estimate, now)
+		if !stillRelevant {
+			continue
+		}
+
+		// Só itens contados são arredondados para cima; kg e L mantêm as casas decimais
+		suggestedQuantity := math.Ceil(estimate.QuantityPerPurchase)
+		if history.Unit != "un" {
+			suggestedQuantity = utils.FormatDecimal(estimate.QuantityPerPurchase)
+		}
+		suggestions = append(suggestions, dto.RestockSuggestionDTO{
+			ProductID:          history.ProductID,
+			ProductName:        history.ProductName,
+			Unit:               history.Unit,
+			LastPurchaseDate:   estimate.LastPurchaseDate.Format(time.RFC3339),
+			ExpectedRunOutDate: estimate.ExpectedRunOutDate.Format(time.RFC3339),
+			DaysUntilRunOut:    utils.FormatForDisplay(daysUntilRunOut),
+			MeanIntervalDays:   utils.FormatForDisplay(estimate.MeanIntervalDays),
+			QuantityPerDay:     utils.FormatDecimal(estimate.QuantityPerDay),
+			SuggestedQuantity:  suggestedQuantity,
+			PurchaseCount:      estimate.PurchaseDays,
+			Confidence:         confidence,
+		})
+	}
+
+	// Mais urgentes primeiro; empate decidido pela confiança e depois pelo ID (ordem determinística)
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].DaysUntilRunOut != suggestions[j].DaysUntilRunOut {
+			return suggestions[i].DaysUntilRunOut < suggestions[j].DaysUntilRunOut
+		}
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		if suggestions[i].ProductID != suggestions[j].ProductID {
+			return suggestions[i].ProductID < suggestions[j].ProductID
+		}
+		return suggestions[i].Unit < suggestions[j].Unit
+	})
+
+	return suggestions, nil
+}
+
+// GetBuyAgainSuggestions ranqueia os produtos do usuário por frequência e recência
+func (service *SuggestionService) GetBuyAgainSuggestions(userID uint, limit int, now time.Time) ([]dto.BuyAgainSuggestionDTO, error) {
+	histories, err := service.loadProductHistories(userID, now, false)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]dto.BuyAgainSuggestionDTO, 0, len(histories))
+	for _, history := range histories {
+		score := ScoreBuyAgain(history.Events, now)
+		suggestions = append(suggestions, dto.BuyAgainSuggestionDTO{
+			ProductID:        history.ProductID,
+			ProductName:      history.ProductName,
+			PurchaseCount:    score.PurchaseDays,
+			LastPurchaseDate: score.LastPurchaseDate.Format(time.RFC3339),
+			DaysSinceLast:    utils.FormatForDisplay(score.DaysSinceLast),
+			Score:            score.Score,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].ProductID < suggestions[j].ProductID
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
@@ -43,11 +43,12 @@ func (service *UserCategoryProductService) CreateUserCategoryProduct(
 		return nil, errors.New("CreateUserCategoryProduct: esta categoria não pertence ao usuário")
 	}
 
-	// Verify if product exists
-	_, err = service.productService.GetProductByID(createDTO.ProductID)
-	if err != nil {
+	// Verify if product exists (another user's pending proposal counts as not found)
+	product, err := service.productService.GetProductByID(createDTO.ProductID)
+	if err != nil || !productUsableBy(product, userID) {
 		return nil, errors.New("CreateUserCategoryProduct: produto não encontrado")
 	}
+	createDTO.ProductID = product.ID
 
 	// Check if the relationship already exists
 	existingUCP, err := service.ucpRepository.GetUserCategoryProduct(userID, createDTO.CategoryID, createDTO.ProductID)
@@ -100,7 +101,8 @@ func (service *UserCategoryProductService) GetUserCategoryProductsByUserID(userI
 	return service.ucpRepository.GetUserCategoryProductsByUserID(userID)
 }
 
-// GetUserCategoryProductsByCategory retrieves all user-category-product relationships for a specific category
+// GetUserCategoryProductsByCategory retrieves all user-category-product relationships for a specific category,
+// including the ones of its subcategories
 func (service *UserCategoryProductService) GetUserCategoryProductsByCategory(
 	categoryID uint, userID uint, userRole string) ([]*models.UserCategoryProduct, error) {
 
@@ -118,6 +120,76 @@ func (service *UserCategoryProductService) GetUserCategoryProductsByCategory(
 	return service.ucpRepository.GetUserCategoryProductsByCategoryID(categoryID)
 }
 
+// GetUserCategoryProductsByCategories retrieves the relationships of several categories at once, grouped by category.
+// Each category also receives the relationships of its subcategories.
+// Categories of other users are skipped unless the requester is an admin.
+func (service *UserCategoryProductService) GetUserCategoryProductsByCategories(
+	categories []*models.Category, userID uint, userRole string) (map[uint][]*models.UserCategoryProduct, error) {
+
+	categoryIDs := make([]uint, 0, len(categories))
+	for _, category := range categories {
+		if category.UserID == userID || userRole == string(models.RoleAdmin) {
+			categoryIDs = append(categoryIDs, category.ID)
+		}
+	}
+	subtrees, err := service.categoryService.GetSubtreeCategoryIDs(categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	var subtreeIDs []uint
+	for _, ids := range subtrees {
+		subtreeIDs = append(subtreeIDs, ids...)
+	}
+	ucps, err := service.ucpRepository.GetUserCategoryProductsByCategoryIDs(subtreeIDs)
+	if err != nil {
+		return nil, err
+	}
+	ucpsByID := make(map[uint][]*models.UserCategoryProduct, len(ucps))
+	for _, ucp := range ucps {
+		ucpsByID[ucp.CategoryID] = append(ucpsByID[ucp.CategoryID], ucp)
+	}
+
+	ucpsByCategory := make(map[uint][]*models.UserCategoryProduct, len(categoryIDs))
+	for rootID, ids := range subtrees {
+		for _, id := range ids {
+			ucpsByCategory[rootID] = append(ucpsByCategory[rootID], ucpsByID[id]...)
+		}
+	}
+	return ucpsByCategory, nil
+}
+
+// GetUserCategoryProductsByUserIDs retrieves the relationships of several users at once, grouped by user.
+// Regular users only receive their own relationships.
+func (service *UserCategoryProductService) GetUserCategoryProductsByUserIDs(
+	userIDs []uint, userID uint, userRole string) (map[uint][]*models.UserCategoryProduct, error) {
+	if userRole != string(models.RoleAdmin) {
+		userIDs = filterIDs(userIDs, userID)
+	}
+	ucps, err := service.ucpRepository.GetUserCategoryProductsByUserIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	ucpsByUser := make(map[uint][]*models.UserCategoryProduct, len(userIDs))
+	for _, ucp := range ucps {
+		ucpsByUser[ucp.UserID] = append(ucpsByUser[ucp.UserID], ucp)
+	}
+	return ucpsByUser, nil
+}
+
+// GetUserCategoryProductsByProducts retrieves the user's own relationships for several products at once, grouped by product
+func (service *UserCategoryProductService) GetUserCategoryProductsByProducts(
+	productIDs []uint, userID uint) (map[uint][]*models.UserCategoryProduct, error) {
+	ucps, err := service.ucpRepository.GetUserCategoryProductsByUserAndProductIDs(userID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	ucpsByProduct := make(map[uint][]*models.UserCategoryProduct, len(productIDs))
+	for _, ucp := range ucps {
+		ucpsByProduct[ucp.ProductID] = append(ucpsByProduct[ucp.ProductID], ucp)
+	}
+	return ucpsByProduct, nil
+}
+
 // DeleteUserCategoryProduct deletes a user-category-product relationship
 func (service *UserCategoryProductService) DeleteUserCategoryProduct(
 	ucpID uint, userID uint, userRole string) error {
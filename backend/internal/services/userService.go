@@ -166,3 +166,29 @@ func (service *UserService) ToUserResponseDTO(user *models.User) dto.UserRespons
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
 	}
 }
+
+// GetUsersByIDs busca de uma vez os usuários visíveis ao solicitante: admins veem todos, os demais apenas a si mesmos
+func (service *UserService) GetUsersByIDs(ids []uint, requestingUserID uint, requestingUserRole string) (map[uint]*models.User, error) {
+	if requestingUserRole != string(models.RoleAdmin) {
+		ids = filterIDs(ids, requestingUserID)
+	}
+	users, err := service.userRepository.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	usersByID := make(map[uint]*models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+	return usersByID, nil
+}
+
+// filterIDs mantém apenas o ID permitido, se ele estiver na lista
+func filterIDs(ids []uint, allowedID uint) []uint {
+	for _, id := range ids {
+		if id == allowedID {
+			return []uint{allowedID}
+		}
+	}
+	return nil
+}
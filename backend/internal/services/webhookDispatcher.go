@@ -0,0 +1,268 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/webhook"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookBatchSize         = 100
+	webhookDeliveryBatchSize = 20 // Entregas reservadas por rodada; são enviadas uma após a outra
+	webhookBaseBackoff       = 30 * time.Second
+	webhookMaxBackoff        = 6 * time.Hour
+	webhookMaxResponseRead   = 1024 // Bytes da resposta lidos (e descartados) antes de fechar a conexão
+)
+
+// webhookEnvelope é o corpo enviado em cada entrega
+type webhookEnvelope struct {
+	ID        uint            `json:"id"` // ID do evento: o mesmo em todas as tentativas (idempotência)
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDispatcher lê a outbox, cria as entregas de cada evento e as envia com assinatura HMAC,
+// repetindo as que falham com backoff exponencial até MaxAttempts.
+type WebhookDispatcher struct {
+	outboxRepository   *repositories.OutboxRepository
+	webhookRepository  *repositories.WebhookRepository
+	transactionManager *repositories.TransactionManager
+	httpClient         *http.Client
+	interval           time.Duration
+	maxAttempts        int
+}
+
+// NewWebhookDispatcher creates a new instance of WebhookDispatcher
+func NewWebhookDispatcher(
+	outboxRepo *repositories.OutboxRepository,
+	webhookRepo *repositories.WebhookRepository,
+	transactionManager *repositories.TransactionManager,
+	interval time.Duration,
+	timeout time.Duration,
+	maxAttempts int) *WebhookDispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &WebhookDispatcher{
+		outboxRepository:   outboxRepo,
+		webhookRepository:  webhookRepo,
+		transactionManager: transactionManager,
+		httpClient:         webhook.NewHTTPClient(timeout),
+		interval:           interval,
+		maxAttempts:        maxAttempts,
+	}
+}
+
+// Start executa o dispatcher em segundo plano até o contexto ser cancelado
+func (dispatcher *WebhookDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(dispatcher.interval)
+		defer ticker.Stop()
+		for {
+			if err := dispatcher.RunOnce(ctx); err != nil {
+				log.Printf("[webhooks] %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce distribui os eventos pendentes da outbox e envia as entregas vencidas
+func (dispatcher *WebhookDispatcher) RunOnce(ctx context.Context) error {
+	if err := dispatcher.fanOutEvents(); err != nil {
+		return fmt.Errorf("distribuição de eventos: %w", err)
+	}
+	return dispatcher.deliverDue(ctx)
+}
+
+// fanOutEvents cria uma entrega para cada assinatura interessada em cada evento pendente
+func (dispatcher *WebhookDispatcher) fanOutEvents() error {
+	return dispatcher.transactionManager.Transaction(func(tx *gorm.DB) error {
+		outboxRepository := dispatcher.outboxRepository.WithTx(tx)
+		webhookRepository := dispatcher.webhookRepository.WithTx(tx)
+
+		events, err := outboxRepository.GetPendingEvents(webhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, event := range events {
+			subscriptions, err := webhookRepository.GetActiveSubscriptionsForEvent(event.EventType, event.UserID)
+			if err != nil {
+				return err
+			}
+
+			deliveries := make([]models.WebhookDelivery, len(subscriptions))
+			for i, subscription := range subscriptions {
+				deliveries[i] = models.WebhookDelivery{
+					SubscriptionID: subscription.ID,
+					EventID:        event.ID,
+					EventType:      event.EventType,
+					Status:         models.WebhookDeliveryPending,
+					NextAttemptAt:  now,
+				}
+			}
+			if err := webhookRepository.CreateDeliveries(deliveries); err != nil {
+				return err
+			}
+			if err := outboxRepository.MarkEventDispatched(event.ID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deliverDue reserva as entregas vencidas (adiando a próxima tentativa, para que outro dispatcher
+// não as pegue durante o envio) e as envia fora da transação. Como o envio é sequencial, a reserva
+// de cada entrega cobre o pior caso das que vêm antes dela no lote.
+func (dispatcher *WebhookDispatcher) deliverDue(ctx context.Context) error {
+	var deliveries []models.WebhookDelivery
+	err := dispatcher.transactionManager.Transaction(func(tx *gorm.DB) error {
+		webhookRepository := dispatcher.webhookRepository.WithTx(tx)
+
+		now := time.Now()
+		var err error
+		deliveries, err = webhookRepository.GetDueDeliveries(now, webhookDeliveryBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range deliveries {
+			deliveries[i].NextAttemptAt = now.Add(time.Duration(i+1)*dispatcher.httpClient.Timeout + time.Minute)
+			if err := webhookRepository.UpdateDelivery(&deliveries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reserva de entregas: %w", err)
+	}
+
+	subscriptions := make(map[uint]*models.WebhookSubscription)
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return nil // Entregas reservadas voltam a vencer após o prazo da reserva
+		}
+		delivery := &deliveries[i]
+
+		subscription, cached := subscriptions[delivery.SubscriptionID]
+		if !cached {
+			subscription, err = dispatcher.webhookRepository.GetSubscriptionByID(delivery.SubscriptionID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		dispatcher.attempt(ctx, delivery, subscription)
+		if err := dispatcher.webhookRepository.UpdateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt envia a entrega uma vez e registra o resultado (status, erro, duração e próxima tentativa)
+func (dispatcher *WebhookDispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) {
+	delivery.Attempts++
+
+	if subscription == nil || !subscription.Active {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "assinatura removida ou desativada"
+		return
+	}
+
+	event, err := dispatcher.webhookRepository.GetEventByID(delivery.EventID)
+	if err != nil {
+		dispatcher.scheduleRetry(delivery, 0, "evento não encontrado: "+err.Error())
+		return
+	}
+	body, err := json.Marshal(webhookEnvelope{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "payload inválido: " + err.Error()
+		return
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "AppMercado-Webhooks/1.0")
+	request.Header.Set(webhook.EventHeader, event.EventType)
+	request.Header.Set(webhook.DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(webhook.SignatureHeader, webhook.Sign(subscription.Secret, time.Now(), body))
+
+	startedAt := time.Now()
+	response, err := dispatcher.httpClient.Do(request)
+	delivery.LastDurationMs = time.Since(startedAt).Milliseconds()
+	if err != nil {
+		dispatcher.scheduleRetry(delivery, 0, err.Error())
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(response.Body, webhookMaxResponseRead))
+		deliveredAt := time.Now()
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.LastStatusCode = response.StatusCode
+		delivery.LastError = ""
+		delivery.DeliveredAt = &deliveredAt
+		return
+	}
+
+	// O corpo da resposta não é guardado: o log de entregas é visível ao dono do webhook
+	io.Copy(io.Discard, io.LimitReader(response.Body, webhookMaxResponseRead))
+	dispatcher.scheduleRetry(delivery, response.StatusCode, fmt.Sprintf("HTTP %d", response.StatusCode))
+}
+
+// scheduleRetry agenda a próxima tentativa com backoff exponencial (30s, 1min, 2min... até 6h, com
+// variação aleatória de até 10%) ou marca a entrega como falha quando as tentativas se esgotam
+func (dispatcher *WebhookDispatcher) scheduleRetry(delivery *models.WebhookDelivery, statusCode int, message string) {
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = truncateText(message, 1000)
+
+	if delivery.Attempts >= dispatcher.maxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return
+	}
+
+	backoff := webhookBaseBackoff << (delivery.Attempts - 1)
+	if backoff > webhookMaxBackoff || backoff <= 0 {
+		backoff = webhookMaxBackoff
+	}
+	backoff += time.Duration(rand.Int64N(int64(backoff/10) + 1))
+	delivery.NextAttemptAt = time.Now().Add(backoff)
+}
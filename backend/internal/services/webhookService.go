@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/internal/dto"
+	"github.com/Parron01/AppMercado/backend/internal/models"
+	"github.com/Parron01/AppMercado/backend/internal/repositories"
+	"github.com/Parron01/AppMercado/backend/pkg/webhook"
+)
+
+const (
+	webhookSecretBytes        = 32
+	defaultWebhookDeliveryLog = 50
+	maxWebhookDeliveryLog     = 200
+	webhookResolveTimeout     = 5 * time.Second
+)
+
+// WebhookService manages the webhook subscriptions of each user and their delivery log
+type WebhookService struct {
+	webhookRepository *repositories.WebhookRepository
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(webhookRepo *repositories.WebhookRepository) *WebhookService {
+	return &WebhookService{webhookRepository: webhookRepo}
+}
+
+// CreateWebhook cria uma assinatura para o usuário e gera o segredo usado nas assinaturas HMAC
+func (service *WebhookService) CreateWebhook(createDTO dto.CreateWebhookDTO, userID uint) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(createDTO.URL); err != nil {
+		return nil, errors.New("CreateWebhook: " + err.Error())
+	}
+	eventTypes, err := normalizeEventTypes(createDTO.EventTypes)
+	if err != nil {
+		return nil, errors.New("CreateWebhook: " + err.Error())
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.WebhookSubscription{
+		UserID:      userID,
+		URL:         createDTO.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Description: createDTO.Description,
+		Active:      true,
+	}
+	if err := service.webhookRepository.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetWebhookByID retorna uma assinatura do usuário (ou qualquer uma, para admin)
+func (service *WebhookService) GetWebhookByID(webhookID uint, userID uint, userRole string) (*models.WebhookSubscription, error) {
+	subscription, err := service.webhookRepository.GetSubscriptionByID(webhookID)
+	if err != nil {
+		return nil, errors.New("GetWebhookByID: webhook não encontrado")
+	}
+	if subscription.UserID != userID && userRole != string(models.RoleAdmin) {
+		return nil, errors.New("GetWebhookByID: permissão negada: você não pode acessar webhooks de outros usuários")
+	}
+	return subscription, nil
+}
+
+// GetWebhooksByUserID lista as assinaturas do usuário
+func (service *WebhookService) GetWebhooksByUserID(userID uint) ([]*models.WebhookSubscription, error) {
+	return service.webhookRepository.GetSubscriptionsByUserID(userID)
+}
+
+// UpdateWebhook atualiza URL, eventos, descrição ou situação da assinatura
+func (service *WebhookService) UpdateWebhook(
+	webhookID uint, updateDTO dto.UpdateWebhookDTO, userID uint, userRole string) (*models.WebhookSubscription, error) {
+	subscription, err := service.GetWebhookByID(webhookID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateDTO.URL != nil {
+		if err := validateWebhookURL(*updateDTO.URL); err != nil {
+			return nil, errors.New("UpdateWebhook: " + err.Error())
+		}
+		subscription.URL = *updateDTO.URL
+	}
+	if updateDTO.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(updateDTO.EventTypes)
+		if err != nil {
+			return nil, errors.New("UpdateWebhook: " + err.Error())
+		}
+		subscription.EventTypes = eventTypes
+	}
+	if updateDTO.Description != nil {
+		subscription.Description = *updateDTO.Description
+	}
+	if updateDTO.Active != nil {
+		subscription.Active = *updateDTO.Active
+	}
+
+	if err := service.webhookRepository.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// RotateWebhookSecret gera um novo segredo; entregas seguintes já usam o novo valor
+func (service *WebhookService) RotateWebhookSecret(webhookID uint, userID uint, userRole string) (*models.WebhookSubscription, error) {
+	subscription, err := service.GetWebhookByID(webhookID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = secret
+	if err := service.webhookRepository.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// DeleteWebhook remove a assinatura e descarta as entregas pendentes
+func (service *WebhookService) DeleteWebhook(webhookID uint, userID uint, userRole string) error {
+	if _, err := service.GetWebhookByID(webhookID, userID, userRole); err != nil {
+		return err
+	}
+	return service.webhookRepository.DeleteSubscription(webhookID)
+}
+
+// GetWebhookDeliveries retorna o log das entregas mais recentes da assinatura
+func (service *WebhookService) GetWebhookDeliveries(
+	webhookID uint, limit int, userID uint, userRole string) ([]*models.WebhookDelivery, error) {
+	if _, err := service.GetWebhookByID(webhookID, userID, userRole); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryLog
+	}
+	if limit > maxWebhookDeliveryLog {
+		limit = maxWebhookDeliveryLog
+	}
+	return service.webhookRepository.GetDeliveriesBySubscriptionID(webhookID, limit)
+}
+
+// RedeliverWebhook agenda uma nova tentativa para a entrega (inclusive as que já falharam ou foram entregues)
+func (service *WebhookService) RedeliverWebhook(deliveryID uint, userID uint, userRole string) (*models.WebhookDelivery, error) {
+	delivery, err := service.webhookRepository.GetDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, errors.New("RedeliverWebhook: entrega não encontrada")
+	}
+	if _, err := service.GetWebhookByID(delivery.SubscriptionID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := service.webhookRepository.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// validateWebhookURL aceita apenas URLs http(s) absolutas cujo host resolva para endereços públicos.
+// O dispatcher confere de novo o endereço a cada conexão, pois o DNS pode mudar depois do cadastro.
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("URL do webhook deve ser http ou https")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+	return webhook.CheckHost(ctx, parsed.Hostname())
+}
+
+// normalizeEventTypes valida os tipos de evento e os serializa (sem duplicatas, em ordem)
+func normalizeEventTypes(eventTypes []string) (string, error) {
+	var normalized []string
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return "", errors.New("tipo de evento desconhecido: " + eventType + " (válidos: " + strings.Join(WebhookEventTypes, ", ") + ")")
+		}
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	slices.Sort(normalized)
+	return strings.Join(normalized, ","), nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buffer := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buffer), nil
+}
+
+// ToWebhookResponseDTO converts a WebhookSubscription model to WebhookResponseDTO (without the secret)
+func (service *WebhookService) ToWebhookResponseDTO(subscription *models.WebhookSubscription) dto.WebhookResponseDTO {
+	return dto.WebhookResponseDTO{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		EventTypes:  strings.Split(subscription.EventTypes, ","),
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		CreatedAt:   subscription.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   subscription.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToWebhookWithSecretResponseDTO includes the secret (creation and rotation only)
+func (service *WebhookService) ToWebhookWithSecretResponseDTO(subscription *models.WebhookSubscription) dto.WebhookResponseDTO {
+	response := service.ToWebhookResponseDTO(subscription)
+	response.Secret = subscription.Secret
+	return response
+}
+
+// ToWebhookResponseDTOList converts a list of WebhookSubscription models to WebhookResponseDTOs
+func (service *WebhookService) ToWebhookResponseDTOList(subscriptions []*models.WebhookSubscription) []dto.WebhookResponseDTO {
+	dtos := make([]dto.WebhookResponseDTO, len(subscriptions))
+	for i, subscription := range subscriptions {
+		dtos[i] = service.ToWebhookResponseDTO(subscription)
+	}
+	return dtos
+}
+
+// ToWebhookDeliveryResponseDTO converts a WebhookDelivery model to WebhookDeliveryResponseDTO
+func (service *WebhookService) ToWebhookDeliveryResponseDTO(delivery *models.WebhookDelivery) dto.WebhookDeliveryResponseDTO {
+	response := dto.WebhookDeliveryResponseDTO{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		LastDurationMs: delivery.LastDurationMs,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		response.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	if delivery.DeliveredAt != nil {
+		response.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// ToWebhookDeliveryResponseDTOList converts a list of WebhookDelivery models to WebhookDeliveryResponseDTOs
+func (service *WebhookService) ToWebhookDeliveryResponseDTOList(deliveries []*models.WebhookDelivery) []dto.WebhookDeliveryResponseDTO {
+	dtos := make([]dto.WebhookDeliveryResponseDTO, len(deliveries))
+	for i, delivery := range deliveries {
+		dtos[i] = service.ToWebhookDeliveryResponseDTO(delivery)
+	}
+	return dtos
+}
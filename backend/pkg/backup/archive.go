@@ -0,0 +1,169 @@
+// Package backup define o arquivo portátil de backup do AppMercado: um zip com um manifest.json
+// versionado e um arquivo NDJSON por entidade, com os IDs originais da instância de origem.
+// Os IDs servem apenas para ligar os registros entre si; a restauração gera novos IDs.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// FormatVersion é a versão do formato gravada pelos backups atuais.
+// Arquivos de versões anteriores continuam legíveis; versões mais novas são recusadas.
+// Versão 2: estabelecimentos (stores) e storeId em compras e histórico de preços.
+// Versão 3: moderação dos produtos, códigos de barras adicionais, PLUs, redirecionamentos de produtos
+// mesclados e dono dos estabelecimentos criados a partir de locais digitados.
+const FormatVersion = 3
+
+const manifestFile = "manifest.json"
+
+// Escopo do backup
+const (
+	ScopeInstance = "instance" // Todos os usuários e dados da instância
+	ScopeUser     = "user"     // Dados de um único usuário e os produtos que ele referencia
+)
+
+// Manifest descreve o conteúdo do arquivo
+type Manifest struct {
+	FormatVersion int            `json:"formatVersion"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Scope         string         `json:"scope"`
+	UserEmail     string         `json:"userEmail,omitempty"` // Dono dos dados, em backups de usuário
+	Entities      map[string]int `json:"entities"`            // Quantidade de registros por entidade
+}
+
+// Writer grava um backup em streaming; o manifesto é gravado por último, com as contagens
+type Writer struct {
+	zipWriter *zip.Writer
+	counts    map[string]int
+	current   *EntityWriter
+}
+
+// NewWriter cria um Writer sobre output
+func NewWriter(output io.Writer) *Writer {
+	return &Writer{zipWriter: zip.NewWriter(output), counts: make(map[string]int)}
+}
+
+// EntityWriter grava os registros de uma entidade, um objeto JSON por linha
+type EntityWriter struct {
+	name    string
+	encoder *json.Encoder
+	counts  map[string]int
+}
+
+// Entity inicia o arquivo da entidade; o EntityWriter anterior deixa de ser válido
+func (writer *Writer) Entity(name string) (*EntityWriter, error) {
+	if _, exists := writer.counts[name]; exists {
+		return nil, fmt.Errorf("entidade %s já gravada", name)
+	}
+	file, err := writer.zipWriter.Create(name + ".ndjson")
+	if err != nil {
+		return nil, err
+	}
+	writer.counts[name] = 0
+	writer.current = &EntityWriter{name: name, encoder: json.NewEncoder(file), counts: writer.counts}
+	return writer.current, nil
+}
+
+// Write grava um registro
+func (entity *EntityWriter) Write(record any) error {
+	if err := entity.encoder.Encode(record); err != nil {
+		return err
+	}
+	entity.counts[entity.name]++
+	return nil
+}
+
+// Close grava o manifesto (versão e contagens são preenchidas aqui) e finaliza o zip
+func (writer *Writer) Close(manifest Manifest) error {
+	manifest.FormatVersion = FormatVersion
+	manifest.Entities = writer.counts
+
+	file, err := writer.zipWriter.Create(manifestFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return writer.zipWriter.Close()
+}
+
+// Reader lê um backup. Precisa de acesso aleatório (arquivo em disco ou upload multipart),
+// já que o manifesto fica no fim do zip.
+type Reader struct {
+	Manifest  Manifest
+	zipReader *zip.Reader
+}
+
+// NewReader abre o arquivo e valida o manifesto
+func NewReader(input io.ReaderAt, size int64) (*Reader, error) {
+	zipReader, err := zip.NewReader(input, size)
+	if err != nil {
+		return nil, errors.New("arquivo de backup inválido: " + err.Error())
+	}
+
+	reader := &Reader{zipReader: zipReader}
+	file, err := zipReader.Open(manifestFile)
+	if err != nil {
+		return nil, errors.New("arquivo de backup inválido: manifest.json ausente")
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&reader.Manifest); err != nil {
+		return nil, errors.New("manifest.json inválido: " + err.Error())
+	}
+
+	switch {
+	case reader.Manifest.FormatVersion < 1:
+		return nil, errors.New("manifest.json inválido: versão do formato ausente")
+	case reader.Manifest.FormatVersion > FormatVersion:
+		return nil, fmt.Errorf("backup na versão %d do formato; esta instância lê até a versão %d",
+			reader.Manifest.FormatVersion, FormatVersion)
+	}
+	if reader.Manifest.Scope != ScopeInstance && reader.Manifest.Scope != ScopeUser {
+		return nil, errors.New("manifest.json inválido: escopo desconhecido " + reader.Manifest.Scope)
+	}
+	return reader, nil
+}
+
+// Each decodifica os registros da entidade, um por vez. A função recebe decode, que preenche
+// o registro informado; entidades ausentes no arquivo (versões anteriores) não chamam fn.
+func (reader *Reader) Each(name string, fn func(decode func(record any) error) error) error {
+	file, err := reader.zipReader.Open(name + ".ndjson")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		data := scanner.Bytes()
+		decode := func(record any) error {
+			if err := json.Unmarshal(data, record); err != nil {
+				return fmt.Errorf("%s.ndjson linha %d: %w", name, line, err)
+			}
+			return nil
+		}
+		if err := fn(decode); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,163 @@
+package backup
+
+import "time"
+
+// Entidades do arquivo, na ordem em que são gravadas e restauradas (dependências primeiro)
+const (
+	EntityUsers                = "users"
+	EntityHouseholds           = "households"
+	EntityCategories           = "categories"
+	EntityProducts             = "products"
+	EntityProductBarcodes      = "product_barcodes"
+	EntityProductRedirects     = "product_redirects"
+	EntityStores               = "stores"
+	EntityProductPLUs          = "product_plus"
+	EntityPurchases            = "purchases"
+	EntityPurchaseItems        = "purchase_items"
+	EntityPriceHistory         = "price_history"
+	EntityUserCategoryProducts = "user_category_products"
+	EntityBudgets              = "budgets"
+)
+
+// Entities lista as entidades na ordem de restauração
+var Entities = []string{
+	EntityUsers, EntityHouseholds, EntityCategories, EntityProducts, EntityProductBarcodes, EntityProductRedirects,
+	EntityStores, EntityProductPLUs, EntityPurchases, EntityPurchaseItems, EntityPriceHistory,
+	EntityUserCategoryProducts, EntityBudgets,
+}
+
+// Base são os campos comuns a todos os registros. ID é o da instância de origem.
+type Base struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type UserRecord struct {
+	Base
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"passwordHash"`
+	Role         string `json:"role"`
+	HouseholdID  *uint  `json:"householdId,omitempty"`
+}
+
+type HouseholdRecord struct {
+	Base
+	Name       string `json:"name"`
+	OwnerID    uint   `json:"ownerId"`
+	InviteCode string `json:"inviteCode"`
+}
+
+type CategoryRecord struct {
+	Base
+	UserID   uint   `json:"userId"`
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId,omitempty"`
+	Position int    `json:"position"`
+}
+
+type ProductRecord struct {
+	Base
+	Name         string  `json:"name"`
+	Barcode      *string `json:"barcode,omitempty"`
+	Brand        string  `json:"brand,omitempty"`
+	PackageLabel string  `json:"packageLabel,omitempty"`
+	ImageURL     string  `json:"imageUrl,omitempty"`
+
+	PackageQuantity *float64 `json:"packageQuantity,omitempty"`
+	Unit            string   `json:"unit,omitempty"`
+
+	// Desde a versão 3 do formato; vazio em arquivos antigos (approved)
+	Status         string `json:"status,omitempty"`
+	ProposedByID   *uint  `json:"proposedById,omitempty"`
+	ModerationNote string `json:"moderationNote,omitempty"`
+}
+
+type ProductBarcodeRecord struct {
+	Base
+	ProductID    uint   `json:"productId"`
+	Code         string `json:"code"`
+	UnitsPerPack int    `json:"unitsPerPack"`
+	Description  string `json:"description,omitempty"`
+}
+
+// ProductRedirectRecord leva um produto mesclado (removido, fora de products) ao produto que o
+// absorveu; o nome e o código do produto mesclado permitem recriá-lo no destino
+type ProductRedirectRecord struct {
+	Base
+	FromProductID uint    `json:"fromProductId"`
+	FromName      string  `json:"fromName"`
+	FromBarcode   *string `json:"fromBarcode,omitempty"`
+	ProductID     uint    `json:"productId"`
+}
+
+type ProductPLURecord struct {
+	Base
+	ProductID uint   `json:"productId"`
+	StoreID   *uint  `json:"storeId,omitempty"` // Sem estabelecimento, vale para todos
+	PLU       string `json:"plu"`
+}
+
+type StoreRecord struct {
+	Base
+	OwnerID    *uint    `json:"ownerId,omitempty"` // Desde a versão 3; nil = compartilhado
+	Name       string   `json:"name"`
+	Chain      string   `json:"chain,omitempty"` // Nome da rede
+	BranchName string   `json:"branchName,omitempty"`
+	Address    string   `json:"address,omitempty"`
+	CNPJ       *string  `json:"cnpj,omitempty"`
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+}
+
+type PurchaseRecord struct {
+	Base
+	UserID           uint      `json:"userId"`
+	PurchaseDate     time.Time `json:"purchaseDate"`
+	PurchaseLocation string    `json:"purchaseLocation"`
+	StoreID          *uint     `json:"storeId,omitempty"` // Desde a versão 2 do formato
+	InvoiceKey       *string   `json:"invoiceKey,omitempty"`
+	Total            float64   `json:"total"`
+}
+
+type PurchaseItemRecord struct {
+	Base
+	PurchaseID uint    `json:"purchaseId"`
+	ProductID  uint    `json:"productId"`
+	Quantity   float64 `json:"quantity"`
+	Unit       string  `json:"unit,omitempty"` // Vazio em arquivos antigos (un)
+	UnitPrice  float64 `json:"unitPrice"`
+	TotalPrice float64 `json:"totalPrice"`
+}
+
+type PriceHistoryRecord struct {
+	Base
+	UserID        uint      `json:"userId"`
+	ProductID     uint      `json:"productId"`
+	PurchaseDate  time.Time `json:"purchaseDate"`
+	PurchasePlace string    `json:"purchasePlace"`
+	StoreID       *uint     `json:"storeId,omitempty"` // Desde a versão 2 do formato
+	PricePaid     float64   `json:"pricePaid"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit,omitempty"` // Vazio em arquivos antigos (un)
+}
+
+type UserCategoryProductRecord struct {
+	Base
+	UserID     uint `json:"userId"`
+	CategoryID uint `json:"categoryId"`
+	ProductID  uint `json:"productId"`
+}
+
+type BudgetRecord struct {
+	Base
+	UserID            uint    `json:"userId"`
+	CategoryID        *uint   `json:"categoryId,omitempty"`
+	MonthlyLimit      float64 `json:"monthlyLimit"`
+	WarningThreshold  float64 `json:"warningThreshold"`
+	RolloverEnabled   bool    `json:"rolloverEnabled"`
+	RolloverMaxMonths int     `json:"rolloverMaxMonths"`
+	LastAlertPeriod   string  `json:"lastAlertPeriod,omitempty"`
+	LastAlertLevel    string  `json:"lastAlertLevel,omitempty"`
+}
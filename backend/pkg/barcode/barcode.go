@@ -0,0 +1,198 @@
+// Package barcode interpreta códigos de barras GTIN (EAN-8, UPC-A, EAN-13 e GTIN-14): valida o
+// dígito verificador, normaliza para GTIN-14 e decodifica as etiquetas de peso variável das balanças
+// (EAN-13 começando com 2), que carregam o código interno do produto (PLU) e o preço ou o peso.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Erros de validação de um código GTIN
+var (
+	ErrInvalidLength     = errors.New("código de barras deve ter 8, 12, 13 ou 14 dígitos")
+	ErrInvalidCharacters = errors.New("código de barras deve conter apenas dígitos")
+	ErrInvalidCheckDigit = errors.New("dígito verificador do código de barras inválido")
+)
+
+// GTIN14Length é o tamanho da forma normalizada dos códigos
+const GTIN14Length = 14
+
+// CheckDigit calcula o dígito verificador GS1 de um código sem o dígito (pesos 3 e 1 a partir da direita)
+func CheckDigit(body string) int {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		digit := int(body[len(body)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
+
+// Validate confere o tamanho, os caracteres e o dígito verificador de um GTIN-8, 12, 13 ou 14
+func Validate(code string) error {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return ErrInvalidLength
+	}
+	if strings.Trim(code, "0123456789") != "" {
+		return ErrInvalidCharacters
+	}
+	if CheckDigit(code[:len(code)-1]) != int(code[len(code)-1]-'0') {
+		return ErrInvalidCheckDigit
+	}
+	return nil
+}
+
+// Normalize valida o código e o completa com zeros à esquerda até 14 dígitos, de modo que o mesmo
+// produto lido como UPC-A, EAN-13 ou GTIN-14 tenha uma única representação
+func Normalize(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if err := Validate(code); err != nil {
+		return "", err
+	}
+	return strings.Repeat("0", GTIN14Length-len(code)) + code, nil
+}
+
+// IsVariableMeasure indica se o código (em qualquer forma) é um EAN-13 de circulação restrita com
+// prefixo 2, usado pelas balanças para etiquetas de peso variável
+func IsVariableMeasure(code string) bool {
+	normalized, err := Normalize(code)
+	return err == nil && normalized[0] == '0' && normalized[1] == '2'
+}
+
+// MeasureKind indica o que a etiqueta de peso variável carrega além do PLU
+type MeasureKind string
+
+const (
+	MeasurePrice  MeasureKind = "price"  // Preço total em centavos
+	MeasureWeight MeasureKind = "weight" // Peso em gramas
+)
+
+// DefaultLayout é o leiaute mais comum das balanças: prefixo 2, PLU de 5 dígitos, preço total de
+// 6 dígitos (centavos) e dígito verificador
+const DefaultLayout = "2PPPPPVVVVVVC"
+
+// Layout descreve as posições de uma etiqueta de peso variável de 13 dígitos, escrito como um padrão:
+// dígitos são literais do prefixo, P é o PLU, V o preço total (centavos), W o peso (gramas),
+// X um dígito ignorado (como o verificador interno do preço) e C o dígito verificador final.
+type Layout struct {
+	Pattern     string
+	Prefix      string
+	PLUStart    int
+	PLULength   int
+	Kind        MeasureKind
+	ValueStart  int
+	ValueLength int
+}
+
+// ParseLayout valida o padrão de uma etiqueta (ex.: "2PPPPPVVVVVVC" ou "2PPPPXWWWWWXC")
+func ParseLayout(pattern string) (Layout, error) {
+	pattern = strings.ToUpper(strings.TrimSpace(pattern))
+	layout := Layout{Pattern: pattern, PLUStart: -1, ValueStart: -1}
+	if len(pattern) != 13 {
+		return Layout{}, errors.New("leiaute de etiqueta deve ter 13 posições")
+	}
+	if pattern[0] != '2' {
+		return Layout{}, errors.New("leiaute de etiqueta deve começar com o prefixo 2")
+	}
+	if pattern[12] != 'C' {
+		return Layout{}, errors.New("leiaute de etiqueta deve terminar com o dígito verificador (C)")
+	}
+
+	prefixDone := false
+	for i := 0; i < 12; i++ {
+		char := pattern[i]
+		switch {
+		case char >= '0' && char <= '9':
+			if prefixDone {
+				return Layout{}, fmt.Errorf("dígito literal fora do prefixo na posição %d", i+1)
+			}
+			layout.Prefix += string(char)
+			continue
+		case char == 'P':
+			if err := extend(&layout.PLUStart, &layout.PLULength, i, "PLU"); err != nil {
+				return Layout{}, err
+			}
+		case char == 'V' || char == 'W':
+			kind := MeasurePrice
+			if char == 'W' {
+				kind = MeasureWeight
+			}
+			if layout.Kind != "" && layout.Kind != kind {
+				return Layout{}, errors.New("leiaute de etiqueta deve ter preço (V) ou peso (W), não ambos")
+			}
+			layout.Kind = kind
+			if err := extend(&layout.ValueStart, &layout.ValueLength, i, "valor"); err != nil {
+				return Layout{}, err
+			}
+		case char == 'X':
+		default:
+			return Layout{}, fmt.Errorf("caractere %q inválido no leiaute de etiqueta", char)
+		}
+		prefixDone = true
+	}
+
+	if layout.PLULength == 0 {
+		return Layout{}, errors.New("leiaute de etiqueta sem PLU (P)")
+	}
+	if layout.ValueLength == 0 {
+		return Layout{}, errors.New("leiaute de etiqueta sem preço (V) ou peso (W)")
+	}
+	return layout, nil
+}
+
+// extend acrescenta a posição i a um campo contíguo do leiaute
+func extend(start *int, length *int, i int, field string) error {
+	if *start < 0 {
+		*start = i
+	} else if *start+*length != i {
+		return fmt.Errorf("%s deve ocupar posições contíguas no leiaute de etiqueta", field)
+	}
+	*length++
+	return nil
+}
+
+// VariableMeasure é o conteúdo de uma etiqueta de peso variável
+type VariableMeasure struct {
+	PLU    string   // Código interno do produto, sem zeros à esquerda
+	Price  *float64 // Preço total, quando o leiaute traz o preço
+	Weight *float64 // Peso em kg, quando o leiaute traz o peso
+}
+
+// Decode extrai o PLU e o preço ou peso de uma etiqueta de peso variável; false quando o código não é
+// um EAN-13 válido com o prefixo do leiaute
+func (layout Layout) Decode(code string) (*VariableMeasure, bool) {
+	normalized, err := Normalize(code)
+	if err != nil || normalized[0] != '0' {
+		return nil, false
+	}
+	ean := normalized[1:]
+	if !strings.HasPrefix(ean, layout.Prefix) {
+		return nil, false
+	}
+
+	plu := strings.TrimLeft(ean[layout.PLUStart:layout.PLUStart+layout.PLULength], "0")
+	if plu == "" {
+		plu = "0"
+	}
+	value, err := strconv.Atoi(ean[layout.ValueStart : layout.ValueStart+layout.ValueLength])
+	if err != nil {
+		return nil, false
+	}
+
+	measure := &VariableMeasure{PLU: plu}
+	if layout.Kind == MeasureWeight {
+		weight := float64(value) / 1000
+		measure.Weight = &weight
+	} else {
+		price := float64(value) / 100
+		measure.Price = &price
+	}
+	return measure, true
+}
@@ -14,12 +14,43 @@ type Config struct {
     DBName             string
     JWTSecret          string
     JWTExpirationHours int
+
+    // Número mínimo de usuários distintos para expor agregados de preço da comunidade (k-anonimato)
+    CommunityMinContributors int
+
+    // Consulta de NFC-e pelo QR Code: "http" consulta a SEFAZ, "fixture" lê documentos de NFCeFixtureDir
+    NFCeFetcher             string
+    NFCeFixtureDir          string
+    NFCeFetchTimeoutSeconds int
+
+    // Diretório de onde os dumps de catálogo (Open Food Facts) podem ser importados pela API
+    CatalogImportDir string
+
+    // Webhooks: intervalo do dispatcher, timeout de cada entrega e número máximo de tentativas
+    WebhookDispatchIntervalSeconds int
+    WebhookTimeoutSeconds          int
+    WebhookMaxAttempts             int
+
+    // Leiaute padrão das etiquetas de peso variável das balanças (EAN-13 com prefixo 2)
+    ScaleBarcodeLayout string
+
+    // Fuso horário das lojas (IANA), usado para ler a data e a hora impressas nos cupons
+    TimeZone string
 }
 
 // Load carrega as variáveis de ambiente
 func Load() *Config {
     viper.SetConfigFile(".env")
     viper.AutomaticEnv()
+    viper.SetDefault("COMMUNITY_MIN_CONTRIBUTORS", 5)
+    viper.SetDefault("NFCE_FETCHER", "http")
+    viper.SetDefault("NFCE_FETCH_TIMEOUT_SECONDS", 15)
+    viper.SetDefault("CATALOG_IMPORT_DIR", "data/imports")
+    viper.SetDefault("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 5)
+    viper.SetDefault("WEBHOOK_TIMEOUT_SECONDS", 10)
+    viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+    viper.SetDefault("SCALE_BARCODE_LAYOUT", "2PPPPPVVVVVVC")
+    viper.SetDefault("TIME_ZONE", "America/Sao_Paulo")
 
     if err := viper.ReadInConfig(); err != nil {
         panic("Erro ao ler o arquivo .env: " + err.Error())
@@ -34,5 +65,21 @@ func Load() *Config {
         DBName:             viper.GetString("DB_NAME"),
         JWTSecret:          viper.GetString("JWT_SECRET"),
         JWTExpirationHours: viper.GetInt("JWT_EXPIRATION_HOURS"),
+
+        CommunityMinContributors: viper.GetInt("COMMUNITY_MIN_CONTRIBUTORS"),
+
+        NFCeFetcher:             viper.GetString("NFCE_FETCHER"),
+        NFCeFixtureDir:          viper.GetString("NFCE_FIXTURE_DIR"),
+        NFCeFetchTimeoutSeconds: viper.GetInt("NFCE_FETCH_TIMEOUT_SECONDS"),
+
+        CatalogImportDir: viper.GetString("CATALOG_IMPORT_DIR"),
+
+        WebhookDispatchIntervalSeconds: viper.GetInt("WEBHOOK_DISPATCH_INTERVAL_SECONDS"),
+        WebhookTimeoutSeconds:          viper.GetInt("WEBHOOK_TIMEOUT_SECONDS"),
+        WebhookMaxAttempts:             viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+
+        ScaleBarcodeLayout: viper.GetString("SCALE_BARCODE_LAYOUT"),
+
+        TimeZone: viper.GetString("TIME_ZONE"),
     }
 }
@@ -0,0 +1,79 @@
+// Package dataloader agrupa buscas por chave e guarda o resultado durante uma requisição, para que
+// a mesma entidade pedida em vários pontos de uma resposta seja carregada uma única vez.
+package dataloader
+
+import "sync"
+
+// FetchFunc busca de uma vez os valores das chaves informadas; chaves inexistentes ficam fora do map
+type FetchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader guarda em cache os valores já buscados. Deve ser criado por requisição.
+type Loader[K comparable, V any] struct {
+	fetch   FetchFunc[K, V]
+	mutex   sync.Mutex
+	cache   map[K]V
+	missing map[K]bool
+}
+
+// New creates a new Loader
+func New[K comparable, V any](fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, cache: make(map[K]V), missing: make(map[K]bool)}
+}
+
+// LoadMany retorna os valores das chaves, buscando numa única chamada apenas as que ainda não estão no cache
+func (loader *Loader[K, V]) LoadMany(keys []K) (map[K]V, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	var pending []K
+	seen := make(map[K]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, cached := loader.cache[key]; !cached && !loader.missing[key] {
+			pending = append(pending, key)
+		}
+	}
+
+	if len(pending) > 0 {
+		fetched, err := loader.fetch(pending)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range pending {
+			if value, found := fetched[key]; found {
+				loader.cache[key] = value
+			} else {
+				loader.missing[key] = true
+			}
+		}
+	}
+
+	values := make(map[K]V, len(seen))
+	for key := range seen {
+		if value, found := loader.cache[key]; found {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// Load retorna o valor de uma chave (found = false quando ela não existe)
+func (loader *Loader[K, V]) Load(key K) (value V, found bool, err error) {
+	values, err := loader.LoadMany([]K{key})
+	if err != nil {
+		return value, false, err
+	}
+	value, found = values[key]
+	return value, found, nil
+}
+
+// Prime adiciona ao cache um valor já carregado por outro caminho
+func (loader *Loader[K, V]) Prime(key K, value V) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+	loader.cache[key] = value
+	delete(loader.missing, key)
+}
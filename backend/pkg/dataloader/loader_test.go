@@ -0,0 +1,65 @@
+package dataloader
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	var calls [][]int
+	loader := New(func(keys []int) (map[int]string, error) {
+		sorted := append([]int(nil), keys...)
+		sort.Ints(sorted)
+		calls = append(calls, sorted)
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key != 404 {
+				values[key] = "item" + string(rune('0'+key))
+			}
+		}
+		return values, nil
+	})
+
+	values, err := loader.LoadMany([]int{1, 2, 2, 404})
+	if err != nil {
+		t.Fatalf("LoadMany: %v", err)
+	}
+	if want := map[int]string{1: "item1", 2: "item2"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("LoadMany = %v, esperado %v", values, want)
+	}
+
+	// Chaves já buscadas, inclusive as inexistentes, não voltam ao fetch
+	if _, found, _ := loader.Load(404); found {
+		t.Fatalf("Load(404) encontrado, esperado ausente")
+	}
+	if value, found, _ := loader.Load(3); !found || value != "item3" {
+		t.Fatalf("Load(3) = %q, %v", value, found)
+	}
+	loader.Prime(5, "primed")
+	if value, _, _ := loader.Load(5); value != "primed" {
+		t.Fatalf("Load(5) = %q, esperado o valor do Prime", value)
+	}
+
+	if want := [][]int{{1, 2, 404}, {3}}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("chamadas do fetch = %v, esperado %v", calls, want)
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	fetchErr := errors.New("falha no banco")
+	attempts := 0
+	loader := New(func(keys []int) (map[int]string, error) {
+		attempts++
+		return nil, fetchErr
+	})
+
+	if _, err := loader.LoadMany([]int{1}); !errors.Is(err, fetchErr) {
+		t.Fatalf("LoadMany err = %v, esperado %v", err, fetchErr)
+	}
+	// Um erro não é guardado em cache: a próxima chamada tenta de novo
+	if _, _, err := loader.Load(1); !errors.Is(err, fetchErr) || attempts != 2 {
+		t.Fatalf("Load err = %v, tentativas = %d", err, attempts)
+	}
+}
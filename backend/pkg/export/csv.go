@@ -0,0 +1,35 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter grava as linhas como CSV com cabeçalho
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(output io.Writer, columns []string) (*csvWriter, error) {
+	writer := csv.NewWriter(output)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) WriteRow(row Row) error {
+	for i := range w.record {
+		w.record[i] = ""
+		if i < len(row) {
+			w.record[i] = formatCell(row[i])
+		}
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
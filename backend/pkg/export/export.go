@@ -0,0 +1,139 @@
+// Package export grava linhas tabulares em CSV, XLSX ou JSON delimitado por linha (NDJSON)
+// de forma incremental, sem manter o conjunto de resultados em memória.
+package export
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format identifica o formato de saída de uma exportação
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+// Row é uma linha da exportação. Os valores aceitos são string, números, bool, time.Time,
+// os ponteiros *string, *uint e *time.Time, e nil; a ordem segue as colunas informadas ao criar o RowWriter.
+type Row []any
+
+// RowWriter grava linhas em um formato de exportação. Close finaliza o arquivo
+// (rodapé do XLSX, flush do CSV) e deve ser chamado mesmo quando nenhuma linha foi gravada.
+type RowWriter interface {
+	WriteRow(row Row) error
+	Close() error
+}
+
+// ParseFormat valida o formato informado; vazio equivale a CSV
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX, FormatNDJSON:
+		return Format(value), nil
+	}
+	return "", errors.New("formato de exportação inválido: use csv, xlsx ou ndjson")
+}
+
+// ContentType retorna o MIME type do formato
+func (format Format) ContentType() string {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileExtension retorna a extensão de arquivo do formato
+func (format Format) FileExtension() string {
+	return string(format)
+}
+
+// NewRowWriter cria o RowWriter do formato informado, gravando em output
+func NewRowWriter(format Format, output io.Writer, columns []string, sheetName string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(output, columns)
+	case FormatXLSX:
+		return newXLSXWriter(output, columns, sheetName)
+	case FormatNDJSON:
+		return newNDJSONWriter(output, columns), nil
+	}
+	return nil, errors.New("formato de exportação não suportado: " + string(format))
+}
+
+// formatText converte um valor da linha em texto (CSV e células de texto do XLSX)
+func formatText(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case *string:
+		if typed == nil {
+			return ""
+		}
+		return *typed
+	case time.Time:
+		return typed.Format(time.RFC3339)
+	case *time.Time:
+		if typed == nil {
+			return ""
+		}
+		return typed.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32)
+	case int:
+		return strconv.Itoa(typed)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case uint:
+		return strconv.FormatUint(uint64(typed), 10)
+	case uint64:
+		return strconv.FormatUint(typed, 10)
+	case *uint:
+		if typed == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*typed), 10)
+	case bool:
+		return strconv.FormatBool(typed)
+	}
+	return ""
+}
+
+// formatCell converte um valor da linha no texto de uma célula de planilha (CSV e XLSX). Textos que
+// começam por =, +, -, @ (ou tabulação e CR) recebem um apóstrofo na frente, para que a planilha não
+// os interprete como fórmula (CSV/formula injection); números, datas e bool não são alterados.
+func formatCell(value any) string {
+	text := formatText(value)
+	switch value.(type) {
+	case string, *string:
+		if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+			return "'" + text
+		}
+	}
+	return text
+}
+
+// isNumeric indica se o valor deve ser gravado como número (células numéricas do XLSX)
+func isNumeric(value any) bool {
+	switch typed := value.(type) {
+	case float64, float32, int, int64, uint, uint64:
+		return true
+	case *uint:
+		return typed != nil
+	}
+	return false
+}
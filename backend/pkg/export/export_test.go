@@ -0,0 +1,128 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRows grava as linhas no formato informado e retorna o arquivo gerado
+func writeRows(t *testing.T, format Format, columns []string, rows ...Row) []byte {
+	t.Helper()
+	var output bytes.Buffer
+	writer, err := NewRowWriter(format, &output, columns, "Compras")
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return output.Bytes()
+}
+
+func TestCSVQuoting(t *testing.T) {
+	name := "Arroz \"tipo 1\""
+	output := writeRows(t, FormatCSV, []string{"produto", "local", "quantidade", "data"},
+		Row{&name, "Mercado A, Centro\nLoja 2", 1.5, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)},
+		Row{nil, "", -2, nil},
+	)
+
+	want := "produto,local,quantidade,data\n" +
+		"\"Arroz \"\"tipo 1\"\"\",\"Mercado A, Centro\nLoja 2\",1.5,2025-03-01T10:00:00Z\n" +
+		",,-2,\n"
+	if string(output) != want {
+		t.Errorf("CSV =\n%s\nesperado\n%s", output, want)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv.ReadAll: %v", err)
+	}
+	if records[1][0] != name || records[1][1] != "Mercado A, Centro\nLoja 2" {
+		t.Errorf("linha relida = %q", records[1])
+	}
+}
+
+func TestFormulaEscaping(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"=HYPERLINK(\"http://exemplo.com\")", "'=HYPERLINK(\"http://exemplo.com\")"},
+		{"+5511999999999", "'+5511999999999"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"Arroz", "Arroz"},
+		{"", ""},
+		{-2.5, "-2.5"}, // Números não são texto e não são escapados
+	}
+	for _, test := range tests {
+		if got := formatCell(test.value); got != test.want {
+			t.Errorf("formatCell(%q) = %q, esperado %q", test.value, got, test.want)
+		}
+	}
+
+	output := writeRows(t, FormatCSV, []string{"produto"}, Row{"=1+1"})
+	if !strings.Contains(string(output), "'=1+1") {
+		t.Errorf("CSV sem o escape da fórmula:\n%s", output)
+	}
+}
+
+// TestXLSXStrings confere que os textos vão como células de texto (inlineStr) escapadas para XML,
+// com o escape de fórmulas, e os números como células numéricas
+func TestXLSXStrings(t *testing.T) {
+	output := writeRows(t, FormatXLSX, []string{"produto", "preço"},
+		Row{"Feijão <preto> & cia", 7.99},
+		Row{"=cmd|' /C calc'!A0", nil},
+	)
+
+	archive, err := zip.NewReader(bytes.NewReader(output), int64(len(output)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		entry, err := file.Open()
+		if err != nil {
+			t.Fatalf("abrir %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(entry)
+		entry.Close()
+		if err != nil {
+			t.Fatalf("ler %s: %v", file.Name, err)
+		}
+		files[file.Name] = string(content)
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Compras"`) {
+		t.Errorf("workbook sem o nome da planilha:\n%s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">produto</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Feijão &lt;preto&gt; &amp; cia</t></is></c><c r="B2"><v>7.99</v></c>`,
+		`<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">&#39;=cmd|&#39; /C calc&#39;!A0</t></is></c></row>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("planilha sem %s:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, esperado %q", index, got, want)
+		}
+	}
+}
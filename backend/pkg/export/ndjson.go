@@ -0,0 +1,52 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonWriter grava cada linha como um objeto JSON (coluna -> valor) seguido de quebra de linha.
+// O objeto é montado manualmente para preservar a ordem das colunas.
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	columns [][]byte // Nomes das colunas já codificados em JSON
+}
+
+func newNDJSONWriter(output io.Writer, columns []string) *ndjsonWriter {
+	encoded := make([][]byte, len(columns))
+	for i, column := range columns {
+		encoded[i], _ = json.Marshal(column)
+	}
+	return &ndjsonWriter{buffer: bufio.NewWriter(output), columns: encoded}
+}
+
+func (w *ndjsonWriter) WriteRow(row Row) error {
+	w.buffer.WriteByte('{')
+	for i, column := range w.columns {
+		var value any
+		if i < len(row) {
+			value = row[i]
+		}
+		if date, ok := value.(time.Time); ok {
+			value = date.Format(time.RFC3339)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			w.buffer.WriteByte(',')
+		}
+		w.buffer.Write(column)
+		w.buffer.WriteByte(':')
+		w.buffer.Write(encoded)
+	}
+	w.buffer.WriteString("}\n")
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.buffer.Flush()
+}
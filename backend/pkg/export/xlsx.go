@@ -0,0 +1,138 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Partes fixas de um pacote XLSX com uma única planilha
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+	xlsxWorkbookFooter = `</sheets></workbook>`
+	xlsxSheetHeader    = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+// xlsxWriter grava uma planilha XLSX mínima. As partes fixas são gravadas primeiro e a planilha
+// é a última entrada do zip, escrita linha a linha (células de texto inline, sem sharedStrings).
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	columns int
+	rowNum  int
+}
+
+func newXLSXWriter(output io.Writer, columns []string, sheetName string) (*xlsxWriter, error) {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	archive := zip.NewWriter(output)
+	workbook := xlsxWorkbookHeader + `<sheet name="` + string(appendEscaped(nil, sheetName)) + `" sheetId="1" r:id="rId1"/>` + xlsxWorkbookFooter
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(entry), columns: len(columns)}
+	writer.sheet.WriteString(xlsxSheetHeader)
+
+	header := make(Row, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *xlsxWriter) WriteRow(row Row) error {
+	w.rowNum++
+	rowRef := strconv.Itoa(w.rowNum)
+
+	line := make([]byte, 0, 64*w.columns)
+	line = append(line, `<row r="`+rowRef+`">`...)
+	for i := 0; i < w.columns && i < len(row); i++ {
+		value := row[i]
+		if value == nil {
+			continue
+		}
+		cellRef := columnName(i) + rowRef
+		if isNumeric(value) {
+			line = append(line, `<c r="`+cellRef+`"><v>`+formatText(value)+`</v></c>`...)
+			continue
+		}
+		line = append(line, `<c r="`+cellRef+`" t="inlineStr"><is><t xml:space="preserve">`...)
+		line = appendEscaped(line, formatCell(value))
+		line = append(line, `</t></is></c>`...)
+	}
+	line = append(line, `</row>`...)
+
+	_, err := w.sheet.Write(line)
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := w.sheet.WriteString(xlsxSheetFooter); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// columnName converte o índice (0-based) na letra da coluna da planilha: 0 -> A, 26 -> AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// appendEscaped acrescenta o texto escapado para XML
+func appendEscaped(buffer []byte, text string) []byte {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(text))
+	return append(buffer, escaped.Bytes()...)
+}
@@ -0,0 +1,729 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Response é o corpo da resposta GraphQL
+type Response struct {
+	Data   any      `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error é um erro de validação ou de um campo específico (Path aponta o campo na resposta)
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Execute valida e executa a operação. Erros de sintaxe ou validação retornam sem data; erros de
+// resolvers deixam o campo nulo e são listados em Errors.
+func (schema *Schema) Execute(ctx context.Context, query string, operationName string, variables map[string]any) *Response {
+	document, err := Parse(query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	operation, err := selectOperation(document, operationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	if operation.Type != "query" {
+		return &Response{Errors: []*Error{{Message: "apenas consultas (query) são suportadas"}}}
+	}
+
+	execution := &execution{schema: schema, document: document, fragments: make(map[string]*fragmentInfo)}
+	height, cost := execution.validate(schema.query, operation.SelectionSet)
+	if len(execution.errors) == 0 && schema.MaxDepth > 0 && height > schema.MaxDepth {
+		execution.addError(nil, fmt.Sprintf("a consulta excede a profundidade máxima de %d níveis", schema.MaxDepth))
+	}
+	if len(execution.errors) == 0 && schema.MaxComplexity > 0 && cost > schema.MaxComplexity {
+		execution.addError(nil, fmt.Sprintf("a consulta excede o limite de %d campos", schema.MaxComplexity))
+	}
+	if len(execution.errors) > 0 {
+		return &Response{Errors: execution.errors}
+	}
+	if execution.variables, err = coerceVariables(operation, variables); err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	data := execution.executeSelections(ctx, schema.query, []any{nil}, [][]any{{}}, operation.SelectionSet)[0]
+	return &Response{Data: data, Errors: execution.errors}
+}
+
+func selectOperation(document *Document, operationName string) (*Operation, error) {
+	if operationName == "" {
+		if len(document.Operations) > 1 {
+			return nil, errors.New("o documento tem várias operações: informe operationName")
+		}
+		return document.Operations[0], nil
+	}
+	for _, operation := range document.Operations {
+		if operation.Name == operationName {
+			return operation, nil
+		}
+	}
+	return nil, errors.New("operação não encontrada: " + operationName)
+}
+
+type execution struct {
+	schema    *Schema
+	document  *Document
+	fragments map[string]*fragmentInfo
+	variables map[string]any
+	errors    []*Error
+}
+
+// nullResult marca um valor que virou nulo por erro do resolver (o erro já foi registrado)
+type nullResult struct{}
+
+func (execution *execution) addError(path []any, message string) {
+	execution.errors = append(execution.errors, &Error{Message: message, Path: path})
+}
+
+// validate confere campos, argumentos, seleções e fragmentos e mede a seleção: height conta os níveis de
+// campos e de fragmentos nomeados aninhados; cost conta os campos com os fragmentos já expandidos.
+// Os erros são acumulados em execution.errors.
+func (execution *execution) validate(object *Object, selectionSet []Selection) (height int, cost int) {
+	fail := func(format string, args ...any) {
+		execution.addError(nil, fmt.Sprintf(format, args...))
+	}
+
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *Field:
+			cost = addCost(cost, 1)
+			height = max(height, 1)
+			if selection.Name == "__typename" {
+				continue
+			}
+			field, ok := object.fields[selection.Name]
+			if !ok {
+				fail("o campo %s não existe no tipo %s", selection.Name, object.Name)
+				continue
+			}
+			for _, argument := range selection.Arguments {
+				if !hasArgument(field, argument.Name) {
+					fail("o campo %s.%s não aceita o argumento %s", object.Name, field.Name, argument.Name)
+				}
+			}
+			for _, argument := range field.Args {
+				if argument.typeRef.NonNull && argument.Default == nil && findArgument(selection.Arguments, argument.Name) == nil {
+					fail("o argumento %s de %s.%s é obrigatório", argument.Name, object.Name, field.Name)
+				}
+			}
+
+			child, isObject := execution.schema.objects[field.typeRef.NamedType()]
+			switch {
+			case isObject && selection.SelectionSet == nil:
+				fail("o campo %s.%s é do tipo %s e precisa de uma seleção de subcampos", object.Name, field.Name, field.Type)
+			case !isObject && selection.SelectionSet != nil:
+				fail("o campo %s.%s é escalar e não aceita seleção de subcampos", object.Name, field.Name)
+			case isObject:
+				childHeight, childCost := execution.validate(child, selection.SelectionSet)
+				height = max(height, childHeight+1)
+				cost = addCost(cost, childCost)
+			}
+		case *FragmentSpread:
+			// Cada uso conta como um nível: cadeias de fragmentos ficam limitadas por MaxDepth
+			if info := execution.validateFragment(selection.Name); info != nil {
+				height = max(height, info.height+1)
+				cost = addCost(cost, info.cost)
+			}
+		case *InlineFragment:
+			target := object
+			if selection.TypeCondition != "" {
+				var ok bool
+				if target, ok = execution.schema.objects[selection.TypeCondition]; !ok {
+					fail("tipo desconhecido: %s", selection.TypeCondition)
+					continue
+				}
+			}
+			fragmentHeight, fragmentCost := execution.validate(target, selection.SelectionSet)
+			height = max(height, fragmentHeight)
+			cost = addCost(cost, fragmentCost)
+		}
+	}
+	return height, cost
+}
+
+// fragmentInfo guarda a validação de um fragmento nomeado, feita uma única vez por consulta
+type fragmentInfo struct {
+	validating bool // Em validação: uma nova referência ao fragmento forma um ciclo
+	height     int
+	cost       int
+}
+
+// validateFragment valida o fragmento na primeira vez que ele é usado e reaproveita a medida nas demais,
+// de modo que fragmentos usados várias vezes não multiplicam o trabalho da validação. Retorna nil para
+// fragmentos inválidos (o erro é registrado uma vez).
+func (execution *execution) validateFragment(name string) *fragmentInfo {
+	if info, seen := execution.fragments[name]; seen {
+		if info != nil && info.validating {
+			execution.addError(nil, fmt.Sprintf("o fragmento %s referencia a si mesmo", name))
+			return nil
+		}
+		return info
+	}
+	execution.fragments[name] = nil
+
+	fragment, ok := execution.document.Fragments[name]
+	if !ok {
+		execution.addError(nil, "fragmento desconhecido: "+name)
+		return nil
+	}
+	target, ok := execution.schema.objects[fragment.TypeCondition]
+	if !ok {
+		execution.addError(nil, fmt.Sprintf("tipo desconhecido no fragmento %s: %s", fragment.Name, fragment.TypeCondition))
+		return nil
+	}
+
+	info := &fragmentInfo{validating: true}
+	execution.fragments[name] = info
+	info.height, info.cost = execution.validate(target, fragment.SelectionSet)
+	info.validating = false
+	return info
+}
+
+// maxCost é o teto da soma dos custos, que cresce exponencialmente com fragmentos reutilizados
+const maxCost = math.MaxInt32
+
+func addCost(a, b int) int {
+	if a > maxCost-b {
+		return maxCost
+	}
+	return a + b
+}
+
+func hasArgument(field *FieldDef, name string) bool {
+	for _, argument := range field.Args {
+		if argument.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findArgument(arguments []*Argument, name string) *Argument {
+	for _, argument := range arguments {
+		if argument.Name == name {
+			return argument
+		}
+	}
+	return nil
+}
+
+// collectedField agrupa as ocorrências de um mesmo campo na resposta (mesmo nome ou alias)
+type collectedField struct {
+	key    string
+	fields []*Field
+}
+
+func (execution *execution) collectFields(object *Object, selectionSet []Selection, collected []*collectedField) []*collectedField {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *Field:
+			if !execution.included(selection.Directives) {
+				continue
+			}
+			key := selection.ResponseKey()
+			merged := false
+			for _, existing := range collected {
+				if existing.key == key {
+					existing.fields = append(existing.fields, selection)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				collected = append(collected, &collectedField{key: key, fields: []*Field{selection}})
+			}
+		case *FragmentSpread:
+			fragment := execution.document.Fragments[selection.Name]
+			if execution.included(selection.Directives) && fragment.TypeCondition == object.Name {
+				collected = execution.collectFields(object, fragment.SelectionSet, collected)
+			}
+		case *InlineFragment:
+			if execution.included(selection.Directives) && (selection.TypeCondition == "" || selection.TypeCondition == object.Name) {
+				collected = execution.collectFields(object, selection.SelectionSet, collected)
+			}
+		}
+	}
+	return collected
+}
+
+// included avalia @include(if:) e @skip(if:)
+func (execution *execution) included(directives []*Directive) bool {
+	for _, directive := range directives {
+		argument := findArgument(directive.Arguments, "if")
+		if argument == nil {
+			continue
+		}
+		value, _ := coerceInput(execution.resolveVariables(argument.Value), &TypeRef{Name: Boolean})
+		condition, _ := value.(bool)
+		if (directive.Name == "include" && !condition) || (directive.Name == "skip" && condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// executeSelections executa a seleção para todos os objetos do nível de uma só vez
+func (execution *execution) executeSelections(
+	ctx context.Context, object *Object, sources []any, paths [][]any, selectionSet []Selection) []*orderedMap {
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = &orderedMap{values: make(map[string]any)}
+	}
+
+	for _, collected := range execution.collectFields(object, selectionSet, nil) {
+		first := collected.fields[0]
+		if first.Name == "__typename" {
+			for _, result := range results {
+				result.set(collected.key, object.Name)
+			}
+			continue
+		}
+
+		field := object.fields[first.Name]
+		fieldPaths := make([][]any, len(paths))
+		for i, path := range paths {
+			fieldPaths[i] = appendPath(path, collected.key)
+		}
+
+		var values []any
+		args, err := execution.coerceArguments(field, first.Arguments)
+		if err != nil {
+			execution.addError(fieldPaths[0], err.Error())
+			values = make([]any, len(sources))
+			for i := range values {
+				values[i] = nullResult{}
+			}
+		} else {
+			values = execution.resolve(ctx, field, sources, args, fieldPaths)
+		}
+
+		var subSelection []Selection
+		for _, occurrence := range collected.fields {
+			subSelection = append(subSelection, occurrence.SelectionSet...)
+		}
+		completed := execution.complete(ctx, field.typeRef, values, fieldPaths, subSelection)
+		for i, result := range results {
+			result.set(collected.key, completed[i])
+		}
+	}
+	return results
+}
+
+func (execution *execution) resolve(ctx context.Context, field *FieldDef, sources []any, args map[string]any, paths [][]any) []any {
+	values := make([]any, len(sources))
+	switch {
+	case field.BatchResolve != nil:
+		resolved, err := field.BatchResolve(ctx, sources, args)
+		if err == nil && len(resolved) != len(sources) {
+			err = fmt.Errorf("resolver de %s retornou %d valores para %d objetos", field.Name, len(resolved), len(sources))
+		}
+		if err != nil {
+			execution.addError(paths[0], err.Error())
+			for i := range values {
+				values[i] = nullResult{}
+			}
+			return values
+		}
+		copy(values, resolved)
+	case field.Resolve != nil:
+		for i, source := range sources {
+			value, err := field.Resolve(ctx, source, args)
+			if err != nil {
+				value = err
+			}
+			values[i] = value
+		}
+	default:
+		for i, source := range sources {
+			values[i] = defaultResolve(source, field.Name)
+		}
+	}
+
+	for i, value := range values {
+		if err, ok := value.(error); ok {
+			execution.addError(paths[i], err.Error())
+			values[i] = nullResult{}
+		}
+	}
+	return values
+}
+
+// complete converte os valores resolvidos conforme o tipo do campo, descendo nas listas e objetos
+func (execution *execution) complete(
+	ctx context.Context, typeRef *TypeRef, values []any, paths [][]any, selectionSet []Selection) []any {
+	results := make([]any, len(values))
+
+	pending := make([]int, 0, len(values))
+	for i, value := range values {
+		if _, failed := value.(nullResult); failed {
+			continue
+		}
+		if isNil(value) {
+			if typeRef.NonNull {
+				execution.addError(paths[i], "valor nulo em campo obrigatório")
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	switch object, isObject := execution.schema.objects[typeRef.Name]; {
+	case typeRef.Elem != nil:
+		var items []any
+		var itemPaths [][]any
+		counts := make(map[int]int, len(pending))
+		for _, i := range pending {
+			list := reflect.ValueOf(values[i])
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				execution.addError(paths[i], "o resolver não retornou uma lista")
+				counts[i] = -1
+				continue
+			}
+			counts[i] = list.Len()
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, list.Index(j).Interface())
+				itemPaths = append(itemPaths, appendPath(paths[i], j))
+			}
+		}
+		completedItems := execution.complete(ctx, typeRef.Elem, items, itemPaths, selectionSet)
+		offset := 0
+		for _, i := range pending {
+			if counts[i] < 0 {
+				continue
+			}
+			results[i] = completedItems[offset : offset+counts[i]]
+			offset += counts[i]
+		}
+	case isObject:
+		sources := make([]any, len(pending))
+		sourcePaths := make([][]any, len(pending))
+		for j, i := range pending {
+			sources[j], sourcePaths[j] = values[i], paths[i]
+		}
+		objects := execution.executeSelections(ctx, object, sources, sourcePaths, selectionSet)
+		for j, i := range pending {
+			results[i] = objects[j]
+		}
+	default:
+		for _, i := range pending {
+			value, err := serializeScalar(typeRef.Name, values[i])
+			if err != nil {
+				execution.addError(paths[i], err.Error())
+				continue
+			}
+			results[i] = value
+		}
+	}
+	return results
+}
+
+func appendPath(path []any, element any) []any {
+	extended := make([]any, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, element)
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return reflected.IsNil()
+	}
+	return false
+}
+
+// defaultResolve lê o campo do objeto de origem: chave de map, tag json ou nome do campo Go
+func defaultResolve(source any, name string) any {
+	if values, ok := source.(map[string]any); ok {
+		return values[name]
+	}
+
+	reflected := reflect.ValueOf(source)
+	for reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return nil
+		}
+		reflected = reflected.Elem()
+	}
+	if reflected.Kind() != reflect.Struct {
+		return nil
+	}
+
+	structType := reflected.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		tag, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return reflected.Field(i).Interface()
+		}
+	}
+	if value := reflected.FieldByNameFunc(func(fieldName string) bool { return strings.EqualFold(fieldName, name) }); value.IsValid() {
+		return value.Interface()
+	}
+	return nil
+}
+
+// serializeScalar converte o valor Go para a representação JSON do escalar
+func serializeScalar(scalar string, value any) (any, error) {
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return nil, nil
+		}
+		reflected = reflected.Elem()
+	}
+	value = reflected.Interface()
+
+	if moment, ok := value.(time.Time); ok && (scalar == String || scalar == ID) {
+		return moment.Format(time.RFC3339), nil
+	}
+
+	switch scalar {
+	case String:
+		if reflected.Kind() == reflect.String {
+			return reflected.String(), nil
+		}
+		if stringer, ok := value.(fmt.Stringer); ok {
+			return stringer.String(), nil
+		}
+	case ID:
+		switch reflected.Kind() {
+		case reflect.String:
+			return reflected.String(), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(reflected.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(reflected.Uint(), 10), nil
+		}
+	case Int:
+		switch reflected.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return reflected.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(reflected.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			if number := reflected.Float(); number == math.Trunc(number) {
+				return int64(number), nil
+			}
+		}
+	case Float:
+		switch reflected.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(reflected.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(reflected.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return reflected.Float(), nil
+		}
+	case Boolean:
+		if reflected.Kind() == reflect.Bool {
+			return reflected.Bool(), nil
+		}
+	}
+	return nil, fmt.Errorf("valor %v não pode ser representado como %s", value, scalar)
+}
+
+// coerceVariables valida as variáveis recebidas contra as declaradas na operação
+func coerceVariables(operation *Operation, provided map[string]any) (map[string]any, error) {
+	variables := make(map[string]any, len(operation.Variables))
+	for _, definition := range operation.Variables {
+		if !builtinScalars[definition.Type.NamedType()] {
+			return nil, fmt.Errorf("variável $%s: tipo %s não é aceito como entrada", definition.Name, definition.Type)
+		}
+		value, ok := provided[definition.Name]
+		if !ok {
+			if definition.Default == nil {
+				if definition.Type.NonNull {
+					return nil, fmt.Errorf("variável $%s é obrigatória", definition.Name)
+				}
+				continue
+			}
+			value = definition.Default
+		}
+		coerced, err := coerceInput(value, definition.Type)
+		if err != nil {
+			return nil, fmt.Errorf("variável $%s: %w", definition.Name, err)
+		}
+		variables[definition.Name] = coerced
+	}
+	return variables, nil
+}
+
+// coerceArguments resolve variáveis e padrões dos argumentos do campo
+func (execution *execution) coerceArguments(field *FieldDef, arguments []*Argument) (map[string]any, error) {
+	args := make(map[string]any, len(field.Args))
+	for _, definition := range field.Args {
+		argument := findArgument(arguments, definition.Name)
+		var value any
+		present := false
+		if argument != nil {
+			if variable, isVariable := argument.Value.(Variable); isVariable {
+				value, present = execution.variables[string(variable)]
+			} else {
+				value, present = execution.resolveVariables(argument.Value), true
+			}
+		}
+		if !present {
+			if definition.Default != nil {
+				args[definition.Name] = definition.Default
+				continue
+			}
+			if definition.typeRef.NonNull {
+				return nil, fmt.Errorf("o argumento %s é obrigatório", definition.Name)
+			}
+			continue
+		}
+
+		coerced, err := coerceInput(value, definition.typeRef)
+		if err != nil {
+			return nil, fmt.Errorf("argumento %s: %w", definition.Name, err)
+		}
+		args[definition.Name] = coerced
+	}
+	return args, nil
+}
+
+// resolveVariables substitui as variáveis dentro de um literal (inclusive em listas)
+func (execution *execution) resolveVariables(value Value) any {
+	switch value := value.(type) {
+	case Variable:
+		return execution.variables[string(value)]
+	case ListValue:
+		resolved := make([]any, len(value))
+		for i, item := range value {
+			resolved[i] = execution.resolveVariables(item)
+		}
+		return resolved
+	}
+	return value
+}
+
+// coerceInput converte um literal ou valor JSON para o tipo de entrada (int, float64, string, bool ou []any)
+func coerceInput(value any, typeRef *TypeRef) (any, error) {
+	if value == nil {
+		if typeRef.NonNull {
+			return nil, errors.New("valor nulo não permitido")
+		}
+		return nil, nil
+	}
+
+	if typeRef.Elem != nil {
+		var items []any
+		switch list := value.(type) {
+		case []any:
+			items = list
+		case ListValue:
+			items = make([]any, len(list))
+			for i, item := range list {
+				items[i] = item
+			}
+		default:
+			items = []any{value} // Um valor isolado vale como lista de um elemento
+		}
+		coerced := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if coerced[i], err = coerceInput(item, typeRef.Elem); err != nil {
+				return nil, err
+			}
+		}
+		return coerced, nil
+	}
+
+	switch typeRef.Name {
+	case Int:
+		switch number := value.(type) {
+		case int64:
+			if number >= math.MinInt32 && number <= math.MaxInt32 {
+				return int(number), nil
+			}
+		case float64:
+			if number == math.Trunc(number) && number >= math.MinInt32 && number <= math.MaxInt32 {
+				return int(number), nil
+			}
+		case int:
+			return number, nil
+		}
+	case Float:
+		switch number := value.(type) {
+		case int64:
+			return float64(number), nil
+		case int:
+			return float64(number), nil
+		case float64:
+			return number, nil
+		}
+	case String:
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+	case ID:
+		switch id := value.(type) {
+		case string:
+			return id, nil
+		case int64:
+			return strconv.FormatInt(id, 10), nil
+		case int:
+			return strconv.Itoa(id), nil
+		case float64:
+			if id == math.Trunc(id) {
+				return strconv.FormatFloat(id, 'f', 0, 64), nil
+			}
+		}
+	case Boolean:
+		if boolean, ok := value.(bool); ok {
+			return boolean, nil
+		}
+	}
+	return nil, fmt.Errorf("valor %v inválido para o tipo %s", value, typeRef.Name)
+}
+
+// orderedMap preserva a ordem dos campos pedidos na consulta ao serializar o objeto
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (object *orderedMap) set(key string, value any) {
+	if _, exists := object.values[key]; !exists {
+		object.keys = append(object.keys, key)
+	}
+	object.values[key] = value
+}
+
+func (object *orderedMap) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range object.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		encodedValue, err := json.Marshal(object.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encodedValue)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,276 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testUser struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Email    string
+	FriendID uint
+}
+
+var testUsers = map[uint]testUser{
+	1: {ID: 1, Name: "Ana", Email: "ana@exemplo.com", FriendID: 2},
+	2: {ID: 2, Name: "Bruno", Email: "bruno@exemplo.com", FriendID: 1},
+	3: {ID: 3, Name: "Carla", Email: "carla@exemplo.com"},
+}
+
+// newTestSchema monta um schema pequeno; batchCalls conta as chamadas do BatchResolve de friend
+func newTestSchema(t *testing.T, batchCalls *int) *Schema {
+	t.Helper()
+	user := &Object{Name: "User", Fields: []*FieldDef{
+		{Name: "id", Type: "ID!"},
+		{Name: "name", Type: "String!"},
+		{Name: "email", Type: "String"},
+		{Name: "friend", Type: "User", BatchResolve: func(ctx context.Context, sources []any, args map[string]any) ([]any, error) {
+			*batchCalls++
+			values := make([]any, len(sources))
+			for i, source := range sources {
+				if friend, ok := testUsers[source.(testUser).FriendID]; ok {
+					values[i] = friend
+				}
+			}
+			return values, nil
+		}},
+		{Name: "secret", Type: "String", Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return nil, errors.New("acesso negado")
+		}},
+		{Name: "required", Type: "String!", Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return nil, nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: []*FieldDef{
+		{Name: "user", Type: "User", Args: []*ArgumentDef{{Name: "id", Type: "ID!"}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				var id uint
+				fmt.Sscan(args["id"].(string), &id)
+				if found, ok := testUsers[id]; ok {
+					return found, nil
+				}
+				return nil, nil
+			}},
+		{Name: "users", Type: "[User!]!", Args: []*ArgumentDef{{Name: "limit", Type: "Int", Default: 10}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				users := []testUser{testUsers[1], testUsers[2], testUsers[3]}
+				if limit := args["limit"].(int); limit < len(users) {
+					users = users[:limit]
+				}
+				return users, nil
+			}},
+		{Name: "echo", Type: "String", Args: []*ArgumentDef{{Name: "text", Type: "String!"}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				return args["text"], nil
+			}},
+		{Name: "stats", Type: "Stats!", Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return map[string]any{"count": 3, "average": 2.5}, nil
+		}},
+	}}
+	stats := &Object{Name: "Stats", Fields: []*FieldDef{
+		{Name: "count", Type: "Int!"},
+		{Name: "average", Type: "Float"},
+	}}
+
+	schema, err := NewSchema(query, user, stats)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	schema.MaxDepth = 3
+	return schema
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		operation  string
+		variables  map[string]any
+		wantData   string
+		wantErrors []string
+		batchCalls int
+	}{
+		{
+			name:     "campos padrão por tag json, nome e chave de map",
+			query:    `{ user(id: 1) { id name email } stats { count average } }`,
+			wantData: `{"user":{"id":"1","name":"Ana","email":"ana@exemplo.com"},"stats":{"count":3,"average":2.5}}`,
+		},
+		{
+			name:     "alias e __typename",
+			query:    `{ a: user(id: 1) { nome: name __typename } b: user(id: 3) { nome: name } }`,
+			wantData: `{"a":{"nome":"Ana","__typename":"User"},"b":{"nome":"Carla"}}`,
+		},
+		{
+			name:     "objeto inexistente vira nulo",
+			query:    `{ user(id: 99) { name } }`,
+			wantData: `{"user":null}`,
+		},
+		{
+			name:       "lista resolve o nível inteiro numa única chamada",
+			query:      `{ users { name friend { name } } }`,
+			wantData:   `{"users":[{"name":"Ana","friend":{"name":"Bruno"}},{"name":"Bruno","friend":{"name":"Ana"}},{"name":"Carla","friend":null}]}`,
+			batchCalls: 1,
+		},
+		{
+			name:      "variáveis e default de argumento",
+			query:     `query Lista($limit: Int) { users(limit: $limit) { id } }`,
+			variables: map[string]any{"limit": float64(2)},
+			wantData:  `{"users":[{"id":"1"},{"id":"2"}]}`,
+		},
+		{
+			name:      "@include e @skip",
+			query:     `query ($com: Boolean!) { user(id: 1) { name @skip(if: true) email @include(if: $com) id @include(if: false) } }`,
+			variables: map[string]any{"com": true},
+			wantData:  `{"user":{"email":"ana@exemplo.com"}}`,
+		},
+		{
+			name:     "fragmentos nomeados e inline são mesclados",
+			query:    `{ user(id: 2) { ...Basico ... on User { email } } } fragment Basico on User { id name }`,
+			wantData: `{"user":{"id":"2","name":"Bruno","email":"bruno@exemplo.com"}}`,
+		},
+		{
+			name:      "seleção da operação pelo nome",
+			query:     `query A { echo(text: "a") } query B { echo(text: "b") }`,
+			operation: "B",
+			wantData:  `{"echo":"b"}`,
+		},
+		{
+			name:       "erro do resolver deixa o campo nulo com path",
+			query:      `{ user(id: 1) { name secret } }`,
+			wantData:   `{"user":{"name":"Ana","secret":null}}`,
+			wantErrors: []string{"acesso negado @ user.secret"},
+		},
+		{
+			name:       "nulo em campo obrigatório é reportado",
+			query:      `{ user(id: 1) { required } }`,
+			wantData:   `{"user":{"required":null}}`,
+			wantErrors: []string{"valor nulo em campo obrigatório @ user.required"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var batchCalls int
+			response := newTestSchema(t, &batchCalls).Execute(context.Background(), test.query, test.operation, test.variables)
+
+			data, err := json.Marshal(response.Data)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(data) != test.wantData {
+				t.Errorf("data = %s\n  esperado %s", data, test.wantData)
+			}
+			if got := formatErrors(response.Errors); strings.Join(got, "|") != strings.Join(test.wantErrors, "|") {
+				t.Errorf("errors = %q, esperado %q", got, test.wantErrors)
+			}
+			if batchCalls != test.batchCalls {
+				t.Errorf("chamadas de BatchResolve = %d, esperado %d", batchCalls, test.batchCalls)
+			}
+		})
+	}
+}
+
+func TestExecuteRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]any
+		want      string
+	}{
+		{"erro de sintaxe", `{ user(id: 1) { name }`, "", nil, "erro de sintaxe"},
+		{"mutation", `mutation { user(id: 1) { name } }`, "", nil, "apenas consultas (query) são suportadas"},
+		{"várias operações sem nome", `query A { echo(text: "a") } query B { echo(text: "b") }`, "", nil, "informe operationName"},
+		{"operação inexistente", `query A { echo(text: "a") }`, "C", nil, "operação não encontrada: C"},
+		{"campo desconhecido", `{ user(id: 1) { phone } }`, "", nil, "o campo phone não existe no tipo User"},
+		{"argumento desconhecido", `{ users(page: 2) { id } }`, "", nil, "o campo Query.users não aceita o argumento page"},
+		{"argumento obrigatório ausente", `{ user { id } }`, "", nil, "o argumento id de Query.user é obrigatório"},
+		{"objeto sem subseleção", `{ user(id: 1) }`, "", nil, "precisa de uma seleção de subcampos"},
+		{"escalar com subseleção", `{ echo(text: "a") { length } }`, "", nil, "é escalar e não aceita seleção de subcampos"},
+		{"fragmento desconhecido", `{ user(id: 1) { ...Nada } }`, "", nil, "fragmento desconhecido: Nada"},
+		{"fragmento recursivo", `{ user(id: 1) { ...F } } fragment F on User { friend { ...F } }`, "", nil, "o fragmento F referencia a si mesmo"},
+		{"profundidade máxima", `{ user(id: 1) { friend { friend { name } } } }`, "", nil, "a consulta excede a profundidade máxima de 3 níveis"},
+		{"profundidade via fragmento", `{ user(id: 1) { ...F } } fragment F on User { friend { friend { id } } }`, "", nil, "a consulta excede a profundidade máxima de 3 níveis"},
+		{"fragmentos aninhados contam na profundidade", `{ user(id: 1) { ...A } } fragment A on User { ...B } fragment B on User { id }`, "", nil, "a consulta excede a profundidade máxima de 3 níveis"},
+		{"ciclo entre fragmentos", `{ user(id: 1) { ...A } } fragment A on User { ...B } fragment B on User { ...A }`, "", nil, "o fragmento A referencia a si mesmo"},
+		{"variável obrigatória ausente", `query ($id: ID!) { user(id: $id) { id } }`, "", nil, "variável $id é obrigatória"},
+		{"variável de tipo errado", `query ($limit: Int) { users(limit: $limit) { id } }`, "", map[string]any{"limit": "dez"}, "variável $limit"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var batchCalls int
+			response := newTestSchema(t, &batchCalls).Execute(context.Background(), test.query, test.operation, test.variables)
+			if response.Data != nil {
+				t.Errorf("data = %#v, esperado nil", response.Data)
+			}
+			if len(response.Errors) == 0 || !strings.Contains(response.Errors[0].Message, test.want) {
+				t.Fatalf("errors = %q, esperado conter %q", formatErrors(response.Errors), test.want)
+			}
+			if batchCalls != 0 {
+				t.Errorf("nenhum resolver deveria executar, BatchResolve chamado %d vezes", batchCalls)
+			}
+		})
+	}
+}
+
+func TestExecuteWithinMaxDepth(t *testing.T) {
+	var batchCalls int
+	response := newTestSchema(t, &batchCalls).Execute(context.Background(), `{ user(id: 1) { friend { name } } }`, "", nil)
+	if len(response.Errors) > 0 {
+		t.Fatalf("errors = %q", formatErrors(response.Errors))
+	}
+}
+
+// TestExecuteFragmentExplosion usa uma cadeia em que cada fragmento usa o seguinte duas vezes: expandida,
+// a consulta teria 2^40 campos. A validação mede cada fragmento uma vez e recusa a consulta pelo limite.
+func TestExecuteFragmentExplosion(t *testing.T) {
+	var query strings.Builder
+	query.WriteString("{ user(id: 1) { ...F0 } }")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&query, " fragment F%d on User { ...F%d ...F%d }", i, i+1, i+1)
+	}
+	query.WriteString(" fragment F40 on User { id }")
+
+	var batchCalls int
+	schema := newTestSchema(t, &batchCalls)
+	schema.MaxDepth = 0
+	schema.MaxComplexity = 1000
+	response := schema.Execute(context.Background(), query.String(), "", nil)
+	if response.Data != nil || len(response.Errors) != 1 || response.Errors[0].Message != "a consulta excede o limite de 1000 campos" {
+		t.Fatalf("errors = %q, esperado o limite de campos", formatErrors(response.Errors))
+	}
+
+	schema.MaxDepth = 8
+	response = schema.Execute(context.Background(), query.String(), "", nil)
+	if len(response.Errors) == 0 || !strings.Contains(response.Errors[0].Message, "profundidade máxima de 8 níveis") {
+		t.Fatalf("errors = %q, esperado o limite de profundidade", formatErrors(response.Errors))
+	}
+}
+
+func TestExecuteWithinComplexity(t *testing.T) {
+	var batchCalls int
+	schema := newTestSchema(t, &batchCalls)
+	schema.MaxComplexity = 6 // user, email e os dois campos de F a cada uso
+	response := schema.Execute(context.Background(), `{ user(id: 1) { ...F ...F email } } fragment F on User { id name }`, "", nil)
+	if len(response.Errors) > 0 {
+		t.Fatalf("errors = %q", formatErrors(response.Errors))
+	}
+}
+
+func formatErrors(errs []*Error) []string {
+	var formatted []string
+	for _, err := range errs {
+		path := make([]string, len(err.Path))
+		for i, element := range err.Path {
+			path[i] = fmt.Sprint(element)
+		}
+		formatted = append(formatted, err.Message+" @ "+strings.Join(path, "."))
+	}
+	return formatted
+}
@@ -0,0 +1,545 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document é uma requisição GraphQL já interpretada
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation é uma operação (query) do documento
+type Operation struct {
+	Type         string // query, mutation ou subscription
+	Name         string
+	Variables    []*VariableDefinition
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// VariableDefinition declara uma variável da operação
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default Value
+}
+
+// Selection é um campo, um fragment spread ou um inline fragment
+type Selection interface{}
+
+// Field é um campo selecionado
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// ResponseKey é o nome do campo na resposta (o alias, quando houver)
+func (field *Field) ResponseKey() string {
+	if field.Alias != "" {
+		return field.Alias
+	}
+	return field.Name
+}
+
+// FragmentSpread é um ...NomeDoFragmento
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+// InlineFragment é um ... on Tipo { }
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// Fragment é um fragmento nomeado
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Argument é um argumento de campo ou diretiva
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Directive é uma diretiva (@include, @skip)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// Value é um valor literal: nil, bool, int64, float64, string, EnumValue, Variable, ListValue ou ObjectValue
+type Value interface{}
+
+type (
+	Variable    string
+	EnumValue   string
+	ListValue   []Value
+	ObjectValue map[string]Value
+)
+
+// Parse interpreta o texto da requisição
+func Parse(source string) (document *Document, err error) {
+	parser := &parser{lexer: &lexer{source: source}}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			syntaxErr, ok := recovered.(syntaxError)
+			if !ok {
+				panic(recovered)
+			}
+			document, err = nil, syntaxErr
+		}
+	}()
+
+	parser.next()
+	document = &Document{Fragments: make(map[string]*Fragment)}
+	for parser.token.kind != tokenEOF {
+		switch {
+		case parser.token.kind == tokenPunct && parser.token.value == "{":
+			document.Operations = append(document.Operations, &Operation{Type: "query", SelectionSet: parser.parseSelectionSet()})
+		case parser.token.kind == tokenName && parser.token.value == "fragment":
+			fragment := parser.parseFragment()
+			if _, exists := document.Fragments[fragment.Name]; exists {
+				parser.fail("fragmento duplicado: " + fragment.Name)
+			}
+			document.Fragments[fragment.Name] = fragment
+		case parser.token.kind == tokenName:
+			document.Operations = append(document.Operations, parser.parseOperation())
+		default:
+			parser.fail("esperada uma operação ou fragmento")
+		}
+	}
+	if len(document.Operations) == 0 {
+		return nil, syntaxError{message: "o documento não tem nenhuma operação"}
+	}
+	return document, nil
+}
+
+type syntaxError struct {
+	message string
+	line    int
+	column  int
+}
+
+func (err syntaxError) Error() string {
+	if err.line == 0 {
+		return "erro de sintaxe: " + err.message
+	}
+	return fmt.Sprintf("erro de sintaxe na linha %d, coluna %d: %s", err.line, err.column, err.message)
+}
+
+type parser struct {
+	lexer *lexer
+	token token
+}
+
+func (parser *parser) next() {
+	parser.token = parser.lexer.next()
+}
+
+func (parser *parser) fail(message string) {
+	line, column := parser.lexer.position(parser.token.offset)
+	panic(syntaxError{message: message, line: line, column: column})
+}
+
+func (parser *parser) peek(punct string) bool {
+	return parser.token.kind == tokenPunct && parser.token.value == punct
+}
+
+func (parser *parser) expect(punct string) {
+	if !parser.peek(punct) {
+		parser.fail(fmt.Sprintf("esperado %q, encontrado %q", punct, parser.token.value))
+	}
+	parser.next()
+}
+
+func (parser *parser) expectName() string {
+	if parser.token.kind != tokenName {
+		parser.fail(fmt.Sprintf("esperado um nome, encontrado %q", parser.token.value))
+	}
+	name := parser.token.value
+	parser.next()
+	return name
+}
+
+func (parser *parser) parseOperation() *Operation {
+	operation := &Operation{Type: parser.expectName()}
+	if operation.Type != "query" && operation.Type != "mutation" && operation.Type != "subscription" {
+		parser.fail("tipo de operação desconhecido: " + operation.Type)
+	}
+	if parser.token.kind == tokenName {
+		operation.Name = parser.expectName()
+	}
+	if parser.peek("(") {
+		parser.next()
+		for !parser.peek(")") {
+			parser.expect("$")
+			definition := &VariableDefinition{Name: parser.expectName()}
+			parser.expect(":")
+			definition.Type = parser.parseType()
+			if parser.peek("=") {
+				parser.next()
+				definition.Default = parser.parseValue(true)
+			}
+			operation.Variables = append(operation.Variables, definition)
+		}
+		parser.next()
+	}
+	operation.Directives = parser.parseDirectives()
+	operation.SelectionSet = parser.parseSelectionSet()
+	return operation
+}
+
+func (parser *parser) parseFragment() *Fragment {
+	parser.next() // fragment
+	fragment := &Fragment{Name: parser.expectName()}
+	if parser.expectName() != "on" {
+		parser.fail("esperado on após o nome do fragmento")
+	}
+	fragment.TypeCondition = parser.expectName()
+	parser.parseDirectives()
+	fragment.SelectionSet = parser.parseSelectionSet()
+	return fragment
+}
+
+func (parser *parser) parseSelectionSet() []Selection {
+	parser.expect("{")
+	var selections []Selection
+	for !parser.peek("}") {
+		if parser.token.kind == tokenEOF {
+			parser.fail("seleção não fechada")
+		}
+		selections = append(selections, parser.parseSelection())
+	}
+	parser.next()
+	if len(selections) == 0 {
+		parser.fail("seleção vazia")
+	}
+	return selections
+}
+
+func (parser *parser) parseSelection() Selection {
+	if parser.peek("...") {
+		parser.next()
+		if parser.token.kind == tokenName && parser.token.value != "on" {
+			return &FragmentSpread{Name: parser.expectName(), Directives: parser.parseDirectives()}
+		}
+		inline := &InlineFragment{}
+		if parser.token.kind == tokenName {
+			parser.next() // on
+			inline.TypeCondition = parser.expectName()
+		}
+		inline.Directives = parser.parseDirectives()
+		inline.SelectionSet = parser.parseSelectionSet()
+		return inline
+	}
+
+	field := &Field{Name: parser.expectName()}
+	if parser.peek(":") {
+		parser.next()
+		field.Alias, field.Name = field.Name, parser.expectName()
+	}
+	field.Arguments = parser.parseArguments(false)
+	field.Directives = parser.parseDirectives()
+	if parser.peek("{") {
+		field.SelectionSet = parser.parseSelectionSet()
+	}
+	return field
+}
+
+func (parser *parser) parseArguments(constant bool) []*Argument {
+	if !parser.peek("(") {
+		return nil
+	}
+	parser.next()
+	var arguments []*Argument
+	for !parser.peek(")") {
+		argument := &Argument{Name: parser.expectName()}
+		parser.expect(":")
+		argument.Value = parser.parseValue(constant)
+		arguments = append(arguments, argument)
+	}
+	parser.next()
+	return arguments
+}
+
+func (parser *parser) parseDirectives() []*Directive {
+	var directives []*Directive
+	for parser.peek("@") {
+		parser.next()
+		directives = append(directives, &Directive{Name: parser.expectName(), Arguments: parser.parseArguments(false)})
+	}
+	return directives
+}
+
+func (parser *parser) parseType() *TypeRef {
+	var typeRef *TypeRef
+	if parser.peek("[") {
+		parser.next()
+		typeRef = &TypeRef{Elem: parser.parseType()}
+		parser.expect("]")
+	} else {
+		typeRef = &TypeRef{Name: parser.expectName()}
+	}
+	if parser.peek("!") {
+		parser.next()
+		typeRef.NonNull = true
+	}
+	return typeRef
+}
+
+func (parser *parser) parseValue(constant bool) Value {
+	token := parser.token
+	switch token.kind {
+	case tokenPunct:
+		switch token.value {
+		case "$":
+			if constant {
+				parser.fail("variáveis não são permitidas aqui")
+			}
+			parser.next()
+			return Variable(parser.expectName())
+		case "[":
+			parser.next()
+			list := ListValue{}
+			for !parser.peek("]") {
+				list = append(list, parser.parseValue(constant))
+			}
+			parser.next()
+			return list
+		case "{":
+			parser.next()
+			object := ObjectValue{}
+			for !parser.peek("}") {
+				name := parser.expectName()
+				parser.expect(":")
+				object[name] = parser.parseValue(constant)
+			}
+			parser.next()
+			return object
+		}
+	case tokenInt:
+		parser.next()
+		value, err := strconv.ParseInt(token.value, 10, 64)
+		if err != nil {
+			parser.fail("inteiro inválido: " + token.value)
+		}
+		return value
+	case tokenFloat:
+		parser.next()
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			parser.fail("número inválido: " + token.value)
+		}
+		return value
+	case tokenString:
+		parser.next()
+		return token.value
+	case tokenName:
+		parser.next()
+		switch token.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return EnumValue(token.value)
+	}
+	parser.fail(fmt.Sprintf("valor inesperado %q", token.value))
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	offset int
+}
+
+type lexer struct {
+	source string
+	offset int
+}
+
+func (lexer *lexer) position(offset int) (int, int) {
+	before := lexer.source[:min(offset, len(lexer.source))]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
+
+func (lexer *lexer) fail(offset int, message string) {
+	line, column := lexer.position(offset)
+	panic(syntaxError{message: message, line: line, column: column})
+}
+
+func (lexer *lexer) next() token {
+	source := lexer.source
+	// Ignora espaços, vírgulas, BOM e comentários
+	for lexer.offset < len(source) {
+		char := source[lexer.offset]
+		if char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == ',' {
+			lexer.offset++
+		} else if char == '#' {
+			for lexer.offset < len(source) && source[lexer.offset] != '\n' {
+				lexer.offset++
+			}
+		} else if strings.HasPrefix(source[lexer.offset:], "\uFEFF") {
+			lexer.offset += len("\uFEFF")
+		} else {
+			break
+		}
+	}
+	if lexer.offset >= len(source) {
+		return token{kind: tokenEOF, offset: lexer.offset}
+	}
+
+	start := lexer.offset
+	char := source[start]
+	switch {
+	case strings.HasPrefix(source[start:], "..."):
+		lexer.offset += 3
+		return token{kind: tokenPunct, value: "...", offset: start}
+	case strings.IndexByte("!$():=@[]{}|&", char) >= 0:
+		lexer.offset++
+		return token{kind: tokenPunct, value: string(char), offset: start}
+	case char == '_' || isLetter(char):
+		for lexer.offset < len(source) && (source[lexer.offset] == '_' || isLetter(source[lexer.offset]) || isDigit(source[lexer.offset])) {
+			lexer.offset++
+		}
+		return token{kind: tokenName, value: source[start:lexer.offset], offset: start}
+	case char == '-' || isDigit(char):
+		return lexer.number(start)
+	case char == '"':
+		return lexer.string(start)
+	}
+	lexer.fail(start, fmt.Sprintf("caractere inesperado %q", char))
+	return token{}
+}
+
+func (lexer *lexer) number(start int) token {
+	source := lexer.source
+	kind := tokenInt
+	if source[lexer.offset] == '-' {
+		lexer.offset++
+	}
+	digits := func() {
+		begin := lexer.offset
+		for lexer.offset < len(source) && isDigit(source[lexer.offset]) {
+			lexer.offset++
+		}
+		if lexer.offset == begin {
+			lexer.fail(begin, "número inválido")
+		}
+	}
+	digits()
+	if lexer.offset < len(source) && source[lexer.offset] == '.' {
+		kind = tokenFloat
+		lexer.offset++
+		digits()
+	}
+	if lexer.offset < len(source) && (source[lexer.offset] == 'e' || source[lexer.offset] == 'E') {
+		kind = tokenFloat
+		lexer.offset++
+		if lexer.offset < len(source) && (source[lexer.offset] == '+' || source[lexer.offset] == '-') {
+			lexer.offset++
+		}
+		digits()
+	}
+	return token{kind: kind, value: source[start:lexer.offset], offset: start}
+}
+
+func (lexer *lexer) string(start int) token {
+	source := lexer.source
+	if strings.HasPrefix(source[start:], `"""`) {
+		end := strings.Index(source[start+3:], `"""`)
+		if end < 0 {
+			lexer.fail(start, "string não terminada")
+		}
+		lexer.offset = start + 3 + end + 3
+		return token{kind: tokenString, value: strings.TrimSpace(source[start+3 : start+3+end]), offset: start}
+	}
+
+	var builder strings.Builder
+	lexer.offset++
+	for {
+		if lexer.offset >= len(source) || source[lexer.offset] == '\n' {
+			lexer.fail(start, "string não terminada")
+		}
+		char := source[lexer.offset]
+		if char == '"' {
+			lexer.offset++
+			return token{kind: tokenString, value: builder.String(), offset: start}
+		}
+		if char != '\\' {
+			builder.WriteByte(char)
+			lexer.offset++
+			continue
+		}
+
+		if lexer.offset+1 >= len(source) {
+			lexer.fail(start, "string não terminada")
+		}
+		escape := source[lexer.offset+1]
+		lexer.offset += 2
+		switch escape {
+		case '"', '\\', '/':
+			builder.WriteByte(escape)
+		case 'b':
+			builder.WriteByte('\b')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case 'u':
+			if lexer.offset+4 > len(source) {
+				lexer.fail(start, "escape unicode inválido")
+			}
+			code, err := strconv.ParseUint(source[lexer.offset:lexer.offset+4], 16, 32)
+			if err != nil {
+				lexer.fail(start, "escape unicode inválido")
+			}
+			builder.WriteRune(rune(code))
+			lexer.offset += 4
+		default:
+			lexer.fail(lexer.offset-2, "escape inválido")
+		}
+	}
+}
+
+func isLetter(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		check func(t *testing.T, document *Document)
+	}{
+		{
+			name:  "consulta abreviada",
+			query: `{ me { id name } }`,
+			check: func(t *testing.T, document *Document) {
+				operation := document.Operations[0]
+				if operation.Type != "query" || operation.Name != "" {
+					t.Fatalf("operação = %s %q, esperado query anônima", operation.Type, operation.Name)
+				}
+				me := operation.SelectionSet[0].(*Field)
+				if me.Name != "me" || len(me.SelectionSet) != 2 {
+					t.Fatalf("campo me = %+v", me)
+				}
+			},
+		},
+		{
+			name:  "operação nomeada com variáveis e default",
+			query: `query Compras($limit: Int = 10, $ids: [ID!]!) { purchases(limit: $limit, ids: $ids) { id } }`,
+			check: func(t *testing.T, document *Document) {
+				operation := document.Operations[0]
+				if operation.Name != "Compras" || len(operation.Variables) != 2 {
+					t.Fatalf("operação = %+v", operation)
+				}
+				if got := operation.Variables[0]; got.Name != "limit" || got.Type.String() != "Int" || got.Default != int64(10) {
+					t.Fatalf("variável limit = %+v", got)
+				}
+				if got := operation.Variables[1].Type.String(); got != "[ID!]!" {
+					t.Fatalf("tipo de $ids = %s", got)
+				}
+				field := operation.SelectionSet[0].(*Field)
+				if field.Arguments[0].Value != Variable("limit") {
+					t.Fatalf("argumento limit = %#v", field.Arguments[0].Value)
+				}
+			},
+		},
+		{
+			name:  "alias, diretivas e fragmentos",
+			query: `{ eu: me @include(if: true) { ...Dados ... on User { email } } } fragment Dados on User { id }`,
+			check: func(t *testing.T, document *Document) {
+				field := document.Operations[0].SelectionSet[0].(*Field)
+				if field.Alias != "eu" || field.ResponseKey() != "eu" || field.Name != "me" {
+					t.Fatalf("alias = %+v", field)
+				}
+				if len(field.Directives) != 1 || field.Directives[0].Name != "include" {
+					t.Fatalf("diretivas = %+v", field.Directives)
+				}
+				if spread, ok := field.SelectionSet[0].(*FragmentSpread); !ok || spread.Name != "Dados" {
+					t.Fatalf("fragment spread = %#v", field.SelectionSet[0])
+				}
+				if inline, ok := field.SelectionSet[1].(*InlineFragment); !ok || inline.TypeCondition != "User" {
+					t.Fatalf("inline fragment = %#v", field.SelectionSet[1])
+				}
+				if fragment := document.Fragments["Dados"]; fragment == nil || fragment.TypeCondition != "User" {
+					t.Fatalf("fragmento Dados = %+v", fragment)
+				}
+			},
+		},
+		{
+			name:  "valores literais",
+			query: "{ f(a: -3, b: 1.5e2, c: \"x\\n\\u00e9\", d: \"\"\"  bloco  \"\"\", e: [1 true null], g: {h: ABC}) }",
+			check: func(t *testing.T, document *Document) {
+				arguments := document.Operations[0].SelectionSet[0].(*Field).Arguments
+				want := []Value{
+					int64(-3),
+					float64(150),
+					"x\né",
+					"bloco",
+					ListValue{int64(1), true, nil},
+					ObjectValue{"h": EnumValue("ABC")},
+				}
+				for i, argument := range arguments {
+					if !reflect.DeepEqual(argument.Value, want[i]) {
+						t.Errorf("argumento %s = %#v, esperado %#v", argument.Name, argument.Value, want[i])
+					}
+				}
+			},
+		},
+		{
+			name:  "comentários e vírgulas são ignorados",
+			query: "# listagem\n{ a, b # fim\n }",
+			check: func(t *testing.T, document *Document) {
+				if got := len(document.Operations[0].SelectionSet); got != 2 {
+					t.Fatalf("seleções = %d, esperado 2", got)
+				}
+			},
+		},
+		{
+			name:  "várias operações",
+			query: `query A { a } query B { b }`,
+			check: func(t *testing.T, document *Document) {
+				if len(document.Operations) != 2 || document.Operations[1].Name != "B" {
+					t.Fatalf("operações = %+v", document.Operations)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := Parse(test.query)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			test.check(t, document)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"documento vazio", "", "o documento não tem nenhuma operação"},
+		{"apenas fragmento", "fragment F on User { id }", "o documento não tem nenhuma operação"},
+		{"seleção não fechada", "{ me { id }", "seleção não fechada"},
+		{"seleção vazia", "{ }", "seleção vazia"},
+		{"tipo de operação desconhecido", "consulta { a }", "tipo de operação desconhecido: consulta"},
+		{"fragmento duplicado", "{ a } fragment F on Q { a } fragment F on Q { a }", "fragmento duplicado: F"},
+		{"variável em default", "query ($a: Int = $b) { a }", "variáveis não são permitidas aqui"},
+		{"string não terminada", `{ a(b: "x) }`, "string não terminada"},
+		{"escape inválido", `{ a(b: "\q") }`, "escape inválido"},
+		{"caractere inesperado", "{ a ? }", "caractere inesperado"},
+		{"número inválido", "{ a(b: 1.) }", "número inválido"},
+		{"posição do erro", "{\n  a(b: ) }", "linha 2, coluna 8"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := Parse(test.query)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, esperado erro", test.query, document)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("erro = %q, esperado conter %q", err.Error(), test.want)
+			}
+		})
+	}
+}
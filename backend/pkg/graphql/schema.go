@@ -0,0 +1,220 @@
+// Package graphql é um executor GraphQL enxuto para consultas (query) sobre tipos objeto e escalares.
+//
+// Em vez de resolver cada campo objeto a objeto, o executor resolve um campo de uma vez para todos os
+// objetos irmãos do mesmo nível (BatchResolve). Uma lista de 50 compras com seus itens e produtos
+// custa, assim, uma busca por nível e não uma por compra (o padrão N+1).
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Escalares embutidos
+const (
+	ID      = "ID"
+	String  = "String"
+	Int     = "Int"
+	Float   = "Float"
+	Boolean = "Boolean"
+)
+
+var builtinScalars = map[string]bool{ID: true, String: true, Int: true, Float: true, Boolean: true}
+
+// TypeRef é uma referência de tipo: nome, lista ([Elem]) e obrigatoriedade (!)
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+// ParseType interpreta uma referência como "[Product!]!"
+func ParseType(text string) (*TypeRef, error) {
+	parser := &parser{lexer: &lexer{source: text}}
+	var typeRef *TypeRef
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				syntaxErr, ok := recovered.(syntaxError)
+				if !ok {
+					panic(recovered)
+				}
+				err = syntaxErr
+			}
+		}()
+		parser.next()
+		typeRef = parser.parseType()
+		if parser.token.kind != tokenEOF {
+			parser.fail("tipo inválido")
+		}
+		return nil
+	}()
+	return typeRef, err
+}
+
+func (typeRef *TypeRef) String() string {
+	text := typeRef.Name
+	if typeRef.Elem != nil {
+		text = "[" + typeRef.Elem.String() + "]"
+	}
+	if typeRef.NonNull {
+		text += "!"
+	}
+	return text
+}
+
+// NamedType retorna o nome do tipo sem lista e obrigatoriedade
+func (typeRef *TypeRef) NamedType() string {
+	for typeRef.Elem != nil {
+		typeRef = typeRef.Elem
+	}
+	return typeRef.Name
+}
+
+// ResolveFunc resolve o campo para um objeto
+type ResolveFunc func(ctx context.Context, source any, args map[string]any) (any, error)
+
+// BatchResolveFunc resolve o campo para todos os objetos do nível de uma vez. O resultado deve ter
+// um valor por objeto, na mesma ordem; um valor do tipo error vira erro apenas daquele objeto.
+type BatchResolveFunc func(ctx context.Context, sources []any, args map[string]any) ([]any, error)
+
+// Object é um tipo objeto do schema
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*FieldDef
+	fields      map[string]*FieldDef
+}
+
+// FieldDef define um campo. Sem Resolve nem BatchResolve, o valor é lido do objeto de origem
+// (chave do map, campo com a tag json correspondente ou campo com o mesmo nome, sem diferenciar maiúsculas).
+type FieldDef struct {
+	Name         string
+	Type         string
+	Description  string
+	Args         []*ArgumentDef
+	Resolve      ResolveFunc
+	BatchResolve BatchResolveFunc
+
+	typeRef *TypeRef
+}
+
+// ArgumentDef define um argumento de campo
+type ArgumentDef struct {
+	Name    string
+	Type    string
+	Default any
+
+	typeRef *TypeRef
+}
+
+// Schema reúne o tipo Query e os demais tipos objeto
+type Schema struct {
+	query   *Object
+	objects map[string]*Object
+
+	// MaxDepth limita o aninhamento das seleções, contando cada fragmento nomeado como um nível (0 = sem limite)
+	MaxDepth int
+	// MaxComplexity limita o número de campos da consulta com os fragmentos expandidos (0 = sem limite)
+	MaxComplexity int
+}
+
+// NewSchema valida os tipos e monta o schema; query é o tipo raiz das consultas
+func NewSchema(query *Object, objects ...*Object) (*Schema, error) {
+	schema := &Schema{query: query, objects: make(map[string]*Object)}
+	for _, object := range append([]*Object{query}, objects...) {
+		if _, exists := schema.objects[object.Name]; exists || builtinScalars[object.Name] {
+			return nil, errors.New("tipo duplicado: " + object.Name)
+		}
+		schema.objects[object.Name] = object
+	}
+
+	for _, object := range schema.objects {
+		object.fields = make(map[string]*FieldDef, len(object.Fields))
+		for _, field := range object.Fields {
+			if _, exists := object.fields[field.Name]; exists {
+				return nil, fmt.Errorf("campo duplicado: %s.%s", object.Name, field.Name)
+			}
+			typeRef, err := schema.checkType(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", object.Name, field.Name, err)
+			}
+			field.typeRef = typeRef
+			for _, argument := range field.Args {
+				argumentType, err := schema.checkType(argument.Type)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s(%s): %w", object.Name, field.Name, argument.Name, err)
+				}
+				if _, isObject := schema.objects[argumentType.NamedType()]; isObject {
+					return nil, fmt.Errorf("%s.%s(%s): argumentos só aceitam escalares", object.Name, field.Name, argument.Name)
+				}
+				argument.typeRef = argumentType
+			}
+			object.fields[field.Name] = field
+		}
+	}
+	return schema, nil
+}
+
+func (schema *Schema) checkType(text string) (*TypeRef, error) {
+	typeRef, err := ParseType(text)
+	if err != nil {
+		return nil, err
+	}
+	name := typeRef.NamedType()
+	if _, isObject := schema.objects[name]; !isObject && !builtinScalars[name] {
+		return nil, errors.New("tipo desconhecido: " + name)
+	}
+	return typeRef, nil
+}
+
+// SDL descreve o schema na linguagem de definição do GraphQL
+func (schema *Schema) SDL() string {
+	names := make([]string, 0, len(schema.objects))
+	for name := range schema.objects {
+		if name != schema.query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range append([]string{schema.query.Name}, names...) {
+		object := schema.objects[name]
+		writeDescription(&builder, object.Description, "")
+		builder.WriteString("type " + object.Name + " {\n")
+		for _, field := range object.Fields {
+			writeDescription(&builder, field.Description, "  ")
+			builder.WriteString("  " + field.Name)
+			if len(field.Args) > 0 {
+				arguments := make([]string, len(field.Args))
+				for i, argument := range field.Args {
+					arguments[i] = argument.Name + ": " + argument.Type
+					if argument.Default != nil {
+						arguments[i] += " = " + formatDefault(argument.Default)
+					}
+				}
+				builder.WriteString("(" + strings.Join(arguments, ", ") + ")")
+			}
+			builder.WriteString(": " + field.Type + "\n")
+		}
+		builder.WriteString("}\n\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func writeDescription(builder *strings.Builder, description string, indent string) {
+	if description != "" {
+		builder.WriteString(indent + `"""` + description + `"""` + "\n")
+	}
+}
+
+func formatDefault(value any) string {
+	if text, ok := value.(string); ok {
+		return fmt.Sprintf("%q", text)
+	}
+	return fmt.Sprint(value)
+}
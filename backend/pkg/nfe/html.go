@@ -0,0 +1,135 @@
+package nfe
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Expressões do leiaute padrão da página de consulta pública da NFC-e (usado pela maioria das SEFAZ
+// e pela SVRS). A página não traz o GTIN dos itens, apenas o código interno do emitente.
+var (
+	htmlItemRow       = regexp.MustCompile(`(?s)<tr[^>]*id="Item\s*\+\s*\d+"[^>]*>(.*?)</tr>`)
+	htmlItemName      = regexp.MustCompile(`(?s)class="txtTit2?"[^>]*>(.*?)</span>`)
+	htmlItemCode      = regexp.MustCompile(`(?s)class="RCod"[^>]*>(.*?)</span>`)
+	htmlItemQuantity  = regexp.MustCompile(`(?s)class="Rqtd"[^>]*>(.*?)</span>`)
+	htmlItemUnit      = regexp.MustCompile(`(?s)class="RUN"[^>]*>(.*?)</span>`)
+	htmlItemUnitPrice = regexp.MustCompile(`(?s)class="RvlUnit"[^>]*>(.*?)</span>`)
+	htmlItemTotal     = regexp.MustCompile(`(?s)class="valor"[^>]*>(.*?)</span>`)
+	htmlStoreName     = regexp.MustCompile(`(?s)id="u20"[^>]*>(.*?)</div>`)
+	htmlStoreCNPJ     = regexp.MustCompile(`CNPJ:\s*([\d./-]+)`)
+	htmlIssueDate     = regexp.MustCompile(`Emiss[ãa]o:\s*</strong>\s*(\d{2}/\d{2}/\d{4}\s+\d{2}:\d{2}:\d{2})|Emiss[ãa]o:\s*(\d{2}/\d{2}/\d{4}\s+\d{2}:\d{2}:\d{2})`)
+	htmlAccessKey     = regexp.MustCompile(`(?s)class="chave"[^>]*>(.*?)</span>`)
+	htmlDiscount      = regexp.MustCompile(`(?s)Descontos\s*R\$:\s*</label>\s*<span[^>]*>(.*?)</span>`)
+	htmlTotal         = regexp.MustCompile(`(?s)Valor a pagar\s*R\$:\s*</label>\s*<span[^>]*>(.*?)</span>`)
+	htmlTags          = regexp.MustCompile(`<[^>]+>`)
+)
+
+// ParseConsultationHTML lê a página HTML de consulta pública de uma NFC-e. O desconto total da nota
+// é rateado entre os itens proporcionalmente ao valor de cada um.
+func ParseConsultationHTML(page []byte) (*Invoice, error) {
+	content := string(page)
+
+	invoice := &Invoice{
+		Model:     ModelNFCe,
+		StoreName: firstMatch(htmlStoreName, content),
+		StoreCNPJ: onlyDigits(firstMatch(htmlStoreCNPJ, content)),
+		AccessKey: onlyDigits(firstMatch(htmlAccessKey, content)),
+	}
+	invoice.LegalName = invoice.StoreName
+	if invoice.StoreName == "" {
+		return nil, errors.New("página de consulta sem os dados do emitente")
+	}
+
+	issuedAt, err := time.ParseInLocation("02/01/2006 15:04:05", firstMatch(htmlIssueDate, content), time.Local)
+	if err != nil {
+		return nil, errors.New("página de consulta sem data de emissão válida")
+	}
+	invoice.IssuedAt = issuedAt
+
+	for i, row := range htmlItemRow.FindAllStringSubmatch(content, -1) {
+		item, err := convertHTMLItem(i+1, row[1])
+		if err != nil {
+			return nil, err
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+	if len(invoice.Items) == 0 {
+		return nil, errors.New("página de consulta sem itens")
+	}
+
+	invoice.Discount, _ = utils.ParseDecimal(firstMatch(htmlDiscount, content))
+	invoice.Total, _ = utils.ParseDecimal(firstMatch(htmlTotal, content))
+	distributeDiscount(invoice)
+	return invoice, nil
+}
+
+// convertHTMLItem converte uma linha da tabela de itens
+func convertHTMLItem(number int, row string) (Item, error) {
+	item := Item{
+		Number:      number,
+		Description: firstMatch(htmlItemName, row),
+		Code:        strings.TrimSpace(strings.TrimSuffix(afterColon(firstMatch(htmlItemCode, row)), ")")),
+		Unit:        afterColon(firstMatch(htmlItemUnit, row)),
+	}
+
+	var err error
+	if item.Quantity, err = utils.ParseDecimal(afterColon(firstMatch(htmlItemQuantity, row))); err != nil || item.Quantity <= 0 {
+		return Item{}, itemError(number, "Qtde.")
+	}
+	if item.UnitPrice, err = utils.ParseDecimal(afterColon(firstMatch(htmlItemUnitPrice, row))); err != nil {
+		return Item{}, itemError(number, "Vl. Unit.")
+	}
+	if item.GrossTotal, err = utils.ParseDecimal(firstMatch(htmlItemTotal, row)); err != nil {
+		return Item{}, itemError(number, "Vl. Total")
+	}
+	return item, nil
+}
+
+// distributeDiscount rateia o desconto da nota entre os itens (o último item absorve o arredondamento)
+func distributeDiscount(invoice *Invoice) {
+	if invoice.Discount <= 0 {
+		return
+	}
+	var gross float64
+	for _, item := range invoice.Items {
+		gross += item.GrossTotal
+	}
+	if gross <= 0 {
+		return
+	}
+
+	remaining := invoice.Discount
+	for i := range invoice.Items {
+		share := remaining
+		if i < len(invoice.Items)-1 {
+			share = utils.FormatForDisplay(invoice.Discount * invoice.Items[i].GrossTotal / gross)
+		}
+		invoice.Items[i].Discount = share
+		remaining = utils.FormatForDisplay(remaining - share)
+	}
+}
+
+// firstMatch retorna o texto do primeiro grupo capturado não vazio, sem tags e entidades HTML
+func firstMatch(expression *regexp.Regexp, content string) string {
+	match := expression.FindStringSubmatch(content)
+	for _, group := range match[min(1, len(match)):] {
+		if group != "" {
+			text := html.UnescapeString(htmlTags.ReplaceAllString(group, " "))
+			return strings.Join(strings.Fields(text), " ")
+		}
+	}
+	return ""
+}
+
+// afterColon descarta o rótulo ("Qtde.: 2" -> "2")
+func afterColon(text string) string {
+	if index := strings.LastIndex(text, ":"); index >= 0 {
+		text = text[index+1:]
+	}
+	return strings.TrimSpace(text)
+}
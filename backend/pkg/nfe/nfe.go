@@ -0,0 +1,288 @@
+// Package nfe lê o XML de notas fiscais eletrônicas brasileiras (NF-e modelo 55 e NFC-e modelo 65),
+// extraindo emitente, data de emissão, itens e descontos.
+package nfe
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Modelos de documento fiscal suportados
+const (
+	ModelNFe  = "55"
+	ModelNFCe = "65"
+)
+
+// AccessKeyLength é o número de dígitos da chave de acesso
+const AccessKeyLength = 44
+
+// Layouts de data de emissão: dhEmi (versões 3.10+) e dEmi (versão 2.00)
+var issueDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Invoice é a nota fiscal já convertida
+type Invoice struct {
+	AccessKey string
+	Model     string
+	Number    string
+	Series    string
+	IssuedAt  time.Time
+	StoreCNPJ string
+	StoreName string // Nome fantasia quando informado, senão razão social
+	LegalName string
+	Items     []Item
+	Discount  float64 // Desconto total da nota
+	Total     float64 // Valor total da nota (vNF)
+}
+
+// Item é um item (det) da nota
+type Item struct {
+	Number      int
+	Code        string // Código interno do emitente (cProd)
+	Barcode     string // GTIN do item; vazio quando "SEM GTIN"
+	Description string
+	Quantity    float64
+	Unit        string
+	UnitPrice   float64 // Preço unitário bruto (vUnCom)
+	GrossTotal  float64 // Valor bruto (vProd)
+	Discount    float64 // Desconto do item (vDesc)
+}
+
+// NetTotal retorna o valor do item descontado
+func (item Item) NetTotal() float64 {
+	return item.GrossTotal - item.Discount
+}
+
+// NetUnitPrice retorna o preço unitário efetivamente pago (com desconto rateado)
+func (item Item) NetUnitPrice() float64 {
+	if item.Quantity <= 0 {
+		return item.UnitPrice
+	}
+	return item.NetTotal() / item.Quantity
+}
+
+// Estruturas do XML. O namespace do portal fiscal é ignorado: os elementos são casados pelo nome local.
+type xmlDocument struct {
+	XMLName xml.Name
+	NFe     *xmlNFe     `xml:"NFe"`     // Raiz nfeProc
+	InfNFe  *xmlInfNFe  `xml:"infNFe"`  // Raiz NFe
+	ProtNFe *xmlProtNFe `xml:"protNFe"` // Protocolo de autorização (nfeProc)
+}
+
+type xmlNFe struct {
+	InfNFe xmlInfNFe `xml:"infNFe"`
+}
+
+type xmlProtNFe struct {
+	ChNFe string `xml:"infProt>chNFe"`
+}
+
+type xmlInfNFe struct {
+	ID  string `xml:"Id,attr"`
+	Ide struct {
+		Mod   string `xml:"mod"`
+		Serie string `xml:"serie"`
+		NNF   string `xml:"nNF"`
+		DhEmi string `xml:"dhEmi"`
+		DEmi  string `xml:"dEmi"`
+	} `xml:"ide"`
+	Emit struct {
+		CNPJ  string `xml:"CNPJ"`
+		CPF   string `xml:"CPF"`
+		XNome string `xml:"xNome"`
+		XFant string `xml:"xFant"`
+	} `xml:"emit"`
+	Det   []xmlDet `xml:"det"`
+	Total struct {
+		VNF   string `xml:"ICMSTot>vNF"`
+		VDesc string `xml:"ICMSTot>vDesc"`
+	} `xml:"total"`
+}
+
+type xmlDet struct {
+	NItem string `xml:"nItem,attr"`
+	Prod  struct {
+		CProd    string `xml:"cProd"`
+		CEAN     string `xml:"cEAN"`
+		CEANTrib string `xml:"cEANTrib"`
+		XProd    string `xml:"xProd"`
+		UCom     string `xml:"uCom"`
+		QCom     string `xml:"qCom"`
+		VUnCom   string `xml:"vUnCom"`
+		VProd    string `xml:"vProd"`
+		VDesc    string `xml:"vDesc"`
+	} `xml:"prod"`
+}
+
+// Parse lê o XML de uma NF-e/NFC-e (raiz nfeProc ou NFe) e valida a chave de acesso
+func Parse(reader io.Reader) (*Invoice, error) {
+	var document xmlDocument
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, errors.New("XML inválido: " + err.Error())
+	}
+
+	var info *xmlInfNFe
+	switch {
+	case document.NFe != nil:
+		info = &document.NFe.InfNFe
+	case document.InfNFe != nil:
+		info = document.InfNFe
+	default:
+		return nil, errors.New("documento não é uma NF-e/NFC-e: elemento infNFe não encontrado")
+	}
+
+	accessKey := strings.TrimPrefix(strings.TrimSpace(info.ID), "NFe")
+	if accessKey == "" && document.ProtNFe != nil {
+		accessKey = strings.TrimSpace(document.ProtNFe.ChNFe)
+	}
+	if err := ValidateAccessKey(accessKey); err != nil {
+		return nil, err
+	}
+
+	invoice := &Invoice{
+		AccessKey: accessKey,
+		Model:     info.Ide.Mod,
+		Number:    info.Ide.NNF,
+		Series:    info.Ide.Serie,
+		StoreCNPJ: onlyDigits(info.Emit.CNPJ),
+		LegalName: strings.TrimSpace(info.Emit.XNome),
+		StoreName: strings.TrimSpace(info.Emit.XFant),
+	}
+	if invoice.StoreCNPJ == "" {
+		invoice.StoreCNPJ = onlyDigits(info.Emit.CPF)
+	}
+	if invoice.StoreName == "" {
+		invoice.StoreName = invoice.LegalName
+	}
+	if invoice.Model != "" && invoice.Model != ModelNFe && invoice.Model != ModelNFCe {
+		return nil, errors.New("modelo de documento não suportado: " + invoice.Model)
+	}
+
+	issuedAt, err := parseIssueDate(info.Ide.DhEmi, info.Ide.DEmi)
+	if err != nil {
+		return nil, err
+	}
+	invoice.IssuedAt = issuedAt
+
+	if len(info.Det) == 0 {
+		return nil, errors.New("nota fiscal sem itens")
+	}
+	for i, det := range info.Det {
+		item, err := convertItem(i, det)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+
+	invoice.Total, _ = parseNumber(info.Total.VNF)
+	invoice.Discount, _ = parseNumber(info.Total.VDesc)
+	return invoice, nil
+}
+
+// convertItem converte um elemento det, validando os campos numéricos obrigatórios
+func convertItem(index int, det xmlDet) (Item, error) {
+	item := Item{
+		Number:      index + 1,
+		Code:        strings.TrimSpace(det.Prod.CProd),
+		Barcode:     normalizeGTIN(det.Prod.CEAN),
+		Description: strings.TrimSpace(det.Prod.XProd),
+		Unit:        strings.TrimSpace(det.Prod.UCom),
+	}
+	if number, err := strconv.Atoi(det.NItem); err == nil {
+		item.Number = number
+	}
+	if item.Barcode == "" {
+		item.Barcode = normalizeGTIN(det.Prod.CEANTrib)
+	}
+
+	var err error
+	if item.Quantity, err = parseNumber(det.Prod.QCom); err != nil || item.Quantity <= 0 {
+		return Item{}, itemError(item.Number, "qCom")
+	}
+	if item.UnitPrice, err = parseNumber(det.Prod.VUnCom); err != nil {
+		return Item{}, itemError(item.Number, "vUnCom")
+	}
+	if item.GrossTotal, err = parseNumber(det.Prod.VProd); err != nil {
+		return Item{}, itemError(item.Number, "vProd")
+	}
+	if det.Prod.VDesc != "" {
+		if item.Discount, err = parseNumber(det.Prod.VDesc); err != nil {
+			return Item{}, itemError(item.Number, "vDesc")
+		}
+	}
+	return item, nil
+}
+
+func itemError(number int, field string) error {
+	return errors.New("item " + strconv.Itoa(number) + ": campo " + field + " inválido")
+}
+
+// ValidateAccessKey confere o tamanho da chave de acesso e o dígito verificador (módulo 11)
+func ValidateAccessKey(accessKey string) error {
+	if len(accessKey) != AccessKeyLength || onlyDigits(accessKey) != accessKey {
+		return errors.New("chave de acesso inválida: deve ter 44 dígitos")
+	}
+
+	sum, weight := 0, 2
+	for i := AccessKeyLength - 2; i >= 0; i-- {
+		sum += int(accessKey[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	checkDigit := 11 - sum%11
+	if checkDigit >= 10 {
+		checkDigit = 0
+	}
+	if int(accessKey[AccessKeyLength-1]-'0') != checkDigit {
+		return errors.New("chave de acesso inválida: dígito verificador não confere")
+	}
+	return nil
+}
+
+// parseIssueDate converte dhEmi (com fuso) ou, na falta dele, dEmi
+func parseIssueDate(values ...string) (time.Time, error) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		for _, layout := range issueDateLayouts {
+			if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, errors.New("data de emissão inválida: " + value)
+	}
+	return time.Time{}, errors.New("data de emissão ausente")
+}
+
+// parseNumber converte números do XML fiscal, que sempre usam ponto como separador decimal
+func parseNumber(value string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(value), 64)
+}
+
+// normalizeGTIN descarta "SEM GTIN" e valores não numéricos
+func normalizeGTIN(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || onlyDigits(value) != value {
+		return ""
+	}
+	return value
+}
+
+// onlyDigits remove tudo o que não é dígito
+func onlyDigits(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,105 @@
+package nfe
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// QRCode é o conteúdo do QR Code de uma NFC-e: a URL de consulta da SEFAZ e a chave de acesso
+type QRCode struct {
+	URL         string
+	AccessKey   string
+	StateCode   string // Código IBGE da UF (dois primeiros dígitos da chave)
+	State       string // Sigla da UF
+	Version     string // Versão do QR Code (2 ou 3)
+	Environment string // 1 = produção, 2 = homologação
+}
+
+// stateConsultation lista, por código IBGE da UF, a sigla e os domínios aceitos para a consulta pública.
+// Estados atendidos pela SEFAZ Virtual do RS também aceitam o domínio da SVRS.
+var stateConsultation = map[string]struct {
+	State   string
+	Domains []string
+}{
+	"11": {"RO", []string{"sefin.ro.gov.br", "svrs.rs.gov.br"}},
+	"12": {"AC", []string{"sefaznet.ac.gov.br", "svrs.rs.gov.br"}},
+	"13": {"AM", []string{"sefaz.am.gov.br"}},
+	"14": {"RR", []string{"sefaz.rr.gov.br", "svrs.rs.gov.br"}},
+	"15": {"PA", []string{"sefa.pa.gov.br"}},
+	"16": {"AP", []string{"sefaz.ap.gov.br", "svrs.rs.gov.br"}},
+	"17": {"TO", []string{"sefaz.to.gov.br", "svrs.rs.gov.br"}},
+	"21": {"MA", []string{"sefaz.ma.gov.br"}},
+	"22": {"PI", []string{"sefaz.pi.gov.br", "svrs.rs.gov.br"}},
+	"23": {"CE", []string{"sefaz.ce.gov.br"}},
+	"24": {"RN", []string{"set.rn.gov.br", "svrs.rs.gov.br"}},
+	"25": {"PB", []string{"receita.pb.gov.br", "sefaz.pb.gov.br", "svrs.rs.gov.br"}},
+	"26": {"PE", []string{"sefaz.pe.gov.br"}},
+	"27": {"AL", []string{"sefaz.al.gov.br", "svrs.rs.gov.br"}},
+	"28": {"SE", []string{"sefaz.se.gov.br", "svrs.rs.gov.br"}},
+	"29": {"BA", []string{"sefaz.ba.gov.br"}},
+	"31": {"MG", []string{"fazenda.mg.gov.br"}},
+	"32": {"ES", []string{"sefaz.es.gov.br", "svrs.rs.gov.br"}},
+	"33": {"RJ", []string{"fazenda.rj.gov.br", "svrs.rs.gov.br"}},
+	"35": {"SP", []string{"fazenda.sp.gov.br"}},
+	"41": {"PR", []string{"fazenda.pr.gov.br"}},
+	"42": {"SC", []string{"sef.sc.gov.br", "svrs.rs.gov.br"}},
+	"43": {"RS", []string{"sefaz.rs.gov.br", "svrs.rs.gov.br"}},
+	"50": {"MS", []string{"fazenda.ms.gov.br"}},
+	"51": {"MT", []string{"sefaz.mt.gov.br"}},
+	"52": {"GO", []string{"sefaz.go.gov.br"}},
+	"53": {"DF", []string{"fazenda.df.gov.br", "svrs.rs.gov.br"}},
+}
+
+// ParseQRCodeURL valida a URL do QR Code de uma NFC-e: esquema http(s), parâmetro p com a chave de acesso
+// válida e domínio da SEFAZ correspondente à UF da chave
+func ParseQRCodeURL(rawURL string) (*QRCode, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("URL do QR Code inválida")
+	}
+
+	// Formato do parâmetro p: chave|versão|ambiente|... (os demais campos variam entre emissão online e offline)
+	fields := strings.Split(parsed.Query().Get("p"), "|")
+	qrCode := &QRCode{URL: parsed.String(), AccessKey: strings.TrimSpace(fields[0])}
+	if len(fields) > 1 {
+		qrCode.Version = fields[1]
+	}
+	if len(fields) > 2 {
+		qrCode.Environment = fields[2]
+	}
+	if qrCode.AccessKey == "" {
+		return nil, errors.New("URL do QR Code sem o parâmetro p com a chave de acesso")
+	}
+	if err := ValidateAccessKey(qrCode.AccessKey); err != nil {
+		return nil, err
+	}
+	if model := qrCode.AccessKey[20:22]; model != ModelNFCe {
+		return nil, errors.New("a chave de acesso não é de uma NFC-e (modelo " + model + ")")
+	}
+
+	qrCode.StateCode = qrCode.AccessKey[:2]
+	consultation, ok := stateConsultation[qrCode.StateCode]
+	if !ok {
+		return nil, errors.New("UF da chave de acesso desconhecida: " + qrCode.StateCode)
+	}
+	qrCode.State = consultation.State
+
+	host := strings.ToLower(parsed.Hostname())
+	if !qrCode.AllowsHost(host) {
+		return nil, errors.New("o domínio " + host + " não é um portal de consulta de NFC-e de " + consultation.State)
+	}
+	return qrCode, nil
+}
+
+// AllowsHost indica se o host é um portal de consulta da UF da nota (o domínio ou um subdomínio dele).
+// Vale também para os destinos de redirecionamento durante a consulta.
+func (qrCode *QRCode) AllowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range stateConsultation[qrCode.StateCode].Domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
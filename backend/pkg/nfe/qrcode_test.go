@@ -0,0 +1,56 @@
+package nfe
+
+import (
+	"strings"
+	"testing"
+)
+
+// Chave de acesso válida de uma NFC-e de SP (modelo 65)
+const testAccessKey = "35250112345678000195650010000001231123456786"
+
+func TestParseQRCodeURL(t *testing.T) {
+	qrCode, err := ParseQRCodeURL("https://www.nfce.fazenda.sp.gov.br/qrcode?p=" + testAccessKey + "|2|1|1|0A1B2C")
+	if err != nil {
+		t.Fatalf("ParseQRCodeURL: %v", err)
+	}
+	if qrCode.AccessKey != testAccessKey || qrCode.StateCode != "35" || qrCode.State != "SP" ||
+		qrCode.Version != "2" || qrCode.Environment != "1" {
+		t.Errorf("QR Code = %+v", qrCode)
+	}
+
+	// Estados atendidos pela SVRS também aceitam o domínio dela
+	svrsKey := "53250112345678000195650010000001231123456784" // DF
+	if _, err := ParseQRCodeURL("https://dec.fazenda.df.gov.br/nfce?p=" + svrsKey); err != nil {
+		t.Errorf("domínio da UF: %v", err)
+	}
+	if _, err := ParseQRCodeURL("https://www.svrs.rs.gov.br/nfce?p=" + svrsKey); err != nil {
+		t.Errorf("domínio da SVRS: %v", err)
+	}
+}
+
+func TestParseQRCodeURLErrors(t *testing.T) {
+	nfeKey := "35250112345678000195550010000001231123456783" // Modelo 55
+	tests := []struct {
+		name      string
+		url       string
+		wantError string
+	}{
+		{"esquema", "ftp://www.nfce.fazenda.sp.gov.br/?p=" + testAccessKey, "URL do QR Code inválida"},
+		{"sem o parâmetro p", "https://www.nfce.fazenda.sp.gov.br/qrcode", "sem o parâmetro p"},
+		{"chave curta", "https://www.nfce.fazenda.sp.gov.br/qrcode?p=3525011234", "deve ter 44 dígitos"},
+		{"dígito verificador", "https://www.nfce.fazenda.sp.gov.br/qrcode?p=" + testAccessKey[:43] + "0", "dígito verificador não confere"},
+		{"NF-e modelo 55", "https://www.nfce.fazenda.sp.gov.br/qrcode?p=" + nfeKey, "não é de uma NFC-e"},
+		{"domínio de outra UF", "https://www.sefaz.rs.gov.br/qrcode?p=" + testAccessKey, "o domínio www.sefaz.rs.gov.br não é um portal de consulta de NFC-e de SP"},
+		{"SVRS para UF sem convênio", "https://www.svrs.rs.gov.br/qrcode?p=" + testAccessKey, "não é um portal de consulta de NFC-e de SP"},
+		{"sufixo sem ponto", "https://falsofazenda.sp.gov.br/qrcode?p=" + testAccessKey, "não é um portal de consulta de NFC-e de SP"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseQRCodeURL(test.url)
+			if err == nil || !strings.Contains(err.Error(), test.wantError) {
+				t.Fatalf("erro = %v, esperado conter %q", err, test.wantError)
+			}
+		})
+	}
+}
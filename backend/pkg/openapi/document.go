@@ -0,0 +1,318 @@
+// Package openapi monta a especificação OpenAPI 3 da API a partir de uma tabela de rotas,
+// descrevendo corpos, parâmetros e respostas por reflexão sobre as structs dos DTOs
+// (tags json/form, regras de binding e exemplos da tag example).
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// Route descreve uma rota da API
+type Route struct {
+	Method      string
+	Path        string // Caminho no formato do Gin (ex.: /products/:id)
+	Tag         string
+	Summary     string
+	Description string
+	Public      bool // Não exige token
+	Admin       bool // Apenas administradores
+
+	Query     any      // Struct com tags form lidas da query string
+	Body      any      // Corpo JSON
+	Form      any      // Struct com os campos de um multipart/form-data
+	FileField string   // Campo de arquivo do multipart/form-data
+	RawBody   []string // Content types aceitos também como corpo bruto (ex.: application/xml)
+
+	Status   int    // Status da resposta de sucesso (padrão: 200)
+	Response any    // Corpo JSON da resposta de sucesso (Object, DTO ou lista de DTOs)
+	Download string // Content type da resposta quando ela é um arquivo
+	Errors   []int  // Status de erro além dos deduzidos da rota (400, 401, 403 e 404)
+}
+
+// Object descreve um objeto JSON montado no handler (gin.H) campo a campo
+type Object map[string]any
+
+// OneOf descreve um corpo que assume uma das formas listadas
+type OneOf []any
+
+// Info identifica a API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document é a especificação OpenAPI
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Tag agrupa as operações na interface
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components guarda os schemas referenciados e o esquema de autenticação
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme descreve a autenticação por token
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Operation é uma operação de um caminho
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter é um parâmetro de caminho ou de query string
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody é o corpo aceito pela operação
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response é uma resposta da operação
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType associa um content type ao schema do corpo
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+const (
+	bearerScheme    = "bearerAuth"
+	errorSchemaName = "ErrorResponse"
+)
+
+// ErrorResponse é o corpo de erro padrão dos handlers
+type ErrorResponse struct {
+	Error string `json:"error" example:"mensagem de erro"`
+}
+
+var errorDescriptions = map[int]string{
+	http.StatusBadRequest:            "Requisição inválida",
+	http.StatusUnauthorized:          "Token ausente ou inválido",
+	http.StatusForbidden:             "Permissão negada",
+	http.StatusNotFound:              "Recurso não encontrado",
+	http.StatusConflict:              "Conflito com um registro existente",
+	http.StatusRequestEntityTooLarge: "Arquivo maior que o permitido",
+	http.StatusUnprocessableEntity:   "Dados recusados pela validação de negócio",
+	http.StatusBadGateway:            "Falha ao consultar um serviço externo",
+}
+
+// Build monta o documento a partir da tabela de rotas
+func Build(info Info, tags []Tag, routes []Route) *Document {
+	registry := newSchemaRegistry()
+	registry.schemaFor(reflect.TypeOf(ErrorResponse{}))
+
+	document := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Tags:    tags,
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: registry.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		path := PathFromGin(route.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]*Operation)
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = registry.operation(route)
+	}
+	return document
+}
+
+// Has indica se a operação (método e caminho no formato do Gin) está documentada
+func (document *Document) Has(method string, ginPath string) bool {
+	_, ok := document.Paths[PathFromGin(ginPath)][strings.ToLower(method)]
+	return ok
+}
+
+// Operations lista as operações documentadas como "MÉTODO /caminho" no formato do Gin
+func (document *Document) Operations() []string {
+	var operations []string
+	for path, methods := range document.Paths {
+		for method := range methods {
+			operations = append(operations, strings.ToUpper(method)+" "+GinPath(path))
+		}
+	}
+	slices.Sort(operations)
+	return operations
+}
+
+// PathFromGin converte os parâmetros do Gin (:id, *path) para o formato do OpenAPI ({id})
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// GinPath faz a conversão inversa de PathFromGin
+func GinPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.Trim(segment, "{}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (registry *schemaRegistry) operation(route Route) *Operation {
+	operation := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(route.Method, route.Path),
+		Responses:   make(map[string]*Response),
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+	if route.Admin {
+		operation.Description = strings.TrimSpace("Apenas administradores. " + operation.Description)
+	}
+	if !route.Public {
+		operation.Security = []map[string][]string{{bearerScheme: {}}}
+	}
+
+	errors := slices.Clone(route.Errors)
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name: segment[1:], In: "path", Required: true, Schema: &Schema{Type: "integer", Minimum: ptr(1.0)},
+			})
+			errors = append(errors, http.StatusBadRequest, http.StatusNotFound)
+		}
+	}
+	if route.Query != nil {
+		for _, field := range structFields(reflect.TypeOf(route.Query), "form") {
+			schema := registry.schemaFor(field.goType)
+			applyTags(schema, field)
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name: field.name, In: "query", Required: field.required, Schema: schema,
+			})
+		}
+		errors = append(errors, http.StatusBadRequest)
+	}
+
+	if content := registry.requestContent(route); len(content) > 0 {
+		operation.RequestBody = &RequestBody{Required: true, Content: content}
+		errors = append(errors, http.StatusBadRequest)
+	}
+
+	operation.Responses[strconv.Itoa(successStatus(route))] = registry.successResponse(route)
+
+	if !route.Public {
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	if route.Admin {
+		errors = append(errors, http.StatusForbidden)
+	}
+	for _, status := range errors {
+		description, ok := errorDescriptions[status]
+		if !ok {
+			description = http.StatusText(status)
+		}
+		operation.Responses[strconv.Itoa(status)] = &Response{
+			Description: description,
+			Content: map[string]*MediaType{
+				"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + errorSchemaName}},
+			},
+		}
+	}
+	return operation
+}
+
+func (registry *schemaRegistry) requestContent(route Route) map[string]*MediaType {
+	content := make(map[string]*MediaType)
+	if route.Body != nil {
+		content["application/json"] = &MediaType{Schema: registry.schemaFor(reflect.TypeOf(route.Body))}
+	}
+	if route.Form != nil || route.FileField != "" {
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		if route.Form != nil {
+			schema = registry.structSchema(reflect.TypeOf(route.Form), "form")
+		}
+		if route.FileField != "" {
+			schema.Properties[route.FileField] = &Schema{Type: "string", Format: "binary"}
+			schema.Required = append(schema.Required, route.FileField)
+		}
+		content["multipart/form-data"] = &MediaType{Schema: schema}
+	}
+	for _, contentType := range route.RawBody {
+		content[contentType] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	return content
+}
+
+func (registry *schemaRegistry) successResponse(route Route) *Response {
+	response := &Response{Description: http.StatusText(successStatus(route))}
+	switch {
+	case route.Download != "":
+		response.Content = map[string]*MediaType{
+			route.Download: {Schema: &Schema{Type: "string", Format: "binary"}},
+		}
+	case route.Response != nil:
+		response.Content = map[string]*MediaType{
+			"application/json": {Schema: registry.schemaForValue(route.Response)},
+		}
+	}
+	return response
+}
+
+func successStatus(route Route) int {
+	if route.Status == 0 {
+		return http.StatusOK
+	}
+	return route.Status
+}
+
+// operationID gera um identificador estável a partir do método e do caminho (ex.: get_products_id_statistics)
+func operationID(method string, path string) string {
+	replacer := strings.NewReplacer("/", "_", ":", "", "*", "", "-", "_", ".", "_")
+	return strings.ToLower(method) + strings.TrimRight(replacer.Replace(path), "_")
+}
@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema é um objeto Schema do OpenAPI 3.0 (apenas os campos usados pela API)
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry gera os schemas a partir dos tipos Go; structs nomeadas viram componentes
+// referenciados por $ref, structs anônimas são descritas no próprio local
+type schemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: make(map[string]*Schema)}
+}
+
+// schemaForValue descreve um valor do corpo da resposta; textos não vazios viram o exemplo do campo
+func (registry *schemaRegistry) schemaForValue(value any) *Schema {
+	if object, ok := value.(Object); ok {
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema, len(object))}
+		for name, fieldValue := range object {
+			schema.Properties[name] = registry.schemaForValue(fieldValue)
+			schema.Required = append(schema.Required, name)
+		}
+		slices.Sort(schema.Required)
+		return schema
+	}
+
+	if alternatives, ok := value.(OneOf); ok {
+		schema := &Schema{}
+		for _, alternative := range alternatives {
+			schema.OneOf = append(schema.OneOf, registry.schemaForValue(alternative))
+		}
+		return schema
+	}
+
+	schema := registry.schemaFor(reflect.TypeOf(value))
+	if text, ok := value.(string); ok && text != "" {
+		schema.Example = text
+	}
+	return schema
+}
+
+// schemaFor descreve um tipo Go
+func (registry *schemaRegistry) schemaFor(goType reflect.Type) *Schema {
+	if goType == nil {
+		return &Schema{}
+	}
+
+	switch goType {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{Description: "JSON livre"}
+	}
+
+	switch goType.Kind() {
+	case reflect.Pointer:
+		schema := registry.schemaFor(goType.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: ptr(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if goType.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: registry.schemaFor(goType.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: registry.schemaFor(goType.Elem())}
+	case reflect.Struct:
+		if goType.Name() == "" {
+			return registry.structSchema(goType, "json")
+		}
+		if _, exists := registry.schemas[goType.Name()]; !exists {
+			registry.schemas[goType.Name()] = &Schema{} // Reserva o nome antes de descer (tipos recursivos)
+			registry.schemas[goType.Name()] = registry.structSchema(goType, "json")
+		}
+		return &Schema{Ref: "#/components/schemas/" + goType.Name()}
+	}
+	return &Schema{}
+}
+
+// structSchema descreve os campos da struct usando a tag informada (json ou form) para os nomes
+func (registry *schemaRegistry) structSchema(goType reflect.Type, tagName string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range structFields(goType, tagName) {
+		fieldSchema := registry.schemaFor(field.goType)
+		if fieldSchema.Ref == "" {
+			applyTags(fieldSchema, field)
+		}
+		schema.Properties[field.name] = fieldSchema
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
+
+// structField é um campo exportado da struct com as tags já interpretadas
+type structField struct {
+	name     string
+	goType   reflect.Type
+	required bool
+	binding  string
+	example  string
+}
+
+// structFields lista os campos exportados (achatando structs embutidas) com nome vindo da tag informada
+func structFields(goType reflect.Type, tagName string) []structField {
+	for goType.Kind() == reflect.Pointer {
+		goType = goType.Elem()
+	}
+
+	var fields []structField
+	for i := 0; i < goType.NumField(); i++ {
+		field := goType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(field.Type, tagName)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		binding := field.Tag.Get("binding")
+		fields = append(fields, structField{
+			name:     name,
+			goType:   field.Type,
+			required: hasRule(binding, "required"),
+			binding:  binding,
+			example:  field.Tag.Get("example"),
+		})
+	}
+	return fields
+}
+
+// applyTags converte as regras de validação do binding e a tag example em restrições do schema
+func applyTags(schema *Schema, field structField) {
+	for _, rule := range strings.Split(field.binding, ",") {
+		name, argument, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return // As regras seguintes valem para os elementos
+		case "oneof":
+			for _, option := range strings.Fields(argument) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "numeric":
+			schema.Pattern = "^[0-9]+$"
+		case "gt", "gte", "min", "lte", "max", "len":
+			setLimit(schema, name, argument)
+		}
+	}
+
+	if field.example != "" {
+		schema.Example = parseExample(schema.Type, field.example)
+	}
+}
+
+// setLimit aplica min/max/gt/len ao campo de acordo com o tipo (tamanho de texto, itens ou valor)
+func setLimit(schema *Schema, rule string, argument string) {
+	value, err := strconv.ParseFloat(argument, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		switch rule {
+		case "min", "gte":
+			schema.MinLength = ptr(int(value))
+		case "max", "lte":
+			schema.MaxLength = ptr(int(value))
+		case "len":
+			schema.MinLength, schema.MaxLength = ptr(int(value)), ptr(int(value))
+		}
+	case "array":
+		if rule == "min" || rule == "gte" {
+			schema.MinItems = ptr(int(value))
+		}
+	case "integer", "number":
+		switch rule {
+		case "gt":
+			schema.Minimum, schema.ExclusiveMinimum = ptr(value), true
+		case "gte", "min":
+			schema.Minimum = ptr(value)
+		case "lte", "max":
+			schema.Maximum = ptr(value)
+		}
+	}
+}
+
+func parseExample(schemaType string, example string) any {
+	switch schemaType {
+	case "integer":
+		if value, err := strconv.ParseInt(example, 10, 64); err == nil {
+			return value
+		}
+	case "number":
+		if value, err := strconv.ParseFloat(example, 64); err == nil {
+			return value
+		}
+	case "boolean":
+		if value, err := strconv.ParseBool(example); err == nil {
+			return value
+		}
+	}
+	return example
+}
+
+func hasRule(binding string, rule string) bool {
+	for _, candidate := range strings.Split(binding, ",") {
+		if candidate == "dive" {
+			return false
+		}
+		if candidate == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,340 @@
+// Package openfoodfacts lê os dumps públicos do Open Food Facts (CSV separado por tab ou JSONL,
+// opcionalmente compactados com gzip) registro a registro, sem carregar o arquivo em memória.
+package openfoodfacts
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrMalformedRecord indica um registro malformado (aspas inválidas, quantidade de colunas diferente
+// do cabeçalho ou JSON inválido). A leitura pode continuar com o próximo registro; os demais erros de
+// Next (leitura do arquivo, gzip corrompido) são definitivos.
+var ErrMalformedRecord = errors.New("registro malformado")
+
+// Format é o formato do dump
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// Record é um produto do dump, com os campos usados pelo catálogo
+type Record struct {
+	Barcode   string
+	Name      string
+	Brand     string
+	Quantity  string // Tamanho/quantidade da embalagem (ex.: "1 L")
+	ImageURL  string
+	Countries []string // Tags de países (ex.: "en:brazil")
+}
+
+// DetectFormat identifica o formato pela extensão do arquivo (.csv/.tsv ou .jsonl/.json/.ndjson, com ou sem .gz)
+func DetectFormat(path string) (Format, error) {
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch {
+	case strings.HasSuffix(name, ".csv"), strings.HasSuffix(name, ".tsv"):
+		return FormatCSV, nil
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"), strings.HasSuffix(name, ".json"):
+		return FormatJSONL, nil
+	}
+	return "", errors.New("formato do dump não reconhecido: use .csv, .tsv ou .jsonl (opcionalmente .gz)")
+}
+
+// DumpReader lê um dump registro a registro.
+// Offset informa a posição (no conteúdo descompactado) logo após o último registro lido; em arquivos
+// não compactados ela pode ser usada para retomar a leitura com OpenDump.
+type DumpReader struct {
+	file       *os.File
+	size       int64
+	counter    *countingReader
+	compressed bool
+	format     Format
+
+	// CSV
+	csvReader  *csv.Reader
+	columns    map[string]int
+	fields     int // Colunas do cabeçalho, exigidas em todas as linhas
+	baseOffset int64
+
+	// JSONL
+	lineReader *bufio.Reader
+	lineOffset int64
+}
+
+// OpenDump abre o dump. Com resumeOffset > 0 a leitura começa nessa posição, o que só é possível
+// em arquivos não compactados (veja Seekable); nos compactados, use Skip.
+func OpenDump(path string, format Format, resumeOffset int64) (*DumpReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	reader := &DumpReader{
+		file:       file,
+		size:       info.Size(),
+		counter:    &countingReader{reader: file},
+		compressed: strings.HasSuffix(strings.ToLower(path), ".gz"),
+		format:     format,
+	}
+	if reader.compressed && resumeOffset > 0 {
+		file.Close()
+		return nil, errors.New("dumps compactados não permitem retomar por posição")
+	}
+
+	if err := reader.open(resumeOffset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// open prepara o decodificador do formato; o cabeçalho do CSV é sempre lido do início do arquivo
+func (reader *DumpReader) open(resumeOffset int64) error {
+	stream, err := reader.stream()
+	if err != nil {
+		return err
+	}
+
+	switch reader.format {
+	case FormatCSV:
+		reader.csvReader = newTabReader(stream, 0)
+		header, err := reader.csvReader.Read()
+		if err != nil {
+			return errors.New("cabeçalho do CSV inválido: " + err.Error())
+		}
+		reader.fields = len(header)
+		reader.csvReader.FieldsPerRecord = reader.fields
+		reader.columns = make(map[string]int, len(header))
+		for i, column := range header {
+			reader.columns[strings.TrimSpace(column)] = i
+		}
+		if _, ok := reader.columns["code"]; !ok {
+			return errors.New("o CSV não tem a coluna code")
+		}
+		if resumeOffset > 0 {
+			if err := reader.seek(resumeOffset); err != nil {
+				return err
+			}
+			reader.csvReader = newTabReader(reader.counter, reader.fields)
+			reader.baseOffset = resumeOffset
+		}
+	case FormatJSONL:
+		if resumeOffset > 0 {
+			if err := reader.seek(resumeOffset); err != nil {
+				return err
+			}
+			stream = reader.counter
+		}
+		reader.lineReader = bufio.NewReaderSize(stream, 1<<20)
+		reader.lineOffset = resumeOffset
+	default:
+		return errors.New("formato do dump não suportado: " + string(reader.format))
+	}
+	return nil
+}
+
+// stream devolve o conteúdo (descompactado, se necessário) a partir da posição atual do arquivo
+func (reader *DumpReader) stream() (io.Reader, error) {
+	if !reader.compressed {
+		return reader.counter, nil
+	}
+	return gzip.NewReader(reader.counter)
+}
+
+func (reader *DumpReader) seek(offset int64) error {
+	if _, err := reader.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader.counter.count = offset
+	return nil
+}
+
+// newTabReader cria o leitor do CSV separado por tab: aspas seguem a RFC 4180 (sem LazyQuotes, que
+// deixaria uma aspa solta engolir as linhas seguintes) e toda linha deve ter fields colunas (0 = as do
+// primeiro registro lido, o cabeçalho)
+func newTabReader(stream io.Reader, fields int) *csv.Reader {
+	csvReader := csv.NewReader(stream)
+	csvReader.Comma = '\t'
+	csvReader.LazyQuotes = false
+	csvReader.FieldsPerRecord = fields
+	csvReader.ReuseRecord = true
+	return csvReader
+}
+
+// Next lê o próximo registro; retorna io.EOF ao fim do arquivo.
+// Um registro malformado retorna ErrMalformedRecord, e a leitura pode continuar com a próxima chamada.
+func (reader *DumpReader) Next() (*Record, error) {
+	if reader.format == FormatCSV {
+		return reader.nextCSV()
+	}
+	return reader.nextJSONL()
+}
+
+func (reader *DumpReader) nextCSV() (*Record, error) {
+	fields, err := reader.csvReader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, fmt.Errorf("%w: linha %d: %v", ErrMalformedRecord, parseErr.StartLine, parseErr.Err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := func(column string) string {
+		index, ok := reader.columns[column]
+		if !ok || index >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[index])
+	}
+
+	record := &Record{
+		Barcode:  value("code"),
+		Name:     firstNonEmpty(value("product_name"), value("product_name_pt"), value("product_name_en"), value("generic_name")),
+		Brand:    firstBrand(value("brands")),
+		Quantity: value("quantity"),
+		ImageURL: firstNonEmpty(value("image_url"), value("image_front_url")),
+	}
+	if tags := value("countries_tags"); tags != "" {
+		record.Countries = strings.Split(tags, ",")
+	}
+	return record, nil
+}
+
+// jsonlProduct contém os campos lidos de cada linha do dump JSONL
+type jsonlProduct struct {
+	Code          string   `json:"code"`
+	ProductName   string   `json:"product_name"`
+	ProductNamePT string   `json:"product_name_pt"`
+	ProductNameEN string   `json:"product_name_en"`
+	GenericName   string   `json:"generic_name"`
+	Brands        string   `json:"brands"`
+	Quantity      string   `json:"quantity"`
+	ImageURL      string   `json:"image_url"`
+	ImageFrontURL string   `json:"image_front_url"`
+	CountriesTags []string `json:"countries_tags"`
+}
+
+func (reader *DumpReader) nextJSONL() (*Record, error) {
+	for {
+		line, err := reader.lineReader.ReadBytes('\n')
+		reader.lineOffset += int64(len(line))
+		if len(strings.TrimSpace(string(line))) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		var product jsonlProduct
+		if err := json.Unmarshal(line, &product); err != nil {
+			return nil, fmt.Errorf("%w: linha JSON inválida: %v", ErrMalformedRecord, err)
+		}
+		return &Record{
+			Barcode:   strings.TrimSpace(product.Code),
+			Name:      strings.TrimSpace(firstNonEmpty(product.ProductName, product.ProductNamePT, product.ProductNameEN, product.GenericName)),
+			Brand:     firstBrand(product.Brands),
+			Quantity:  strings.TrimSpace(product.Quantity),
+			ImageURL:  firstNonEmpty(product.ImageURL, product.ImageFrontURL),
+			Countries: product.CountriesTags,
+		}, nil
+	}
+}
+
+// Skip descarta os próximos count registros (retomada de dumps compactados)
+func (reader *DumpReader) Skip(count int64) error {
+	for i := int64(0); i < count; i++ {
+		if _, err := reader.Next(); err == io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// Seekable indica se a leitura pode ser retomada por posição (arquivo não compactado)
+func (reader *DumpReader) Seekable() bool {
+	return !reader.compressed
+}
+
+// Offset retorna a posição no conteúdo descompactado logo após o último registro lido
+func (reader *DumpReader) Offset() int64 {
+	if reader.format == FormatCSV {
+		return reader.baseOffset + reader.csvReader.InputOffset()
+	}
+	return reader.lineOffset
+}
+
+// BytesRead retorna quantos bytes do arquivo (compactado ou não) já foram lidos, para cálculo de progresso
+func (reader *DumpReader) BytesRead() int64 {
+	return reader.counter.count
+}
+
+// Size retorna o tamanho do arquivo em bytes
+func (reader *DumpReader) Size() int64 {
+	return reader.size
+}
+
+// Close fecha o arquivo
+func (reader *DumpReader) Close() error {
+	return reader.file.Close()
+}
+
+// MatchesCountry indica se o produto é vendido no país informado ("brazil", "en:brazil" ou "Brazil")
+func (record *Record) MatchesCountry(country string) bool {
+	if country == "" {
+		return true
+	}
+	wanted := strings.ToLower(strings.TrimSpace(country))
+	if !strings.Contains(wanted, ":") {
+		wanted = "en:" + strings.ReplaceAll(wanted, " ", "-")
+	}
+	for _, tag := range record.Countries {
+		if strings.ToLower(strings.TrimSpace(tag)) == wanted {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader conta os bytes lidos do arquivo
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (counter *countingReader) Read(buffer []byte) (int, error) {
+	n, err := counter.reader.Read(buffer)
+	counter.count += int64(n)
+	return n, err
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// firstBrand retorna a primeira marca da lista separada por vírgulas
+func firstBrand(brands string) string {
+	brand, _, _ := strings.Cut(brands, ",")
+	return strings.TrimSpace(brand)
+}
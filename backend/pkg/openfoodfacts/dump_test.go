@@ -0,0 +1,68 @@
+package openfoodfacts
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDumpSkipsMalformedRows confere que linhas com aspas inválidas ou com colunas a mais ou a menos
+// retornam ErrMalformedRecord sem interromper a leitura, e que um retorno pela posição mantém as regras
+func TestDumpSkipsMalformedRows(t *testing.T) {
+	dump := "code\tproduct_name\tbrands\tcountries_tags\n" +
+		"7891000100103\tLeite UHT\tItambé,Outra\ten:brazil\n" +
+		"7891000100110\tBiscoito \"recheado\tMarca\ten:brazil\n" +
+		"7891000100127\tSem coluna\ten:brazil\n" +
+		"7891000100134\tColuna\ta\tmais\ten:brazil\n" +
+		"7891000100141\t\"Café \"\"extra\"\" forte\"\tMarca\ten:brazil\n"
+	path := filepath.Join(t.TempDir(), "products.csv")
+	if err := os.WriteFile(path, []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := OpenDump(path, FormatCSV, 0)
+	if err != nil {
+		t.Fatalf("OpenDump: %v", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	malformed := 0
+	resumeOffset := int64(0)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, ErrMalformedRecord) {
+			malformed++
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		names = append(names, record.Name)
+		if len(names) == 1 {
+			resumeOffset = reader.Offset()
+		}
+	}
+
+	if len(names) != 2 || names[0] != "Leite UHT" || names[1] != "Café \"extra\" forte" {
+		t.Errorf("registros lidos = %q", names)
+	}
+	if malformed != 3 {
+		t.Errorf("%d linhas malformadas, esperado 3", malformed)
+	}
+
+	// Retomada logo após o primeiro registro: a quantidade de colunas continua sendo exigida
+	resumed, err := OpenDump(path, FormatCSV, resumeOffset)
+	if err != nil {
+		t.Fatalf("OpenDump com retomada: %v", err)
+	}
+	defer resumed.Close()
+	if _, err := resumed.Next(); !errors.Is(err, ErrMalformedRecord) {
+		t.Errorf("primeira linha após a retomada: err = %v, esperado ErrMalformedRecord", err)
+	}
+}
@@ -0,0 +1,174 @@
+package receipt
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Layout descreve como os itens aparecem em um modelo de cupom.
+// Os padrões usam grupos nomeados: code, desc, qty, unit, unitPrice e total (apenas desc é obrigatório).
+type Layout struct {
+	Name   string
+	Detect *regexp.Regexp // Reconhece o cupom de uma rede específica; nil para leiautes genéricos
+	Items  []ItemPattern
+}
+
+// ItemPattern casa um item que ocupa uma ou mais linhas consecutivas (uma expressão por linha)
+type ItemPattern struct {
+	Lines      []*regexp.Regexp
+	Confidence float64 // Confiança base dos campos extraídos por este padrão
+}
+
+// Fragmentos reutilizados pelos leiautes padrão
+const (
+	seqPart       = `(?:\d{1,3}\s+)?`
+	codePart      = `(?P<code>\d{3,14})\s+`
+	qtyPart       = `(?P<qty>\d+(?:[.,]\d{1,3})?)\s*`
+	unitPart      = `(?P<unit>UN|UND|KG|G|GR|L|LT|ML|PC|PCT|CX|FD|DZ|BD|SC)\b\.?\s*`
+	timesPart     = `[Xx*]\s*`
+	unitPricePart = `(?P<unitPrice>\d{1,3}(?:\.\d{3})+,\d{2,3}|\d+[.,]\d{2,3})` // Aceita separador de milhar: 1.234,56
+	taxPart       = `(?:\s+(?:\(\s*[\d.,]+\s*\)|[A-Z]{1,2}\d{0,2}%?|\d{1,2}[.,]\d{2}%))?`
+	totalPart     = `\s+(?P<total>\d{1,3}(?:\.\d{3})+,\d{2}|\d+[.,]\d{2})\s*[A-Z]?$`
+)
+
+// DefaultLayouts retorna os leiautes genéricos de cupom fiscal (SAT/ECF/NFC-e impressa):
+//
+//	001 7891000100103 LEITE UHT 1L 2 UN X 4,99 9,98
+//
+//	001 7891000100103 LEITE UHT INTEGRAL 1L
+//	    2 UN X 4,99 T17% 9,98
+func DefaultLayouts() []Layout {
+	return []Layout{
+		{
+			Name: "generic-single-line",
+			Items: []ItemPattern{{
+				Lines: []*regexp.Regexp{regexp.MustCompile(
+					`^` + seqPart + codePart + `(?P<desc>.+?)\s+` + qtyPart + unitPart + timesPart + unitPricePart + taxPart + totalPart)},
+				Confidence: 0.9,
+			}},
+		},
+		{
+			Name: "generic-two-lines",
+			Items: []ItemPattern{{
+				Lines: []*regexp.Regexp{
+					regexp.MustCompile(`^` + seqPart + codePart + `(?P<desc>\D.*?)$`),
+					regexp.MustCompile(`^` + qtyPart + unitPart + timesPart + unitPricePart + taxPart + totalPart),
+				},
+				Confidence: 0.85,
+			}},
+		},
+		{
+			// Cupons sem código: "LEITE UHT 1L 2 X 4,99 9,98"
+			Name: "generic-no-code",
+			Items: []ItemPattern{{
+				Lines: []*regexp.Regexp{regexp.MustCompile(
+					`^` + seqPart + `(?P<desc>\D.*?)\s+` + qtyPart + `(?:` + unitPart + `)?` + timesPart + unitPricePart + totalPart)},
+				Confidence: 0.7,
+			}},
+		},
+	}
+}
+
+// parseItems aplica os padrões do leiaute linha a linha
+func (layout Layout) parseItems(lines []string) []Item {
+	var items []Item
+	for index := 0; index < len(lines); {
+		item, consumed := layout.matchAt(lines, index)
+		if consumed == 0 {
+			index++
+			continue
+		}
+		items = append(items, item)
+		index += consumed
+	}
+	return items
+}
+
+// matchAt tenta cada padrão a partir da linha index; retorna o item e quantas linhas ele ocupa
+func (layout Layout) matchAt(lines []string, index int) (Item, int) {
+	for _, pattern := range layout.Items {
+		if index+len(pattern.Lines) > len(lines) {
+			continue
+		}
+		groups := make(map[string]string)
+		matched := true
+		for offset, expression := range pattern.Lines {
+			match := expression.FindStringSubmatch(lines[index+offset])
+			if match == nil {
+				matched = false
+				break
+			}
+			for i, name := range expression.SubexpNames() {
+				if name != "" && match[i] != "" {
+					groups[name] = match[i]
+				}
+			}
+		}
+		if matched && groups["desc"] != "" {
+			return buildItem(index+1, groups, pattern.Confidence), len(pattern.Lines)
+		}
+	}
+	return Item{}, 0
+}
+
+// buildItem converte os grupos capturados e calcula a confiança de cada campo.
+// Quantidade x preço unitário conferindo com o total confirma os três valores.
+func buildItem(line int, groups map[string]string, base float64) Item {
+	item := Item{
+		Line:        line,
+		Description: Field[string]{Value: strings.TrimSpace(groups["desc"]), Confidence: base},
+	}
+	if code := groups["code"]; code != "" {
+		item.Code = Field[string]{Value: code, Confidence: base}
+	}
+	if unit := groups["unit"]; unit != "" {
+		item.Unit = Field[string]{Value: strings.ToUpper(unit), Confidence: base}
+	}
+
+	item.Quantity = parseNumberField(groups["qty"], base)
+	item.UnitPrice = parseNumberField(groups["unitPrice"], base)
+	item.Total = parseNumberField(groups["total"], base)
+
+	switch {
+	case item.Quantity.Confidence > 0 && item.UnitPrice.Confidence > 0 && item.Total.Confidence > 0:
+		expected := item.Quantity.Value * item.UnitPrice.Value
+		if math.Abs(expected-item.Total.Value) <= 0.011 {
+			item.Quantity.Confidence = math.Min(1, base+0.1)
+			item.UnitPrice.Confidence = math.Min(1, base+0.1)
+			item.Total.Confidence = math.Min(1, base+0.1)
+		} else {
+			// Algum dos três foi lido errado (comum em OCR); não dá para saber qual
+			item.Quantity.Confidence = base / 2
+			item.UnitPrice.Confidence = base / 2
+			item.Total.Confidence = base / 2
+		}
+	case item.UnitPrice.Confidence > 0 && item.Total.Confidence == 0:
+		if item.Quantity.Confidence == 0 {
+			item.Quantity = Field[float64]{Value: 1, Confidence: base / 2}
+		}
+		item.Total = Field[float64]{Value: utils.FormatForDisplay(item.Quantity.Value * item.UnitPrice.Value), Confidence: base / 2}
+	case item.Total.Confidence > 0 && item.UnitPrice.Confidence == 0:
+		if item.Quantity.Confidence == 0 {
+			item.Quantity = Field[float64]{Value: 1, Confidence: base / 2}
+		}
+		item.UnitPrice = Field[float64]{Value: utils.FormatDecimal(item.Total.Value / item.Quantity.Value), Confidence: base / 2}
+	}
+
+	item.Confidence = math.Min(item.Quantity.Confidence, math.Min(item.UnitPrice.Confidence, item.Total.Confidence))
+	return item
+}
+
+// parseNumberField converte um valor numérico capturado; ausente ou inválido tem confiança zero
+func parseNumberField(value string, confidence float64) Field[float64] {
+	if value == "" {
+		return Field[float64]{}
+	}
+	parsed, err := utils.ParseDecimal(value)
+	if err != nil || parsed <= 0 {
+		return Field[float64]{}
+	}
+	return Field[float64]{Value: parsed, Confidence: confidence}
+}
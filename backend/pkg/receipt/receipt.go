@@ -0,0 +1,262 @@
+// Package receipt extrai loja, data e itens do texto de cupons fiscais (colado ou obtido por OCR).
+// Cada campo extraído vem com uma confiança entre 0 e 1. Os leiautes são regras declarativas:
+// redes com formato próprio podem registrar um Layout específico, detectado pelo cabeçalho do cupom.
+package receipt
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Parron01/AppMercado/backend/pkg/utils"
+)
+
+// Field é um valor extraído do cupom e a confiança da extração (0 = ausente, 1 = certeza)
+type Field[T any] struct {
+	Value      T
+	Confidence float64
+}
+
+// Item é uma linha de produto do cupom
+type Item struct {
+	Line        int // Linha (1-based) onde o item começa no texto
+	Code        Field[string]
+	Description Field[string]
+	Quantity    Field[float64]
+	Unit        Field[string]
+	UnitPrice   Field[float64]
+	Total       Field[float64]
+	Confidence  float64 // Menor confiança entre os campos numéricos do item
+}
+
+// Receipt é o resultado da leitura de um cupom
+type Receipt struct {
+	Layout   string
+	Store    Field[string]
+	CNPJ     Field[string]
+	Date     Field[time.Time]
+	Items    []Item
+	Total    Field[float64]
+	Warnings []string
+}
+
+// Parser lê cupons usando os leiautes registrados
+type Parser struct {
+	layouts  []Layout
+	location *time.Location // Fuso da data impressa no cupom (horário da loja)
+}
+
+// NewParser cria um parser com os leiautes informados; sem leiautes, usa DefaultLayouts.
+// As datas são lidas em UTC até que SetLocation informe o fuso das lojas.
+func NewParser(layouts ...Layout) *Parser {
+	if len(layouts) == 0 {
+		layouts = DefaultLayouts()
+	}
+	return &Parser{layouts: layouts, location: time.UTC}
+}
+
+// SetLocation define o fuso em que a data e a hora impressas no cupom são interpretadas
+func (parser *Parser) SetLocation(location *time.Location) {
+	parser.location = location
+}
+
+// Register acrescenta um leiaute (por exemplo, de uma rede específica). Leiautes com Detect
+// têm prioridade sobre os genéricos quando o cabeçalho do cupom corresponde.
+func (parser *Parser) Register(layout Layout) {
+	parser.layouts = append(parser.layouts, layout)
+}
+
+// Expressões dos campos de cabeçalho e rodapé, comuns a todos os leiautes
+var (
+	cnpjPattern     = regexp.MustCompile(`(?i)CNPJ\s*:?\s*(\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2})`)
+	dateTimePattern = regexp.MustCompile(`\b(\d{2}/\d{2}/\d{4})\s+(\d{2}:\d{2}(?::\d{2})?)\b`)
+	datePattern     = regexp.MustCompile(`\b(\d{2}/\d{2}/(?:\d{4}|\d{2}))\b`)
+	totalPattern    = regexp.MustCompile(`(?i)^\s*(?:VALOR\s+)?(?:TOTAL|A\s+PAGAR)\s*(?:R\$)?\s*:?\s*(?:R\$)?\s*(\d{1,3}(?:\.\d{3})*,\d{2}|\d+[.,]\d{2})\s*$`)
+	lettersPattern  = regexp.MustCompile(`\p{L}`)
+)
+
+// Parse lê o texto do cupom. O leiaute escolhido é o que reconhece mais itens, com prioridade
+// para leiautes específicos detectados no texto.
+func (parser *Parser) Parse(text string) *Receipt {
+	lines := normalizeLines(text)
+
+	receipt := &Receipt{}
+	parseHeader(lines, receipt, parser.location)
+	parseTotal(lines, receipt)
+
+	bestScore := -1.0
+	for _, layout := range parser.layouts {
+		specific := layout.Detect != nil
+		if specific && !layout.Detect.MatchString(text) {
+			continue
+		}
+		items := layout.parseItems(lines)
+		score := float64(len(items))
+		if specific {
+			score += 0.5 // Desempate a favor do leiaute da rede
+		}
+		if score > bestScore {
+			bestScore = score
+			receipt.Layout = layout.Name
+			receipt.Items = items
+		}
+	}
+
+	crossCheckTotal(receipt)
+	return receipt
+}
+
+// normalizeLines separa as linhas, troca tabs por espaços e colapsa espaços repetidos
+func normalizeLines(text string) []string {
+	rawLines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	lines := make([]string, len(rawLines))
+	for i, line := range rawLines {
+		lines[i] = strings.Join(strings.Fields(strings.ReplaceAll(line, "\t", " ")), " ")
+	}
+	return lines
+}
+
+// parseHeader extrai loja, CNPJ e data de emissão (no fuso informado)
+func parseHeader(lines []string, receipt *Receipt, location *time.Location) {
+	cnpjLine := -1
+	for i, line := range lines {
+		match := cnpjPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		digits := onlyDigits(match[1])
+		receipt.CNPJ = Field[string]{Value: digits, Confidence: 0.5}
+		if validCNPJ(digits) {
+			receipt.CNPJ.Confidence = 1
+		}
+		cnpjLine = i
+		break
+	}
+
+	// Loja: primeira linha predominantemente alfabética do cabeçalho (antes do CNPJ)
+	headerEnd := len(lines)
+	if cnpjLine >= 0 {
+		headerEnd = cnpjLine
+	}
+	for _, line := range lines[:min(headerEnd, 6)] {
+		if line == "" || letterRatio(line) < 0.6 {
+			continue
+		}
+		receipt.Store = Field[string]{Value: line, Confidence: 0.5}
+		if cnpjLine >= 0 {
+			receipt.Store.Confidence = 0.8
+		}
+		break
+	}
+
+	for _, line := range lines {
+		if match := dateTimePattern.FindStringSubmatch(line); match != nil {
+			layout := "02/01/2006 15:04:05"
+			if len(match[2]) == 5 {
+				layout = "02/01/2006 15:04"
+			}
+			if date, err := time.ParseInLocation(layout, match[1]+" "+match[2], location); err == nil {
+				receipt.Date = Field[time.Time]{Value: date, Confidence: 0.95}
+				return
+			}
+		}
+	}
+	for _, line := range lines {
+		if match := datePattern.FindStringSubmatch(line); match != nil {
+			layout, confidence := "02/01/2006", 0.8
+			if len(match[1]) == 8 {
+				layout, confidence = "02/01/06", 0.6
+			}
+			if date, err := time.ParseInLocation(layout, match[1], location); err == nil {
+				receipt.Date = Field[time.Time]{Value: date, Confidence: confidence}
+				return
+			}
+		}
+	}
+	receipt.Warnings = append(receipt.Warnings, "data de emissão não encontrada")
+}
+
+// parseTotal extrai o valor total do rodapé (última ocorrência, que costuma ser o valor a pagar)
+func parseTotal(lines []string, receipt *Receipt) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		match := totalPattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		if total, err := utils.ParseDecimal(match[1]); err == nil {
+			receipt.Total = Field[float64]{Value: total, Confidence: 0.8}
+			return
+		}
+	}
+}
+
+// crossCheckTotal compara o total do cupom com a soma dos itens e ajusta as confianças
+func crossCheckTotal(receipt *Receipt) {
+	if len(receipt.Items) == 0 {
+		receipt.Warnings = append(receipt.Warnings, "nenhum item reconhecido")
+		return
+	}
+	var sum float64
+	for _, item := range receipt.Items {
+		sum += item.Total.Value
+	}
+	sum = utils.FormatForDisplay(sum)
+
+	switch {
+	case receipt.Total.Confidence == 0:
+		receipt.Total = Field[float64]{Value: sum, Confidence: 0.5}
+		receipt.Warnings = append(receipt.Warnings, "total não encontrado; usada a soma dos itens")
+	case math.Abs(receipt.Total.Value-sum) < 0.01:
+		receipt.Total.Confidence = 1
+	case receipt.Total.Value < sum:
+		// Total menor que a soma costuma indicar descontos no rodapé
+		receipt.Total.Confidence = 0.7
+		receipt.Warnings = append(receipt.Warnings, "total do cupom menor que a soma dos itens (possível desconto)")
+	default:
+		receipt.Total.Confidence = 0.4
+		receipt.Warnings = append(receipt.Warnings, "soma dos itens não confere com o total; pode haver itens não reconhecidos")
+	}
+}
+
+// letterRatio é a fração de caracteres não brancos que são letras
+func letterRatio(line string) float64 {
+	compact := strings.ReplaceAll(line, " ", "")
+	if compact == "" {
+		return 0
+	}
+	return float64(len(lettersPattern.FindAllString(compact, -1))) / float64(len([]rune(compact)))
+}
+
+// validCNPJ confere os dígitos verificadores do CNPJ
+func validCNPJ(cnpj string) bool {
+	if len(cnpj) != 14 || strings.Count(cnpj, cnpj[:1]) == 14 {
+		return false
+	}
+	checkDigit := func(length int) byte {
+		sum, weight := 0, length-7
+		for i := 0; i < length; i++ {
+			sum += int(cnpj[i]-'0') * weight
+			weight--
+			if weight < 2 {
+				weight = 9
+			}
+		}
+		digit := 11 - sum%11
+		if digit >= 10 {
+			digit = 0
+		}
+		return byte('0' + digit)
+	}
+	return cnpj[12] == checkDigit(12) && cnpj[13] == checkDigit(13)
+}
+
+func onlyDigits(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
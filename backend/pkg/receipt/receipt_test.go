@@ -0,0 +1,110 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regrava os arquivos .golden.json com o resultado atual")
+
+// TestParseGolden lê um cupom de exemplo por leiaute de DefaultLayouts e compara o resultado
+// completo, inclusive as confianças, com testdata/<leiaute>.golden.json
+func TestParseGolden(t *testing.T) {
+	for _, layout := range DefaultLayouts() {
+		t.Run(layout.Name, func(t *testing.T) {
+			text, err := os.ReadFile(filepath.Join("testdata", layout.Name+".txt"))
+			if err != nil {
+				t.Fatalf("cupom de exemplo do leiaute: %v", err)
+			}
+			receipt := NewParser().Parse(string(text))
+			if receipt.Layout != layout.Name {
+				t.Errorf("leiaute escolhido = %q, esperado %q", receipt.Layout, layout.Name)
+			}
+
+			got, err := json.MarshalIndent(receipt, "", "  ")
+			if err != nil {
+				t.Fatalf("json.MarshalIndent: %v", err)
+			}
+			got = append(got, '\n')
+
+			goldenPath := filepath.Join("testdata", layout.Name+".golden.json")
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+					t.Fatalf("gravando %s: %v", goldenPath, err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("lendo %s (rode com -update para criá-lo): %v", goldenPath, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("resultado difere de %s:\n%s", goldenPath, got)
+			}
+		})
+	}
+}
+
+// TestParseDetectedLayout confere que um leiaute de rede detectado no cabeçalho vence os genéricos
+func TestParseDetectedLayout(t *testing.T) {
+	text, err := os.ReadFile(filepath.Join("testdata", "generic-single-line.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser()
+	chain := DefaultLayouts()[0]
+	chain.Name, chain.Detect = "bom-preco", cnpjPattern
+	parser.Register(chain)
+
+	if receipt := parser.Parse(string(text)); receipt.Layout != "bom-preco" {
+		t.Fatalf("leiaute escolhido = %q, esperado bom-preco", receipt.Layout)
+	}
+}
+
+// TestParseDateLocation confere que a data do cupom é lida no fuso configurado, não no do servidor
+func TestParseDateLocation(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	parser := NewParser()
+	parser.SetLocation(saoPaulo)
+
+	receipt := parser.Parse("MERCADO BOM PRECO\nCNPJ: 11.222.333/0001-81\nEmissao: 05/03/2025 21:30:00\n")
+	want := time.Date(2025, 3, 6, 0, 30, 0, 0, time.UTC)
+	if !receipt.Date.Value.Equal(want) {
+		t.Errorf("data = %s, esperado %s", receipt.Date.Value, want)
+	}
+	if receipt.Date.Value.Location() != saoPaulo {
+		t.Errorf("fuso = %s, esperado %s", receipt.Date.Value.Location(), saoPaulo)
+	}
+}
+
+// TestParseThousandsSeparator confere preços e totais com separador de milhar
+func TestParseThousandsSeparator(t *testing.T) {
+	receipt := NewParser().Parse("MERCADO BOM PRECO\n" +
+		"001 7891000100103 TV LED 32 POL 1 UN X 1.234,56 T17% 1.234,56\n" +
+		"002 7891000100110 GELADEIRA 2 UN X 2.100,00 4.200,00\n" +
+		"TOTAL R$ 5.434,56\n")
+
+	if len(receipt.Items) != 2 {
+		t.Fatalf("%d itens lidos, esperado 2: %+v", len(receipt.Items), receipt.Items)
+	}
+	tests := []struct {
+		unitPrice, total float64
+	}{
+		{1234.56, 1234.56},
+		{2100, 4200},
+	}
+	for i, test := range tests {
+		item := receipt.Items[i]
+		if item.UnitPrice.Value != test.unitPrice || item.Total.Value != test.total || item.Confidence < 0.9 {
+			t.Errorf("item %d = preço %v, total %v (confiança %v); esperado %v e %v conferidos",
+				i+1, item.UnitPrice.Value, item.Total.Value, item.Confidence, test.unitPrice, test.total)
+		}
+	}
+	if receipt.Total.Value != 5434.56 {
+		t.Errorf("total = %v, esperado 5434.56", receipt.Total.Value)
+	}
+}
@@ -0,0 +1,122 @@
+// Package units trata as unidades de medida das embalagens (g, kg, ml, L, un) e a conversão para
+// a unidade em que o preço é comparado: kg para peso, L para volume e un para itens contados.
+package units
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit é uma unidade de medida aceita no cadastro de produtos
+type Unit string
+
+const (
+	Gram       Unit = "g"
+	Kilogram   Unit = "kg"
+	Milliliter Unit = "ml"
+	Liter      Unit = "L"
+	Each       Unit = "un"
+)
+
+// All lista as unidades aceitas, na ordem usada nas mensagens de erro
+var All = []Unit{Gram, Kilogram, Milliliter, Liter, Each}
+
+// aliases mapeia as grafias comuns (em minúsculas) para a unidade
+var aliases = map[string]Unit{
+	"g": Gram, "gr": Gram, "grs": Gram, "grama": Gram, "gramas": Gram,
+	"kg": Kilogram, "kgs": Kilogram, "quilo": Kilogram, "quilos": Kilogram,
+	"ml": Milliliter, "mililitro": Milliliter, "mililitros": Milliliter,
+	"l": Liter, "lt": Liter, "lts": Liter, "litro": Liter, "litros": Liter,
+	"un": Each, "und": Each, "unid": Each, "unidade": Each, "unidades": Each, "u": Each,
+}
+
+// Parse reconhece uma unidade, sem diferenciar maiúsculas ("KG", "Litro", "und")
+func Parse(value string) (Unit, bool) {
+	unit, ok := aliases[strings.ToLower(strings.TrimSpace(value))]
+	return unit, ok
+}
+
+// Names retorna as unidades aceitas separadas por vírgula
+func Names() string {
+	names := make([]string, len(All))
+	for i, unit := range All {
+		names[i] = string(unit)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Base retorna a unidade em que o preço é comparado (g -> kg, ml -> L)
+func (unit Unit) Base() Unit {
+	switch unit {
+	case Gram:
+		return Kilogram
+	case Milliliter:
+		return Liter
+	}
+	return unit
+}
+
+// ToBase converte uma quantidade na unidade para a unidade base (500 g -> 0,5 kg)
+func (unit Unit) ToBase(quantity float64) float64 {
+	switch unit {
+	case Gram, Milliliter:
+		return quantity / 1000
+	}
+	return quantity
+}
+
+// Measured indica se a unidade é de peso ou volume (e não de itens contados)
+func (unit Unit) Measured() bool {
+	return unit.Base() == Kilogram || unit.Base() == Liter
+}
+
+// Compatible indica se quantidades de uma unidade podem ser convertidas para a outra (g/kg, ml/L, un/un)
+func Compatible(a, b Unit) bool {
+	return a.Base() == b.Base()
+}
+
+// Convert converte uma quantidade entre unidades compatíveis (1500 g -> 1,5 kg)
+func Convert(quantity float64, from, to Unit) (float64, bool) {
+	if !Compatible(from, to) {
+		return 0, false
+	}
+	return from.ToBase(quantity) / to.ToBase(1), true
+}
+
+// PricePerBase calcula o preço por kg, L ou unidade de uma embalagem com a quantidade informada;
+// false quando a unidade não é reconhecida ou a quantidade não é positiva
+func PricePerBase(price, quantity float64, unit Unit) (float64, bool) {
+	if _, ok := Parse(string(unit)); !ok || quantity <= 0 {
+		return 0, false
+	}
+	return price / unit.ToBase(quantity), true
+}
+
+// labelPattern reconhece rótulos de embalagem como "500 g", "1,5L" ou "6 x 200 ml"
+var labelPattern = regexp.MustCompile(`(?i)^\s*(?:(\d+)\s*[x×]\s*)?(\d+(?:[.,]\d+)?)\s*([a-z]+)\.?\s*$`)
+
+// ParseLabel extrai a quantidade e a unidade do rótulo impresso na embalagem; embalagens múltiplas
+// ("6 x 200 ml") somam o conteúdo (1200 ml)
+func ParseLabel(label string) (float64, Unit, bool) {
+	match := labelPattern.FindStringSubmatch(label)
+	if match == nil {
+		return 0, "", false
+	}
+	unit, ok := Parse(match[3])
+	if !ok {
+		return 0, "", false
+	}
+	quantity, err := strconv.ParseFloat(strings.Replace(match[2], ",", ".", 1), 64)
+	if err != nil || quantity <= 0 {
+		return 0, "", false
+	}
+	if match[1] != "" {
+		count, err := strconv.Atoi(match[1])
+		if err != nil || count <= 0 {
+			return 0, "", false
+		}
+		quantity *= float64(count)
+	}
+	return quantity, unit, true
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDateLayouts são os formatos de data aceitos quando nenhum formato é informado
+var DefaultDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"02/01/06",
+}
+
+// ParseDecimal converte números escritos no padrão brasileiro ("1.234,56", "5,99", "R$ 5,99")
+// ou internacional ("1,234.56", "5.99") para float64
+func ParseDecimal(value string) (float64, error) {
+	cleaned := strings.TrimSpace(value)
+	cleaned = strings.TrimPrefix(cleaned, "R$")
+	cleaned = strings.ReplaceAll(cleaned, " ", "")
+	cleaned = strings.ReplaceAll(cleaned, "\u00a0", "") // Espaço não separável comum em planilhas
+	if cleaned == "" {
+		return 0, errors.New("valor numérico vazio")
+	}
+
+	lastComma := strings.LastIndex(cleaned, ",")
+	lastDot := strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		// O último separador é o decimal; o outro é separador de milhar
+		if lastComma > lastDot {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	case lastComma >= 0:
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	}
+
+	parsed, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, errors.New("valor numérico inválido: " + value)
+	}
+	return parsed, nil
+}
+
+// ParseDate tenta converter a data usando os formatos informados, na ordem, no fuso do servidor
+func ParseDate(value string, layouts []string) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if parsed, err := time.ParseInLocation(layout, trimmed, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("data inválida: " + value)
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// Quantile calcula o quantil q (0..1) com interpolação linear, equivalente ao percentile_cont do PostgreSQL.
+// Os valores não precisam estar ordenados; a fatia original não é alterada.
+func Quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+// Mean calcula a média aritmética
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// StdDev calcula o desvio padrão amostral (equivalente ao stddev_samp do PostgreSQL)
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := Mean(values)
+	var sumSquares float64
+	for _, value := range values {
+		sumSquares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)-1))
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accentFolding mapeia letras acentuadas comuns em português para a letra sem acento
+var accentFolding = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// NormalizeText prepara um texto livre para comparação: minúsculas, sem acentos, sem pontuação e
+// com espaços simples ("  Pão-de-Açúcar " -> "pao de acucar")
+func NormalizeText(value string) string {
+	var builder strings.Builder
+	space := false
+	for _, char := range strings.ToLower(value) {
+		if folded, ok := accentFolding[char]; ok {
+			char = folded
+		}
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteRune(char)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return builder.String()
+}
+
+// Similarity retorna a semelhança entre dois textos já normalizados, de 0 (nada em comum) a 1 (iguais),
+// calculada pela distância de edição (Levenshtein) relativa ao texto mais longo
+func Similarity(a, b string) float64 {
+	first, second := []rune(a), []rune(b)
+	longest := max(len(first), len(second))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(first, second))/float64(longest)
+}
+
+func levenshtein(first, second []rune) int {
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(second)]
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrInternalAddress indica um destino interno: loopback, rede privada, link-local (inclusive os serviços
+// de metadados de nuvem em 169.254.169.254) ou outra faixa reservada
+var ErrInternalAddress = errors.New("o destino do webhook é um endereço interno")
+
+// reservedPrefixes são faixas que não cabem nos métodos de netip.Addr, mas também não são a internet pública
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT; inclui o serviço de metadados 100.100.100.200
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64: leva a endereços IPv4 que não são conferidos aqui
+}
+
+// IsPublicAddress indica se o endereço pode receber entregas de webhook
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost resolve o host e recusa-o se algum dos endereços for interno
+func CheckHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddress(addr) {
+			return ErrInternalAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return errors.New("não foi possível resolver o host do webhook: " + host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddress(addr) {
+			return ErrInternalAddress
+		}
+	}
+	return nil
+}
+
+// NewHTTPClient cria o cliente das entregas. A conexão é recusada quando o endereço efetivamente
+// discado é interno, o que vale também para hosts que mudam de endereço depois do cadastro (DNS
+// rebinding); não há proxy nem redirecionamentos seguidos.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddress(addrPort.Addr()) {
+				return ErrInternalAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.0.10", false},
+		{"169.254.169.254", false}, // Metadados de AWS, GCP e Azure
+		{"100.100.100.200", false}, // Metadados da Alibaba Cloud
+		{"fd00:ec2::254", false},   // Metadados da AWS em IPv6
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false}, // IPv4 mapeado em IPv6
+		{"64:ff9b::a00:1", false},
+		{"224.0.0.1", false},
+	}
+	for _, test := range tests {
+		if got := IsPublicAddress(netip.MustParseAddr(test.address)); got != test.public {
+			t.Errorf("IsPublicAddress(%s) = %v, esperado %v", test.address, got, test.public)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "169.254.169.254", "::1", "localhost"} {
+		if err := CheckHost(context.Background(), host); !errors.Is(err, ErrInternalAddress) {
+			t.Errorf("CheckHost(%s) = %v, esperado ErrInternalAddress", host, err)
+		}
+	}
+	if err := CheckHost(context.Background(), "93.184.216.34"); err != nil {
+		t.Errorf("CheckHost de endereço público: %v", err)
+	}
+}
+
+// TestHTTPClientRefusesInternalAddress confere que a recusa vale na conexão, mesmo que a URL tenha
+// passado pela validação do cadastro
+func TestHTTPClientRefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a requisição não deveria chegar ao servidor local")
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPClient(time.Second).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrInternalAddress) {
+		t.Fatalf("erro = %v, esperado ErrInternalAddress", err)
+	}
+}
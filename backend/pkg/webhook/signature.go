@@ -0,0 +1,75 @@
+// Package webhook assina e verifica o corpo das entregas de webhook (HMAC-SHA256).
+//
+// Cada entrega traz o cabeçalho SignatureHeader no formato "t=<unix>,v1=<hex>", em que v1 é o
+// HMAC-SHA256 de "<t>.<corpo>" com o segredo da assinatura. Incluir o timestamp na assinatura
+// permite ao receptor recusar entregas antigas reenviadas por terceiros (replay).
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cabeçalhos enviados em cada entrega
+const (
+	SignatureHeader = "X-AppMercado-Signature"
+	EventHeader     = "X-AppMercado-Event"
+	DeliveryHeader  = "X-AppMercado-Delivery"
+)
+
+// Sign calcula o valor do cabeçalho de assinatura para o corpo no instante informado
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + computeMAC(secret, unix, body)
+}
+
+// Verify confere o cabeçalho de assinatura e recusa entregas com timestamp mais de tolerance no passado
+// ou no futuro (a mesma folga cobre a diferença entre os relógios; 0 = sem limite)
+func Verify(secret string, header string, body []byte, tolerance time.Duration) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+	if unix == "" || signature == "" {
+		return errors.New("cabeçalho de assinatura malformado")
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return errors.New("timestamp da assinatura inválido")
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(seconds, 0))
+		if age > tolerance {
+			return errors.New("assinatura expirada")
+		}
+		if age < -tolerance {
+			return errors.New("timestamp da assinatura no futuro")
+		}
+	}
+
+	expected := computeMAC(secret, unix, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("assinatura não confere")
+	}
+	return nil
+}
+
+func computeMAC(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+	tests := []struct {
+		name    string
+		header  string
+		wantErr string
+	}{
+		{"assinatura válida", Sign("segredo", now, body), ""},
+		{"segredo errado", Sign("outro", now, body), "assinatura não confere"},
+		{"dentro da tolerância", Sign("segredo", now.Add(-4*time.Minute), body), ""},
+		{"expirada", Sign("segredo", now.Add(-6*time.Minute), body), "assinatura expirada"},
+		{"relógio pouco adiantado", Sign("segredo", now.Add(time.Minute), body), ""},
+		{"timestamp no futuro", Sign("segredo", now.Add(time.Hour), body), "timestamp da assinatura no futuro"},
+		{"cabeçalho malformado", "v1=abc", "cabeçalho de assinatura malformado"},
+		{"timestamp inválido", "t=agora,v1=abc", "timestamp da assinatura inválido"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify("segredo", test.header, body, 5*time.Minute)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Fatalf("erro inesperado: %v", err)
+			case test.wantErr != "" && (err == nil || err.Error() != test.wantErr):
+				t.Fatalf("erro = %v, esperado %q", err, test.wantErr)
+			}
+		})
+	}
+}